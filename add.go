@@ -0,0 +1,119 @@
+//go:build !bm25md_noparser
+
+package bm25md
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DocumentOption sets attributes of a document added with AddMarkdown or AddFile
+type DocumentOption func(*Document)
+
+// WithExternalID sets the document's ExternalID, replacing any document with the same one
+func WithExternalID(id string) DocumentOption {
+	return func(d *Document) {
+		d.ExternalID = id
+	}
+}
+
+// WithMetadata adds metadata to the document
+func WithMetadata(metadata map[string]any) DocumentOption {
+	return func(d *Document) {
+		if d.Metadata == nil {
+			d.Metadata = make(map[string]any, len(metadata))
+		}
+		maps.Copy(d.Metadata, metadata)
+	}
+}
+
+// WithBoost sets the document's score boost
+func WithBoost(boost float64) DocumentOption {
+	return func(d *Document) {
+		d.Boost = boost
+	}
+}
+
+// WithTimestamp sets the document's timestamp, used by recency decay
+func WithTimestamp(timestamp time.Time) DocumentOption {
+	return func(d *Document) {
+		d.Timestamp = timestamp
+	}
+}
+
+// defaultParser parses documents for corpora without WithFieldParser
+var defaultParser = sync.OnceValue(func() *MarkdownFieldParser {
+	return NewMarkdownFieldParser()
+})
+
+// fieldParser returns the parser for added documents
+func (c *Corpus) fieldParser() FieldParser {
+	if c.parser != nil {
+		return c.parser
+	}
+	return defaultParser()
+}
+
+// AddMarkdown parses content with the corpus's field parser and indexes it,
+// returning the document's ID
+func (c *Corpus) AddMarkdown(content string, opts ...DocumentOption) int {
+	doc := Document{
+		Fields:   c.fieldParser().ParseDocument(content),
+		Original: content,
+	}
+	for _, opt := range opts {
+		opt(&doc)
+	}
+	return c.addParsed(doc)
+}
+
+// AddFile reads, parses, and indexes the file at path, returning the document's
+// ID. The path is its ExternalID (so re-adding a file replaces it) and its
+// MetaFilePath; its file name and directory are indexed (see PathFields) and
+// front matter dates become its Timestamp, as with IndexDir. Without
+// WithFieldParser, .html and .txt files are parsed as HTML and plain text
+func (c *Corpus) AddFile(path string, opts ...DocumentOption) (int, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return 0, fmt.Errorf("bm25md: reading %s: %w", path, err)
+	}
+
+	parser := c.fieldParser()
+	if c.parser == nil {
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".html", ".htm":
+			parser = HTMLFieldParser{}
+		case ".txt":
+			parser = PlainTextFieldParser{}
+		}
+	}
+
+	fields := parser.ParseDocument(string(content))
+	maps.Copy(fields, PathFields(path))
+	doc := Document{
+		ExternalID: path,
+		Fields:     fields,
+		Original:   string(content),
+		Metadata:   map[string]any{MetaFilePath: path},
+		Timestamp:  fileTimestamp(string(content)),
+	}
+	for _, opt := range opts {
+		opt(&doc)
+	}
+	return c.addParsed(doc), nil
+}
+
+// addParsed adds a document and returns its ID, which is reused when the
+// document replaces one with the same ExternalID
+func (c *Corpus) addParsed(doc Document) int {
+	c.AddDocument(doc)
+	if id, exists := c.LookupID(doc.ExternalID); exists {
+		return id
+	}
+	return len(c.documents) - 1
+}
@@ -0,0 +1,94 @@
+//go:build !bm25md_noparser
+
+package bm25md
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCorpus_AddMarkdown(t *testing.T) {
+	corpus := NewCorpus()
+	id := corpus.AddMarkdown("# Habeas Corpus\n\nPetitions for release.", WithExternalID("habeas"), WithBoost(2))
+	corpus.AddMarkdown("Court calendar.")
+	corpus.AddMarkdown("Filing deadlines.")
+	corpus.AddMarkdown("Appeal rules.")
+
+	if id != 0 {
+		t.Errorf("AddMarkdown() ID = %d, want 0", id)
+	}
+	doc := corpus.Documents()[id]
+	if doc.Fields[FieldH1] != "Habeas Corpus" || doc.ExternalID != "habeas" || doc.Boost != 2 {
+		t.Errorf("document = %+v, want parsed fields and options applied", doc)
+	}
+
+	// re-adding an external ID replaces the document in place
+	if id := corpus.AddMarkdown("# Habeas Petitions", WithExternalID("habeas")); id != 0 {
+		t.Errorf("replacing AddMarkdown() ID = %d, want 0", id)
+	}
+	if id := corpus.AddMarkdown("Judge assignments."); id != 4 {
+		t.Errorf("AddMarkdown() ID = %d, want 4", id)
+	}
+	if results := corpus.Search("petitions", 1); len(results) != 1 || results[0].ExternalID != "habeas" {
+		t.Errorf("Search(petitions) = %+v, want the replaced document", results)
+	}
+
+	html := NewCorpus(WithFieldParser(HTMLFieldParser{}))
+	html.AddMarkdown("<h1>Habeas Corpus</h1>")
+	if got := html.Documents()[0].Fields[FieldH1]; got != "Habeas Corpus" {
+		t.Errorf("h1 with HTML parser = %q", got)
+	}
+}
+
+func TestCorpus_AddFile(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"habeas-corpus.md": "---\ndate: 2024-03-01\n---\n# Petitions\n\nFile early.",
+		"rules.html":       "<title>Court Rules</title><p>Serve the respondent.</p>",
+		"notes.txt":        "# plain notes",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	corpus := NewCorpus()
+	path := filepath.Join(dir, "habeas-corpus.md")
+	id, err := corpus.AddFile(path, WithMetadata(map[string]any{"court": "federal"}))
+	if err != nil {
+		t.Fatalf("AddFile() error = %v", err)
+	}
+	doc := corpus.Documents()[id]
+	if doc.ExternalID != path || doc.Metadata[MetaFilePath] != path || doc.Metadata["court"] != "federal" {
+		t.Errorf("document = %+v, want path IDs and merged metadata", doc)
+	}
+	if doc.Fields[FieldFileName] != "habeas corpus" || doc.Fields[FieldH1] != "Petitions" {
+		t.Errorf("fields = %v, want file name and parsed markdown", doc.Fields)
+	}
+	if !doc.Timestamp.Equal(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("timestamp = %v, want front matter date", doc.Timestamp)
+	}
+
+	id, err = corpus.AddFile(filepath.Join(dir, "rules.html"))
+	if err != nil {
+		t.Fatalf("AddFile() error = %v", err)
+	}
+	if got := corpus.Documents()[id].Fields[FieldTitle]; got != "Court Rules" {
+		t.Errorf("html title = %q, want Court Rules", got)
+	}
+
+	id, err = corpus.AddFile(filepath.Join(dir, "notes.txt"))
+	if err != nil {
+		t.Fatalf("AddFile() error = %v", err)
+	}
+	if got := corpus.Documents()[id].Fields[FieldBody]; got != "# plain notes" {
+		t.Errorf("text body = %q, want the raw text", got)
+	}
+
+	if _, err := corpus.AddFile(filepath.Join(dir, "missing.md")); err == nil {
+		t.Error("AddFile() of a missing file succeeded")
+	}
+}
@@ -0,0 +1,298 @@
+package bm25md
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// CharFilter rewrites raw text before it is tokenized (eg stripping markup)
+type CharFilter interface {
+	Filter(text string) string
+}
+
+// CharFilterFunc is a func adapter that allows using functions as CharFilters
+type CharFilterFunc func(string) string
+
+// Filter implements the CharFilter interface for function types
+func (f CharFilterFunc) Filter(text string) string {
+	return f(text)
+}
+
+// TokenFilter transforms, drops, or adds tokens after tokenization
+type TokenFilter interface {
+	Filter(tokens []string) []string
+}
+
+// TokenFilterFunc is a func adapter that allows using functions as TokenFilters
+type TokenFilterFunc func([]string) []string
+
+// Filter implements the TokenFilter interface for function types
+func (f TokenFilterFunc) Filter(tokens []string) []string {
+	return f(tokens)
+}
+
+// Analyzer turns text into index terms in three stages: character filters
+// rewrite the raw text, the tokenizer splits it, and token filters normalize
+// the resulting tokens. An Analyzer is itself a Tokenizer
+type Analyzer struct {
+	CharFilters  []CharFilter
+	Tokenizer    Tokenizer // defaults to UnicodeTokenizer
+	TokenFilters []TokenFilter
+}
+
+// Tokenize implements the Tokenizer interface by running the full pipeline
+func (a Analyzer) Tokenize(text string) []string {
+	for _, filter := range a.CharFilters {
+		text = filter.Filter(text)
+	}
+
+	tokenizer := a.Tokenizer
+	if tokenizer == nil {
+		tokenizer = UnicodeTokenizer{}
+	}
+	tokens := tokenizer.Tokenize(text)
+
+	for _, filter := range a.TokenFilters {
+		tokens = filter.Filter(tokens)
+	}
+	return tokens
+}
+
+// WithAnalyzer sets the analysis pipeline used for indexed content and queries
+func WithAnalyzer(analyzer Analyzer) CorpusOption {
+	return WithTokenizer(analyzer)
+}
+
+// StandardAnalyzer returns an analyzer that splits on non-alphanumeric characters,
+// folds accents, lowercases, and drops tokens shorter than three characters
+func StandardAnalyzer() Analyzer {
+	return Analyzer{
+		Tokenizer: UnicodeTokenizer{},
+		TokenFilters: []TokenFilter{
+			LowercaseFilter(),
+			ASCIIFoldingFilter(),
+			MinLengthFilter(3),
+		},
+	}
+}
+
+// EnglishAnalyzer extends StandardAnalyzer with English stopwords and minimal stemming
+func EnglishAnalyzer() Analyzer {
+	analyzer := StandardAnalyzer()
+	analyzer.TokenFilters = append(analyzer.TokenFilters,
+		StopwordFilter(EnglishStopwords...),
+		EnglishMinimalStemFilter(),
+	)
+	return analyzer
+}
+
+// UnicodeTokenizer splits text on anything that is not a letter or digit,
+// keeping case and all token lengths (leave normalization to token filters)
+type UnicodeTokenizer struct{}
+
+// Tokenize implements the Tokenizer interface
+func (UnicodeTokenizer) Tokenize(text string) []string {
+	return strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// PatternReplaceCharFilter replaces every match of pattern with replacement
+func PatternReplaceCharFilter(pattern *regexp.Regexp, replacement string) CharFilter {
+	return CharFilterFunc(func(text string) string {
+		return pattern.ReplaceAllString(text, replacement)
+	})
+}
+
+// MappingCharFilter replaces each key of mapping found in the text with its value
+func MappingCharFilter(mapping map[string]string) CharFilter {
+	pairs := make([]string, 0, len(mapping)*2)
+	for from, to := range mapping {
+		pairs = append(pairs, from, to)
+	}
+	replacer := strings.NewReplacer(pairs...)
+	return CharFilterFunc(replacer.Replace)
+}
+
+// mapTokens applies fn to each token, dropping tokens it maps to ""
+func mapTokens(tokens []string, fn func(string) string) []string {
+	out := make([]string, 0, len(tokens))
+	for _, token := range tokens {
+		if token = fn(token); token != "" {
+			out = append(out, token)
+		}
+	}
+	return out
+}
+
+// LowercaseFilter lowercases tokens
+func LowercaseFilter() TokenFilter {
+	return TokenFilterFunc(func(tokens []string) []string {
+		return mapTokens(tokens, strings.ToLower)
+	})
+}
+
+// MinLengthFilter drops tokens shorter than n characters
+func MinLengthFilter(n int) TokenFilter {
+	return TokenFilterFunc(func(tokens []string) []string {
+		return mapTokens(tokens, func(token string) string {
+			if utf8.RuneCountInString(token) < n {
+				return ""
+			}
+			return token
+		})
+	})
+}
+
+// StopwordFilter drops the given words (compared case-insensitively)
+func StopwordFilter(words ...string) TokenFilter {
+	stopwords := make(map[string]bool, len(words))
+	for _, word := range words {
+		stopwords[strings.ToLower(word)] = true
+	}
+	return TokenFilterFunc(func(tokens []string) []string {
+		return mapTokens(tokens, func(token string) string {
+			if stopwords[strings.ToLower(token)] {
+				return ""
+			}
+			return token
+		})
+	})
+}
+
+// SynonymFilter follows each token that has synonyms with them ("car" ->
+// "car", "automobile"), typically in a query analyzer so the index is unchanged.
+// Synonyms would take positions of their own between the words of a phrase, so
+// a query analyzer skips them for quoted phrases and NEAR operands
+func SynonymFilter(synonyms map[string][]string) TokenFilter {
+	return synonymFilter(synonyms)
+}
+
+// synonymFilter is the TokenFilter returned by SynonymFilter
+type synonymFilter map[string][]string
+
+// Filter implements the TokenFilter interface
+func (s synonymFilter) Filter(tokens []string) []string {
+	out := make([]string, 0, len(tokens))
+	for _, token := range tokens {
+		out = append(out, token)
+		out = append(out, s[token]...)
+	}
+	return out
+}
+
+// withoutSynonyms returns the analyzer without its synonym filters
+func (a Analyzer) withoutSynonyms() Analyzer {
+	filters := make([]TokenFilter, 0, len(a.TokenFilters))
+	for _, filter := range a.TokenFilters {
+		if _, synonyms := filter.(synonymFilter); !synonyms {
+			filters = append(filters, filter)
+		}
+	}
+	a.TokenFilters = filters
+	return a
+}
+
+// StemFilter reduces tokens with a caller-supplied stemmer (eg a Snowball binding)
+func StemFilter(stem func(string) string) TokenFilter {
+	return TokenFilterFunc(func(tokens []string) []string {
+		return mapTokens(tokens, stem)
+	})
+}
+
+// EnglishMinimalStemFilter strips English plural endings ("writs" -> "writ",
+// "parties" -> "party"), a conservative stemmer that rarely conflates unrelated words
+func EnglishMinimalStemFilter() TokenFilter {
+	return StemFilter(englishMinimalStem)
+}
+
+// englishMinimalStem removes plural suffixes from a lowercase token
+func englishMinimalStem(token string) string {
+	if len(token) < 3 || token[len(token)-1] != 's' {
+		return token
+	}
+	switch {
+	case strings.HasSuffix(token, "us") || strings.HasSuffix(token, "ss"):
+		return token
+	case strings.HasSuffix(token, "ies") && len(token) > 3 && !strings.HasSuffix(token, "eies") && !strings.HasSuffix(token, "aies"):
+		return token[:len(token)-3] + "y"
+	case strings.HasSuffix(token, "ies") || strings.HasSuffix(token, "aes") ||
+		strings.HasSuffix(token, "oes") || strings.HasSuffix(token, "ees"):
+		return token
+	}
+	return token[:len(token)-1]
+}
+
+// ASCIIFoldingFilter replaces accented Latin letters with their ASCII equivalents
+// ("café" -> "cafe"), so queries match with or without diacritics
+func ASCIIFoldingFilter() TokenFilter {
+	return TokenFilterFunc(func(tokens []string) []string {
+		return mapTokens(tokens, foldASCII)
+	})
+}
+
+// foldASCII folds the accented Latin letters in s to ASCII
+func foldASCII(s string) string {
+	ascii := true
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			ascii = false
+			break
+		}
+	}
+	if ascii {
+		return s
+	}
+
+	var b strings.Builder
+	b.Grow(len(s))
+	for _, r := range s {
+		if folded, ok := asciiFolding[r]; ok {
+			b.WriteString(folded)
+		} else {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// asciiFolding maps accented Latin letters to ASCII
+var asciiFolding = buildASCIIFolding(map[string]string{
+	"a": "àáâãäåāăą", "A": "ÀÁÂÃÄÅĀĂĄ",
+	"c": "çćĉċč", "C": "ÇĆĈĊČ",
+	"d": "ďđ", "D": "ĎĐ",
+	"e": "èéêëēĕėęě", "E": "ÈÉÊËĒĔĖĘĚ",
+	"g": "ĝğġģ", "G": "ĜĞĠĢ",
+	"h": "ĥħ", "H": "ĤĦ",
+	"i": "ìíîïĩīĭįı", "I": "ÌÍÎÏĨĪĬĮİ",
+	"j": "ĵ", "J": "Ĵ",
+	"k": "ķ", "K": "Ķ",
+	"l": "ĺļľŀł", "L": "ĹĻĽĿŁ",
+	"n": "ñńņňŉ", "N": "ÑŃŅŇ",
+	"o": "òóôõöøōŏő", "O": "ÒÓÔÕÖØŌŎŐ",
+	"r": "ŕŗř", "R": "ŔŖŘ",
+	"s": "śŝşš", "S": "ŚŜŞŠ",
+	"t": "ţťŧ", "T": "ŢŤŦ",
+	"u": "ùúûüũūŭůűų", "U": "ÙÚÛÜŨŪŬŮŰŲ",
+	"w": "ŵ", "W": "Ŵ",
+	"y": "ýÿŷ", "Y": "ÝŸŶ",
+	"z": "źżž", "Z": "ŹŻŽ",
+	"ae": "æ", "AE": "Æ",
+	"oe": "œ", "OE": "Œ",
+	"ss": "ß",
+	"th": "þ", "TH": "Þ",
+	"dh": "ð", "DH": "Ð",
+})
+
+// buildASCIIFolding inverts a table of ASCII replacements to accented letters
+func buildASCIIFolding(table map[string]string) map[rune]string {
+	folding := make(map[rune]string)
+	for ascii, accented := range table {
+		for _, r := range accented {
+			folding[r] = ascii
+		}
+	}
+	return folding
+}
@@ -0,0 +1,217 @@
+package bm25md
+
+import (
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestAnalyzer_Tokenize(t *testing.T) {
+	tests := []struct {
+		name     string
+		analyzer Analyzer
+		input    string
+		expected []string
+	}{
+		{
+			name:     "empty analyzer splits on unicode boundaries",
+			analyzer: Analyzer{},
+			input:    "Café au lait, s'il vous plaît",
+			expected: []string{"Café", "au", "lait", "s", "il", "vous", "plaît"},
+		},
+		{
+			name:     "standard analyzer",
+			analyzer: StandardAnalyzer(),
+			input:    "Café au lait, s'il vous plaît",
+			expected: []string{"cafe", "lait", "vous", "plait"},
+		},
+		{
+			name:     "english analyzer",
+			analyzer: EnglishAnalyzer(),
+			input:    "The Courts issued writs to the parties",
+			expected: []string{"court", "issued", "writ", "party"},
+		},
+		{
+			name: "char filters run before tokenizing",
+			analyzer: Analyzer{
+				CharFilters: []CharFilter{
+					PatternReplaceCharFilter(regexp.MustCompile(`<[^>]+>`), " "),
+					MappingCharFilter(map[string]string{"&amp;": "and"}),
+				},
+				TokenFilters: []TokenFilter{LowercaseFilter()},
+			},
+			input:    "<p>Bench &amp; <b>Bar</b></p>",
+			expected: []string{"bench", "and", "bar"},
+		},
+		{
+			name: "custom tokenizer and stemmer",
+			analyzer: Analyzer{
+				Tokenizer:    TokenizerFunc(strings.Fields),
+				TokenFilters: []TokenFilter{StemFilter(strings.ToUpper)},
+			},
+			input:    "habeas-corpus writ",
+			expected: []string{"HABEAS-CORPUS", "WRIT"},
+		},
+		{
+			name: "synonyms follow their token",
+			analyzer: Analyzer{
+				TokenFilters: []TokenFilter{
+					LowercaseFilter(),
+					SynonymFilter(map[string][]string{"car": {"automobile", "vehicle"}}),
+				},
+			},
+			input:    "Car rental",
+			expected: []string{"car", "automobile", "vehicle", "rental"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.analyzer.Tokenize(tt.input); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Tokenize(%q) = %v, want %v", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestEnglishMinimalStem(t *testing.T) {
+	tests := map[string]string{
+		"writs":   "writ",
+		"parties": "party",
+		"cases":   "case",
+		"class":   "class",
+		"corpus":  "corpus",
+		"is":      "is",
+		"trees":   "trees",
+		"heroes":  "heroes",
+		"appeal":  "appeal",
+	}
+	for input, expected := range tests {
+		if got := englishMinimalStem(input); got != expected {
+			t.Errorf("englishMinimalStem(%q) = %q, want %q", input, got, expected)
+		}
+	}
+}
+
+func TestFoldASCII(t *testing.T) {
+	tests := map[string]string{
+		"plain":      "plain",
+		"naïve":      "naive",
+		"Ærøskøbing": "AEroskobing",
+		"straße":     "strasse",
+		"Łódź":       "Lodz",
+		"東京":         "東京",
+	}
+	for input, expected := range tests {
+		if got := foldASCII(input); got != expected {
+			t.Errorf("foldASCII(%q) = %q, want %q", input, got, expected)
+		}
+	}
+}
+
+func TestCorpus_WithAnalyzer(t *testing.T) {
+	corpus := NewCorpus(WithAnalyzer(EnglishAnalyzer()))
+	bodies := []string{
+		"the café serves espresso",
+		"appeals of the parties",
+		"court calendar",
+		"filing deadlines",
+	}
+	for _, body := range bodies {
+		corpus.AddDocument(Document{Fields: map[Field]string{FieldBody: body}})
+	}
+
+	// accents and plurals normalize identically at index and query time
+	tests := map[string]int{
+		"CAFE":   0,
+		"party":  1,
+		"appeal": 1,
+	}
+	for query, expected := range tests {
+		results := corpus.Search(query, 10)
+		if len(results) != 1 || results[0].Index != expected {
+			t.Errorf("Search(%q) = %+v, want document %d", query, results, expected)
+		}
+	}
+}
+
+func TestCorpus_IndexAndQueryAnalyzers(t *testing.T) {
+	synonyms := StandardAnalyzer()
+	synonyms.TokenFilters = append(synonyms.TokenFilters, SynonymFilter(map[string][]string{"car": {"automobile"}}))
+	stemming := EnglishAnalyzer()
+
+	corpus := NewCorpus(WithIndexAnalyzer(stemming), WithQueryAnalyzer(synonyms))
+	bodies := []string{
+		"automobile insurance claims",
+		"the parties appealed",
+		"court calendar",
+		"filing deadlines",
+		"motion practice",
+	}
+	for _, body := range bodies {
+		corpus.AddDocument(Document{Fields: map[Field]string{FieldBody: body}})
+	}
+
+	// synonyms are only expanded in queries
+	if results := corpus.Search("car", 10); len(results) != 1 || results[0].Index != 0 {
+		t.Errorf("Search(car) = %+v, want document 0", results)
+	}
+	// documents are stemmed at index time, so the stem matches but the plural query does not
+	if results := corpus.Search("claim", 10); len(results) != 1 || results[0].Index != 0 {
+		t.Errorf("Search(claim) = %+v, want document 0", results)
+	}
+	if results := corpus.Search("claims", 10); len(results) != 0 {
+		t.Errorf("Search(claims) returned %d results, want 0", len(results))
+	}
+
+	// without a query analyzer, queries use the corpus tokenizer
+	indexOnly := NewCorpus(WithIndexAnalyzer(stemming))
+	indexOnly.AddDocument(Document{Fields: map[Field]string{FieldBody: bodies[0]}})
+	if got := indexOnly.tokenize("Claims"); !reflect.DeepEqual(got, []string{"claims"}) {
+		t.Errorf("query tokens = %q, want the default tokenizer's", got)
+	}
+}
+
+func TestCorpus_SynonymsInPhrases(t *testing.T) {
+	query := StandardAnalyzer()
+	query.TokenFilters = append(query.TokenFilters, SynonymFilter(map[string][]string{"car": {"automobile"}}))
+
+	corpus := NewCorpus(WithQueryAnalyzer(query))
+	for _, body := range []string{
+		"red car for sale",
+		"blue automobile repairs",
+		"court calendar",
+		"filing deadlines",
+		"motion practice",
+	} {
+		corpus.AddDocument(Document{Fields: map[Field]string{FieldBody: body}})
+	}
+
+	tests := []struct {
+		query    string
+		expected []int
+	}{
+		{"car", []int{0, 1}},
+		// synonyms would sit between the words of a phrase, so phrases skip them
+		{`"red car"`, []int{0}},
+		{"red NEAR/1 car", []int{0}},
+		{"car NEAR/2 red", []int{0}},
+		{`"blue car"`, nil},
+	}
+	for _, tt := range tests {
+		for name, searcher := range map[string]interface {
+			Search(string, int) []SearchResult
+		}{"corpus": corpus, "index": corpus.Freeze()} {
+			var got []int
+			for _, result := range searcher.Search(tt.query, 10) {
+				got = append(got, result.Index)
+			}
+			slices.Sort(got)
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("%s Search(%s) = documents %v, want %v", name, tt.query, got, tt.expected)
+			}
+		}
+	}
+}
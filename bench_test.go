@@ -0,0 +1,182 @@
+package bm25md
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// Benchmarks for indexing throughput, query latency by corpus size, and the
+// candidate count at which parallel scoring overtakes sequential scoring. Run
+// them with profiles for pprof, eg
+//
+//	go test -run '^$' -bench Search -benchmem -cpuprofile cpu.out
+//	go tool pprof -http :8080 cpu.out
+//
+// or with make bench and make profile
+
+// benchSizes are the corpus sizes of the query latency benchmarks
+var benchSizes = []int{1_000, 10_000, 100_000}
+
+// benchQueries mix a frequent, a mid-frequency, and a rare term, as well as
+// multi-term and phrase queries
+var benchQueries = []string{
+	benchWord(0),
+	benchWord(50),
+	benchWord(2_000),
+	benchWord(1) + " " + benchWord(20) + " " + benchWord(300),
+	`"` + benchWord(2) + " " + benchWord(3) + `"`,
+}
+
+// benchVocabulary is the number of distinct words in benchmark documents
+const benchVocabulary = 10_000
+
+// benchWord returns the word of rank i in the benchmark vocabulary, spelled in
+// letters since tokenizers drop digits
+func benchWord(i int) string {
+	var b strings.Builder
+	for {
+		b.WriteByte(byte('a' + i%26))
+		i /= 26
+		if i == 0 {
+			break
+		}
+	}
+	return "zq" + b.String()
+}
+
+// benchDocuments generates n documents whose words follow a Zipf distribution,
+// like natural text, with a short heading, a body, and sometimes code
+func benchDocuments(n int) []Document {
+	rng := rand.New(rand.NewSource(1))
+	zipf := rand.NewZipf(rng, 1.1, 1, benchVocabulary-1)
+	words := func(count int) string {
+		parts := make([]string, count)
+		for i := range parts {
+			parts[i] = benchWord(int(zipf.Uint64()))
+		}
+		return strings.Join(parts, " ")
+	}
+
+	docs := make([]Document, n)
+	for i := range docs {
+		docs[i] = Document{
+			ExternalID: fmt.Sprintf("doc-%d", i),
+			Fields: map[Field]string{
+				FieldH1:   words(2 + rng.Intn(6)),
+				FieldBody: words(50 + rng.Intn(250)),
+			},
+		}
+		if i%4 == 0 {
+			docs[i].Fields[FieldCode] = words(5 + rng.Intn(20))
+		}
+	}
+	return docs
+}
+
+// benchCorpora caches benchmark corpora by size, since building the largest
+// takes longer than searching it
+var benchCorpora sync.Map
+
+// benchCorpus returns a corpus of n benchmark documents
+func benchCorpus(b *testing.B, n int) *Corpus {
+	b.Helper()
+	if corpus, ok := benchCorpora.Load(n); ok {
+		return corpus.(*Corpus)
+	}
+	corpus := NewCorpus()
+	corpus.AddDocuments(benchDocuments(n))
+	benchCorpora.Store(n, corpus)
+	return corpus
+}
+
+func BenchmarkAddDocument(b *testing.B) {
+	docs := benchDocuments(1_000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		corpus := NewCorpus()
+		for _, doc := range docs {
+			corpus.AddDocument(doc)
+		}
+	}
+	b.ReportMetric(float64(b.N*len(docs))/b.Elapsed().Seconds(), "docs/s")
+}
+
+func BenchmarkAddDocuments(b *testing.B) {
+	docs := benchDocuments(1_000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		NewCorpus().AddDocuments(docs)
+	}
+	b.ReportMetric(float64(b.N*len(docs))/b.Elapsed().Seconds(), "docs/s")
+}
+
+func BenchmarkFreeze(b *testing.B) {
+	corpus := benchCorpus(b, 10_000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		corpus.Freeze()
+	}
+}
+
+func BenchmarkSearch(b *testing.B) {
+	for _, size := range benchSizes {
+		// corpora are built within sub-benchmarks, so -bench filters skip them
+		b.Run(fmt.Sprintf("docs=%d", size), func(b *testing.B) {
+			corpus := benchCorpus(b, size)
+			for q, query := range benchQueries {
+				b.Run(fmt.Sprintf("query=%d", q), func(b *testing.B) {
+					b.ReportAllocs()
+					for i := 0; i < b.N; i++ {
+						corpus.Search(query, 10)
+					}
+				})
+			}
+		})
+	}
+}
+
+func BenchmarkIndexSearch(b *testing.B) {
+	for _, size := range benchSizes {
+		b.Run(fmt.Sprintf("docs=%d", size), func(b *testing.B) {
+			index := benchCorpus(b, size).Freeze()
+			for q, query := range benchQueries {
+				b.Run(fmt.Sprintf("query=%d", q), func(b *testing.B) {
+					b.ReportAllocs()
+					for i := 0; i < b.N; i++ {
+						index.Search(query, 10)
+					}
+				})
+			}
+		})
+	}
+}
+
+// BenchmarkScoring compares sequential and parallel scoring of the same
+// candidates as their number grows, to locate the crossover behind the parallel
+// threshold of collect
+func BenchmarkScoring(b *testing.B) {
+	corpus := benchCorpus(b, 10_000)
+	_, terms := corpus.prepareQueryString(benchWord(0) + " " + benchWord(1))
+	all := candidates(terms)
+
+	for _, n := range []int{25, 50, 100, 200, 400, 800, 1_600, 3_200} {
+		if n > len(all) {
+			break
+		}
+		docs := all[:n]
+		b.Run(fmt.Sprintf("candidates=%d/sequential", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				corpus.searchSequential(context.Background(), terms, docs, newTopResults(10, 0))
+			}
+		})
+		b.Run(fmt.Sprintf("candidates=%d/parallel", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				corpus.searchParallel(context.Background(), terms, docs, newTopResults(10, 0))
+			}
+		})
+	}
+}
@@ -6,16 +6,21 @@
 //
 // The algorithm handles multiple fields, where each field can have its own weight,
 // allowing for more nuanced ranking based on where terms appear in the document structure.
+//
+// Building with the bm25md_noparser tag leaves out the goldmark-based markdown parser
+// and the loaders built on it, so the corpus and scoring code depends only on the
+// standard library (eg for small WASM bundles that search pre-parsed documents).
 package bm25md
 
 import (
+	"context"
+	"fmt"
 	"log/slog"
 	"math"
 	"regexp"
-	"runtime"
-	"sort"
 	"strings"
 	"sync"
+	"time"
 )
 
 // tokenRegex is compiled once for efficient tokenization
@@ -35,6 +40,37 @@ const (
 	FieldItalic Field = "italic"
 	FieldCode   Field = "code"
 	FieldBody   Field = "body"
+
+	// front matter fields
+	FieldTitle       Field = "title"
+	FieldTags        Field = "tags"
+	FieldDescription Field = "description"
+
+	// pandoc fields
+	FieldTerm     Field = "term"     // definition list terms
+	FieldCitation Field = "citation" // citation keys
+
+	// GFM fields
+	FieldTable         Field = "table"         // table cell text
+	FieldStrikethrough Field = "strikethrough" // ~~deleted~~ text (see WithStrikethrough)
+
+	// block fields (see WithBlockquoteField and WithListField)
+	FieldBlockquote Field = "blockquote" // blockquote text
+	FieldList       Field = "list"       // list item text
+
+	// link fields
+	FieldLink Field = "link" // link anchor text
+	FieldURL  Field = "url"  // link destinations and autolinked URLs
+
+	// image fields
+	FieldAltText Field = "alt" // image alt text and titles
+
+	// section fields
+	FieldBreadcrumb Field = "breadcrumb" // heading path of a section chunk
+
+	// file fields
+	FieldFileName Field = "filename" // source file name, without extension
+	FieldPath     Field = "path"     // source directory path
 )
 
 // DefaultFieldWeights provides sensible default weights for markdown fields
@@ -49,19 +85,86 @@ var DefaultFieldWeights = map[Field]float64{
 	FieldItalic: 1.2,
 	FieldCode:   0.8,
 	FieldBody:   1.0,
+
+	FieldTitle:       5.0,
+	FieldTags:        3.0,
+	FieldDescription: 2.0,
+
+	FieldTerm:     1.5,
+	FieldCitation: 0.5,
+
+	FieldTable:         1.2,
+	FieldStrikethrough: 0.3,
+
+	FieldBlockquote: 1.0,
+	FieldList:       1.2,
+
+	FieldLink: 1.3,
+	FieldURL:  0.5,
+
+	FieldAltText: 1.2,
+
+	FieldBreadcrumb: 2.0,
+
+	FieldFileName: 3.0,
+	FieldPath:     1.0,
 }
 
 // Document represents a parsed document with field-separated content
 type Document struct {
-	ID       int              // document identifier
-	Fields   map[Field]string // content separated by field type
-	Original string           // original document text
+	ID          int                    // document identifier (assigned by the corpus)
+	ExternalID  string                 // optional caller-supplied identifier (eg file path, UUID)
+	Fields      map[Field]string       // content separated by field type
+	Original    string                 // original document text
+	Metadata    map[string]any         // arbitrary caller-supplied attributes (not indexed)
+	Boost       float64                // multiplicative score boost; zero means no boost
+	Timestamp   time.Time              // optional date (eg last modified) for WithRecencyDecay
+	Occurrences map[Field][]Occurrence // optional per-occurrence values with offsets into Original
+	Stats       DocumentStats          // optional structural counts (set by ParseDocuments)
+}
+
+// boost returns the document's score multiplier, treating unset values as 1
+func (d Document) boost() float64 {
+	if d.Boost > 0 {
+		return d.Boost
+	}
+	return 1.0
+}
+
+// Occurrence is a single appearance of field content in a document, such as one
+// heading or one bold span, with its byte offsets in the parsed content
+type Occurrence struct {
+	Text  string // extracted plain text
+	Start int    // byte offset where the occurrence begins (-1 when unknown)
+	End   int    // byte offset just past the occurrence (-1 when unknown)
+}
+
+// JoinOccurrences flattens per-occurrence values into space-joined field strings,
+// with every default field present (empty when it has no occurrences)
+func JoinOccurrences(occurrences map[Field][]Occurrence) map[Field]string {
+	fields := make(map[Field]string)
+	for field := range DefaultFieldWeights {
+		fields[field] = ""
+	}
+
+	for field, occs := range occurrences {
+		texts := make([]string, 0, len(occs))
+		for _, occ := range occs {
+			texts = append(texts, occ.Text)
+		}
+		if len(texts) > 0 {
+			fields[field] = strings.Join(texts, " ")
+		}
+	}
+
+	return fields
 }
 
 // BM25Parameters holds the tuning parameters for BM25 algorithm
 type BM25Parameters struct {
-	K1 float64 // controls term frequency saturation
-	B  float64 // controls length normalization
+	K1    float64 // controls term frequency saturation
+	B     float64 // controls length normalization
+	Delta float64 // BM25+ lower bound added to every matching term (0 = plain BM25)
 }
 
 // DefaultBM25Parameters returns recommended BM25 parameters
@@ -92,6 +195,22 @@ func DefaultFieldBM25Parameters() map[Field]BM25Parameters {
 
 		// body: higher saturation for longer content–term frequency matters more
 		FieldBody: {K1: 1.5, B: 0.75},
+
+		// front matter: titles behave like headers, tags are short keyword lists
+		FieldTitle:       {K1: 1.0, B: 0.9},
+		FieldTags:        {K1: 0.9, B: 0.5},
+		FieldDescription: {K1: 1.2, B: 0.8},
+
+		// pandoc: terms are short like emphasis, citation keys barely saturate
+		FieldTerm:     {K1: 0.9, B: 0.85},
+		FieldCitation: {K1: 0.8, B: 0.3},
+
+		// tables: short cells, lenient on length since tables vary widely in size
+		FieldTable: {K1: 1.2, B: 0.5},
+
+		// links: anchor text is short and descriptive, URLs are noisy and long
+		FieldLink: {K1: 1.0, B: 0.8},
+		FieldURL:  {K1: 0.8, B: 0.3},
 	}
 }
 
@@ -139,21 +258,27 @@ func (f TokenizerFunc) Tokenize(text string) []string {
 type fieldBM25 struct {
 	field           Field
 	weight          float64
-	params          BM25Parameters   // field-specific BM25 parameters
-	termFrequencies []map[string]int // term frequencies per doc
-	docFrequencies  map[string]int   // doc frequencies per term
-	docLengths      []int            // length of each doc
-	avgDocLength    float64          // average doc length
-	totalDocs       int              // total number of docs
+	params          BM25Parameters     // field-specific BM25 parameters
+	vocab           *vocabulary        // term IDs (shared by the corpus's fields)
+	termFrequencies []termVector       // delta-encoded term frequencies per doc
+	positions       []map[string][]int // token positions of each term per doc (for phrases)
+	docFrequencies  map[string]int     // doc frequencies per term
+	docLengths      []int              // length of each doc
+	avgDocLength    float64            // average doc length
+	totalLength     int                // sum of doc lengths
+	fieldDocs       int                // number of docs with this field
+	totalDocs       int                // total number of docs
 }
 
-// newFieldBM25 creates a new field-specific BM25 scorer
-func newFieldBM25(field Field, weight float64, params BM25Parameters) *fieldBM25 {
+// newFieldBM25 creates a new field-specific BM25 scorer that interns terms in vocab
+func newFieldBM25(field Field, weight float64, params BM25Parameters, vocab *vocabulary) *fieldBM25 {
 	return &fieldBM25{
 		field:           field,
 		weight:          weight,
 		params:          params,
-		termFrequencies: make([]map[string]int, 0),
+		vocab:           vocab,
+		termFrequencies: make([]termVector, 0),
+		positions:       make([]map[string][]int, 0),
 		docFrequencies:  make(map[string]int),
 		docLengths:      make([]int, 0),
 	}
@@ -161,34 +286,108 @@ func newFieldBM25(field Field, weight float64, params BM25Parameters) *fieldBM25
 
 // addDocument indexes pre-tokenized content for this field
 func (f *fieldBM25) addDocument(tokens []string) {
+	f.appendDocument(analyzeTokens(tokens))
+	f.updateAvgDocLength()
+}
 
-	// calculate term frequencies
+// appendDocument stores an analyzed document in a new slot without refreshing
+// the average length (bulk indexing refreshes it once at the end)
+func (f *fieldBM25) appendDocument(analysis fieldAnalysis) {
+	f.termFrequencies = append(f.termFrequencies, nil)
+	f.positions = append(f.positions, nil)
+	f.docLengths = append(f.docLengths, 0)
+	f.totalDocs++
+	f.storeDocument(len(f.termFrequencies)-1, analysis)
+}
+
+// setDocument stores an analyzed document in an existing slot, replacing its content
+func (f *fieldBM25) setDocument(docIndex int, analysis fieldAnalysis) {
+	f.clearDocument(docIndex)
+	f.storeDocument(docIndex, analysis)
+	f.updateAvgDocLength()
+}
+
+// fieldAnalysis holds the term statistics of one document field
+type fieldAnalysis struct {
+	tf        map[string]int
+	positions map[string][]int
+	length    int
+}
+
+// analyzeTokens calculates term frequencies and positions for a token stream
+func analyzeTokens(tokens []string) fieldAnalysis {
 	tf := make(map[string]int)
-	for _, token := range tokens {
+	positions := make(map[string][]int)
+	for i, token := range tokens {
 		tf[token]++
+		positions[token] = append(positions[token], i)
 	}
-	f.termFrequencies = append(f.termFrequencies, tf)
+	return fieldAnalysis{tf: tf, positions: positions, length: len(tokens)}
+}
 
-	// update doc frequencies
-	seen := make(map[string]bool)
-	for _, token := range tokens {
-		if !seen[token] {
-			f.docFrequencies[token]++
-			seen[token] = true
+// storeDocument writes an analysis into an empty slot and updates doc frequencies;
+// terms are interned so every map shares the vocabulary's copy of each string
+func (f *fieldBM25) storeDocument(docIndex int, analysis fieldAnalysis) {
+	f.termFrequencies[docIndex] = encodeTermVector(f.vocab, analysis.tf)
+	positions := make(map[string][]int, len(analysis.positions))
+	f.eachTerm(docIndex, func(term string, _ int) {
+		positions[term] = analysis.positions[term]
+		f.docFrequencies[term]++
+	})
+	f.positions[docIndex] = positions
+	f.docLengths[docIndex] = analysis.length
+}
+
+// removeDocument clears a document slot and excludes it from corpus statistics
+func (f *fieldBM25) removeDocument(docIndex int) {
+	f.clearDocument(docIndex)
+	f.totalDocs--
+	f.updateAvgDocLength()
+}
+
+// clearDocument removes a slot's terms from doc frequencies and empties it
+func (f *fieldBM25) clearDocument(docIndex int) {
+	f.eachTerm(docIndex, func(term string, _ int) {
+		f.docFrequencies[term]--
+		if f.docFrequencies[term] <= 0 {
+			delete(f.docFrequencies, term)
 		}
-	}
+	})
+	f.termFrequencies[docIndex] = nil
+	f.positions[docIndex] = map[string][]int{}
+	f.docLengths[docIndex] = 0
+}
 
-	// store doc length
-	f.docLengths = append(f.docLengths, len(tokens))
-	f.totalDocs++
+// compact keeps only the given document slots, in order
+func (f *fieldBM25) compact(keep []int) {
+	termFrequencies := make([]termVector, len(keep))
+	positions := make([]map[string][]int, len(keep))
+	docLengths := make([]int, len(keep))
+	for i, docIndex := range keep {
+		termFrequencies[i] = f.termFrequencies[docIndex]
+		positions[i] = f.positions[docIndex]
+		docLengths[i] = f.docLengths[docIndex]
+	}
+	f.termFrequencies = termFrequencies
+	f.positions = positions
+	f.docLengths = docLengths
+}
 
-	// update average doc length
-	totalLength := 0
+// updateAvgDocLength recomputes the average length over documents that have this
+// field, so sparse fields (eg titles) are not judged against mostly empty documents
+func (f *fieldBM25) updateAvgDocLength() {
+	totalLength, withField := 0, 0
 	for _, length := range f.docLengths {
-		totalLength += length
+		if length > 0 {
+			totalLength += length
+			withField++
+		}
 	}
-	if f.totalDocs > 0 {
-		f.avgDocLength = float64(totalLength) / float64(f.totalDocs)
+	f.totalLength = totalLength
+	f.fieldDocs = withField
+	f.avgDocLength = 0
+	if withField > 0 {
+		f.avgDocLength = float64(totalLength) / float64(withField)
 	}
 }
 
@@ -199,11 +398,10 @@ func (f *fieldBM25) score(queryTerms []string, docIndex int) float64 {
 	}
 
 	score := 0.0
-	docTF := f.termFrequencies[docIndex]
 	docLen := float64(f.docLengths[docIndex])
 
 	for _, term := range queryTerms {
-		tf := float64(docTF[term])
+		tf := float64(f.termFrequency(docIndex, term))
 		if tf == 0 {
 			continue
 		}
@@ -221,7 +419,7 @@ func (f *fieldBM25) score(queryTerms []string, docIndex int) float64 {
 		}
 
 		// calculate normalized term frequency using field-specific parameters
-		normTF := tf * (f.params.K1 + 1) / (tf + f.params.K1*(1-f.params.B+f.params.B*docLen/f.avgDocLength))
+		normTF := tf*(f.params.K1+1)/(tf+f.params.K1*(1-f.params.B+f.params.B*docLen/f.avgDocLength)) + f.params.Delta
 
 		// accumulate score
 		score += idf * normTF
@@ -238,7 +436,41 @@ type Corpus struct {
 	fieldWeights map[Field]float64
 	params       BM25Parameters
 	tokenizer    Tokenizer
+	tokenizers   map[Field]Tokenizer      // per-field tokenizers for indexing (see WithFieldTokenizer)
+	edgeNGrams   edgeNGramSize            // prefix lengths indexed for search-as-you-type (zero = none)
+	shingles     shingleSize              // word counts of indexed shingles (zero = none)
+	languages    LanguageDetector         // routes documents and queries to language analyzers (nil = off)
+	proximity    proximityBonus           // optional clause rewarding query words close together
+	parser       FieldParser              // parser for AddMarkdown and AddFile (nil = markdown)
+	stopwords    map[string]bool          // lowercased tokens dropped from content and queries
 	fieldParams  map[Field]BM25Parameters // per-field BM25 parameters
+	maxTokens    int                      // cap on tokens indexed per field per doc (0 = unlimited)
+	tokenLimits  map[Field]int            // per-field overrides of maxTokens
+	deleted      map[int]bool             // tombstones of removed documents (until Compact)
+	postings     map[string]postingList   // inverted index of term to documents and field frequencies
+	externalIDs  map[string]int           // external document IDs to internal IDs
+	searchHook   SearchHook               // optional callback invoked after each search
+	calibrator   Calibrator               // optional mapping of scores to probabilities
+	similarity   Similarity               // ranking function (default BM25FSimilarity)
+	decay        DecayFunc                // optional score decay by document age
+	now          func() time.Time         // clock for recency decay (nil = time.Now)
+	log          *slog.Logger             // destination of log messages (nil = slog.Default())
+	tracer       Tracer                   // optional tracer of searches and indexing
+	parallelMin  int                      // fewest candidates scored in parallel (0 = default, < 0 = never)
+	maxWorkers   int                      // cap on goroutines per search or batch (0 = one per CPU)
+
+	indexAnalyzer Tokenizer // analysis of indexed content only (nil = tokenizer)
+	queryAnalyzer Tokenizer // analysis of queries only (nil = tokenizer)
+
+	maxExpansions int        // cap on terms a prefix query expands to (0 = default)
+	maxRegexpScan int        // cap on dictionary terms a regexp query tests (0 = default)
+	termDictMu    sync.Mutex // guards the lazily rebuilt term dictionary
+	termDict      []string   // sorted index terms, for prefix queries
+	termDictDirty bool       // terms were added or removed since termDict was built
+
+	feedbackWeight float64                // strength of feedback priors (0 = disabled)
+	feedbackMu     sync.RWMutex           // guards feedback
+	feedback       map[int]feedbackCounts // per-document feedback tallies
 }
 
 // CorpusOption defines a function that configures a corpus
@@ -251,6 +483,45 @@ func WithTokenizer(tokenizer Tokenizer) CorpusOption {
 	}
 }
 
+// WithIndexAnalyzer sets the analysis of indexed content only, eg aggressive
+// stemming, leaving queries to the corpus tokenizer or WithQueryAnalyzer
+func WithIndexAnalyzer(analyzer Analyzer) CorpusOption {
+	return func(c *Corpus) {
+		c.indexAnalyzer = analyzer
+	}
+}
+
+// WithQueryAnalyzer sets the analysis of queries only, eg synonym expansion,
+// leaving indexed content to the corpus tokenizer or WithIndexAnalyzer. Query
+// tokens must take the form of the index terms they should match
+func WithQueryAnalyzer(analyzer Analyzer) CorpusOption {
+	return func(c *Corpus) {
+		c.queryAnalyzer = analyzer
+	}
+}
+
+// WithFieldTokenizer indexes a field with its own tokenizer, eg CodeTokenizer for
+// FieldCode. Queries are still tokenized by the corpus tokenizer, so a field
+// tokenizer should produce tokens in the same normalized form
+func WithFieldTokenizer(field Field, tokenizer Tokenizer) CorpusOption {
+	return func(c *Corpus) {
+		tokenizers := make(map[Field]Tokenizer, len(c.tokenizers)+1)
+		for f, t := range c.tokenizers {
+			tokenizers[f] = t
+		}
+		tokenizers[field] = tokenizer
+		c.tokenizers = tokenizers
+	}
+}
+
+// WithFieldParser sets the parser AddMarkdown and AddFile use, instead of a default
+// MarkdownFieldParser
+func WithFieldParser(parser FieldParser) CorpusOption {
+	return func(c *Corpus) {
+		c.parser = parser
+	}
+}
+
 // WithFieldWeights sets custom field weights for the corpus
 func WithFieldWeights(fieldWeights map[Field]float64) CorpusOption {
 	return func(c *Corpus) {
@@ -260,6 +531,20 @@ func WithFieldWeights(fieldWeights map[Field]float64) CorpusOption {
 	}
 }
 
+// WithFieldWeight sets the weight of a single field, keeping the other weights,
+// eg to index a custom field mapped with WithNodeField
+func WithFieldWeight(field Field, weight float64) CorpusOption {
+	return func(c *Corpus) {
+		// copy so shared maps like DefaultFieldWeights are never modified
+		weights := make(map[Field]float64, len(c.fieldWeights)+1)
+		for f, w := range c.fieldWeights {
+			weights[f] = w
+		}
+		weights[field] = weight
+		c.fieldWeights = weights
+	}
+}
+
 // WithBM25Params sets custom BM25 parameters for the corpus
 func WithBM25Params(params BM25Parameters) CorpusOption {
 	return func(c *Corpus) {
@@ -276,9 +561,35 @@ func WithFieldParams(fieldParams map[Field]BM25Parameters) CorpusOption {
 	}
 }
 
+// WithMaxFieldTokens caps the number of tokens indexed per field per document,
+// protecting scoring statistics and memory from pathological documents
+func WithMaxFieldTokens(limit int) CorpusOption {
+	return func(c *Corpus) {
+		c.maxTokens = limit
+	}
+}
+
+// WithFieldTokenLimits sets per-field token caps that take precedence over WithMaxFieldTokens
+func WithFieldTokenLimits(limits map[Field]int) CorpusOption {
+	return func(c *Corpus) {
+		if limits != nil {
+			c.tokenLimits = limits
+		}
+	}
+}
+
+// tokenLimit returns the maximum number of tokens indexed for a field (0 = unlimited)
+func (c *Corpus) tokenLimit(field Field) int {
+	if limit, exists := c.tokenLimits[field]; exists {
+		return limit
+	}
+	return c.maxTokens
+}
+
 // buildFieldScorers builds the field scorers based on current corpus configuration
 func (c *Corpus) buildFieldScorers() {
 	c.fieldScorers = make(map[Field]*fieldBM25)
+	vocab := newVocabulary()
 	for field, weight := range c.fieldWeights {
 		// use field-specific parameters if available, otherwise default
 		params := c.params
@@ -287,7 +598,7 @@ func (c *Corpus) buildFieldScorers() {
 				params = fieldParam
 			}
 		}
-		c.fieldScorers[field] = newFieldBM25(field, weight, params)
+		c.fieldScorers[field] = newFieldBM25(field, weight, params, vocab)
 	}
 }
 
@@ -299,6 +610,9 @@ func NewCorpus(opts ...CorpusOption) *Corpus {
 		fieldWeights: DefaultFieldWeights,
 		params:       DefaultBM25Parameters(),
 		tokenizer:    DefaultTokenizer{},
+		similarity:   BM25FSimilarity{},
+
+		feedbackWeight: DefaultFeedbackWeight,
 	}
 
 	// apply user options
@@ -312,201 +626,399 @@ func NewCorpus(opts ...CorpusOption) *Corpus {
 	return corpus
 }
 
-// AddDocument adds a document to the corpus
+// AddDocument adds a document to the corpus. A document whose ExternalID is
+// already indexed replaces that document, keeping its internal ID
 func (c *Corpus) AddDocument(doc Document) {
+	_, span := c.startSpan(context.Background(), "bm25md.AddDocument", time.Now())
+	defer span.End()
+	span.SetAttribute("bm25md.fields", len(doc.Fields))
+	if id, exists := c.LookupID(doc.ExternalID); exists {
+		_ = c.UpdateDocument(id, doc)
+		return
+	}
+
 	doc.ID = len(c.documents)
+	c.trackExternalID(doc)
 	c.documents = append(c.documents, doc)
 
 	// index content in each field
 	for field, scorer := range c.fieldScorers {
-		content := doc.Fields[field]
-		tokens := c.tokenizer.Tokenize(content)
-		scorer.addDocument(tokens)
+		scorer.appendDocument(c.analyzeField(doc, field))
+		scorer.updateAvgDocLength()
 	}
+	c.indexPostings(doc.ID)
 
-	slog.Debug("Added document to BM25md corpus", "docID", doc.ID, "fields", len(doc.Fields))
+	c.logger().Debug("Added document to BM25md corpus", "docID", doc.ID, "fields", len(doc.Fields))
 }
 
-// Score calculates the BM25md score for a query against a specific document
-func (c *Corpus) Score(query string, docIndex int) float64 {
-	queryTerms := c.tokenizer.Tokenize(query)
-	return c.scoreWithTokens(queryTerms, docIndex)
+// analyzeField tokenizes a document field, applying the field's token limit, and
+// analyzes the tokens along with any edge n-grams and shingles. N-grams and
+// shingles are extra postings rather than words, so they don't add to the field length
+func (c *Corpus) analyzeField(doc Document, field Field) fieldAnalysis {
+	tokens := c.tokenizeField(doc, field)
+	if limit := c.tokenLimit(field); limit > 0 && len(tokens) > limit {
+		tokens = tokens[:limit]
+	}
+	analysis := analyzeTokens(c.shingles.appendShingles(c.edgeNGrams.withEdgeNGrams(tokens), tokens))
+	analysis.length = len(tokens)
+	return analysis
 }
 
-// This implements a BM25F formula which combines term frequencies across fields
-func (c *Corpus) scoreWithTokens(queryTerms []string, docIndex int) float64 {
-	if docIndex < 0 || docIndex >= len(c.documents) {
-		return 0.0
+// RemoveDocument removes a document from search results and corpus statistics.
+// The slot is kept as a tombstone, so other document IDs stay stable until Compact
+func (c *Corpus) RemoveDocument(id int) error {
+	if !c.isLive(id) {
+		return fmt.Errorf("bm25md: no document with ID %d", id)
 	}
 
-	if len(queryTerms) == 0 {
-		return 0.0
+	c.unindexPostings(id)
+	for _, scorer := range c.fieldScorers {
+		scorer.removeDocument(id)
+	}
+	delete(c.externalIDs, c.documents[id].ExternalID)
+	if c.deleted == nil {
+		c.deleted = make(map[int]bool)
 	}
+	c.deleted[id] = true
+	c.documents[id] = Document{ID: id}
 
-	totalScore := 0.0
-	totalDocs := len(c.documents)
+	c.logger().Debug("Removed document from BM25md corpus", "docID", id)
+	return nil
+}
 
-	// calculate score per term across all fields
-	for _, term := range queryTerms {
-		docFreq := 0
-		for i := 0; i < len(c.documents); i++ {
-			termFound := false
-			for _, scorer := range c.fieldScorers {
-				if i < len(scorer.termFrequencies) {
-					if scorer.termFrequencies[i][term] > 0 {
-						termFound = true
-						break
-					}
-				}
-			}
-			if termFound {
-				docFreq++
-			}
-		}
-		if docFreq == 0 {
+// UpdateDocument replaces the content of a document in place, keeping its ID
+func (c *Corpus) UpdateDocument(id int, doc Document) error {
+	if !c.isLive(id) {
+		return fmt.Errorf("bm25md: no document with ID %d", id)
+	}
+
+	if other, exists := c.LookupID(doc.ExternalID); exists && other != id {
+		return fmt.Errorf("bm25md: external ID %q already belongs to document %d", doc.ExternalID, other)
+	}
+
+	doc.ID = id
+	delete(c.externalIDs, c.documents[id].ExternalID)
+	c.trackExternalID(doc)
+	c.documents[id] = doc
+	c.unindexPostings(id)
+	for field, scorer := range c.fieldScorers {
+		scorer.setDocument(id, c.analyzeField(doc, field))
+	}
+	c.indexPostings(id)
+
+	c.logger().Debug("Updated document in BM25md corpus", "docID", id, "fields", len(doc.Fields))
+	return nil
+}
+
+// SetDocumentBoost changes a document's score multiplier without reindexing it,
+// eg to promote canonical pages (boost > 1) or demote archived ones (boost < 1)
+func (c *Corpus) SetDocumentBoost(id int, boost float64) error {
+	if !c.isLive(id) {
+		return fmt.Errorf("bm25md: no document with ID %d", id)
+	}
+	if boost <= 0 || math.IsNaN(boost) || math.IsInf(boost, 0) {
+		return fmt.Errorf("bm25md: invalid boost %v", boost)
+	}
+	c.documents[id].Boost = boost
+	return nil
+}
+
+// Tombstones returns how many removed documents hold slots until Compact, and
+// the number of document slots in all, eg to decide when to compact
+func (c *Corpus) Tombstones() (removed, slots int) {
+	return len(c.deleted), len(c.documents)
+}
+
+// Compact drops removed documents from the index and renumbers the remaining
+// ones; it returns a map from old to new IDs for callers holding references
+func (c *Corpus) Compact() map[int]int {
+	remap := make(map[int]int, len(c.documents)-len(c.deleted))
+	keep := make([]int, 0, len(c.documents)-len(c.deleted))
+	documents := make([]Document, 0, len(c.documents)-len(c.deleted))
+	for i, doc := range c.documents {
+		if c.deleted[i] {
 			continue
 		}
+		remap[i] = len(documents)
+		doc.ID = len(documents)
+		keep = append(keep, i)
+		documents = append(documents, doc)
+	}
 
-		idf := math.Log((float64(totalDocs) - float64(docFreq) + 0.5) / (float64(docFreq) + 0.5))
-		if idf < 0 {
-			idf = 0 // prevent negative IDF for small corpora
+	for _, scorer := range c.fieldScorers {
+		scorer.compact(keep)
+	}
+	c.documents = documents
+	c.deleted = nil
+	c.rebuildPostings()
+	c.rebuildExternalIDs()
+	c.remapFeedback(remap)
+
+	c.logger().Debug("Compacted BM25md corpus", "documents", len(documents))
+	return remap
+}
+
+// LookupID returns the internal ID of the document with the given external ID
+func (c *Corpus) LookupID(externalID string) (int, bool) {
+	if externalID == "" {
+		return 0, false
+	}
+	id, exists := c.externalIDs[externalID]
+	return id, exists
+}
+
+// trackExternalID records the document's external ID, if it has one
+func (c *Corpus) trackExternalID(doc Document) {
+	if doc.ExternalID == "" {
+		return
+	}
+	if c.externalIDs == nil {
+		c.externalIDs = make(map[string]int)
+	}
+	c.externalIDs[doc.ExternalID] = doc.ID
+}
+
+// rebuildExternalIDs rebuilds the external ID mapping from live documents
+func (c *Corpus) rebuildExternalIDs() {
+	c.externalIDs = nil
+	for i, doc := range c.documents {
+		if !c.deleted[i] {
+			c.trackExternalID(doc)
 		}
+	}
+}
 
-		// calculate weighted term frequency across all fields (true BM25F)
-		weightedTF := 0.0
-		for field, scorer := range c.fieldScorers {
-			if docIndex < len(scorer.termFrequencies) {
-				tf := float64(scorer.termFrequencies[docIndex][term])
-				if tf > 0 {
-					weight := c.fieldWeights[field]
-					weightedTF += weight * tf
-				}
-			}
+// isLive reports whether id refers to an indexed, non-removed document
+func (c *Corpus) isLive(id int) bool {
+	return id >= 0 && id < len(c.documents) && !c.deleted[id]
+}
+
+// liveDocuments returns the number of indexed documents that have not been removed
+func (c *Corpus) liveDocuments() int {
+	return len(c.documents) - len(c.deleted)
+}
+
+// Documents returns the indexed (non-removed) documents in index order
+func (c *Corpus) Documents() []Document {
+	documents := make([]Document, 0, c.liveDocuments())
+	for i, doc := range c.documents {
+		if !c.deleted[i] {
+			documents = append(documents, doc)
+		}
+	}
+	return documents
+}
+
+// Document returns the indexed document with the given internal ID, reporting
+// false for unknown and removed IDs
+func (c *Corpus) Document(id int) (Document, bool) {
+	if !c.isLive(id) {
+		return Document{}, false
+	}
+	return c.documents[id], true
+}
+
+// Score calculates the BM25md score for a query against a specific document,
+// using the same query syntax as Search
+func (c *Corpus) Score(query string, docIndex int) float64 {
+	if !c.isLive(docIndex) {
+		return 0.0
+	}
+	_, terms := c.prepareQueryString(query)
+	return c.scoreDocument(terms, docIndex)
+}
+
+// scoreDocument scores a document with the corpus similarity, passing each term's
+// per-field matches along with the field lengths, weights, and parameters
+func (c *Corpus) scoreDocument(terms []queryTerm, docIndex int) float64 {
+	if !matches(terms, docIndex) {
+		return 0.0
+	}
+	totalScore := 0.0
+
+	// calculate score per term across all fields
+	for _, qt := range terms {
+		fields, exists := qt.postings[docIndex]
+		if !exists || qt.occur == occurMustNot {
+			continue
 		}
 
-		// apply BM25F normalization with combined term frequency
-		if weightedTF > 0 {
-			// use default K1=1.2 for the combined normalization
-			k1 := 1.2
-			normTF := weightedTF * (k1 + 1) / (weightedTF + k1)
-			termScore := idf * normTF
-			totalScore += termScore
+		fieldMatches := make([]FieldMatch, 0, len(fields))
+		for field, tf := range fields {
+			scorer := c.fieldScorers[field]
+			if scorer == nil {
+				continue
+			}
+			fieldMatches = append(fieldMatches, FieldMatch{
+				Field:     field,
+				Freq:      tf,
+				Length:    scorer.docLengths[docIndex],
+				AvgLength: scorer.avgDocLength,
+				Weight:    c.fieldWeights[field],
+				Params:    scorer.params,
+			})
 		}
+		totalScore += qt.scorer(fieldMatches)
 	}
 
-	return totalScore
+	return totalScore * c.documents[docIndex].boost() * c.feedbackPrior(docIndex) * c.recency(docIndex)
 }
 
 // SearchResult represents a document with its relevance score
 type SearchResult struct {
-	Document Document
-	Score    float64
-	Index    int
+	Document    Document
+	Score       float64
+	Index       int
+	ExternalID  string  // caller-supplied document ID, if any
+	Probability float64 // calibrated relevance probability (set only WithCalibrator)
 }
 
-// Search performs a BM25md search and returns ranked results
+// Search performs a BM25md search and returns ranked results. Terms are optional
+// by default; +term or AND requires a term, -term or NOT excludes it, and
+// double-quoted phrases (eg "habeas corpus") only match consecutive tokens
 func (c *Corpus) Search(query string, limit int) []SearchResult {
-	queryTerms := c.tokenizer.Tokenize(query)
-	if len(queryTerms) == 0 {
-		return []SearchResult{}
+	start := time.Now()
+	queryTerms, terms := c.prepareQueryString(query)
+	results, _ := c.search(context.Background(), query, queryTerms, terms, SearchOptions{Limit: limit}, start)
+	return results
+}
+
+// SearchContext performs a search like Search that stops scoring promptly once ctx
+// is cancelled or its deadline passes, returning the context's error
+func (c *Corpus) SearchContext(ctx context.Context, query string, limit int) ([]SearchResult, error) {
+	start := time.Now()
+	queryTerms, terms := c.prepareQueryString(query)
+	return c.search(ctx, query, queryTerms, terms, SearchOptions{Limit: limit}, start)
+}
+
+// cancelCheckInterval is how many documents are scored between context checks
+const cancelCheckInterval = 256
+
+// search ranks documents for query terms resolved against the index
+func (c *Corpus) search(ctx context.Context, query string, queryTerms []string, terms []queryTerm, opts SearchOptions, start time.Time) ([]SearchResult, error) {
+	// the query was tokenized by the caller, between start and now
+	ctx, span := c.startSpan(ctx, "bm25md.Search", start)
+	defer span.End()
+	_, tokenizeSpan := c.startSpan(ctx, "bm25md.tokenize", start)
+	tokenizeSpan.SetAttribute("bm25md.query_terms", len(queryTerms))
+	tokenizeSpan.End()
+	if err := ctx.Err(); err != nil {
+		return nil, err
 	}
 
-	// for small corpora, use sequential processing to avoid overhead
-	if len(c.documents) < 100 {
-		return c.searchSequential(queryTerms, limit)
+	// only the results up to the requested page need to be kept
+	k := opts.resultCap()
+
+	top := newTopResults(k, opts.MinScore)
+	_, scoreSpan := c.startSpan(ctx, "bm25md.score", time.Now())
+	parallel := c.collect(ctx, terms, opts, top, true)
+	scoreSpan.SetAttribute("bm25md.candidates", top.candidates)
+	scoreSpan.SetAttribute("bm25md.scored", top.scored)
+	scoreSpan.SetAttribute("bm25md.parallel", parallel)
+	scoreSpan.End()
+	if err := ctx.Err(); err != nil {
+		return nil, err
 	}
+	results := c.finishSearch(ctx, query, queryTerms, top, opts, start, parallel)
+	span.SetAttribute("bm25md.hits", top.hits)
+	return results, nil
+}
 
-	return c.searchParallel(queryTerms, limit)
+// collect scores the documents that can match terms into top, in parallel when
+// allowed and there are enough candidates; it reports whether it ran in parallel
+func (c *Corpus) collect(ctx context.Context, terms []queryTerm, opts SearchOptions, top *topResults, allowParallel bool) bool {
+	terms = restrictFields(terms, opts.Fields)
+
+	// only documents containing a query term (and passing the filter) can score
+	docs := candidates(terms)
+	top.candidates += len(docs)
+	docs = c.filterDocuments(docs, opts)
+	switch {
+	case len(docs) == 0:
+		return false
+	case !allowParallel || len(docs) < c.parallelThreshold() || c.workerCount(len(docs)) < 2:
+		// for few candidates, use sequential processing to avoid overhead
+		c.searchSequential(ctx, terms, docs, top)
+		return false
+	default:
+		c.searchParallel(ctx, terms, docs, top)
+		return true
+	}
 }
 
-// searchSequential performs sequential document scoring for small corpora
-func (c *Corpus) searchSequential(queryTerms []string, limit int) []SearchResult {
-	results := make([]SearchResult, 0, len(c.documents))
+// finishSearch pages collected results, attaches their documents and calibrated
+// probabilities, and reports the search to the hook
+func (c *Corpus) finishSearch(ctx context.Context, query string, queryTerms []string, top *topResults, opts SearchOptions, start time.Time, parallel bool) []SearchResult {
+	totalHits := top.hits
 
-	// score all documents sequentially
-	for i, doc := range c.documents {
-		score := c.scoreWithTokens(queryTerms, i)
-		if score > 0 {
-			results = append(results, SearchResult{
-				Document: doc,
-				Score:    score,
-				Index:    i,
-			})
-		}
+	// drop copies, apply offset and limit, then attach documents to the returned page only
+	_, sortSpan := c.startSpan(ctx, "bm25md.sort", time.Now())
+	results, _ := c.dedupe(top.sorted(), opts, func(index int) (Document, error) {
+		return c.documents[index], nil
+	})
+	results = opts.page(results)
+	sortSpan.SetAttribute("bm25md.results", len(results))
+	sortSpan.End()
+	for i := range results {
+		results[i].Document = c.documents[results[i].Index]
+		results[i].ExternalID = results[i].Document.ExternalID
 	}
 
-	// sort by score (highest first)
-	sort.Slice(results, func(i, j int) bool {
-		return results[i].Score > results[j].Score
-	})
+	if c.calibrator != nil {
+		for i := range results {
+			results[i].Probability = c.calibrator.Probability(results[i].Score)
+		}
+	}
 
-	// apply limit
-	if limit > 0 && len(results) > limit {
-		results = results[:limit]
+	duration := time.Since(start)
+	c.logger().Debug("Searched BM25md corpus", "query", query, "hits", totalHits, "results", len(results), "parallel", parallel, "duration", duration)
+	if c.searchHook != nil {
+		c.searchHook(query, results, SearchStats{
+			QueryTerms: queryTerms,
+			TotalHits:  totalHits,
+			Candidates: top.candidates,
+			Scored:     top.scored,
+			Documents:  c.liveDocuments(),
+			Duration:   duration,
+			Parallel:   parallel,
+		})
 	}
 
 	return results
 }
 
-// searchParallel performs parallel document scoring for large collections
-func (c *Corpus) searchParallel(queryTerms []string, limit int) []SearchResult {
-	numWorkers := runtime.NumCPU()
-	if numWorkers > len(c.documents) {
-		numWorkers = len(c.documents)
-	}
-
-	// create channels for work distribution/result collection
-	docChan := make(chan int, len(c.documents))
-	resultsChan := make(chan SearchResult, len(c.documents))
-
-	// start worker goroutines
-	var wg sync.WaitGroup
-	for i := 0; i < numWorkers; i++ {
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			for docIndex := range docChan {
-				score := c.scoreWithTokens(queryTerms, docIndex)
-				if score > 0 {
-					resultsChan <- SearchResult{
-						Document: c.documents[docIndex],
-						Score:    score,
-						Index:    docIndex,
-					}
-				}
-			}
-		}()
-	}
-
-	// send work to workers
-	go func() {
-		defer close(docChan)
-		for i := range c.documents {
-			docChan <- i
+// searchSequential scores candidate documents one after another
+func (c *Corpus) searchSequential(ctx context.Context, terms []queryTerm, docs []int, top *topResults) {
+	for i, docIndex := range docs {
+		if i%cancelCheckInterval == 0 && ctx.Err() != nil {
+			return
 		}
-	}()
+		top.push(SearchResult{Score: c.scoreDocument(terms, docIndex), Index: docIndex})
+	}
+}
 
-	// start result collection goroutine
-	go func() {
-		wg.Wait()
-		close(resultsChan)
-	}()
+// searchParallel scores candidate documents across pool workers, each claiming
+// chunks of candidates and keeping its own top results, which are merged once
+// all workers finish
+func (c *Corpus) searchParallel(ctx context.Context, terms []queryTerm, docs []int, top *topResults) {
+	numWorkers := c.workerCount(len(docs))
+	c.logger().Debug("Scoring BM25md candidates in parallel", "candidates", len(docs), "workers", numWorkers)
 
-	// collect results
-	results := make([]SearchResult, 0, len(c.documents))
-	for result := range resultsChan {
-		results = append(results, result)
+	collectors := make([]*topResults, numWorkers)
+	for i := range collectors {
+		collectors[i] = newTopResults(top.k, top.minScore)
 	}
-
-	// sort by score (highest first)
-	sort.Slice(results, func(i, j int) bool {
-		return results[i].Score > results[j].Score
+	pool.forEach(len(docs), numWorkers, parallelChunk, func(worker, start, end int) {
+		// stop promptly once the caller gives up
+		if ctx.Err() != nil {
+			return
+		}
+		for _, docIndex := range docs[start:end] {
+			collectors[worker].push(SearchResult{Score: c.scoreDocument(terms, docIndex), Index: docIndex})
+		}
 	})
 
-	// apply limit
-	if limit > 0 && len(results) > limit {
-		results = results[:limit]
+	for _, collector := range collectors {
+		top.merge(collector)
 	}
-
-	return results
 }
@@ -1,7 +1,10 @@
 package bm25md
 
 import (
+	"context"
+	"errors"
 	"math"
+	"reflect"
 	"testing"
 )
 
@@ -80,7 +83,7 @@ func TestTokenize(t *testing.T) {
 }
 
 func TestFieldBM25_AddDocument(t *testing.T) {
-	field := newFieldBM25(FieldBody, 1.0, DefaultBM25Parameters())
+	field := newFieldBM25(FieldBody, 1.0, DefaultBM25Parameters(), newVocabulary())
 	tokenizer := DefaultTokenizer{}
 
 	// add the first doc
@@ -104,14 +107,14 @@ func TestFieldBM25_AddDocument(t *testing.T) {
 	if field.docFrequencies["all"] != 2 {
 		t.Errorf("docFrequencies[all] = %d, want 2", field.docFrequencies["all"])
 	}
-	if field.termFrequencies[1]["lift"] != 1 {
-		t.Errorf("termFrequencies[1][lift] = %d, want 1", field.termFrequencies[1]["lift"])
+	if got := field.termFrequency(1, "lift"); got != 1 {
+		t.Errorf("termFrequency(1, lift) = %d, want 1", got)
 	}
 }
 
 func TestFieldBM25_Score(t *testing.T) {
 	params := DefaultBM25Parameters()
-	field := newFieldBM25(FieldBody, 2.0, params) // weight of 2.0
+	field := newFieldBM25(FieldBody, 2.0, params, newVocabulary()) // weight of 2.0
 	tokenizer := DefaultTokenizer{}
 
 	// add docs from the poem
@@ -136,7 +139,7 @@ func TestFieldBM25_Score(t *testing.T) {
 	}
 
 	// test that the field weight is applied correctly
-	fieldNoWeight := newFieldBM25(FieldBody, 1.0, params)
+	fieldNoWeight := newFieldBM25(FieldBody, 1.0, params, newVocabulary())
 	fieldNoWeight.addDocument(tokenizer.Tokenize("The stars go waltzing out in blue and red"))
 	fieldNoWeight.addDocument(tokenizer.Tokenize("I dreamed that you bewitched me into bed"))
 	fieldNoWeight.addDocument(tokenizer.Tokenize("I should have loved a thunderbird instead"))
@@ -296,6 +299,38 @@ func TestCorpus_SearchParallel(t *testing.T) {
 	}
 }
 
+func TestCorpus_SearchContext(t *testing.T) {
+	corpus := NewCorpus()
+	// enough candidates for both the sequential and parallel paths
+	for i := 0; i < 300; i++ {
+		body := "writ filler"
+		if i%2 == 0 {
+			body = "writ habeas corpus"
+		}
+		corpus.AddDocument(Document{Fields: map[Field]string{FieldBody: body}})
+	}
+	for i := 0; i < 400; i++ {
+		corpus.AddDocument(Document{Fields: map[Field]string{FieldBody: "court calendar"}})
+	}
+
+	for _, query := range []string{"habeas", "writ habeas"} {
+		results, err := corpus.SearchContext(context.Background(), query, 5)
+		if err != nil {
+			t.Fatalf("SearchContext(%q) error = %v", query, err)
+		}
+		if want := corpus.Search(query, 5); !reflect.DeepEqual(results, want) {
+			t.Errorf("SearchContext(%q) = %v, want %v", query, results, want)
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		results, err = corpus.SearchContext(ctx, query, 5)
+		if !errors.Is(err, context.Canceled) || results != nil {
+			t.Errorf("cancelled SearchContext(%q) = %v, %v, want context.Canceled", query, results, err)
+		}
+	}
+}
+
 func TestFieldWeighting(t *testing.T) {
 	weights := map[Field]float64{
 		FieldH1:   5.0, // high weight
@@ -371,56 +406,291 @@ func TestFieldWeighting(t *testing.T) {
 	}
 }
 
-// basic markdown parser tests
-func TestMarkdownFieldParser_BasicFields(t *testing.T) {
-	parser := NewMarkdownFieldParser()
+func TestWithFieldWeight(t *testing.T) {
+	admonition := Field("admonition")
+	corpus := NewCorpus(WithFieldWeight(admonition, 2.5))
+
+	if got := corpus.fieldWeights[admonition]; got != 2.5 {
+		t.Errorf("admonition weight = %v, want 2.5", got)
+	}
+	if got := corpus.fieldWeights[FieldH1]; got != DefaultFieldWeights[FieldH1] {
+		t.Errorf("h1 weight = %v, want default %v", got, DefaultFieldWeights[FieldH1])
+	}
+	if _, exists := DefaultFieldWeights[admonition]; exists {
+		t.Error("WithFieldWeight modified DefaultFieldWeights")
+	}
+
+	corpus.AddDocument(Document{Fields: map[Field]string{admonition: "mind the deadline"}})
+	corpus.AddDocument(Document{Fields: map[Field]string{FieldBody: "unrelated notes"}})
+	corpus.AddDocument(Document{Fields: map[Field]string{FieldBody: "other notes"}})
+	corpus.AddDocument(Document{Fields: map[Field]string{FieldBody: "more notes"}})
+	if results := corpus.Search("deadline", 10); len(results) != 1 || results[0].Document.ID != 0 {
+		t.Errorf("results = %+v, want the document with the custom field", results)
+	}
+}
+
+func TestCorpus_BM25FNormalization(t *testing.T) {
+	build := func(opts ...CorpusOption) *Corpus {
+		corpus := NewCorpus(opts...)
+		corpus.AddDocument(Document{Fields: map[Field]string{FieldBody: "writ issued"}})
+		corpus.AddDocument(Document{Fields: map[Field]string{FieldBody: "writ issued after a long hearing about detention and custody"}})
+		for _, body := range []string{"court calendar", "filing deadlines", "appeal rules", "judge assignments"} {
+			corpus.AddDocument(Document{Fields: map[Field]string{FieldBody: body}})
+		}
+		return corpus
+	}
+
+	tests := []struct {
+		name    string
+		opts    []CorpusOption
+		shorter func(short, long float64) bool
+	}{
+		{
+			name:    "length normalized by default",
+			shorter: func(short, long float64) bool { return short > long },
+		},
+		{
+			name:    "no normalization with B=0",
+			opts:    []CorpusOption{WithBM25Params(BM25Parameters{K1: 1.2, B: 0})},
+			shorter: func(short, long float64) bool { return short == long },
+		},
+		{
+			name:    "per-field B overrides the corpus default",
+			opts:    []CorpusOption{WithFieldParams(map[Field]BM25Parameters{FieldBody: {K1: 1.2, B: 0}})},
+			shorter: func(short, long float64) bool { return short == long },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			corpus := build(tt.opts...)
+			short, long := corpus.Score("writ", 0), corpus.Score("writ", 1)
+			if short == 0 || !tt.shorter(short, long) {
+				t.Errorf("short doc score = %f, long doc score = %f", short, long)
+			}
+		})
+	}
+
+	// K1 controls saturation of the combined frequency
+	low := build(WithBM25Params(BM25Parameters{K1: 0.5, B: 0.75}))
+	high := build(WithBM25Params(BM25Parameters{K1: 3.0, B: 0.75}))
+	if low.Score("writ", 0) == high.Score("writ", 0) {
+		t.Error("Score() ignores the configured K1")
+	}
+}
+
+func TestFieldTokenLimits(t *testing.T) {
+	corpus := NewCorpus(
+		WithMaxFieldTokens(3),
+		WithFieldTokenLimits(map[Field]int{FieldCode: 1, FieldH1: 0}),
+	)
+	corpus.AddDocument(Document{Fields: map[Field]string{
+		FieldBody: "shut eyes world drops dead",
+		FieldCode: "func main return",
+		FieldH1:   "mad girl love song villanelle",
+	}})
+
+	if got := corpus.fieldScorers[FieldBody].docLengths[0]; got != 3 {
+		t.Errorf("body length = %d, want 3", got)
+	}
+	if got := corpus.fieldScorers[FieldCode].docLengths[0]; got != 1 {
+		t.Errorf("code length = %d, want 1", got)
+	}
+	if got := corpus.fieldScorers[FieldH1].docLengths[0]; got != 5 {
+		t.Errorf("h1 length = %d, want 5 (explicit 0 means unlimited)", got)
+	}
+	if corpus.fieldScorers[FieldBody].termFrequency(0, "dead") != 0 {
+		t.Error("tokens past the limit should not be indexed")
+	}
+}
+
+func TestCorpus_RemoveDocument(t *testing.T) {
+	corpus, docs := createTestCorpus()
+
+	if err := corpus.RemoveDocument(5); err != nil {
+		t.Fatalf("RemoveDocument() error = %v", err)
+	}
+	if err := corpus.RemoveDocument(5); err == nil {
+		t.Error("RemoveDocument() of a removed document should fail")
+	}
+	if err := corpus.RemoveDocument(99); err == nil {
+		t.Error("RemoveDocument() of an unknown document should fail")
+	}
+
+	// statistics match a corpus built without the removed document
+	expected := NewCorpus()
+	for i, doc := range docs {
+		if i != 5 {
+			expected.AddDocument(doc)
+		}
+	}
+	if got, want := corpus.Score("dead world", 0), expected.Score("dead world", 0); math.Abs(got-want) > 1e-9 {
+		t.Errorf("Score() after removal = %v, want %v", got, want)
+	}
+	if got, want := corpus.fieldScorers[FieldBody].avgDocLength, expected.fieldScorers[FieldBody].avgDocLength; math.Abs(got-want) > 1e-9 {
+		t.Errorf("avgDocLength = %v, want %v", got, want)
+	}
+
+	for _, result := range corpus.Search("dreamed bewitched", 10) {
+		if result.Index == 5 {
+			t.Error("removed document returned by Search()")
+		}
+	}
+	if got := len(corpus.Documents()); got != len(docs)-1 {
+		t.Errorf("Documents() returned %d documents, want %d", got, len(docs)-1)
+	}
+
+	if doc, ok := corpus.Document(4); !ok || doc.ID != 4 || doc.Fields[FieldBody] != docs[4].Fields[FieldBody] {
+		t.Errorf("Document(4) = %+v, %v, want document 4", doc, ok)
+	}
+	for _, id := range []int{5, -1, 99} {
+		if _, ok := corpus.Document(id); ok {
+			t.Errorf("Document(%d) found a removed or unknown document", id)
+		}
+	}
+}
+
+func TestCorpus_UpdateDocument(t *testing.T) {
+	corpus, docs := createTestCorpus()
+	updated := Document{Fields: map[Field]string{FieldBody: "Galloping horses in the moonlight"}}
 
-	markdown := `# Main Title
-This is **bold** and *italic* text.
-Here is some ` + "`code`" + ` and regular content.`
+	if err := corpus.UpdateDocument(4, updated); err != nil {
+		t.Fatalf("UpdateDocument() error = %v", err)
+	}
 
-	fields := parser.ParseDocument(markdown)
+	expected := NewCorpus()
+	for i, doc := range docs {
+		if i == 4 {
+			doc = updated
+		}
+		expected.AddDocument(doc)
+	}
 
-	if fields[FieldH1] != "Main Title" {
-		t.Errorf("H1 field = %q, want %q", fields[FieldH1], "Main Title")
+	for _, query := range []string{"blackness gallops", "galloping horses", "moonlight"} {
+		for i := range docs {
+			if got, want := corpus.Score(query, i), expected.Score(query, i); math.Abs(got-want) > 1e-9 {
+				t.Errorf("Score(%q, %d) = %v, want %v", query, i, got, want)
+			}
+		}
 	}
-	if fields[FieldBold] != "bold" {
-		t.Errorf("Bold field = %q, want %q", fields[FieldBold], "bold")
+	if corpus.Documents()[4].ID != 4 {
+		t.Errorf("updated document ID = %d, want 4", corpus.Documents()[4].ID)
 	}
-	if fields[FieldItalic] != "italic" {
-		t.Errorf("Italic field = %q, want %q", fields[FieldItalic], "italic")
+
+	_ = corpus.RemoveDocument(4)
+	if err := corpus.UpdateDocument(4, updated); err == nil {
+		t.Error("UpdateDocument() of a removed document should fail")
 	}
-	if fields[FieldCode] != "code" {
-		t.Errorf("Code field = %q, want %q", fields[FieldCode], "code")
+}
+
+func TestCorpus_Compact(t *testing.T) {
+	corpus, docs := createTestCorpus()
+	_ = corpus.RecordFeedback("dead", 6, true)
+	_ = corpus.RemoveDocument(1)
+	_ = corpus.RemoveDocument(3)
+
+	before := corpus.Score("insane moon", 6)
+	remap := corpus.Compact()
+
+	if len(remap) != len(docs)-2 {
+		t.Fatalf("remap has %d entries, want %d", len(remap), len(docs)-2)
+	}
+	if _, exists := remap[1]; exists {
+		t.Error("removed document present in remap")
 	}
-	if len(fields[FieldBody]) == 0 {
-		t.Error("Body field should not be empty")
+	if remap[6] != 4 {
+		t.Errorf("remap[6] = %d, want 4", remap[6])
+	}
+
+	// scores, including feedback priors, follow the document to its new ID
+	if after := corpus.Score("insane moon", 4); math.Abs(after-before) > 1e-9 {
+		t.Errorf("Score() after Compact = %v, want %v", after, before)
+	}
+	for i, doc := range corpus.Documents() {
+		if doc.ID != i {
+			t.Errorf("document %d has ID %d after Compact", i, doc.ID)
+		}
 	}
 }
 
-func TestMarkdownFieldParser_NestedFormatting(t *testing.T) {
-	parser := NewMarkdownFieldParser()
+func TestCorpus_ExternalIDs(t *testing.T) {
+	corpus, _ := createTestCorpus()
+	corpus.AddDocument(Document{ExternalID: "notes/habeas.md", Fields: map[Field]string{FieldBody: "habeas corpus petition"}})
+	corpus.AddDocument(Document{ExternalID: "notes/appeal.md", Fields: map[Field]string{FieldBody: "notice of appeal"}})
 
-	markdown := `This is **bold _italic_** text.`
-	fields := parser.ParseDocument(markdown)
+	id, ok := corpus.LookupID("notes/habeas.md")
+	if !ok || id != 10 {
+		t.Fatalf("LookupID() = %d, %v, want 10, true", id, ok)
+	}
+	if _, ok := corpus.LookupID("missing.md"); ok {
+		t.Error("LookupID() found an unknown external ID")
+	}
+
+	results := corpus.Search("habeas", 10)
+	if len(results) != 1 || results[0].ExternalID != "notes/habeas.md" {
+		t.Fatalf("Search() = %+v, want notes/habeas.md", results)
+	}
 
-	// parser extracts nested content with spaces preserved
-	if fields[FieldBold] != "bold  italic" {
-		t.Errorf("Bold field = %q, want %q", fields[FieldBold], "bold  italic")
+	// re-adding an external ID replaces the document in place
+	corpus.AddDocument(Document{ExternalID: "notes/habeas.md", Fields: map[Field]string{FieldBody: "writ of mandamus"}})
+	if len(corpus.Documents()) != 12 {
+		t.Errorf("Documents() returned %d documents, want 12", len(corpus.Documents()))
+	}
+	if results := corpus.Search("habeas", 10); len(results) != 0 {
+		t.Errorf("Search(habeas) after replacement = %+v, want none", results)
 	}
-	// italicized content appears within bold, may not be separately extracted
-	if len(fields[FieldItalic]) > 0 && fields[FieldItalic] != "italic" {
-		t.Errorf("Italic field = %q, want %q", fields[FieldItalic], "italic")
+	if results := corpus.Search("mandamus", 10); len(results) != 1 || results[0].Index != id {
+		t.Errorf("Search(mandamus) = %+v, want document %d", results, id)
+	}
+
+	// updates cannot steal another document's external ID
+	if err := corpus.UpdateDocument(id, Document{ExternalID: "notes/appeal.md"}); err == nil {
+		t.Error("UpdateDocument() with a duplicate external ID should fail")
+	}
+
+	// removal and compaction keep the mapping current
+	_ = corpus.RemoveDocument(0)
+	if _, ok := corpus.LookupID("notes/habeas.md"); !ok {
+		t.Error("LookupID() lost a live document after RemoveDocument")
+	}
+	_ = corpus.RemoveDocument(id)
+	if _, ok := corpus.LookupID("notes/habeas.md"); ok {
+		t.Error("LookupID() found a removed document")
+	}
+	corpus.Compact()
+	if got, ok := corpus.LookupID("notes/appeal.md"); !ok || got != 9 {
+		t.Errorf("LookupID() after Compact = %d, %v, want 9, true", got, ok)
 	}
 }
 
-func TestMarkdownFieldParser_CodeBlocks(t *testing.T) {
-	parser := NewMarkdownFieldParser()
+func TestCorpus_DocumentBoost(t *testing.T) {
+	corpus := NewCorpus()
+	corpus.AddDocument(Document{Fields: map[Field]string{FieldBody: "habeas corpus guide"}})
+	corpus.AddDocument(Document{Fields: map[Field]string{FieldBody: "habeas corpus guide"}, Boost: 2})
+	for _, body := range []string{"court calendar", "appeal rules", "filing deadlines"} {
+		corpus.AddDocument(Document{Fields: map[Field]string{FieldBody: body}})
+	}
+
+	base := corpus.Score("habeas", 0)
+	if got := corpus.Score("habeas", 1); math.Abs(got-2*base) > 1e-9 {
+		t.Errorf("boosted score = %v, want %v", got, 2*base)
+	}
 
-	markdown := "```go\nfmt.Println(\"hello\")\n```"
-	fields := parser.ParseDocument(markdown)
+	// demote the boosted copy below the original without reindexing
+	if err := corpus.SetDocumentBoost(1, 0.5); err != nil {
+		t.Fatalf("SetDocumentBoost() error = %v", err)
+	}
+	results := corpus.Search("habeas", 2)
+	if len(results) != 2 || results[0].Index != 0 || math.Abs(results[1].Score-0.5*base) > 1e-9 {
+		t.Errorf("Search() after demotion = %+v", results)
+	}
 
-	if fields[FieldCode] != "fmt.Println(\"hello\")" {
-		t.Errorf("Code field = %q, want %q", fields[FieldCode], "fmt.Println(\"hello\")")
+	for _, tt := range []struct {
+		id    int
+		boost float64
+	}{{1, 0}, {1, -1}, {1, math.NaN()}, {99, 2}} {
+		if err := corpus.SetDocumentBoost(tt.id, tt.boost); err == nil {
+			t.Errorf("SetDocumentBoost(%d, %v) succeeded", tt.id, tt.boost)
+		}
 	}
 }
@@ -0,0 +1,287 @@
+// Package boltstore implements bm25md.Storage on bbolt, a pure-Go embedded
+// key-value store. Every write is a crash-safe bbolt transaction, which suits
+// long-running services that index incrementally.
+package boltstore
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/chriscorrea/bm25md"
+	bolt "go.etcd.io/bbolt"
+)
+
+// bucket names
+var (
+	bucketDocuments   = []byte("documents")    // document ID -> external ID and encoded document
+	bucketInfo        = []byte("info")         // document ID -> encoded document info
+	bucketExternalIDs = []byte("external_ids") // external ID -> document ID
+	bucketPostings    = []byte("postings")     // term, NUL, document ID -> field frequencies
+	bucketMeta        = []byte("meta")         // metaKey -> encoded statistics
+)
+
+// metaKey holds the corpus statistics in the meta bucket
+var metaKey = []byte("stats")
+
+// Store is a bm25md.Storage in a bbolt database file
+type Store struct {
+	db *bolt.DB
+}
+
+// Open opens (creating if needed) the bbolt database at path. A nil options
+// value uses bbolt's defaults
+func Open(path string, options *bolt.Options) (*Store, error) {
+	db, err := bolt.Open(path, 0o600, options)
+	if err != nil {
+		return nil, fmt.Errorf("boltstore: opening %s: %w", path, err)
+	}
+	store, err := New(db)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return store, nil
+}
+
+// New uses an open bbolt database, creating the store's buckets if they do not
+// exist. Closing the store closes db
+func New(db *bolt.DB) (*Store, error) {
+	err := db.Update(func(btx *bolt.Tx) error {
+		for _, name := range [][]byte{bucketDocuments, bucketInfo, bucketExternalIDs, bucketPostings, bucketMeta} {
+			if _, err := btx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("boltstore: creating buckets: %w", err)
+	}
+	return &Store{db: db}, nil
+}
+
+// View runs fn in a read-only bbolt transaction
+func (s *Store) View(ctx context.Context, fn func(tx bm25md.StorageTx) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return s.db.View(func(btx *bolt.Tx) error {
+		return fn(&tx{tx: btx})
+	})
+}
+
+// Update runs fn in a read-write bbolt transaction, committing it if fn returns nil
+func (s *Store) Update(ctx context.Context, fn func(tx bm25md.StorageTx) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return s.db.Update(func(btx *bolt.Tx) error {
+		return fn(&tx{tx: btx})
+	})
+}
+
+// Close closes the database
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// tx is a bm25md.StorageTx over a bbolt transaction
+type tx struct {
+	tx *bolt.Tx
+}
+
+// idKey encodes a document ID so keys sort numerically
+func idKey(id int) []byte {
+	return binary.BigEndian.AppendUint64(nil, uint64(id))
+}
+
+// postingKey builds the key of a term's posting for a document
+func postingKey(term string, id int) []byte {
+	key := append([]byte(term), 0)
+	return binary.BigEndian.AppendUint64(key, uint64(id))
+}
+
+// splitPostingKey returns the term and document ID of a posting key
+func splitPostingKey(key []byte) (string, int, bool) {
+	i := bytes.IndexByte(key, 0)
+	if i < 0 || len(key)-i-1 != 8 {
+		return "", 0, false
+	}
+	return string(key[:i]), int(binary.BigEndian.Uint64(key[i+1:])), true
+}
+
+// decodeDocument splits a documents bucket value into its external ID and data
+func decodeDocument(value []byte) (string, []byte, error) {
+	n, size := binary.Uvarint(value)
+	if size <= 0 || uint64(len(value)-size) < n {
+		return "", nil, errors.New("boltstore: corrupt document record")
+	}
+	return string(value[size : size+int(n)]), value[size+int(n):], nil
+}
+
+func (t *tx) Document(id int) ([]byte, error) {
+	value := t.tx.Bucket(bucketDocuments).Get(idKey(id))
+	if value == nil {
+		return nil, nil
+	}
+	_, data, err := decodeDocument(value)
+	if err != nil {
+		return nil, err
+	}
+	// bbolt values are only valid during the transaction
+	return bytes.Clone(data), nil
+}
+
+func (t *tx) DocumentInfo(id int) ([]byte, error) {
+	// bbolt values are only valid during the transaction
+	return bytes.Clone(t.tx.Bucket(bucketInfo).Get(idKey(id))), nil
+}
+
+func (t *tx) PutDocument(id int, externalID string, info, data []byte) error {
+	if err := t.DeleteDocument(id); err != nil {
+		return err
+	}
+	value := binary.AppendUvarint(nil, uint64(len(externalID)))
+	value = append(value, externalID...)
+	value = append(value, data...)
+	if err := t.tx.Bucket(bucketDocuments).Put(idKey(id), value); err != nil {
+		return fmt.Errorf("boltstore: writing document %d: %w", id, err)
+	}
+	if err := t.tx.Bucket(bucketInfo).Put(idKey(id), info); err != nil {
+		return fmt.Errorf("boltstore: writing document %d: %w", id, err)
+	}
+	if externalID != "" {
+		if err := t.tx.Bucket(bucketExternalIDs).Put([]byte(externalID), idKey(id)); err != nil {
+			return fmt.Errorf("boltstore: writing external ID %q: %w", externalID, err)
+		}
+	}
+	return nil
+}
+
+func (t *tx) DeleteDocument(id int) error {
+	documents := t.tx.Bucket(bucketDocuments)
+	value := documents.Get(idKey(id))
+	if value == nil {
+		return nil
+	}
+	externalID, _, err := decodeDocument(value)
+	if err != nil {
+		return err
+	}
+	if externalID != "" {
+		if err := t.tx.Bucket(bucketExternalIDs).Delete([]byte(externalID)); err != nil {
+			return fmt.Errorf("boltstore: deleting external ID %q: %w", externalID, err)
+		}
+	}
+	if err := documents.Delete(idKey(id)); err != nil {
+		return fmt.Errorf("boltstore: deleting document %d: %w", id, err)
+	}
+	if err := t.tx.Bucket(bucketInfo).Delete(idKey(id)); err != nil {
+		return fmt.Errorf("boltstore: deleting document %d: %w", id, err)
+	}
+	return nil
+}
+
+func (t *tx) LookupID(externalID string) (int, bool, error) {
+	value := t.tx.Bucket(bucketExternalIDs).Get([]byte(externalID))
+	if len(value) != 8 {
+		return 0, false, nil
+	}
+	return int(binary.BigEndian.Uint64(value)), true, nil
+}
+
+func (t *tx) Postings(term string) (map[int]map[bm25md.Field]int, error) {
+	postings := make(map[int]map[bm25md.Field]int)
+	prefix := append([]byte(term), 0)
+	cursor := t.tx.Bucket(bucketPostings).Cursor()
+	for key, value := cursor.Seek(prefix); key != nil && bytes.HasPrefix(key, prefix); key, value = cursor.Next() {
+		_, id, ok := splitPostingKey(key)
+		if !ok {
+			return nil, fmt.Errorf("boltstore: corrupt posting key %q", key)
+		}
+		freqs, err := decodeFreqs(value)
+		if err != nil {
+			return nil, err
+		}
+		postings[id] = freqs
+	}
+	return postings, nil
+}
+
+func (t *tx) PutPosting(term string, id int, freqs map[bm25md.Field]int) error {
+	if err := t.tx.Bucket(bucketPostings).Put(postingKey(term, id), encodeFreqs(freqs)); err != nil {
+		return fmt.Errorf("boltstore: writing posting of %q: %w", term, err)
+	}
+	return nil
+}
+
+func (t *tx) DeletePosting(term string, id int) error {
+	if err := t.tx.Bucket(bucketPostings).Delete(postingKey(term, id)); err != nil {
+		return fmt.Errorf("boltstore: deleting posting of %q: %w", term, err)
+	}
+	return nil
+}
+
+func (t *tx) Terms(prefix string) ([]string, error) {
+	var terms []string
+	cursor := t.tx.Bucket(bucketPostings).Cursor()
+	for key, _ := cursor.Seek([]byte(prefix)); key != nil; {
+		term, _, ok := splitPostingKey(key)
+		if !ok {
+			return nil, fmt.Errorf("boltstore: corrupt posting key %q", key)
+		}
+		if !strings.HasPrefix(term, prefix) {
+			break
+		}
+		terms = append(terms, term)
+		// skip the term's remaining postings
+		key, _ = cursor.Seek(append([]byte(term), 1))
+	}
+	return terms, nil
+}
+
+func (t *tx) Meta() ([]byte, error) {
+	return bytes.Clone(t.tx.Bucket(bucketMeta).Get(metaKey)), nil
+}
+
+func (t *tx) PutMeta(data []byte) error {
+	if err := t.tx.Bucket(bucketMeta).Put(metaKey, data); err != nil {
+		return fmt.Errorf("boltstore: writing statistics: %w", err)
+	}
+	return nil
+}
+
+// encodeFreqs encodes field frequencies as varint-prefixed field names and varint counts
+func encodeFreqs(freqs map[bm25md.Field]int) []byte {
+	var value []byte
+	for field, freq := range freqs {
+		value = binary.AppendUvarint(value, uint64(len(field)))
+		value = append(value, field...)
+		value = binary.AppendUvarint(value, uint64(freq))
+	}
+	return value
+}
+
+// decodeFreqs decodes field frequencies written by encodeFreqs
+func decodeFreqs(value []byte) (map[bm25md.Field]int, error) {
+	freqs := make(map[bm25md.Field]int, 1)
+	for len(value) > 0 {
+		n, size := binary.Uvarint(value)
+		if size <= 0 || uint64(len(value)-size) < n {
+			return nil, errors.New("boltstore: corrupt posting")
+		}
+		field := bm25md.Field(value[size : size+int(n)])
+		value = value[size+int(n):]
+		freq, size := binary.Uvarint(value)
+		if size <= 0 {
+			return nil, errors.New("boltstore: corrupt posting")
+		}
+		value = value[size:]
+		freqs[field] = int(freq)
+	}
+	return freqs, nil
+}
@@ -0,0 +1,63 @@
+package boltstore
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/chriscorrea/bm25md"
+	"github.com/chriscorrea/bm25md/storagetest"
+)
+
+func openStore(t *testing.T, path string) *Store {
+	t.Helper()
+	store, err := Open(path, nil)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	return store
+}
+
+func TestStore(t *testing.T) {
+	storagetest.TestStorage(t, func(t *testing.T) bm25md.Storage {
+		store := openStore(t, filepath.Join(t.TempDir(), "index.bolt"))
+		t.Cleanup(func() { store.Close() })
+		return store
+	})
+}
+
+func TestStore_Reopen(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "index.bolt")
+
+	corpus := bm25md.NewStoredCorpus(openStore(t, path))
+	docs := []bm25md.Document{
+		{ExternalID: "habeas.md", Fields: map[bm25md.Field]string{bm25md.FieldH1: "Habeas Corpus", bm25md.FieldBody: "the great writ"}},
+		{ExternalID: "appeals.md", Fields: map[bm25md.Field]string{bm25md.FieldBody: "notice of appeal"}},
+		{ExternalID: "calendar.md", Fields: map[bm25md.Field]string{bm25md.FieldBody: "court calendar"}},
+		{ExternalID: "fees.md", Fields: map[bm25md.Field]string{bm25md.FieldBody: "filing fees"}},
+	}
+	// documents indexed one at a time, as a service would
+	for _, doc := range docs {
+		if err := corpus.AddDocument(ctx, doc); err != nil {
+			t.Fatalf("AddDocument() error = %v", err)
+		}
+	}
+	want, err := corpus.Search(ctx, "habeas writ", 10)
+	if err != nil || len(want) != 1 {
+		t.Fatalf("Search() = %v, %v, want one result", want, err)
+	}
+	if err := corpus.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	reopened := bm25md.NewStoredCorpus(openStore(t, path))
+	defer reopened.Close()
+	got, err := reopened.Search(ctx, "habeas writ", 10)
+	if err != nil || len(got) != 1 || got[0].ExternalID != "habeas.md" || got[0].Score != want[0].Score {
+		t.Errorf("Search() after reopening = %v, %v, want %v", got, err, want)
+	}
+	if id, exists, err := reopened.LookupID(ctx, "fees.md"); err != nil || !exists || id != 3 {
+		t.Errorf("LookupID(fees.md) = %d, %v, %v, want 3", id, exists, err)
+	}
+}
@@ -0,0 +1,18 @@
+module github.com/chriscorrea/bm25md/boltstore
+
+go 1.24.3
+
+require (
+	github.com/chriscorrea/bm25md v0.0.0-20261016193234-3b3ff99011a9
+	go.etcd.io/bbolt v1.4.3
+)
+
+require (
+	github.com/BurntSushi/toml v1.6.0 // indirect
+	github.com/fsnotify/fsnotify v1.10.1 // indirect
+	github.com/yuin/goldmark v1.7.13 // indirect
+	golang.org/x/net v0.39.0 // indirect
+	golang.org/x/sys v0.39.0 // indirect
+	golang.org/x/text v0.24.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
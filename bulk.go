@@ -0,0 +1,61 @@
+package bm25md
+
+import (
+	"context"
+	"time"
+)
+
+// AddDocuments adds many documents at once, tokenizing them in parallel across
+// CPUs before merging them into the index. The result is the same as calling
+// AddDocument for each document in order (including upserts by ExternalID).
+func (c *Corpus) AddDocuments(docs []Document) {
+	ctx, span := c.startSpan(context.Background(), "bm25md.AddDocuments", time.Now())
+	defer span.End()
+	span.SetAttribute("bm25md.documents", len(docs))
+
+	_, analyzeSpan := c.startSpan(ctx, "bm25md.analyze", time.Now())
+	analyzed := c.analyzeDocuments(docs)
+	analyzeSpan.End()
+
+	added := 0
+	for i, doc := range docs {
+		if id, exists := c.LookupID(doc.ExternalID); exists {
+			_ = c.UpdateDocument(id, doc)
+			continue
+		}
+
+		doc.ID = len(c.documents)
+		c.trackExternalID(doc)
+		c.documents = append(c.documents, doc)
+		for field, scorer := range c.fieldScorers {
+			scorer.appendDocument(analyzed[i][field])
+		}
+		c.indexPostings(doc.ID)
+		added++
+	}
+
+	// average lengths are refreshed once rather than per document
+	for _, scorer := range c.fieldScorers {
+		scorer.updateAvgDocLength()
+	}
+
+	c.logger().Debug("Added documents to BM25md corpus", "documents", added, "updated", len(docs)-added)
+}
+
+// analyzeDocuments tokenizes and analyzes every field of docs on pool workers
+func (c *Corpus) analyzeDocuments(docs []Document) []map[Field]fieldAnalysis {
+	analyzed := make([]map[Field]fieldAnalysis, len(docs))
+
+	numWorkers := c.workerCount(len(docs))
+	c.logger().Debug("Analyzing BM25md documents in parallel", "documents", len(docs), "workers", numWorkers)
+
+	pool.forEach(len(docs), numWorkers, 1, func(_, i, _ int) {
+		fields := make(map[Field]fieldAnalysis, len(c.fieldScorers))
+		for field := range c.fieldScorers {
+			fields[field] = c.analyzeField(docs[i], field)
+		}
+		analyzed[i] = fields
+	})
+
+	return analyzed
+}
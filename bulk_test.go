@@ -0,0 +1,49 @@
+package bm25md
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestCorpus_AddDocuments(t *testing.T) {
+	docs := make([]Document, 0, 300)
+	for i := 0; i < 300; i++ {
+		docs = append(docs, Document{
+			ExternalID: fmt.Sprintf("doc-%d", i%280), // the last 20 update earlier documents
+			Fields: map[Field]string{
+				FieldH1:   fmt.Sprintf("section %d", i%7),
+				FieldBody: fmt.Sprintf("habeas corpus petition number %d filed in court %d", i, i%13),
+			},
+		})
+	}
+
+	sequential := NewCorpus()
+	sequential.AddDocument(Document{ExternalID: "doc-5", Fields: map[Field]string{FieldBody: "old version"}})
+	for _, doc := range docs {
+		sequential.AddDocument(doc)
+	}
+
+	bulk := NewCorpus()
+	bulk.AddDocument(Document{ExternalID: "doc-5", Fields: map[Field]string{FieldBody: "old version"}})
+	bulk.AddDocuments(docs)
+
+	if !reflect.DeepEqual(bulk.Documents(), sequential.Documents()) {
+		t.Fatal("AddDocuments() indexed different documents than AddDocument()")
+	}
+	if !reflect.DeepEqual(bulk.postings, sequential.postings) {
+		t.Error("AddDocuments() built different postings than AddDocument()")
+	}
+	for field, scorer := range sequential.fieldScorers {
+		if got := bulk.fieldScorers[field]; got.avgDocLength != scorer.avgDocLength ||
+			!reflect.DeepEqual(got.docFrequencies, scorer.docFrequencies) {
+			t.Errorf("field %s statistics differ", field)
+		}
+	}
+
+	for _, query := range []string{"habeas", "section court", `"petition number 12"`} {
+		if got, want := bulk.Search(query, 10), sequential.Search(query, 10); !reflect.DeepEqual(got, want) {
+			t.Errorf("Search(%q) differs between bulk and sequential indexing", query)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package bm25md
+
+import (
+	"errors"
+	"math"
+)
+
+// LabeledScore is a raw relevance score paired with a human judgment, used to
+// fit a calibrator
+type LabeledScore struct {
+	Score    float64
+	Relevant bool
+}
+
+// Calibrator maps raw BM25F scores to pseudo-probabilities of relevance
+type Calibrator interface {
+	Probability(score float64) float64
+}
+
+// SigmoidCalibrator is a Platt-scaled sigmoid: P(relevant) = 1 / (1 + exp(A*score + B))
+type SigmoidCalibrator struct {
+	A float64
+	B float64
+}
+
+// Probability implements Calibrator
+func (s SigmoidCalibrator) Probability(score float64) float64 {
+	fApB := s.A*score + s.B
+	// evaluate in the numerically stable direction
+	if fApB >= 0 {
+		return math.Exp(-fApB) / (1.0 + math.Exp(-fApB))
+	}
+	return 1.0 / (1.0 + math.Exp(fApB))
+}
+
+// FitSigmoid fits a SigmoidCalibrator to labeled scores with Platt's method,
+// using Newton's method with backtracking line search and smoothed targets
+func FitSigmoid(samples []LabeledScore) (SigmoidCalibrator, error) {
+	positives, negatives := 0, 0
+	for _, sample := range samples {
+		if sample.Relevant {
+			positives++
+		} else {
+			negatives++
+		}
+	}
+	if positives == 0 || negatives == 0 {
+		return SigmoidCalibrator{}, errors.New("bm25md: calibration needs both relevant and non-relevant samples")
+	}
+
+	// smoothed targets avoid overfitting to the training labels
+	hiTarget := (float64(positives) + 1.0) / (float64(positives) + 2.0)
+	loTarget := 1.0 / (float64(negatives) + 2.0)
+	targets := make([]float64, len(samples))
+	for i, sample := range samples {
+		if sample.Relevant {
+			targets[i] = hiTarget
+		} else {
+			targets[i] = loTarget
+		}
+	}
+
+	const (
+		maxIterations = 100
+		minStep       = 1e-10
+		sigma         = 1e-12 // keeps the Hessian positive definite
+		epsilon       = 1e-5
+	)
+
+	a := 0.0
+	b := math.Log((float64(negatives) + 1.0) / (float64(positives) + 1.0))
+	objective := func(a, b float64) float64 {
+		total := 0.0
+		for i, sample := range samples {
+			fApB := sample.Score*a + b
+			if fApB >= 0 {
+				total += targets[i]*fApB + math.Log1p(math.Exp(-fApB))
+			} else {
+				total += (targets[i]-1)*fApB + math.Log1p(math.Exp(fApB))
+			}
+		}
+		return total
+	}
+	current := objective(a, b)
+
+	for iter := 0; iter < maxIterations; iter++ {
+		// gradient and Hessian of the negative log likelihood
+		h11, h22, h21 := sigma, sigma, 0.0
+		g1, g2 := 0.0, 0.0
+		for i, sample := range samples {
+			fApB := sample.Score*a + b
+			var p, q float64
+			if fApB >= 0 {
+				p = math.Exp(-fApB) / (1.0 + math.Exp(-fApB))
+				q = 1.0 / (1.0 + math.Exp(-fApB))
+			} else {
+				p = 1.0 / (1.0 + math.Exp(fApB))
+				q = math.Exp(fApB) / (1.0 + math.Exp(fApB))
+			}
+			d2 := p * q
+			h11 += sample.Score * sample.Score * d2
+			h22 += d2
+			h21 += sample.Score * d2
+			d1 := targets[i] - p
+			g1 += sample.Score * d1
+			g2 += d1
+		}
+
+		if math.Abs(g1) < epsilon && math.Abs(g2) < epsilon {
+			break
+		}
+
+		// Newton direction
+		det := h11*h22 - h21*h21
+		dA := -(h22*g1 - h21*g2) / det
+		dB := -(-h21*g1 + h11*g2) / det
+		gd := g1*dA + g2*dB
+
+		// backtracking line search
+		step := 1.0
+		for step >= minStep {
+			newA, newB := a+step*dA, b+step*dB
+			next := objective(newA, newB)
+			if next < current+0.0001*step*gd {
+				a, b, current = newA, newB, next
+				break
+			}
+			step /= 2.0
+		}
+		if step < minStep {
+			break
+		}
+	}
+
+	return SigmoidCalibrator{A: a, B: b}, nil
+}
+
+// WithCalibrator maps search scores to pseudo-probabilities, filling
+// SearchResult.Probability so callers can apply confidence thresholds
+func WithCalibrator(calibrator Calibrator) CorpusOption {
+	return func(c *Corpus) {
+		c.calibrator = calibrator
+	}
+}
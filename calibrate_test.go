@@ -0,0 +1,62 @@
+package bm25md
+
+import "testing"
+
+func TestFitSigmoid(t *testing.T) {
+	// relevance rises with score
+	var samples []LabeledScore
+	for i := 0; i < 20; i++ {
+		score := float64(i) / 2.0
+		samples = append(samples, LabeledScore{Score: score, Relevant: i >= 10 || i%4 == 3})
+	}
+
+	calibrator, err := FitSigmoid(samples)
+	if err != nil {
+		t.Fatalf("FitSigmoid() error = %v", err)
+	}
+	if calibrator.A >= 0 {
+		t.Errorf("A = %v, want negative slope for increasing relevance", calibrator.A)
+	}
+
+	low := calibrator.Probability(0)
+	mid := calibrator.Probability(5)
+	high := calibrator.Probability(10)
+	if !(low < mid && mid < high) {
+		t.Errorf("probabilities not increasing: %v, %v, %v", low, mid, high)
+	}
+	if low <= 0 || high >= 1 {
+		t.Errorf("probabilities out of (0, 1): %v, %v", low, high)
+	}
+}
+
+func TestFitSigmoid_SingleClass(t *testing.T) {
+	_, err := FitSigmoid([]LabeledScore{{Score: 1, Relevant: true}, {Score: 2, Relevant: true}})
+	if err == nil {
+		t.Error("FitSigmoid() error = nil, want error without negative samples")
+	}
+}
+
+func TestWithCalibrator(t *testing.T) {
+	calibrator := SigmoidCalibrator{A: -2, B: 1}
+	corpus := NewCorpus(WithCalibrator(calibrator))
+	for _, body := range []string{"habeas corpus", "filing deadlines", "appeal rules"} {
+		corpus.AddDocument(Document{Fields: map[Field]string{FieldBody: body}})
+	}
+
+	results := corpus.Search("habeas", 10)
+	if len(results) != 1 {
+		t.Fatalf("got %d results, want 1", len(results))
+	}
+	if want := calibrator.Probability(results[0].Score); results[0].Probability != want {
+		t.Errorf("Probability = %v, want %v", results[0].Probability, want)
+	}
+
+	// without a calibrator the probability stays unset
+	plain := NewCorpus()
+	plain.AddDocument(Document{Fields: map[Field]string{FieldBody: "habeas corpus"}})
+	plain.AddDocument(Document{Fields: map[Field]string{FieldBody: "filing deadlines"}})
+	plain.AddDocument(Document{Fields: map[Field]string{FieldBody: "appeal rules"}})
+	if results := plain.Search("habeas", 10); len(results) == 0 || results[0].Probability != 0 {
+		t.Errorf("results = %+v, want unset probability", results)
+	}
+}
@@ -0,0 +1,236 @@
+//go:build !bm25md_noparser
+
+package bm25md
+
+import (
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+)
+
+// Metadata keys attached to chunks produced by a Chunker
+const (
+	MetaHeadings   = "headings"    // heading breadcrumb of the chunk's section, outermost first
+	MetaChunkStart = "chunk_start" // byte offset of the chunk within the source document
+)
+
+// BreadcrumbSeparator joins headings in the FieldBreadcrumb of a chunk
+const BreadcrumbSeparator = " > "
+
+// Chunker splits markdown documents into heading-aware sections, each returned as a
+// Document that keeps its enclosing headings indexed in the matching header fields
+// and its heading path in FieldBreadcrumb
+type Chunker struct {
+	parser  *MarkdownFieldParser
+	maxSize int
+	overlap int
+}
+
+// ChunkerOption defines a function that configures a Chunker
+type ChunkerOption func(*Chunker)
+
+// WithMaxChunkSize splits sections longer than size bytes at paragraph boundaries
+// (falling back to word boundaries for oversized paragraphs)
+func WithMaxChunkSize(size int) ChunkerOption {
+	return func(c *Chunker) {
+		c.maxSize = size
+	}
+}
+
+// WithChunkOverlap repeats up to size bytes from the end of a split chunk at the
+// start of the next, so text near a split point stays searchable in context
+func WithChunkOverlap(size int) ChunkerOption {
+	return func(c *Chunker) {
+		c.overlap = size
+	}
+}
+
+// WithChunkParser sets the parser used to extract chunk fields
+func WithChunkParser(parser *MarkdownFieldParser) ChunkerOption {
+	return func(c *Chunker) {
+		c.parser = parser
+	}
+}
+
+// NewChunker creates a chunker that splits on headings, with optional size limits
+func NewChunker(opts ...ChunkerOption) *Chunker {
+	c := &Chunker{}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.parser == nil {
+		c.parser = NewMarkdownFieldParser()
+	}
+	if c.overlap < 0 || (c.maxSize > 0 && c.overlap >= c.maxSize) {
+		c.overlap = 0
+	}
+	return c
+}
+
+// chunkSection is a span of the source that starts at a heading (or the document start)
+type chunkSection struct {
+	start, end int
+	headings   []chunkHeading // breadcrumb, including the section's own heading
+}
+
+// chunkHeading is a heading in a section breadcrumb
+type chunkHeading struct {
+	level int
+	text  string
+}
+
+// ParseSections splits markdown content at its headings into one Document per
+// section. Each section's full heading path (eg "Installation > Linux >
+// Troubleshooting") is indexed in FieldBreadcrumb, so sections keep their context
+func (p *MarkdownFieldParser) ParseSections(content string) []Document {
+	return NewChunker(WithChunkParser(p)).Chunk(content)
+}
+
+// Chunk splits content into section documents with sequential IDs. Front matter
+// fields (title, tags, ...) are indexed with every chunk.
+func (c *Chunker) Chunk(content string) []Document {
+	_, doc, source, _ := c.parser.parseOccurrences(content)
+
+	// front matter was blanked out of source; index its fields with every chunk
+	var frontMatter map[Field]string
+	bodyStart := 0
+	if fm, body, err := ParseFrontMatter(content); err == nil && fm != nil {
+		bodyStart = len(content) - len(body)
+		frontMatter = c.parser.ParseDocument(content[:bodyStart])
+	}
+
+	documents := make([]Document, 0)
+	for _, section := range c.sections(doc, source, bodyStart) {
+		for i, span := range c.split(content, section.start, section.end) {
+			raw := content[span[0]:span[1]]
+			text := strings.TrimSpace(raw)
+			if text == "" {
+				continue
+			}
+			start := span[0] + strings.Index(raw, text)
+			documents = append(documents, c.document(text, start, section, i > 0, frontMatter, len(documents)))
+		}
+	}
+	return documents
+}
+
+// sections cuts the document at each top-level heading, tracking the heading breadcrumb
+func (c *Chunker) sections(doc ast.Node, source []byte, bodyStart int) []chunkSection {
+	sections := []chunkSection{{start: bodyStart}}
+	var stack []chunkHeading
+
+	for node := doc.FirstChild(); node != nil; node = node.NextSibling() {
+		heading, ok := node.(*ast.Heading)
+		if !ok || heading.Lines().Len() == 0 {
+			continue
+		}
+
+		// sections start at the beginning of the heading's line
+		start := heading.Lines().At(0).Start
+		for start > bodyStart && source[start-1] != '\n' {
+			start--
+		}
+
+		for len(stack) > 0 && stack[len(stack)-1].level >= heading.Level {
+			stack = stack[:len(stack)-1]
+		}
+		stack = append(stack, chunkHeading{level: heading.Level, text: c.parser.extractTextFromChildren(heading, source)})
+
+		sections[len(sections)-1].end = start
+		sections = append(sections, chunkSection{start: start, headings: append([]chunkHeading{}, stack...)})
+	}
+	sections[len(sections)-1].end = len(source)
+
+	return sections
+}
+
+// split breaks [start, end) into spans no longer than the max size (plus overlap)
+func (c *Chunker) split(content string, start, end int) [][2]int {
+	if c.maxSize <= 0 || end-start <= c.maxSize {
+		return [][2]int{{start, end}}
+	}
+
+	var spans [][2]int
+	for start < end {
+		cut := end
+		if end-start > c.maxSize {
+			cut = splitPoint(content, start, start+c.maxSize)
+		}
+		spans = append(spans, [2]int{start, cut})
+		if cut >= end {
+			break
+		}
+
+		// back up into the previous chunk by the overlap, snapping to a word start
+		next := cut
+		if c.overlap > 0 {
+			next = cut - c.overlap
+			for next > start && next < cut && !isSpaceByte(content[next-1]) {
+				next++
+			}
+			if next <= start {
+				next = cut
+			}
+		}
+		start = next
+	}
+	return spans
+}
+
+// splitPoint picks where to end a chunk starting at start, preferring the last
+// paragraph break, then the last whitespace, before limit
+func splitPoint(content string, start, limit int) int {
+	window := content[start:limit]
+	if i := strings.LastIndex(window, "\n\n"); i > 0 {
+		return start + i + 2
+	}
+	if i := strings.LastIndexAny(window, " \t\n"); i > 0 {
+		return start + i + 1
+	}
+	return limit
+}
+
+// document builds a chunk Document, indexing any breadcrumb headings not in its text
+func (c *Chunker) document(text string, start int, section chunkSection, continued bool, frontMatter map[Field]string, id int) Document {
+	fields := c.parser.ParseDocument(text)
+
+	// the section's own heading is part of the first chunk's text already
+	context := section.headings
+	if !continued && len(context) > 0 {
+		context = context[:len(context)-1]
+	}
+	for _, heading := range context {
+		appendField(fields, c.parser.getHeaderField(heading.level), heading.text)
+	}
+	for field, value := range frontMatter {
+		appendField(fields, field, value)
+	}
+
+	breadcrumb := make([]string, len(section.headings))
+	for i, heading := range section.headings {
+		breadcrumb[i] = heading.text
+	}
+	appendField(fields, FieldBreadcrumb, strings.Join(breadcrumb, BreadcrumbSeparator))
+
+	return Document{
+		ID:       id,
+		Fields:   fields,
+		Original: text,
+		Metadata: map[string]any{
+			MetaHeadings:   breadcrumb,
+			MetaChunkStart: start,
+		},
+	}
+}
+
+// appendField adds text to a field, space-separating it from existing content
+func appendField(fields map[Field]string, field Field, text string) {
+	if text == "" {
+		return
+	}
+	if fields[field] != "" {
+		fields[field] += " " + text
+	} else {
+		fields[field] = text
+	}
+}
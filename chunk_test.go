@@ -0,0 +1,145 @@
+//go:build !bm25md_noparser
+
+package bm25md
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestChunker_Chunk(t *testing.T) {
+	input := "---\ntitle: Court Guide\n---\nIntro text.\n\n# Filing\n\nFile early.\n\n## Deadlines\n\n" +
+		"Thirty days.\n\n```\n# not a heading\n```\n\n# Appeals\n\nAppeal rules."
+
+	chunks := NewChunker().Chunk(input)
+
+	tests := []struct {
+		original string
+		headings []string
+		h1       string
+		h2       string
+	}{
+		{"Intro text.", []string{}, "", ""},
+		{"# Filing\n\nFile early.", []string{"Filing"}, "Filing", ""},
+		{"## Deadlines\n\nThirty days.\n\n```\n# not a heading\n```", []string{"Filing", "Deadlines"}, "Filing", "Deadlines"},
+		{"# Appeals\n\nAppeal rules.", []string{"Appeals"}, "Appeals", ""},
+	}
+
+	if len(chunks) != len(tests) {
+		t.Fatalf("got %d chunks, want %d: %+v", len(chunks), len(tests), chunks)
+	}
+	for i, tt := range tests {
+		chunk := chunks[i]
+		if chunk.ID != i {
+			t.Errorf("chunk %d ID = %d", i, chunk.ID)
+		}
+		if chunk.Original != tt.original {
+			t.Errorf("chunk %d Original = %q, want %q", i, chunk.Original, tt.original)
+		}
+		if got := chunk.Metadata[MetaHeadings]; !reflect.DeepEqual(got, tt.headings) {
+			t.Errorf("chunk %d headings = %v, want %v", i, got, tt.headings)
+		}
+		if got := chunk.Fields[FieldH1]; got != tt.h1 {
+			t.Errorf("chunk %d H1 = %q, want %q", i, got, tt.h1)
+		}
+		if got := chunk.Fields[FieldH2]; got != tt.h2 {
+			t.Errorf("chunk %d H2 = %q, want %q", i, got, tt.h2)
+		}
+		if got := chunk.Fields[FieldTitle]; got != "Court Guide" {
+			t.Errorf("chunk %d title = %q, want front matter title", i, got)
+		}
+		start := chunk.Metadata[MetaChunkStart].(int)
+		if !strings.HasPrefix(input[start:], chunk.Original) {
+			t.Errorf("chunk %d start %d does not point at its text", i, start)
+		}
+	}
+}
+
+func TestChunker_MaxSize(t *testing.T) {
+	input := "# Habeas Corpus\n\nThe writ protects liberty.\n\nCourts review detention.\n\nPetitions need facts."
+
+	tests := []struct {
+		name     string
+		opts     []ChunkerOption
+		expected []string
+	}{
+		{
+			name:     "no limit",
+			expected: []string{input},
+		},
+		{
+			name: "splits at paragraphs",
+			opts: []ChunkerOption{WithMaxChunkSize(50)},
+			expected: []string{
+				"# Habeas Corpus\n\nThe writ protects liberty.",
+				"Courts review detention.\n\nPetitions need facts.",
+			},
+		},
+		{
+			name: "overlap repeats trailing words",
+			opts: []ChunkerOption{WithMaxChunkSize(50), WithChunkOverlap(12)},
+			expected: []string{
+				"# Habeas Corpus\n\nThe writ protects liberty.",
+				"liberty.\n\nCourts review detention.",
+				"detention.\n\nPetitions need facts.",
+			},
+		},
+		{
+			name:     "falls back to words",
+			opts:     []ChunkerOption{WithMaxChunkSize(20)},
+			expected: []string{"# Habeas Corpus", "The writ protects", "liberty.", "Courts review", "detention.", "Petitions need", "facts."},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chunks := NewChunker(tt.opts...).Chunk(input)
+			got := make([]string, len(chunks))
+			for i, chunk := range chunks {
+				got[i] = chunk.Original
+				// continuation chunks keep their section heading
+				if chunk.Fields[FieldH1] != "Habeas Corpus" {
+					t.Errorf("chunk %d H1 = %q, want section heading", i, chunk.Fields[FieldH1])
+				}
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("chunks = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestMarkdownFieldParser_ParseSections(t *testing.T) {
+	input := "# Installation\n\nGet the binary.\n\n## Linux\n\nUse the tarball.\n\n### Troubleshooting\n\n" +
+		"Check permissions.\n\n## Windows\n\nRun the installer."
+
+	sections := NewMarkdownFieldParser().ParseSections(input)
+
+	expected := []string{
+		"Installation",
+		"Installation > Linux",
+		"Installation > Linux > Troubleshooting",
+		"Installation > Windows",
+	}
+	if len(sections) != len(expected) {
+		t.Fatalf("got %d sections, want %d", len(sections), len(expected))
+	}
+	for i, breadcrumb := range expected {
+		if got := sections[i].Fields[FieldBreadcrumb]; got != breadcrumb {
+			t.Errorf("section %d breadcrumb = %q, want %q", i, got, breadcrumb)
+		}
+	}
+
+	// the heading path makes a deep section findable by its ancestors
+	corpus := NewCorpus()
+	corpus.AddDocuments(sections)
+	corpus.AddDocuments([]Document{
+		{Fields: map[Field]string{FieldBody: "unrelated notes"}},
+		{Fields: map[Field]string{FieldBody: "other notes"}},
+	})
+	results := corpus.Search("linux troubleshooting", 1)
+	if len(results) == 0 || results[0].Document.Fields[FieldBreadcrumb] != expected[2] {
+		t.Errorf("top result = %+v, want the troubleshooting section", results)
+	}
+}
@@ -0,0 +1,63 @@
+package bm25md
+
+import "unicode"
+
+// CJKTokenizer indexes Chinese, Japanese, and Korean text, which has no spaces
+// between words, as overlapping character bigrams ("東京都" -> "東京", "京都"),
+// or with a caller-supplied word segmenter. Other text is passed to the base
+// tokenizer, so CJK runs are detected per run of text and mixed-language documents
+// keep their usual tokens
+type CJKTokenizer struct {
+	Base    Tokenizer             // tokenizer for non-CJK text; defaults to DefaultTokenizer
+	Segment func(string) []string // optional word segmenter for CJK runs, replacing bigrams
+}
+
+// Tokenize implements the Tokenizer interface
+func (t CJKTokenizer) Tokenize(text string) []string {
+	base := t.Base
+	if base == nil {
+		base = DefaultTokenizer{}
+	}
+
+	tokens := []string{}
+	start, cjk := 0, false
+	flush := func(end int) {
+		if start == end {
+			return
+		}
+		if cjk {
+			tokens = append(tokens, t.cjkTokens(text[start:end])...)
+		} else {
+			tokens = append(tokens, base.Tokenize(text[start:end])...)
+		}
+	}
+	for i, r := range text {
+		if isCJK(r) != cjk {
+			flush(i)
+			start, cjk = i, !cjk
+		}
+	}
+	flush(len(text))
+	return tokens
+}
+
+// cjkTokens splits a run of CJK characters into bigrams (or a lone character)
+func (t CJKTokenizer) cjkTokens(run string) []string {
+	if t.Segment != nil {
+		return t.Segment(run)
+	}
+	runes := []rune(run)
+	if len(runes) == 1 {
+		return []string{run}
+	}
+	bigrams := make([]string, 0, len(runes)-1)
+	for i := 0; i+1 < len(runes); i++ {
+		bigrams = append(bigrams, string(runes[i:i+2]))
+	}
+	return bigrams
+}
+
+// isCJK reports whether r is a Han, Hiragana, Katakana, or Hangul character
+func isCJK(r rune) bool {
+	return r == 'ー' || unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul)
+}
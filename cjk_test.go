@@ -0,0 +1,82 @@
+package bm25md
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCJKTokenizer(t *testing.T) {
+	tests := []struct {
+		name      string
+		tokenizer CJKTokenizer
+		input     string
+		expected  []string
+	}{
+		{
+			name:     "chinese bigrams",
+			input:    "东京都政府",
+			expected: []string{"东京", "京都", "都政", "政府"},
+		},
+		{
+			name:     "japanese with katakana",
+			input:    "データ検索",
+			expected: []string{"デー", "ータ", "タ検", "検索"},
+		},
+		{
+			name:     "korean words",
+			input:    "법원 판결",
+			expected: []string{"법원", "판결"},
+		},
+		{
+			name:     "single character",
+			input:    "法",
+			expected: []string{"法"},
+		},
+		{
+			name:     "mixed scripts keep base tokens in order",
+			input:    "Habeas corpus 人身保护令 petition",
+			expected: []string{"habeas", "corpus", "人身", "身保", "保护", "护令", "petition"},
+		},
+		{
+			name:     "latin only matches the default tokenizer",
+			input:    "The Great Writ of liberty",
+			expected: DefaultTokenizer{}.Tokenize("The Great Writ of liberty"),
+		},
+		{
+			name: "custom segmenter and base",
+			tokenizer: CJKTokenizer{
+				Base:    UnicodeTokenizer{},
+				Segment: func(run string) []string { return strings.Split(run, "的") },
+			},
+			input:    "A 法院的判决",
+			expected: []string{"A", "法院", "判决"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.tokenizer.Tokenize(tt.input); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Tokenize(%q) = %q, want %q", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCJKTokenizer_Search(t *testing.T) {
+	corpus := NewCorpus(WithTokenizer(CJKTokenizer{}))
+	corpus.AddDocuments([]Document{
+		{Fields: map[Field]string{FieldH1: "人身保护令", FieldBody: "人身保护令是保障人身自由的令状。"}},
+		{Fields: map[Field]string{FieldBody: "东京地方法院的判决。"}},
+		{Fields: map[Field]string{FieldBody: "Court calendar."}},
+		{Fields: map[Field]string{FieldBody: "Filing deadlines."}},
+	})
+
+	results := corpus.Search("保护令", 10)
+	if len(results) != 1 || results[0].Document.ID != 0 {
+		t.Errorf("Search(保护令) = %+v, want document 0", results)
+	}
+	if results := corpus.Search("判决", 10); len(results) != 1 || results[0].Document.ID != 1 {
+		t.Errorf("Search(判决) = %+v, want document 1", results)
+	}
+}
@@ -0,0 +1,198 @@
+//go:build !bm25md_noparser
+
+// Command bm25md indexes a directory of markdown files and searches the index
+// from the terminal.
+//
+// Usage:
+//
+//	bm25md index [-o index.bm25md] [-pattern *.md] [-preset docs] [-chunk-size 1000] dir
+//	bm25md search [-i index.bm25md] [-n 10] [-json | -trec [-qid 1] [-tag bm25md]] query...
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/chriscorrea/bm25md"
+	"github.com/chriscorrea/bm25md/eval"
+)
+
+// defaultIndex is the index file used when -o or -i is not given
+const defaultIndex = "index.bm25md"
+
+// plainMark brackets matches in uncolored snippets before being removed
+const plainMark = "\x00"
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout, os.Stderr); err != nil {
+		if !errors.Is(err, flag.ErrHelp) {
+			fmt.Fprintln(os.Stderr, "bm25md:", err)
+		}
+		os.Exit(2)
+	}
+}
+
+// run dispatches a subcommand
+func run(args []string, stdout, stderr io.Writer) error {
+	if len(args) == 0 {
+		usage(stderr)
+		return flag.ErrHelp
+	}
+	switch args[0] {
+	case "index":
+		return runIndex(args[1:], stdout, stderr)
+	case "search":
+		return runSearch(args[1:], stdout, stderr)
+	case "help", "-h", "-help", "--help":
+		usage(stdout)
+		return nil
+	default:
+		usage(stderr)
+		return fmt.Errorf("unknown command %q", args[0])
+	}
+}
+
+func usage(w io.Writer) {
+	fmt.Fprint(w, `usage:
+  bm25md index [flags] dir       index markdown files below dir
+  bm25md search [flags] query... search an index
+
+run "bm25md <command> -h" for flags
+`)
+}
+
+// runIndex indexes a directory and saves the index
+func runIndex(args []string, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("index", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	output := fs.String("o", defaultIndex, "index file to write")
+	pattern := fs.String("pattern", "*.md", "file name (or path) pattern to index")
+	preset := fs.String("preset", "", "field weighting preset ("+strings.Join(bm25md.Presets(), ", ")+")")
+	chunk := fs.Bool("chunk", true, "split files into sections at headings")
+	chunkSize := fs.Int("chunk-size", 1000, "maximum section size in characters (0 for no limit)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errors.New("index needs exactly one directory")
+	}
+
+	var opts []bm25md.CorpusOption
+	if *preset != "" {
+		if _, ok := bm25md.LookupPreset(*preset); !ok {
+			return fmt.Errorf("unknown preset %q", *preset)
+		}
+		opts = append(opts, bm25md.WithPreset(*preset))
+	}
+	var chunker *bm25md.Chunker
+	if *chunk {
+		chunker = bm25md.NewChunker(bm25md.WithMaxChunkSize(*chunkSize))
+	}
+
+	corpus, err := bm25md.IndexDir(os.DirFS(fs.Arg(0)), *pattern, chunker, opts...)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Create(*output)
+	if err != nil {
+		return err
+	}
+	if err := corpus.Save(f); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(stdout, "indexed %d documents into %s\n", len(corpus.Documents()), *output)
+	return nil
+}
+
+// jsonResult is a search result as printed by -json
+type jsonResult struct {
+	ID      string  `json:"id"`
+	Path    string  `json:"path,omitempty"`
+	Score   float64 `json:"score"`
+	Snippet string  `json:"snippet"`
+}
+
+// runSearch loads an index and prints the results of a query
+func runSearch(args []string, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("search", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	input := fs.String("i", defaultIndex, "index file to read")
+	limit := fs.Int("n", 10, "maximum number of results")
+	asJSON := fs.Bool("json", false, "print results as JSON lines")
+	asTREC := fs.Bool("trec", false, "print results as a TREC run for trec_eval")
+	qid := fs.String("qid", "1", "query ID of -trec output")
+	tag := fs.String("tag", eval.DefaultRunTag, "run tag of -trec output")
+	color := fs.Bool("color", isTerminal(stdout), "highlight matches with terminal colors")
+	window := fs.Int("window", 30, "snippet length in words")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	query := strings.Join(fs.Args(), " ")
+	if strings.TrimSpace(query) == "" {
+		return errors.New("search needs a query")
+	}
+
+	f, err := os.Open(*input)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	corpus, err := bm25md.LoadCorpus(f)
+	if err != nil {
+		return err
+	}
+
+	// plain snippets mark matches with a sentinel that is stripped below, since
+	// empty tags would select the default <mark> tags
+	highlight := bm25md.HighlightOptions{WindowSize: *window, PreTag: plainMark, PostTag: plainMark}
+	if *color && !*asJSON {
+		highlight.PreTag, highlight.PostTag = "\x1b[1;33m", "\x1b[0m"
+	}
+
+	results := corpus.Search(query, *limit)
+	if *asTREC {
+		return eval.WriteRun(stdout, *qid, results, *tag)
+	}
+	encoder := json.NewEncoder(stdout)
+	for i, result := range results {
+		snippet := strings.Join(strings.Fields(corpus.SnippetFor(query, result, highlight)), " ")
+		snippet = strings.ReplaceAll(snippet, plainMark, "")
+		path, _ := result.Document.Metadata[bm25md.MetaFilePath].(string)
+
+		if *asJSON {
+			if err := encoder.Encode(jsonResult{ID: result.ExternalID, Path: path, Score: result.Score, Snippet: snippet}); err != nil {
+				return err
+			}
+			continue
+		}
+		fmt.Fprintf(stdout, "%d. %s (%.2f)\n   %s\n", i+1, result.ExternalID, result.Score, snippet)
+	}
+	if len(results) == 0 && !*asJSON {
+		fmt.Fprintln(stdout, "no results")
+	}
+	return nil
+}
+
+// isTerminal reports whether w is a character device, honoring NO_COLOR
+func isTerminal(w io.Writer) bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,81 @@
+//go:build !bm25md_noparser
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	notes := filepath.Join(dir, "notes")
+	files := map[string]string{
+		"habeas.md":   "# Habeas Corpus\n\nA petition for the writ of habeas corpus challenges detention.",
+		"calendar.md": "# Calendar\n\nThe court calendar lists hearings.",
+		"filing.md":   "# Filing\n\nFiling deadlines for motions.",
+		"appeals.md":  "# Appeals\n\nAppeal rules and briefs.",
+		"skip.txt":    "habeas habeas habeas",
+	}
+	if err := os.MkdirAll(notes, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(notes, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	index := filepath.Join(dir, "notes.bm25md")
+
+	var stdout, stderr bytes.Buffer
+	if err := run([]string{"index", "-o", index, "-preset", "docs", notes}, &stdout, &stderr); err != nil {
+		t.Fatalf("index error = %v (%s)", err, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "indexed 4 documents") {
+		t.Errorf("index output = %q", stdout.String())
+	}
+
+	stdout.Reset()
+	if err := run([]string{"search", "-i", index, "-color=false", "habeas", "petition"}, &stdout, &stderr); err != nil {
+		t.Fatalf("search error = %v", err)
+	}
+	if got := stdout.String(); !strings.HasPrefix(got, "1. habeas.md#0") || !strings.Contains(got, "A petition for the writ") {
+		t.Errorf("search output = %q", got)
+	}
+
+	stdout.Reset()
+	if err := run([]string{"search", "-i", index, "-json", "calendar"}, &stdout, &stderr); err != nil {
+		t.Fatalf("search -json error = %v", err)
+	}
+	var result jsonResult
+	if err := json.Unmarshal(stdout.Bytes(), &result); err != nil {
+		t.Fatalf("decoding %q: %v", stdout.String(), err)
+	}
+	if result.Path != "calendar.md" || result.Score <= 0 || strings.Contains(result.Snippet, plainMark) {
+		t.Errorf("json result = %+v", result)
+	}
+
+	stdout.Reset()
+	if err := run([]string{"search", "-i", index, "-trec", "-qid", "301", "-tag", "docs", "calendar"}, &stdout, &stderr); err != nil {
+		t.Fatalf("search -trec error = %v", err)
+	}
+	if fields := strings.Fields(stdout.String()); len(fields) != 6 || fields[0] != "301" || fields[2] != "calendar.md#0" || fields[3] != "1" || fields[5] != "docs" {
+		t.Errorf("trec output = %q", stdout.String())
+	}
+
+	for _, args := range [][]string{
+		{"bogus"},
+		{"index"},
+		{"index", "-preset", "nope", notes},
+		{"search", "-i", index},
+		{"search", "-i", filepath.Join(dir, "missing"), "habeas"},
+	} {
+		if err := run(args, &stdout, &stderr); err == nil {
+			t.Errorf("run(%q) succeeded", args)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package bm25md
+
+import "context"
+
+// Collector receives each document matching a search with its score, for
+// custom aggregation (counts, grouping, sampling) without building results.
+// Documents arrive in no particular order
+type Collector interface {
+	Collect(id int, score float64)
+}
+
+// CollectorFunc adapts a function to the Collector interface
+type CollectorFunc func(id int, score float64)
+
+// Collect implements the Collector interface
+func (f CollectorFunc) Collect(id int, score float64) {
+	f(id, score)
+}
+
+// CountCollector counts matching documents
+type CountCollector struct {
+	Count int
+}
+
+// Collect implements the Collector interface
+func (c *CountCollector) Collect(int, float64) {
+	c.Count++
+}
+
+// SearchCollect scores the documents matching query and passes each to collector,
+// one at a time. MinScore, Fields, Filter, and Docs apply as in SearchWithOptions;
+// paging and deduplication, which need ranked results, do not. It returns the
+// context's error if ctx is cancelled before every document is collected
+func (c *Corpus) SearchCollect(ctx context.Context, query string, opts SearchOptions, collector Collector) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	_, terms := c.prepareQueryString(query)
+	top := newTopResults(0, opts.MinScore)
+	top.collector = collector
+	c.collect(ctx, terms, opts, top, false)
+	return ctx.Err()
+}
+
+// SearchCollect scores the documents matching query and passes each to collector,
+// like Corpus.SearchCollect
+func (ix *Index) SearchCollect(ctx context.Context, query string, opts SearchOptions, collector Collector) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	_, terms := ix.prepareQuery(query, opts)
+	top := newTopResults(0, opts.MinScore)
+	top.collector = collector
+	ix.collect(ctx, terms, opts, top)
+	return ctx.Err()
+}
@@ -0,0 +1,71 @@
+package bm25md
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestSearchCollect(t *testing.T) {
+	ctx := context.Background()
+	corpus := randomCorpus(300)
+	index := corpus.Freeze()
+	searchers := map[string]func(string, SearchOptions, Collector) error{
+		"corpus": func(query string, opts SearchOptions, collector Collector) error {
+			return corpus.SearchCollect(ctx, query, opts, collector)
+		},
+		"index": func(query string, opts SearchOptions, collector Collector) error {
+			return index.SearchCollect(ctx, query, opts, collector)
+		},
+	}
+	queries := []string{"habeas", "+federal court -state", `"habeas corpus" review`}
+	options := []SearchOptions{
+		{},
+		{MinScore: 2},
+		{Fields: []Field{FieldH1}},
+		{Filter: MetadataEquals("even", true), Limit: 3},
+	}
+
+	for name, search := range searchers {
+		for _, query := range queries {
+			for _, opts := range options {
+				t.Run(fmt.Sprintf("%s/%s/%+v", name, query, opts), func(t *testing.T) {
+					collected := make(map[int]float64)
+					err := search(query, opts, CollectorFunc(func(id int, score float64) {
+						collected[id] = score
+					}))
+					if err != nil {
+						t.Fatalf("SearchCollect() error = %v", err)
+					}
+
+					// every ranked result is collected, ignoring the limit
+					opts.Limit = 0
+					want := corpus.SearchWithOptions(query, opts)
+					if len(collected) != len(want) {
+						t.Fatalf("collected %d documents, want %d", len(collected), len(want))
+					}
+					for _, result := range want {
+						if score, ok := collected[result.Index]; !ok || math.Abs(score-result.Score) > 1e-9 {
+							t.Errorf("document %d collected with score %v (%v), want %v", result.Index, score, ok, result.Score)
+						}
+					}
+				})
+			}
+		}
+	}
+
+	counter := &CountCollector{}
+	if err := corpus.SearchCollect(ctx, "habeas", SearchOptions{}, counter); err != nil {
+		t.Fatal(err)
+	}
+	if want := len(corpus.Search("habeas", 0)); counter.Count != want {
+		t.Errorf("CountCollector.Count = %d, want %d", counter.Count, want)
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if err := corpus.SearchCollect(cancelled, "habeas", SearchOptions{}, counter); err != context.Canceled {
+		t.Errorf("SearchCollect() with a cancelled context error = %v, want context.Canceled", err)
+	}
+}
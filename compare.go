@@ -0,0 +1,148 @@
+package bm25md
+
+import (
+	"fmt"
+	"io"
+	"slices"
+)
+
+// QueryComparison describes how the top-k ranking of one query differs between
+// two corpus configurations
+type QueryComparison struct {
+	Query      string
+	A          []int   // top-k document indexes from the first corpus
+	B          []int   // top-k document indexes from the second corpus
+	Overlap    float64 // fraction of top-k documents shared by both rankings
+	KendallTau float64 // rank correlation of shared documents, from -1 to 1
+	Added      []int   // documents only in B's top-k
+	Removed    []int   // documents only in A's top-k
+}
+
+// Changed reports whether the two rankings differ in membership or order
+func (q QueryComparison) Changed() bool {
+	return !slices.Equal(q.A, q.B)
+}
+
+// Comparison summarizes ranking differences across a query set
+type Comparison struct {
+	K              int
+	Queries        []QueryComparison
+	MeanOverlap    float64 // average overlap@k across queries
+	MeanKendallTau float64 // average Kendall tau across queries
+	Changed        int     // number of queries whose ranking changed
+}
+
+// CompareCorpora runs the same queries against two corpus configurations holding
+// the same documents and reports how their top-k rankings differ, so tuning
+// changes (weights, analyzers, similarities) can be reviewed before rollout
+func CompareCorpora(a, b *Corpus, queries []string, k int) Comparison {
+	comparison := Comparison{K: k, Queries: make([]QueryComparison, 0, len(queries))}
+	if len(queries) == 0 {
+		return comparison
+	}
+
+	for _, query := range queries {
+		qc := compareRankings(query, resultIndexes(a.Search(query, k)), resultIndexes(b.Search(query, k)))
+		comparison.Queries = append(comparison.Queries, qc)
+		comparison.MeanOverlap += qc.Overlap
+		comparison.MeanKendallTau += qc.KendallTau
+		if qc.Changed() {
+			comparison.Changed++
+		}
+	}
+
+	comparison.MeanOverlap /= float64(len(queries))
+	comparison.MeanKendallTau /= float64(len(queries))
+	return comparison
+}
+
+// Report writes a human-readable summary with per-query diffs for changed queries
+func (c Comparison) Report(w io.Writer) error {
+	_, err := fmt.Fprintf(w, "queries: %d  changed: %d  overlap@%d: %.3f  kendall tau: %.3f\n",
+		len(c.Queries), c.Changed, c.K, c.MeanOverlap, c.MeanKendallTau)
+	if err != nil {
+		return err
+	}
+
+	for _, q := range c.Queries {
+		if !q.Changed() {
+			continue
+		}
+		_, err := fmt.Fprintf(w, "\n%q  overlap: %.3f  tau: %.3f\n  A: %v\n  B: %v\n  added: %v  removed: %v\n",
+			q.Query, q.Overlap, q.KendallTau, q.A, q.B, q.Added, q.Removed)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resultIndexes returns the document indexes of results in rank order
+func resultIndexes(results []SearchResult) []int {
+	indexes := make([]int, len(results))
+	for i, result := range results {
+		indexes[i] = result.Index
+	}
+	return indexes
+}
+
+// compareRankings computes overlap, Kendall tau, and membership diffs of two rankings
+func compareRankings(query string, a, b []int) QueryComparison {
+	qc := QueryComparison{Query: query, A: a, B: b}
+
+	rankB := make(map[int]int, len(b))
+	for i, doc := range b {
+		rankB[doc] = i
+	}
+	inA := make(map[int]bool, len(a))
+	for _, doc := range a {
+		inA[doc] = true
+	}
+
+	// shared documents in A's order, paired with their rank in B
+	var sharedRanks []int
+	for _, doc := range a {
+		if rank, exists := rankB[doc]; exists {
+			sharedRanks = append(sharedRanks, rank)
+		} else {
+			qc.Removed = append(qc.Removed, doc)
+		}
+	}
+	for _, doc := range b {
+		if !inA[doc] {
+			qc.Added = append(qc.Added, doc)
+		}
+	}
+
+	// overlap is relative to the longer ranking; two empty rankings agree fully
+	size := max(len(a), len(b))
+	if size == 0 {
+		qc.Overlap = 1.0
+	} else {
+		qc.Overlap = float64(len(sharedRanks)) / float64(size)
+	}
+	qc.KendallTau = kendallTau(sharedRanks)
+
+	return qc
+}
+
+// kendallTau returns the Kendall rank correlation between the identity order and
+// ranks; fewer than two items cannot disagree, so they count as fully concordant
+func kendallTau(ranks []int) float64 {
+	n := len(ranks)
+	if n < 2 {
+		return 1.0
+	}
+
+	concordant, discordant := 0, 0
+	for i := 0; i < n; i++ {
+		for j := i + 1; j < n; j++ {
+			if ranks[i] < ranks[j] {
+				concordant++
+			} else {
+				discordant++
+			}
+		}
+	}
+	return float64(concordant-discordant) / float64(n*(n-1)/2)
+}
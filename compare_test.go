@@ -0,0 +1,92 @@
+package bm25md
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestKendallTau(t *testing.T) {
+	tests := []struct {
+		name     string
+		ranks    []int
+		expected float64
+	}{
+		{"identical order", []int{0, 1, 2, 3}, 1.0},
+		{"reversed order", []int{3, 2, 1, 0}, -1.0},
+		{"one swap", []int{1, 0, 2}, 1.0 / 3.0},
+		{"single item", []int{0}, 1.0},
+		{"empty", nil, 1.0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := kendallTau(tt.ranks); math.Abs(got-tt.expected) > 1e-9 {
+				t.Errorf("kendallTau(%v) = %v, want %v", tt.ranks, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCompareRankings(t *testing.T) {
+	qc := compareRankings("q", []int{1, 2, 3, 4}, []int{2, 1, 3, 5})
+
+	if qc.Overlap != 0.75 {
+		t.Errorf("Overlap = %v, want 0.75", qc.Overlap)
+	}
+	if len(qc.Added) != 1 || qc.Added[0] != 5 {
+		t.Errorf("Added = %v, want [5]", qc.Added)
+	}
+	if len(qc.Removed) != 1 || qc.Removed[0] != 4 {
+		t.Errorf("Removed = %v, want [4]", qc.Removed)
+	}
+	if math.Abs(qc.KendallTau-1.0/3.0) > 1e-9 {
+		t.Errorf("KendallTau = %v, want 1/3", qc.KendallTau)
+	}
+	if !qc.Changed() {
+		t.Error("Changed() = false, want true")
+	}
+}
+
+func TestCompareCorpora(t *testing.T) {
+	docs := []Document{
+		{Fields: map[Field]string{FieldH1: "habeas", FieldBody: "corpus"}},
+		{Fields: map[Field]string{FieldBody: "habeas habeas habeas corpus"}},
+		{Fields: map[Field]string{FieldBody: "filing deadlines"}},
+		{Fields: map[Field]string{FieldBody: "appeal rules"}},
+		{Fields: map[Field]string{FieldBody: "court calendar"}},
+	}
+	build := func(opts ...CorpusOption) *Corpus {
+		corpus := NewCorpus(opts...)
+		for _, doc := range docs {
+			corpus.AddDocument(doc)
+		}
+		return corpus
+	}
+
+	baseline := build()
+	bodyHeavy := build(WithFieldWeights(map[Field]float64{FieldH1: 0.1, FieldBody: 5.0}))
+	queries := []string{"habeas", "appeal"}
+
+	same := CompareCorpora(baseline, build(), queries, 3)
+	if same.Changed != 0 || same.MeanOverlap != 1.0 || same.MeanKendallTau != 1.0 {
+		t.Errorf("identical configurations = %+v, want no changes", same)
+	}
+
+	diff := CompareCorpora(baseline, bodyHeavy, queries, 3)
+	if diff.Changed != 1 {
+		t.Errorf("Changed = %d, want 1", diff.Changed)
+	}
+	if diff.Queries[0].KendallTau != -1.0 {
+		t.Errorf("habeas KendallTau = %v, want -1", diff.Queries[0].KendallTau)
+	}
+
+	var buf bytes.Buffer
+	if err := diff.Report(&buf); err != nil {
+		t.Fatalf("Report() error = %v", err)
+	}
+	if !strings.Contains(buf.String(), `"habeas"`) || strings.Contains(buf.String(), `"appeal"`) {
+		t.Errorf("Report() = %q, want only the changed query listed", buf.String())
+	}
+}
@@ -0,0 +1,68 @@
+package bm25md
+
+import (
+	"sort"
+	"strings"
+)
+
+// Completion is a term suggested by CompleteTerm
+type Completion struct {
+	Term    string // index term beginning with the prefix
+	DocFreq int    // live documents containing the term
+}
+
+// CompleteTerm returns up to n index terms beginning with prefix, most frequent
+// (by document frequency) first, for autocompletion driven by the indexed
+// content. The prefix is normalized like a query word when the tokenizer keeps it
+// as one token (eg lowercased). Shingles and edge n-grams from WithEdgeNGrams
+// are not offered, and DocFreq counts only documents containing the term as a word
+func (c *Corpus) CompleteTerm(prefix string, n int) []Completion {
+	if n <= 0 || prefix == "" {
+		return nil
+	}
+	if tokens := c.tokenize(prefix); len(tokens) == 1 {
+		prefix = tokens[0]
+	}
+
+	terms := c.sortedTerms()
+	var completions []Completion
+	for i := sort.SearchStrings(terms, prefix); i < len(terms) && strings.HasPrefix(terms[i], prefix); i++ {
+		if strings.Contains(terms[i], ShingleSeparator) {
+			continue
+		}
+		if docFreq := c.wordDocFreq(terms[i]); docFreq > 0 {
+			completions = append(completions, Completion{Term: terms[i], DocFreq: docFreq})
+		}
+	}
+	// ties keep sorted term order
+	sort.SliceStable(completions, func(i, j int) bool { return completions[i].DocFreq > completions[j].DocFreq })
+	return completions[:min(len(completions), n)]
+}
+
+// wordDocFreq returns how many live documents contain term as a word rather than
+// only as an edge n-gram. N-grams follow a field's words, so their positions lie
+// past the field length
+func (c *Corpus) wordDocFreq(term string) int {
+	postings := c.postings[term]
+	if !c.edgeNGrams.indexed(term) {
+		return len(postings)
+	}
+	docFreq := 0
+	for docIndex, fields := range postings {
+		for field := range fields {
+			if c.occursAsWord(term, docIndex, field) {
+				docFreq++
+				break
+			}
+		}
+	}
+	return docFreq
+}
+
+// occursAsWord reports whether a document's field contains term as a word
+// rather than only as an edge n-gram
+func (c *Corpus) occursAsWord(term string, docIndex int, field Field) bool {
+	scorer := c.fieldScorers[field]
+	positions := scorer.positions[docIndex][term]
+	return len(positions) > 0 && positions[0] < scorer.docLengths[docIndex]
+}
@@ -0,0 +1,81 @@
+package bm25md
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCorpus_CompleteTerm(t *testing.T) {
+	corpus := NewCorpus(WithShingles(2, 2))
+	bodies := []string{
+		"constitution and constitutional review",
+		"the constitution of the state",
+		"constitution constituent assembly",
+		"consent decree",
+	}
+	for _, body := range bodies {
+		corpus.AddDocument(Document{Fields: map[Field]string{FieldBody: body}})
+	}
+
+	tests := []struct {
+		prefix   string
+		n        int
+		expected []Completion
+	}{
+		{
+			prefix: "consti",
+			n:      2,
+			expected: []Completion{
+				{Term: "constitution", DocFreq: 3},
+				{Term: "constituent", DocFreq: 1},
+			},
+		},
+		{
+			prefix: "CONS",
+			n:      10,
+			expected: []Completion{
+				{Term: "constitution", DocFreq: 3},
+				{Term: "consent", DocFreq: 1},
+				{Term: "constituent", DocFreq: 1},
+				{Term: "constitutional", DocFreq: 1},
+			},
+		},
+		{prefix: "xyz", n: 5, expected: nil},
+		{prefix: "cons", n: 0, expected: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.prefix, func(t *testing.T) {
+			if got := corpus.CompleteTerm(tt.prefix, tt.n); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("CompleteTerm(%q, %d) = %+v, want %+v", tt.prefix, tt.n, got, tt.expected)
+			}
+		})
+	}
+
+	// removed documents no longer count
+	_ = corpus.RemoveDocument(2)
+	if got := corpus.CompleteTerm("constitue", 5); got != nil {
+		t.Errorf("CompleteTerm() after removal = %+v, want nil", got)
+	}
+}
+
+func TestCorpus_CompleteTerm_EdgeNGrams(t *testing.T) {
+	corpus := NewCorpus(WithEdgeNGrams(2, 10))
+	for _, body := range []string{
+		"constitution and constitutional review",
+		"the constitution of the state",
+		"cons and pros",
+	} {
+		corpus.AddDocument(Document{Fields: map[Field]string{FieldBody: body}})
+	}
+
+	// n-grams such as "con" and "consti" are indexed but are not words
+	want := []Completion{
+		{Term: "constitution", DocFreq: 2},
+		{Term: "cons", DocFreq: 1}, // a word in document 2, only an n-gram elsewhere
+		{Term: "constitutional", DocFreq: 1},
+	}
+	if got := corpus.CompleteTerm("con", 10); !reflect.DeepEqual(got, want) {
+		t.Errorf("CompleteTerm(con) = %+v, want %+v", got, want)
+	}
+}
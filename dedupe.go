@@ -0,0 +1,79 @@
+package bm25md
+
+// resultCap returns how many top results a search must keep for the requested
+// page (0 = all); deduplication needs every result, since copies may be dropped
+func (opts SearchOptions) resultCap() int {
+	if opts.Limit <= 0 || opts.Dedupe > 0 {
+		return 0
+	}
+	return opts.Offset + opts.Limit
+}
+
+// dedupe drops ranked results whose content is at least opts.Dedupe similar to
+// a higher-ranked kept result, stopping once the requested page is filled.
+// document looks up the document of a result
+func (c *Corpus) dedupe(results []SearchResult, opts SearchOptions, document func(index int) (Document, error)) ([]SearchResult, error) {
+	if opts.Dedupe <= 0 {
+		return results, nil
+	}
+	want := 0
+	if opts.Limit > 0 {
+		want = opts.Offset + opts.Limit
+	}
+
+	kept := results[:0:0]
+	keptTerms := make([]map[string]bool, 0, want)
+	for _, result := range results {
+		if want > 0 && len(kept) == want {
+			break
+		}
+		doc, err := document(result.Index)
+		if err != nil {
+			return nil, err
+		}
+		terms := c.contentTerms(doc)
+		duplicate := false
+		for _, other := range keptTerms {
+			if jaccard(terms, other) >= opts.Dedupe {
+				duplicate = true
+				break
+			}
+		}
+		if !duplicate {
+			kept = append(kept, result)
+			keptTerms = append(keptTerms, terms)
+		}
+	}
+	return kept, nil
+}
+
+// contentTerms returns the set of terms a document is indexed under, across
+// every field
+func (c *Corpus) contentTerms(doc Document) map[string]bool {
+	terms := make(map[string]bool)
+	for field := range doc.Fields {
+		for _, token := range c.tokenizeField(doc, field) {
+			terms[token] = true
+		}
+	}
+	return terms
+}
+
+// jaccard returns the Jaccard similarity of two term sets: the share of their
+// combined terms found in both (1 for two empty sets)
+func jaccard(a, b map[string]bool) float64 {
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+	shared := 0
+	for term := range a {
+		if b[term] {
+			shared++
+		}
+	}
+	union := len(a) + len(b) - shared
+	if union == 0 {
+		return 1
+	}
+	return float64(shared) / float64(union)
+}
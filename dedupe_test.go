@@ -0,0 +1,79 @@
+package bm25md
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestJaccard(t *testing.T) {
+	set := func(terms ...string) map[string]bool {
+		s := make(map[string]bool, len(terms))
+		for _, term := range terms {
+			s[term] = true
+		}
+		return s
+	}
+	tests := []struct {
+		name     string
+		a, b     map[string]bool
+		expected float64
+	}{
+		{name: "identical", a: set("writ", "habeas"), b: set("habeas", "writ"), expected: 1},
+		{name: "half shared", a: set("writ", "habeas", "corpus"), b: set("writ", "habeas", "petition"), expected: 0.5},
+		{name: "disjoint", a: set("writ"), b: set("court"), expected: 0},
+		{name: "empty", a: set(), b: set(), expected: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := jaccard(tt.a, tt.b); got != tt.expected {
+				t.Errorf("jaccard() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCorpus_SearchDedupe(t *testing.T) {
+	boilerplate := "habeas petitions must be filed within one year of the final judgment"
+	docs := []Document{
+		{Fields: map[Field]string{FieldH1: "Filing deadlines", FieldBody: boilerplate}},
+		{Fields: map[Field]string{FieldH1: "Filing deadlines", FieldBody: boilerplate + " habeas"}},
+		{Fields: map[Field]string{FieldBody: "the court denied the habeas petition on the merits"}},
+		{Fields: map[Field]string{FieldH1: "Filing deadline", FieldBody: boilerplate}},
+	}
+	for i := range 10 {
+		docs = append(docs, Document{Fields: map[Field]string{FieldBody: fmt.Sprintf("filler document %d about calendars", i)}})
+	}
+	corpus := NewCorpus()
+	corpus.AddDocuments(docs)
+
+	if got := corpus.SearchWithOptions("habeas", SearchOptions{}); len(got) != 4 {
+		t.Fatalf("without Dedupe got %d results, want 4", len(got))
+	}
+
+	tests := []struct {
+		name     string
+		opts     SearchOptions
+		expected []int
+	}{
+		// the repeated boilerplate keeps its best-scoring copy, document 1
+		{name: "all", opts: SearchOptions{Dedupe: 0.8}, expected: []int{1, 2}},
+		{name: "limit", opts: SearchOptions{Dedupe: 0.8, Limit: 1}, expected: []int{1}},
+		{name: "offset", opts: SearchOptions{Dedupe: 0.8, Offset: 1, Limit: 1}, expected: []int{2}},
+		// document 0 has the same terms as document 1; document 3 differs in one
+		{name: "exact copies only", opts: SearchOptions{Dedupe: 1}, expected: []int{1, 2, 3}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := corpus.SearchWithOptions("habeas", tt.opts)
+			got := make([]int, len(results))
+			for i, result := range results {
+				got[i] = result.Index
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.expected) {
+				t.Errorf("results = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,141 @@
+//go:build !bm25md_noparser
+
+package bm25md
+
+import (
+	"fmt"
+	"io/fs"
+	"maps"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+// MetaFilePath is the metadata key holding the source file of documents from IndexDir
+const MetaFilePath = "path"
+
+// timestampKeys are the front matter keys read, in order, as a file's Timestamp
+var timestampKeys = []string{"updated", "lastmod", "last_modified", "date"}
+
+// IndexDir walks fsys, parses every markdown file whose name matches pattern
+// (eg "*.md", the default), and indexes it into a new corpus. A pattern without
+// a slash matches file names at any depth; one with a slash matches the whole
+// path (eg "docs/*.md"). Hidden directories are skipped. With a chunker, each file
+// is split into section documents with ExternalIDs "path#0", "path#1", ...;
+// without one, each file is a single document whose ExternalID is its path.
+// Documents are timestamped from front matter "updated", "lastmod", or "date",
+// and index their file name and directory (see PathFields).
+func IndexDir(fsys fs.FS, pattern string, chunker *Chunker, opts ...CorpusOption) (*Corpus, error) {
+	docs, err := LoadDir(fsys, pattern, chunker)
+	if err != nil {
+		return nil, err
+	}
+	corpus := NewCorpus(opts...)
+	corpus.AddDocuments(docs)
+	return corpus, nil
+}
+
+// LoadDir reads and parses matching markdown files like IndexDir, returning the
+// documents without indexing them
+func LoadDir(fsys fs.FS, pattern string, chunker *Chunker) ([]Document, error) {
+	if pattern == "" {
+		pattern = "*.md"
+	}
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, fmt.Errorf("bm25md: invalid pattern %q: %w", pattern, err)
+	}
+
+	var paths []string
+	err := fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if p != "." && strings.HasPrefix(d.Name(), ".") {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if matchesPattern(pattern, p) {
+			paths = append(paths, p)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("bm25md: walking directory: %w", err)
+	}
+	sort.Strings(paths)
+
+	documents := make([]Document, 0, len(paths))
+	for _, p := range paths {
+		docs, err := LoadFile(fsys, p, chunker)
+		if err != nil {
+			return nil, err
+		}
+		for _, doc := range docs {
+			doc.ID = len(documents)
+			documents = append(documents, doc)
+		}
+	}
+
+	return documents, nil
+}
+
+// LoadFile reads and parses one markdown file of fsys like LoadDir, returning its
+// document, or its section documents when chunker is not nil
+func LoadFile(fsys fs.FS, p string, chunker *Chunker) ([]Document, error) {
+	content, err := fs.ReadFile(fsys, p)
+	if err != nil {
+		return nil, fmt.Errorf("bm25md: reading %s: %w", p, err)
+	}
+	timestamp := fileTimestamp(string(content))
+	pathFields := PathFields(p)
+
+	if chunker == nil {
+		fields := defaultParser().ParseDocument(string(content))
+		maps.Copy(fields, pathFields)
+		return []Document{{
+			ExternalID: p,
+			Fields:     fields,
+			Original:   string(content),
+			Metadata:   map[string]any{MetaFilePath: p},
+			Timestamp:  timestamp,
+		}}, nil
+	}
+
+	chunks := chunker.Chunk(string(content))
+	for i := range chunks {
+		chunks[i].ExternalID = fmt.Sprintf("%s#%d", p, i)
+		chunks[i].Metadata[MetaFilePath] = p
+		chunks[i].Timestamp = timestamp
+		maps.Copy(chunks[i].Fields, pathFields)
+	}
+	return chunks, nil
+}
+
+// matchesPattern matches a pattern against a file name, or its full path when
+// the pattern contains a slash
+func matchesPattern(pattern, p string) bool {
+	name := path.Base(p)
+	if strings.Contains(pattern, "/") {
+		name = p
+	}
+	matched, _ := path.Match(pattern, name)
+	return matched
+}
+
+// fileTimestamp returns the first date found under timestampKeys in a file's
+// front matter, or the zero time
+func fileTimestamp(content string) time.Time {
+	fm, _, err := ParseFrontMatter(content)
+	if err != nil {
+		return time.Time{}
+	}
+	for _, key := range timestampKeys {
+		if t, ok := fm.Time(key); ok {
+			return t
+		}
+	}
+	return time.Time{}
+}
@@ -0,0 +1,116 @@
+//go:build !bm25md_noparser
+
+package bm25md
+
+import (
+	"reflect"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestLoadDir(t *testing.T) {
+	fsys := fstest.MapFS{
+		"README.md":               {Data: []byte("# Court Guide\n\nHow to file.")},
+		"docs/habeas.md":          {Data: []byte("---\ntitle: Habeas\n---\n# Petitions\n\nFile early.\n\n# Appeals\n\nAppeal late.")},
+		"docs/notes.txt":          {Data: []byte("not markdown")},
+		"docs/deep/calendar.md":   {Data: []byte("Court calendar.")},
+		".obsidian/workspace.md":  {Data: []byte("hidden")},
+		"drafts/unfinished.md.bk": {Data: []byte("backup")},
+	}
+
+	tests := []struct {
+		name     string
+		pattern  string
+		chunker  *Chunker
+		expected []string
+	}{
+		{
+			name:     "default pattern at any depth",
+			expected: []string{"README.md", "docs/deep/calendar.md", "docs/habeas.md"},
+		},
+		{
+			name:     "path pattern",
+			pattern:  "docs/*.md",
+			expected: []string{"docs/habeas.md"},
+		},
+		{
+			name:     "chunked",
+			pattern:  "docs/*.md",
+			chunker:  NewChunker(),
+			expected: []string{"docs/habeas.md#0", "docs/habeas.md#1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			docs, err := LoadDir(fsys, tt.pattern, tt.chunker)
+			if err != nil {
+				t.Fatalf("LoadDir() error = %v", err)
+			}
+			var ids []string
+			for i, doc := range docs {
+				ids = append(ids, doc.ExternalID)
+				if doc.ID != i {
+					t.Errorf("document %d has ID %d", i, doc.ID)
+				}
+				if doc.Metadata[MetaFilePath] == "" {
+					t.Errorf("document %s has no path metadata", doc.ExternalID)
+				}
+				if doc.Fields[FieldFileName] == "" {
+					t.Errorf("document %s has no file name field", doc.ExternalID)
+				}
+			}
+			if !reflect.DeepEqual(ids, tt.expected) {
+				t.Errorf("ExternalIDs = %v, want %v", ids, tt.expected)
+			}
+		})
+	}
+
+	if _, err := LoadDir(fsys, "[", nil); err == nil {
+		t.Error("LoadDir() with a malformed pattern succeeded")
+	}
+}
+
+func TestIndexDir(t *testing.T) {
+	fsys := fstest.MapFS{
+		"habeas.md":   {Data: []byte("---\ntitle: Habeas Corpus\n---\nPetitions for release.")},
+		"calendar.md": {Data: []byte("Court calendar.")},
+		"filing.md":   {Data: []byte("Filing deadlines.")},
+		"appeals.md":  {Data: []byte("Appeal rules.")},
+		"judges.md":   {Data: []byte("Judge assignments.")},
+	}
+
+	corpus, err := IndexDir(fsys, "", nil)
+	if err != nil {
+		t.Fatalf("IndexDir() error = %v", err)
+	}
+	results := corpus.Search("habeas", 5)
+	if len(results) != 1 || results[0].ExternalID != "habeas.md" {
+		t.Errorf("Search(habeas) = %+v, want habeas.md", results)
+	}
+	// file names are searchable even when the text does not mention them
+	if results := corpus.Search("judges", 5); len(results) != 1 || results[0].ExternalID != "judges.md" {
+		t.Errorf("Search(judges) = %+v, want judges.md", results)
+	}
+	if id, ok := corpus.LookupID("calendar.md"); !ok || corpus.Documents()[id].ExternalID != "calendar.md" {
+		t.Errorf("LookupID(calendar.md) = %d, %v", id, ok)
+	}
+}
+
+func TestLoadDir_Timestamps(t *testing.T) {
+	fsys := fstest.MapFS{
+		"dated.md":   {Data: []byte("---\ndate: 2023-01-02\nupdated: 2024-03-04\n---\nRelease notes.")},
+		"undated.md": {Data: []byte("Release notes.")},
+	}
+	docs, err := LoadDir(fsys, "", nil)
+	if err != nil {
+		t.Fatalf("LoadDir() error = %v", err)
+	}
+	if want := time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC); !docs[0].Timestamp.Equal(want) {
+		t.Errorf("Timestamp = %v, want %v", docs[0].Timestamp, want)
+	}
+	if !docs[1].Timestamp.IsZero() {
+		t.Errorf("undated Timestamp = %v, want zero", docs[1].Timestamp)
+	}
+}
@@ -0,0 +1,367 @@
+package bm25md
+
+import (
+	"math/bits"
+	"slices"
+	"sort"
+)
+
+// arrayLimit is the most values a container stores as a sorted array before it
+// switches to a bitmap (at which point both use 8KB)
+const arrayLimit = 4096
+
+// DocSet is a compressed set of document IDs in the style of a roaring bitmap:
+// IDs are grouped by their high 16 bits into containers holding the low 16 bits,
+// either as a sorted array (sparse) or a 65536-bit bitmap (dense). Intersections
+// and unions work container by container, so they stay cheap for large corpora.
+// The zero value is an empty set
+type DocSet struct {
+	keys       []uint16    // high bits of each container, ascending
+	containers []container // parallel to keys
+}
+
+// container holds the low 16 bits of the IDs sharing a key
+type container struct {
+	array  []uint16 // sorted values (nil when the container is a bitmap)
+	bitmap []uint64 // 1024 words (nil when the container is an array)
+	n      int      // number of values
+}
+
+// NewDocSet returns a set holding ids
+func NewDocSet(ids ...int) *DocSet {
+	s := &DocSet{}
+	for _, id := range ids {
+		s.Add(id)
+	}
+	return s
+}
+
+// split separates an ID into its container key and low bits
+func split(id int) (uint16, uint16) {
+	return uint16(uint32(id) >> 16), uint16(id)
+}
+
+// container returns the index of the container for key and whether it exists
+func (s *DocSet) container(key uint16) (int, bool) {
+	// IDs are mostly added in ascending order, so check the last container first
+	if n := len(s.keys); n > 0 && s.keys[n-1] == key {
+		return n - 1, true
+	}
+	return slices.BinarySearch(s.keys, key)
+}
+
+// Add adds a document ID to the set (negative IDs are ignored)
+func (s *DocSet) Add(id int) {
+	if id < 0 {
+		return
+	}
+	key, low := split(id)
+	i, found := s.container(key)
+	if !found {
+		s.keys = slices.Insert(s.keys, i, key)
+		s.containers = slices.Insert(s.containers, i, container{})
+	}
+	s.containers[i].add(low)
+}
+
+// Contains reports whether the set holds id
+func (s *DocSet) Contains(id int) bool {
+	if s == nil || id < 0 {
+		return false
+	}
+	key, low := split(id)
+	i, found := s.container(key)
+	return found && s.containers[i].contains(low)
+}
+
+// Len returns the number of IDs in the set
+func (s *DocSet) Len() int {
+	if s == nil {
+		return 0
+	}
+	n := 0
+	for _, c := range s.containers {
+		n += c.n
+	}
+	return n
+}
+
+// IDs returns the set's IDs in ascending order
+func (s *DocSet) IDs() []int {
+	ids := make([]int, 0, s.Len())
+	if s == nil {
+		return ids
+	}
+	for i, c := range s.containers {
+		high := int(s.keys[i]) << 16
+		c.each(func(low uint16) {
+			ids = append(ids, high|int(low))
+		})
+	}
+	return ids
+}
+
+// And returns the IDs in both sets
+func (s *DocSet) And(other *DocSet) *DocSet {
+	result := &DocSet{}
+	if s == nil || other == nil {
+		return result
+	}
+	for i, j := 0, 0; i < len(s.keys) && j < len(other.keys); {
+		switch {
+		case s.keys[i] < other.keys[j]:
+			i++
+		case s.keys[i] > other.keys[j]:
+			j++
+		default:
+			if c := s.containers[i].and(other.containers[j]); c.n > 0 {
+				result.keys = append(result.keys, s.keys[i])
+				result.containers = append(result.containers, c)
+			}
+			i++
+			j++
+		}
+	}
+	return result
+}
+
+// Or returns the IDs in either set
+func (s *DocSet) Or(other *DocSet) *DocSet {
+	result := &DocSet{}
+	if s == nil {
+		s = &DocSet{}
+	}
+	if other == nil {
+		other = &DocSet{}
+	}
+	i, j := 0, 0
+	for i < len(s.keys) || j < len(other.keys) {
+		switch {
+		case j == len(other.keys) || (i < len(s.keys) && s.keys[i] < other.keys[j]):
+			result.keys = append(result.keys, s.keys[i])
+			result.containers = append(result.containers, s.containers[i].clone())
+			i++
+		case i == len(s.keys) || s.keys[i] > other.keys[j]:
+			result.keys = append(result.keys, other.keys[j])
+			result.containers = append(result.containers, other.containers[j].clone())
+			j++
+		default:
+			result.keys = append(result.keys, s.keys[i])
+			result.containers = append(result.containers, s.containers[i].or(other.containers[j]))
+			i++
+			j++
+		}
+	}
+	return result
+}
+
+// AndNot returns the IDs in s that are not in other
+func (s *DocSet) AndNot(other *DocSet) *DocSet {
+	result := &DocSet{}
+	if s == nil {
+		return result
+	}
+	for i, key := range s.keys {
+		c := s.containers[i].clone()
+		if other != nil {
+			if j, found := other.container(key); found {
+				c = c.andNot(other.containers[j])
+			}
+		}
+		if c.n > 0 {
+			result.keys = append(result.keys, key)
+			result.containers = append(result.containers, c)
+		}
+	}
+	return result
+}
+
+// FilterSet returns the IDs of the live documents accepted by filter, so a filter
+// used by many searches can be evaluated once and passed as SearchOptions.Docs
+func (c *Corpus) FilterSet(filter Filter) *DocSet {
+	set := &DocSet{}
+	for i, doc := range c.documents {
+		if !c.deleted[i] && (filter == nil || filter(doc)) {
+			set.Add(i)
+		}
+	}
+	return set
+}
+
+// FilterSet returns the IDs of the documents accepted by filter, like Corpus.FilterSet
+func (ix *Index) FilterSet(filter Filter) *DocSet {
+	return ix.config.FilterSet(filter)
+}
+
+// add inserts a value, switching to a bitmap once the array grows too large
+func (c *container) add(low uint16) {
+	if c.bitmap != nil {
+		word, bit := low/64, uint64(1)<<(low%64)
+		if c.bitmap[word]&bit == 0 {
+			c.bitmap[word] |= bit
+			c.n++
+		}
+		return
+	}
+	i, found := slices.BinarySearch(c.array, low)
+	if found {
+		return
+	}
+	c.array = slices.Insert(c.array, i, low)
+	c.n++
+	if c.n > arrayLimit {
+		c.toBitmap()
+	}
+}
+
+// contains reports whether the container holds a value
+func (c *container) contains(low uint16) bool {
+	if c.bitmap != nil {
+		return c.bitmap[low/64]&(1<<(low%64)) != 0
+	}
+	_, found := slices.BinarySearch(c.array, low)
+	return found
+}
+
+// each calls fn for every value in ascending order
+func (c *container) each(fn func(uint16)) {
+	if c.bitmap == nil {
+		for _, low := range c.array {
+			fn(low)
+		}
+		return
+	}
+	for word, bitsSet := range c.bitmap {
+		for bitsSet != 0 {
+			bit := bits.TrailingZeros64(bitsSet)
+			fn(uint16(word*64 + bit))
+			bitsSet &= bitsSet - 1
+		}
+	}
+}
+
+// toBitmap converts an array container to a bitmap
+func (c *container) toBitmap() {
+	bitmap := make([]uint64, 1024)
+	for _, low := range c.array {
+		bitmap[low/64] |= 1 << (low % 64)
+	}
+	c.bitmap, c.array = bitmap, nil
+}
+
+// fromBitmap builds a container from bitmap words, using an array when sparse
+func fromBitmap(bitmap []uint64) container {
+	n := 0
+	for _, word := range bitmap {
+		n += bits.OnesCount64(word)
+	}
+	c := container{bitmap: bitmap, n: n}
+	if n <= arrayLimit {
+		array := make([]uint16, 0, n)
+		c.each(func(low uint16) { array = append(array, low) })
+		c = container{array: array, n: n}
+	}
+	return c
+}
+
+// clone returns an independent copy of the container
+func (c container) clone() container {
+	return container{array: slices.Clone(c.array), bitmap: slices.Clone(c.bitmap), n: c.n}
+}
+
+// and intersects two containers
+func (c container) and(other container) container {
+	switch {
+	case c.bitmap != nil && other.bitmap != nil:
+		bitmap := make([]uint64, 1024)
+		for i := range bitmap {
+			bitmap[i] = c.bitmap[i] & other.bitmap[i]
+		}
+		return fromBitmap(bitmap)
+	case c.bitmap != nil:
+		return other.and(c)
+	}
+
+	// c is an array: keep its values found in other
+	array := make([]uint16, 0, min(c.n, other.n))
+	if other.bitmap != nil {
+		for _, low := range c.array {
+			if other.contains(low) {
+				array = append(array, low)
+			}
+		}
+		return container{array: array, n: len(array)}
+	}
+	// merge two sorted arrays, galloping through the longer one
+	small, large := c.array, other.array
+	if len(small) > len(large) {
+		small, large = large, small
+	}
+	for _, low := range small {
+		i := sort.Search(len(large), func(k int) bool { return large[k] >= low })
+		if i == len(large) {
+			break
+		}
+		if large[i] == low {
+			array = append(array, low)
+		}
+		large = large[i:]
+	}
+	return container{array: array, n: len(array)}
+}
+
+// or unites two containers
+func (c container) or(other container) container {
+	if c.bitmap == nil && other.bitmap == nil && c.n+other.n <= arrayLimit {
+		array := make([]uint16, 0, c.n+other.n)
+		i, j := 0, 0
+		for i < len(c.array) || j < len(other.array) {
+			switch {
+			case j == len(other.array) || (i < len(c.array) && c.array[i] < other.array[j]):
+				array = append(array, c.array[i])
+				i++
+			case i == len(c.array) || c.array[i] > other.array[j]:
+				array = append(array, other.array[j])
+				j++
+			default:
+				array = append(array, c.array[i])
+				i++
+				j++
+			}
+		}
+		return container{array: array, n: len(array)}
+	}
+
+	bitmap := make([]uint64, 1024)
+	for _, side := range []container{c, other} {
+		if side.bitmap != nil {
+			for i, word := range side.bitmap {
+				bitmap[i] |= word
+			}
+		} else {
+			for _, low := range side.array {
+				bitmap[low/64] |= 1 << (low % 64)
+			}
+		}
+	}
+	return fromBitmap(bitmap)
+}
+
+// andNot removes the values of other from a container
+func (c container) andNot(other container) container {
+	if c.bitmap == nil {
+		array := make([]uint16, 0, c.n)
+		for _, low := range c.array {
+			if !other.contains(low) {
+				array = append(array, low)
+			}
+		}
+		return container{array: array, n: len(array)}
+	}
+
+	bitmap := slices.Clone(c.bitmap)
+	other.each(func(low uint16) {
+		bitmap[low/64] &^= 1 << (low % 64)
+	})
+	return fromBitmap(bitmap)
+}
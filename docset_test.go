@@ -0,0 +1,130 @@
+package bm25md
+
+import (
+	"math/rand"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// referenceSet builds a sorted ID list and membership map for comparison
+func referenceSet(ids []int) map[int]bool {
+	set := make(map[int]bool, len(ids))
+	for _, id := range ids {
+		set[id] = true
+	}
+	return set
+}
+
+// sortedIDs returns the members of a reference set in order
+func sortedIDs(set map[int]bool) []int {
+	ids := make([]int, 0, len(set))
+	for id := range set {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+func TestDocSet(t *testing.T) {
+	rng := rand.New(rand.NewSource(7))
+	random := func(n, span int) []int {
+		ids := make([]int, n)
+		for i := range ids {
+			ids[i] = rng.Intn(span)
+		}
+		return ids
+	}
+
+	tests := []struct {
+		name string
+		a, b []int
+	}{
+		{"empty", nil, nil},
+		{"sparse", random(100, 1<<20), random(100, 1<<20)},
+		{"overlapping sparse", random(3000, 10000), random(3000, 10000)},
+		{"dense", random(60000, 1<<17), random(50000, 1<<17)},
+		{"dense and sparse", random(60000, 1<<16), random(200, 1<<16)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, b := NewDocSet(tt.a...), NewDocSet(tt.b...)
+			refA, refB := referenceSet(tt.a), referenceSet(tt.b)
+
+			if got := a.IDs(); !reflect.DeepEqual(got, sortedIDs(refA)) {
+				t.Fatalf("IDs() has %d IDs, want %d", len(got), len(refA))
+			}
+			if a.Len() != len(refA) {
+				t.Errorf("Len() = %d, want %d", a.Len(), len(refA))
+			}
+			for _, id := range append(random(50, 1<<17), tt.b...) {
+				if a.Contains(id) != refA[id] {
+					t.Fatalf("Contains(%d) = %v, want %v", id, a.Contains(id), refA[id])
+				}
+			}
+
+			and, or, andNot := map[int]bool{}, map[int]bool{}, map[int]bool{}
+			for id := range refA {
+				or[id] = true
+				if refB[id] {
+					and[id] = true
+				} else {
+					andNot[id] = true
+				}
+			}
+			for id := range refB {
+				or[id] = true
+			}
+			if got := a.And(b).IDs(); !reflect.DeepEqual(got, sortedIDs(and)) {
+				t.Errorf("And() has %d IDs, want %d", len(got), len(and))
+			}
+			if got := a.Or(b).IDs(); !reflect.DeepEqual(got, sortedIDs(or)) {
+				t.Errorf("Or() has %d IDs, want %d", len(got), len(or))
+			}
+			if got := a.AndNot(b).IDs(); !reflect.DeepEqual(got, sortedIDs(andNot)) {
+				t.Errorf("AndNot() has %d IDs, want %d", len(got), len(andNot))
+			}
+			// operations leave their inputs untouched
+			if a.Len() != len(refA) || b.Len() != len(refB) {
+				t.Error("set operations modified their inputs")
+			}
+		})
+	}
+
+	var none *DocSet
+	if none.Len() != 0 || none.Contains(1) || len(none.IDs()) != 0 || NewDocSet(1).And(none).Len() != 0 ||
+		NewDocSet(1).Or(none).Len() != 1 || NewDocSet(1).AndNot(none).Len() != 1 {
+		t.Error("nil sets do not behave as empty")
+	}
+	if NewDocSet(-1).Len() != 0 {
+		t.Error("Add() kept a negative ID")
+	}
+}
+
+func TestSearchOptions_Docs(t *testing.T) {
+	corpus := randomCorpus(400)
+	index := corpus.Freeze()
+	even := corpus.FilterSet(MetadataEquals("even", true))
+	if even.Len() != 200 || !even.Contains(0) || even.Contains(1) {
+		t.Fatalf("FilterSet() = %d documents", even.Len())
+	}
+	if index.FilterSet(MetadataEquals("even", true)).Len() != 200 {
+		t.Error("Index.FilterSet() disagrees with Corpus.FilterSet()")
+	}
+
+	for _, query := range []string{"custody detention", "+court +federal statute", "+court +federal -state", "+custody"} {
+		opts := SearchOptions{Limit: 20, Docs: even}
+		want := corpus.SearchWithOptions(query, opts)
+		for _, result := range want {
+			if result.Index%2 != 0 {
+				t.Fatalf("Search(%q) returned document %d outside the set", query, result.Index)
+			}
+		}
+		sameResults(t, index.SearchWithOptions(query, opts), want)
+
+		// required terms intersect without a document set too
+		opts.Docs = nil
+		sameResults(t, index.SearchWithOptions(query, opts), corpus.SearchWithOptions(query, opts))
+	}
+}
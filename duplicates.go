@@ -0,0 +1,146 @@
+package bm25md
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+	"math"
+	"slices"
+)
+
+// minHashes is the length of the MinHash signature of each document
+const minHashes = 128
+
+// NearDuplicates reports clusters of near-duplicate documents, such as chunks
+// repeating the same boilerplate, so they can be cleaned before serving search.
+// Two documents are near-duplicates when the Jaccard similarity of their indexed
+// terms (the share of their combined terms found in both) is at least threshold,
+// estimated from MinHash signatures built from the postings and compared only
+// for pairs that locality-sensitive hashing buckets together. Clusters join
+// documents linked by such pairs; each lists document IDs in ascending order,
+// and clusters are ordered by their first ID
+func (c *Corpus) NearDuplicates(threshold float64) [][]int {
+	if threshold <= 0 || c.liveDocuments() < 2 {
+		return nil
+	}
+	signatures := c.minHashSignatures()
+
+	// documents agreeing on every row of some band become candidate pairs
+	rows := lshRows(min(threshold, 1))
+	parent := make(map[int]int)
+	var find func(id int) int
+	find = func(id int) int {
+		if p, ok := parent[id]; ok && p != id {
+			parent[id] = find(p)
+			return parent[id]
+		}
+		return id
+	}
+
+	ids := make([]int, 0, len(signatures))
+	for id := range signatures {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	for band := 0; band+rows <= minHashes; band += rows {
+		buckets := make(map[string][]int)
+		key := make([]byte, 8*rows)
+		for _, id := range ids {
+			for r, h := range signatures[id][band : band+rows] {
+				binary.LittleEndian.PutUint64(key[8*r:], h)
+			}
+			buckets[string(key)] = append(buckets[string(key)], id)
+		}
+		for _, bucket := range buckets {
+			for i, a := range bucket {
+				for _, b := range bucket[i+1:] {
+					if find(a) == find(b) || signatureSimilarity(signatures[a], signatures[b]) < threshold {
+						continue
+					}
+					parent[find(b)] = find(a)
+				}
+			}
+		}
+	}
+
+	groups := make(map[int][]int)
+	for _, id := range ids {
+		root := find(id)
+		groups[root] = append(groups[root], id)
+	}
+	var clusters [][]int
+	for _, group := range groups {
+		if len(group) > 1 {
+			clusters = append(clusters, group)
+		}
+	}
+	slices.SortFunc(clusters, func(a, b []int) int { return a[0] - b[0] })
+	return clusters
+}
+
+// minHashSignatures returns the MinHash signature of each live document with
+// indexed terms: for every hash function, the smallest hash of its terms
+func (c *Corpus) minHashSignatures() map[int][]uint64 {
+	signatures := make(map[int][]uint64)
+	hashes := make([]uint64, minHashes)
+	for term, postings := range c.postings {
+		h := fnv.New64a()
+		h.Write([]byte(term))
+		base := h.Sum64()
+		for i := range hashes {
+			hashes[i] = mix64(base ^ uint64(i+1)*0x9e3779b97f4a7c15)
+		}
+
+		for id := range postings {
+			if !c.isLive(id) {
+				continue
+			}
+			signature, exists := signatures[id]
+			if !exists {
+				signature = slices.Clone(hashes)
+				signatures[id] = signature
+				continue
+			}
+			for i, v := range hashes {
+				signature[i] = min(signature[i], v)
+			}
+		}
+	}
+	return signatures
+}
+
+// lshRows returns the rows per band for locality-sensitive hashing: the most
+// rows whose collision threshold, about (1/bands)^(1/rows), stays at or below
+// threshold, so most pairs above it share a band
+func lshRows(threshold float64) int {
+	rows := 1
+	for r := 2; r <= minHashes; r++ {
+		bands := minHashes / r
+		if math.Pow(1/float64(bands), 1/float64(r)) > threshold {
+			break
+		}
+		rows = r
+	}
+	return rows
+}
+
+// signatureSimilarity estimates the Jaccard similarity of two documents as the
+// share of their MinHash values that agree
+func signatureSimilarity(a, b []uint64) float64 {
+	same := 0
+	for i := range a {
+		if a[i] == b[i] {
+			same++
+		}
+	}
+	return float64(same) / float64(len(a))
+}
+
+// mix64 scrambles the bits of x (the splitmix64 finalizer)
+func mix64(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
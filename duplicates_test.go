@@ -0,0 +1,58 @@
+package bm25md
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestCorpus_NearDuplicates(t *testing.T) {
+	boilerplate := "habeas petitions must be filed within one year of the date on which the judgment became final by the conclusion of direct review"
+	docs := []Document{
+		{Fields: map[Field]string{FieldBody: boilerplate}},
+		{Fields: map[Field]string{FieldBody: "the court denied the habeas petition on the merits"}},
+		{Fields: map[Field]string{FieldBody: boilerplate + " or the expiration of the time for seeking such review"}},
+		{Fields: map[Field]string{FieldH1: "Deadlines", FieldBody: boilerplate}},
+		{Fields: map[Field]string{FieldBody: "the court denied the habeas petition on the merits"}},
+	}
+	corpus := NewCorpus()
+	corpus.AddDocuments(docs)
+
+	tests := []struct {
+		threshold float64
+		expected  [][]int
+	}{
+		{threshold: 1, expected: [][]int{{1, 4}}},
+		{threshold: 0.9, expected: [][]int{{0, 3}, {1, 4}}},
+		{threshold: 0.6, expected: [][]int{{0, 2, 3}, {1, 4}}},
+		{threshold: 0, expected: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.threshold), func(t *testing.T) {
+			if got := corpus.NearDuplicates(tt.threshold); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("NearDuplicates(%v) = %v, want %v", tt.threshold, got, tt.expected)
+			}
+		})
+	}
+
+	// removed documents are not reported
+	if err := corpus.RemoveDocument(4); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := corpus.NearDuplicates(1), [][]int(nil); !reflect.DeepEqual(got, want) {
+		t.Errorf("after removal, NearDuplicates(1) = %v, want %v", got, want)
+	}
+}
+
+func TestLSHRows(t *testing.T) {
+	for _, threshold := range []float64{0.3, 0.5, 0.8, 0.95} {
+		rows := lshRows(threshold)
+		bands := minHashes / rows
+		// a pair exactly at the threshold should usually share a band
+		if p := 1 - math.Pow(1-math.Pow(threshold, float64(rows)), float64(bands)); p < 0.5 {
+			t.Errorf("lshRows(%v) = %d, finding pairs at the threshold with probability %v", threshold, rows, p)
+		}
+	}
+}
@@ -0,0 +1,179 @@
+// Package eval measures the ranking quality of bm25md searches against relevance
+// judgments with standard information retrieval metrics (NDCG, MRR, MAP, and
+// precision and recall at a cutoff), so configurations such as field weights can
+// be compared by numbers rather than by eyeballing results.
+package eval
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strconv"
+
+	"github.com/chriscorrea/bm25md"
+)
+
+// Searcher ranks documents for a query; Corpus, Index, ShardedCorpus, and
+// LiveIndex all implement it
+type Searcher interface {
+	Search(query string, limit int) []bm25md.SearchResult
+}
+
+// Judgments grades documents, by ID, for one query: 0 is not relevant, and
+// higher grades are more relevant. Unjudged documents count as not relevant
+type Judgments map[string]int
+
+// Query is a query with its relevance judgments
+type Query struct {
+	ID        string // identifies the query in reports (the text when empty)
+	Text      string
+	Judgments Judgments
+}
+
+// Metrics are the ranking quality of one query, or their mean over queries.
+// Each lies between 0 and 1, higher being better
+type Metrics struct {
+	NDCG      float64 // normalized discounted cumulative gain of graded relevance
+	MRR       float64 // reciprocal rank of the first relevant document
+	MAP       float64 // average precision over the ranks of relevant documents
+	Precision float64 // share of the top k documents that are relevant
+	Recall    float64 // share of relevant documents in the top k
+}
+
+// QueryMetrics are the metrics of one query of an evaluation
+type QueryMetrics struct {
+	Query   Query
+	Ranking []string // IDs of the documents returned, best first
+	Metrics Metrics
+}
+
+// Evaluation is the result of Evaluate
+type Evaluation struct {
+	K       int
+	Queries []QueryMetrics
+	Mean    Metrics // metrics averaged over queries
+}
+
+// Evaluate searches for each query, keeping the top k results (every match when
+// k <= 0), and scores the rankings against the query's judgments. Results are
+// identified by ExternalID, or by internal ID for documents without one
+func Evaluate(searcher Searcher, queries []Query, k int) Evaluation {
+	evaluation := Evaluation{K: k, Queries: make([]QueryMetrics, 0, len(queries))}
+	for _, query := range queries {
+		ranking := DocumentIDs(searcher.Search(query.Text, k))
+		metrics := Score(ranking, query.Judgments, k)
+		evaluation.Queries = append(evaluation.Queries, QueryMetrics{Query: query, Ranking: ranking, Metrics: metrics})
+		evaluation.Mean.add(metrics)
+	}
+	if n := float64(len(queries)); n > 0 {
+		evaluation.Mean.NDCG /= n
+		evaluation.Mean.MRR /= n
+		evaluation.Mean.MAP /= n
+		evaluation.Mean.Precision /= n
+		evaluation.Mean.Recall /= n
+	}
+	return evaluation
+}
+
+// DocumentIDs returns the IDs of results in rank order, as Evaluate identifies them
+func DocumentIDs(results []bm25md.SearchResult) []string {
+	ids := make([]string, len(results))
+	for i, result := range results {
+		ids[i] = result.ExternalID
+		if ids[i] == "" {
+			ids[i] = strconv.Itoa(result.Index)
+		}
+	}
+	return ids
+}
+
+// Score computes the metrics of a ranking of document IDs, cut off after k
+// (none when k <= 0), against judgments. Metrics are 0 for a query without
+// relevant documents. MAP divides by every relevant document, as trec_eval
+// does, so relevant documents ranked below k lower it
+func Score(ranking []string, judgments Judgments, k int) Metrics {
+	if k > 0 && len(ranking) > k {
+		ranking = ranking[:k]
+	}
+	var grades []int
+	for _, grade := range judgments {
+		if grade > 0 {
+			grades = append(grades, grade)
+		}
+	}
+	if len(grades) == 0 {
+		return Metrics{}
+	}
+
+	var m Metrics
+	var dcg float64
+	relevant := 0
+	for i, id := range ranking {
+		grade := judgments[id]
+		if grade <= 0 {
+			continue
+		}
+		relevant++
+		if m.MRR == 0 {
+			m.MRR = 1 / float64(i+1)
+		}
+		m.MAP += float64(relevant) / float64(i+1)
+		dcg += gain(grade, i)
+	}
+	m.MAP /= float64(len(grades))
+	m.Recall = float64(relevant) / float64(len(grades))
+	// a ranking shorter than k is padded with irrelevant documents
+	if depth := max(k, len(ranking)); depth > 0 {
+		m.Precision = float64(relevant) / float64(depth)
+	}
+
+	// the ideal ranking lists relevant documents by descending grade
+	slices.Sort(grades)
+	slices.Reverse(grades)
+	if k > 0 && len(grades) > k {
+		grades = grades[:k]
+	}
+	var idcg float64
+	for i, grade := range grades {
+		idcg += gain(grade, i)
+	}
+	m.NDCG = dcg / idcg
+	return m
+}
+
+// gain returns the discounted gain of a document of grade at rank i (from 0)
+func gain(grade, i int) float64 {
+	return (math.Exp2(float64(grade)) - 1) / math.Log2(float64(i+2))
+}
+
+// add sums the metrics of another query
+func (m *Metrics) add(other Metrics) {
+	m.NDCG += other.NDCG
+	m.MRR += other.MRR
+	m.MAP += other.MAP
+	m.Precision += other.Precision
+	m.Recall += other.Recall
+}
+
+// String formats the metrics on one line
+func (m Metrics) String() string {
+	return fmt.Sprintf("ndcg: %.4f  mrr: %.4f  map: %.4f  p: %.4f  recall: %.4f", m.NDCG, m.MRR, m.MAP, m.Precision, m.Recall)
+}
+
+// Report writes the mean metrics followed by each query's metrics
+func (r Evaluation) Report(w io.Writer) error {
+	if _, err := fmt.Fprintf(w, "queries: %d  k: %d  %v\n", len(r.Queries), r.K, r.Mean); err != nil {
+		return err
+	}
+	for _, q := range r.Queries {
+		id := q.Query.ID
+		if id == "" {
+			id = q.Query.Text
+		}
+		if _, err := fmt.Fprintf(w, "%q  %v\n", id, q.Metrics); err != nil {
+			return err
+		}
+	}
+	return nil
+}
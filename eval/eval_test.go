@@ -0,0 +1,134 @@
+package eval
+
+import (
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/chriscorrea/bm25md"
+)
+
+func TestScore(t *testing.T) {
+	tests := []struct {
+		name      string
+		ranking   []string
+		judgments Judgments
+		k         int
+		expected  Metrics
+	}{
+		{
+			name:      "binary relevance",
+			ranking:   []string{"x", "a", "y", "b"},
+			judgments: Judgments{"a": 1, "b": 1, "c": 1, "z": 0},
+			k:         4,
+			expected: Metrics{
+				NDCG:      (1/math.Log2(3) + 1/math.Log2(5)) / (1 + 1/math.Log2(3) + 1/math.Log2(4)),
+				MRR:       0.5,
+				MAP:       (1.0/2 + 2.0/4) / 3,
+				Precision: 0.5,
+				Recall:    2.0 / 3,
+			},
+		},
+		{
+			name:      "ideal graded ranking",
+			ranking:   []string{"a", "b"},
+			judgments: Judgments{"a": 2, "b": 1},
+			k:         10,
+			expected:  Metrics{NDCG: 1, MRR: 1, MAP: 1, Precision: 0.2, Recall: 1},
+		},
+		{
+			name:      "grades out of order",
+			ranking:   []string{"b", "a"},
+			judgments: Judgments{"a": 2, "b": 1},
+			k:         10,
+			expected:  Metrics{NDCG: (1 + 3/math.Log2(3)) / (3 + 1/math.Log2(3)), MRR: 1, MAP: 1, Precision: 0.2, Recall: 1},
+		},
+		{
+			name:      "relevant document below the cutoff",
+			ranking:   []string{"x", "a"},
+			judgments: Judgments{"a": 1},
+			k:         1,
+			expected:  Metrics{},
+		},
+		{
+			name:      "no cutoff",
+			ranking:   []string{"x", "a"},
+			judgments: Judgments{"a": 1},
+			k:         0,
+			expected:  Metrics{NDCG: 1 / math.Log2(3), MRR: 0.5, MAP: 0.5, Precision: 0.5, Recall: 1},
+		},
+		{
+			name:      "no relevant documents",
+			ranking:   []string{"x"},
+			judgments: Judgments{"x": 0},
+			k:         10,
+			expected:  Metrics{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Score(tt.ranking, tt.judgments, tt.k)
+			for _, metric := range []struct {
+				name      string
+				got, want float64
+			}{
+				{"NDCG", got.NDCG, tt.expected.NDCG},
+				{"MRR", got.MRR, tt.expected.MRR},
+				{"MAP", got.MAP, tt.expected.MAP},
+				{"Precision", got.Precision, tt.expected.Precision},
+				{"Recall", got.Recall, tt.expected.Recall},
+			} {
+				if math.Abs(metric.got-metric.want) > 1e-9 {
+					t.Errorf("%s = %v, want %v", metric.name, metric.got, metric.want)
+				}
+			}
+		})
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	corpus := bm25md.NewCorpus()
+	corpus.AddDocuments([]bm25md.Document{
+		{ExternalID: "writ", Fields: map[bm25md.Field]string{bm25md.FieldH1: "habeas corpus", bm25md.FieldBody: "the writ of habeas corpus"}},
+		{ExternalID: "petition", Fields: map[bm25md.Field]string{bm25md.FieldBody: "filing a habeas petition"}},
+		{ExternalID: "jury", Fields: map[bm25md.Field]string{bm25md.FieldBody: "jury trial procedure"}},
+		{Fields: map[bm25md.Field]string{bm25md.FieldBody: "appeal deadlines"}},
+		{Fields: map[bm25md.Field]string{bm25md.FieldBody: "custody review"}},
+	})
+
+	queries := []Query{
+		{ID: "q1", Text: "habeas corpus", Judgments: Judgments{"writ": 2, "petition": 1}},
+		{ID: "q2", Text: "jury", Judgments: Judgments{"petition": 1}},
+		{ID: "q3", Text: "appeal", Judgments: Judgments{"3": 1}},
+	}
+	evaluation := Evaluate(corpus, queries, 10)
+
+	if got := evaluation.Queries[0].Ranking; !reflect.DeepEqual(got, []string{"writ", "petition"}) {
+		t.Errorf("q1 ranking = %v, want [writ petition]", got)
+	}
+	if got := evaluation.Queries[0].Metrics; got.NDCG != 1 || got.MAP != 1 {
+		t.Errorf("q1 metrics = %v, want ideal", got)
+	}
+	if got := evaluation.Queries[1].Metrics; got != (Metrics{}) {
+		t.Errorf("q2 metrics = %v, want zero", got)
+	}
+	// documents without an ExternalID are identified by internal ID
+	if got := evaluation.Queries[2].Metrics.MRR; got != 1 {
+		t.Errorf("q3 MRR = %v, want 1", got)
+	}
+	if got := evaluation.Mean.MRR; math.Abs(got-2.0/3) > 1e-9 {
+		t.Errorf("mean MRR = %v, want 2/3", got)
+	}
+
+	var report strings.Builder
+	if err := evaluation.Report(&report); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"queries: 3  k: 10  ndcg: ", `"q2"  ndcg: 0.0000`} {
+		if !strings.Contains(report.String(), want) {
+			t.Errorf("report is missing %q:\n%s", want, report.String())
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package eval
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ReadQrels reads relevance judgments in the TREC qrels format used by trec_eval,
+// one judgment per line:
+//
+//	query-id iteration document-id grade
+//
+// The iteration column is ignored, and blank lines are skipped. It returns the
+// judgments of each query by query ID
+func ReadQrels(r io.Reader) (map[string]Judgments, error) {
+	qrels := make(map[string]Judgments)
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) != 4 {
+			return nil, fmt.Errorf("eval: qrels line %d: got %d columns, want 4", line, len(fields))
+		}
+		grade, err := strconv.Atoi(fields[3])
+		if err != nil {
+			return nil, fmt.Errorf("eval: qrels line %d: invalid grade %q", line, fields[3])
+		}
+		if qrels[fields[0]] == nil {
+			qrels[fields[0]] = make(Judgments)
+		}
+		qrels[fields[0]][fields[2]] = grade
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("eval: reading qrels: %w", err)
+	}
+	return qrels, nil
+}
@@ -0,0 +1,45 @@
+package eval
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadQrels(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected map[string]Judgments
+		wantErr  string
+	}{
+		{
+			name:  "judgments",
+			input: "q1 0 writ 2\nq1 0 petition 1\n\nq2 0 jury 0\n",
+			expected: map[string]Judgments{
+				"q1": {"writ": 2, "petition": 1},
+				"q2": {"jury": 0},
+			},
+		},
+		{name: "missing column", input: "q1 0 writ\n", wantErr: "eval: qrels line 1: got 3 columns, want 4"},
+		{name: "invalid grade", input: "q1 0 writ 2\nq1 0 jury high\n", wantErr: `eval: qrels line 2: invalid grade "high"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ReadQrels(strings.NewReader(tt.input))
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("ReadQrels() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ReadQrels() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("ReadQrels() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
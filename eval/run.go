@@ -0,0 +1,66 @@
+package eval
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/chriscorrea/bm25md"
+)
+
+// DefaultRunTag names the system in run files written without a tag
+const DefaultRunTag = "bm25md"
+
+// WriteRun writes the results of one query in the TREC run format read by
+// trec_eval, one line per result:
+//
+//	query-id Q0 document-id rank score tag
+//
+// Ranks start at 1, and documents are identified as by Evaluate. Query and
+// document IDs must not contain whitespace
+func WriteRun(w io.Writer, queryID string, results []bm25md.SearchResult, tag string) error {
+	if tag == "" {
+		tag = DefaultRunTag
+	}
+	for _, id := range []string{queryID, tag} {
+		if err := checkRunID(id); err != nil {
+			return err
+		}
+	}
+	for i, id := range DocumentIDs(results) {
+		if err := checkRunID(id); err != nil {
+			return err
+		}
+		score := strconv.FormatFloat(results[i].Score, 'g', -1, 64)
+		if _, err := fmt.Fprintf(w, "%s Q0 %s %d %s %s\n", queryID, id, i+1, score, tag); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SearchRun searches for each query, keeping the top k results (every match
+// when k <= 0), and writes them as a TREC run. Queries without an ID are
+// numbered by position from 1
+func SearchRun(w io.Writer, searcher Searcher, queries []Query, k int, tag string) error {
+	for i, query := range queries {
+		id := query.ID
+		if id == "" {
+			id = strconv.Itoa(i + 1)
+		}
+		if err := WriteRun(w, id, searcher.Search(query.Text, k), tag); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkRunID rejects IDs that would break the columns of a run file
+func checkRunID(id string) error {
+	if id == "" || strings.ContainsFunc(id, unicode.IsSpace) {
+		return fmt.Errorf("eval: run ID %q is empty or contains whitespace", id)
+	}
+	return nil
+}
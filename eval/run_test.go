@@ -0,0 +1,68 @@
+package eval
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/chriscorrea/bm25md"
+)
+
+func TestWriteRun(t *testing.T) {
+	results := []bm25md.SearchResult{
+		{ExternalID: "writ.md", Score: 2.5},
+		{Index: 7, Score: 1.25},
+	}
+
+	tests := []struct {
+		name     string
+		queryID  string
+		results  []bm25md.SearchResult
+		tag      string
+		expected string
+		wantErr  bool
+	}{
+		{
+			name:     "results",
+			queryID:  "301",
+			results:  results,
+			tag:      "fields",
+			expected: "301 Q0 writ.md 1 2.5 fields\n301 Q0 7 2 1.25 fields\n",
+		},
+		{name: "default tag", queryID: "q", results: results[:1], expected: "q Q0 writ.md 1 2.5 bm25md\n"},
+		{name: "no results", queryID: "q", expected: ""},
+		{name: "query ID with space", queryID: "q 1", results: results, wantErr: true},
+		{name: "document ID with space", queryID: "q", results: []bm25md.SearchResult{{ExternalID: "my notes.md"}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var run strings.Builder
+			err := WriteRun(&run, tt.queryID, tt.results, tt.tag)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("WriteRun() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && run.String() != tt.expected {
+				t.Errorf("WriteRun() = %q, want %q", run.String(), tt.expected)
+			}
+		})
+	}
+}
+
+func TestSearchRun(t *testing.T) {
+	corpus := bm25md.NewCorpus()
+	corpus.AddDocuments([]bm25md.Document{
+		{ExternalID: "writ", Fields: map[bm25md.Field]string{bm25md.FieldBody: "the writ of habeas corpus"}},
+		{ExternalID: "jury", Fields: map[bm25md.Field]string{bm25md.FieldBody: "jury trial procedure"}},
+		{ExternalID: "appeal", Fields: map[bm25md.Field]string{bm25md.FieldBody: "appeal deadlines"}},
+	})
+
+	var run strings.Builder
+	queries := []Query{{ID: "q1", Text: "habeas"}, {Text: "jury trial"}}
+	if err := SearchRun(&run, corpus, queries, 10, "test"); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(run.String()), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], "q1 Q0 writ 1 ") || !strings.HasPrefix(lines[1], "2 Q0 jury 1 ") {
+		t.Errorf("run = %q, want one result for q1 and for query 2", run.String())
+	}
+}
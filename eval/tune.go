@@ -0,0 +1,150 @@
+package eval
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/chriscorrea/bm25md"
+)
+
+// Candidate values Tune tries by default
+var (
+	DefaultK1Values     = []float64{0.6, 0.9, 1.2, 1.5, 2.0}
+	DefaultBValues      = []float64{0, 0.25, 0.5, 0.75, 1}
+	DefaultWeightValues = []float64{0, 0.5, 1, 2, 4, 8}
+)
+
+// Config is a ranking configuration explored by Tune
+type Config struct {
+	Params  bm25md.BM25Parameters
+	Weights map[bm25md.Field]float64
+}
+
+// Options returns the corpus options applying the configuration
+func (c Config) Options() []bm25md.CorpusOption {
+	return []bm25md.CorpusOption{bm25md.WithBM25Params(c.Params), bm25md.WithFieldWeights(c.Weights)}
+}
+
+// String formats the configuration with fields in sorted order
+func (c Config) String() string {
+	s := fmt.Sprintf("k1=%g b=%g", c.Params.K1, c.Params.B)
+	for _, field := range slices.Sorted(maps.Keys(c.Weights)) {
+		s += fmt.Sprintf(" %s=%g", field, c.Weights[field])
+	}
+	return s
+}
+
+// TuneOptions configures Tune; zero values select the defaults
+type TuneOptions struct {
+	K         int                   // cutoff of each evaluation (0 = 10)
+	Objective func(Metrics) float64 // mean metrics to maximize (nil = NDCG)
+	Start     Config                // starting configuration (zero = package defaults)
+	K1        []float64             // K1 values tried (nil = DefaultK1Values)
+	B         []float64             // B values tried (nil = DefaultBValues)
+	Weights   []float64             // weights tried for each field (nil = DefaultWeightValues)
+	Fields    []bm25md.Field        // fields whose weights are tuned (nil = every field of Start)
+	Rounds    int                   // maximum passes over the parameters (0 = 3)
+	Options   []bm25md.CorpusOption // other corpus options, such as analyzers, applied first
+}
+
+// Tuning is the result of Tune
+type Tuning struct {
+	Config     Config
+	Score      float64    // objective of Config
+	Baseline   float64    // objective of the starting configuration
+	Evaluation Evaluation // evaluation of Config
+	Trials     int        // configurations evaluated
+}
+
+// Tune searches for the K1, B, and field weights that rank docs best for the
+// judged queries, by coordinate ascent: starting from opts.Start, it tries each
+// candidate value of one parameter at a time, keeping any that raises the
+// objective, and repeats until a pass improves nothing or opts.Rounds passes
+// are done. Every trial indexes docs into a new corpus, so tuning sets are best
+// kept to the judged documents and a sample of others. The best configuration
+// may overfit the queries; check it against held-out judgments
+func Tune(docs []bm25md.Document, queries []Query, opts TuneOptions) Tuning {
+	opts = opts.withDefaults()
+
+	tuning := Tuning{}
+	scores := make(map[string]float64) // objective of each configuration tried
+	try := func(config Config) bool {
+		key := config.String()
+		if _, tried := scores[key]; tried {
+			return false
+		}
+		corpus := bm25md.NewCorpus(append(slices.Clone(opts.Options), config.Options()...)...)
+		corpus.AddDocuments(docs)
+		evaluation := Evaluate(corpus, queries, opts.K)
+		score := opts.Objective(evaluation.Mean)
+		scores[key] = score
+		tuning.Trials++
+		if tuning.Trials > 1 && score <= tuning.Score {
+			return false
+		}
+		tuning.Config, tuning.Score, tuning.Evaluation = config, score, evaluation
+		return true
+	}
+	try(opts.Start)
+	tuning.Baseline = tuning.Score
+
+	for round := 0; round < opts.Rounds; round++ {
+		improved := false
+		for _, k1 := range opts.K1 {
+			config := tuning.Config
+			config.Params.K1 = k1
+			improved = try(config) || improved
+		}
+		for _, b := range opts.B {
+			config := tuning.Config
+			config.Params.B = b
+			improved = try(config) || improved
+		}
+		for _, field := range opts.Fields {
+			for _, weight := range opts.Weights {
+				config := tuning.Config
+				config.Weights = maps.Clone(config.Weights)
+				config.Weights[field] = weight
+				improved = try(config) || improved
+			}
+		}
+		if !improved {
+			break
+		}
+	}
+	return tuning
+}
+
+// withDefaults fills in unset options
+func (opts TuneOptions) withDefaults() TuneOptions {
+	if opts.K <= 0 {
+		opts.K = 10
+	}
+	if opts.Objective == nil {
+		opts.Objective = func(m Metrics) float64 { return m.NDCG }
+	}
+	if opts.Start.Params == (bm25md.BM25Parameters{}) {
+		opts.Start.Params = bm25md.DefaultBM25Parameters()
+	}
+	if opts.Start.Weights == nil {
+		opts.Start.Weights = bm25md.DefaultFieldWeights
+	}
+	opts.Start.Weights = maps.Clone(opts.Start.Weights) // never modify the caller's map
+	if opts.K1 == nil {
+		opts.K1 = DefaultK1Values
+	}
+	if opts.B == nil {
+		opts.B = DefaultBValues
+	}
+	if opts.Weights == nil {
+		opts.Weights = DefaultWeightValues
+	}
+	if opts.Fields == nil {
+		opts.Fields = slices.Sorted(maps.Keys(opts.Start.Weights))
+	}
+	if opts.Rounds <= 0 {
+		opts.Rounds = 3
+	}
+	return opts
+}
@@ -0,0 +1,57 @@
+package eval
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/chriscorrea/bm25md"
+)
+
+func TestTune(t *testing.T) {
+	// relevant documents discuss a topic in their body, while distractors only
+	// name it in a heading, which the default weights favor
+	var docs []bm25md.Document
+	var queries []Query
+	for i, topic := range []string{"habeas", "custody", "appeal", "statute"} {
+		relevant := fmt.Sprintf("rel-%d", i)
+		docs = append(docs,
+			bm25md.Document{ExternalID: relevant, Fields: map[bm25md.Field]string{
+				bm25md.FieldH1:   "overview",
+				bm25md.FieldBody: topic + " explained: how " + topic + " works and when " + topic + " applies",
+			}},
+			bm25md.Document{ExternalID: fmt.Sprintf("dis-%d", i), Fields: map[bm25md.Field]string{
+				bm25md.FieldH1:   topic,
+				bm25md.FieldBody: "unrelated notes about scheduling meetings",
+			}},
+		)
+		queries = append(queries, Query{ID: topic, Text: topic, Judgments: Judgments{relevant: 1}})
+	}
+	for _, filler := range []string{"jury selection", "court calendars", "filing fees", "judge rotation"} {
+		docs = append(docs, bm25md.Document{Fields: map[bm25md.Field]string{bm25md.FieldBody: filler}})
+	}
+
+	tuning := Tune(docs, queries, TuneOptions{K: 1, Fields: []bm25md.Field{bm25md.FieldH1, bm25md.FieldBody}, Rounds: 2})
+
+	if tuning.Baseline >= 1 {
+		t.Fatalf("baseline = %v, want the default weights to rank distractors first", tuning.Baseline)
+	}
+	if tuning.Score != 1 {
+		t.Errorf("tuned score = %v, want 1 with %v", tuning.Score, tuning.Config)
+	}
+	if tuning.Config.Weights[bm25md.FieldBody] <= tuning.Config.Weights[bm25md.FieldH1] {
+		t.Errorf("tuned weights = %v, want body above h1", tuning.Config)
+	}
+	if tuning.Trials < 2 {
+		t.Errorf("trials = %d, want several", tuning.Trials)
+	}
+	if bm25md.DefaultFieldWeights[bm25md.FieldBody] != 1.0 {
+		t.Errorf("Tune modified DefaultFieldWeights: %v", bm25md.DefaultFieldWeights)
+	}
+
+	// the reported configuration reproduces its score
+	corpus := bm25md.NewCorpus(tuning.Config.Options()...)
+	corpus.AddDocuments(docs)
+	if got := Evaluate(corpus, queries, 1).Mean.NDCG; got != tuning.Score {
+		t.Errorf("re-evaluated NDCG = %v, want %v", got, tuning.Score)
+	}
+}
@@ -1,3 +1,5 @@
+//go:build !bm25md_noparser
+
 package main
 
 import (
@@ -18,31 +20,17 @@ func main() {
 		return
 	}
 
-	// chunk by paragraph and remove empty ones
-	candidates := strings.Split(string(content), "\n\n")
-	var docs []string
-	for _, p := range candidates {
-		if trimmed := strings.TrimSpace(p); trimmed != "" {
-			// only add non-empty paragraphs
-			docs = append(docs, trimmed)
-		}
-	}
+	// split into heading-aware chunks of a few paragraphs each
+	chunker := bm25md.NewChunker(bm25md.WithMaxChunkSize(500))
+	docs := chunker.Chunk(string(content))
 
-	// create corpus and parser
+	// create corpus and index documents
 	corpus := bm25md.NewCorpus()
-	parser := bm25md.NewMarkdownFieldParser()
-
-	// index documents
-	for i, doc := range docs {
-		fields := parser.ParseDocument(doc)
-		corpus.AddDocument(bm25md.Document{
-			ID:       i,
-			Fields:   fields,
-			Original: doc,
-		})
+	for _, doc := range docs {
+		corpus.AddDocument(doc)
 	}
 
-	fmt.Printf("Indexed %d paragraphs from document\n\n", len(docs))
+	fmt.Printf("Indexed %d chunks from document\n\n", len(docs))
 
 	// example queries (try others!)
 	queries := []string{
@@ -0,0 +1,144 @@
+package bm25md
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+)
+
+// RelatedTerm is a term that co-occurs with another more often than chance
+type RelatedTerm struct {
+	Term string
+	PMI  float64 // pointwise mutual information with the source term
+}
+
+// ExpansionModel holds document-level term co-occurrence counts for a corpus,
+// used to expand queries with statistically related terms
+type ExpansionModel struct {
+	documents int
+	minCount  int
+	docFreq   map[string]int
+	pairs     map[string]map[string]int
+}
+
+// BuildExpansionModel counts document-level co-occurrences of indexed terms.
+// Terms or pairs seen in fewer than minCount documents are ignored, as are terms
+// found in more than half the corpus, which keeps the model small and the
+// associations meaningful
+func (c *Corpus) BuildExpansionModel(minCount int) *ExpansionModel {
+	if minCount < 1 {
+		minCount = 1
+	}
+	model := &ExpansionModel{
+		documents: c.liveDocuments(),
+		minCount:  minCount,
+		docFreq:   make(map[string]int),
+		pairs:     make(map[string]map[string]int),
+	}
+
+	// unique terms per document across all fields
+	docTerms := make([][]string, len(c.documents))
+	for i := range c.documents {
+		seen := make(map[string]bool)
+		for _, scorer := range c.fieldScorers {
+			scorer.eachTerm(i, func(term string, _ int) {
+				if !seen[term] {
+					seen[term] = true
+					docTerms[i] = append(docTerms[i], term)
+					model.docFreq[term]++
+				}
+			})
+		}
+	}
+
+	// count pairs among terms that can carry signal
+	maxFreq := max(c.liveDocuments()/2, minCount)
+	for _, terms := range docTerms {
+		candidates := terms[:0:0]
+		for _, term := range terms {
+			if df := model.docFreq[term]; df >= minCount && df <= maxFreq {
+				candidates = append(candidates, term)
+			}
+		}
+		for i, a := range candidates {
+			for _, b := range candidates[i+1:] {
+				model.addPair(a, b)
+				model.addPair(b, a)
+			}
+		}
+	}
+
+	return model
+}
+
+// addPair increments the co-occurrence count of b with a
+func (m *ExpansionModel) addPair(a, b string) {
+	related, exists := m.pairs[a]
+	if !exists {
+		related = make(map[string]int)
+		m.pairs[a] = related
+	}
+	related[b]++
+}
+
+// Related returns up to n terms with the highest positive PMI for term
+func (m *ExpansionModel) Related(term string, n int) []RelatedTerm {
+	related := make([]RelatedTerm, 0, len(m.pairs[term]))
+	for other, count := range m.pairs[term] {
+		if count < m.minCount {
+			continue
+		}
+		// PMI = log(P(a,b) / (P(a) * P(b))) over documents
+		pmi := math.Log(float64(count) * float64(m.documents) / (float64(m.docFreq[term]) * float64(m.docFreq[other])))
+		if pmi > 0 {
+			related = append(related, RelatedTerm{Term: other, PMI: pmi})
+		}
+	}
+
+	sort.Slice(related, func(i, j int) bool {
+		if related[i].PMI != related[j].PMI {
+			return related[i].PMI > related[j].PMI
+		}
+		return related[i].Term < related[j].Term
+	})
+
+	if n > 0 && len(related) > n {
+		related = related[:n]
+	}
+	return related
+}
+
+// Expand returns terms followed by up to perTerm related terms for each one,
+// skipping duplicates
+func (m *ExpansionModel) Expand(terms []string, perTerm int) []string {
+	seen := make(map[string]bool, len(terms))
+	expanded := make([]string, 0, len(terms)*(perTerm+1))
+	for _, term := range terms {
+		if !seen[term] {
+			seen[term] = true
+			expanded = append(expanded, term)
+		}
+	}
+	for _, term := range terms {
+		for _, related := range m.Related(term, perTerm) {
+			if !seen[related.Term] {
+				seen[related.Term] = true
+				expanded = append(expanded, related.Term)
+			}
+		}
+	}
+	return expanded
+}
+
+// SearchExpanded searches with the query expanded by up to perTerm related terms
+// per query term, improving recall without external embeddings
+func (c *Corpus) SearchExpanded(query string, limit int, model *ExpansionModel, perTerm int) []SearchResult {
+	start := time.Now()
+	queryTerms := c.queryTokenizer(query)(query)
+	if model != nil {
+		queryTerms = model.Expand(queryTerms, perTerm)
+	}
+	results, _ := c.search(context.Background(), query, queryTerms, c.prepareQuery(queryTerms), SearchOptions{Limit: limit}, start)
+	return results
+}
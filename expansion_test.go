@@ -0,0 +1,66 @@
+package bm25md
+
+import "testing"
+
+func expansionCorpus() *Corpus {
+	corpus := NewCorpus()
+	for _, body := range []string{
+		"habeas writ detention",
+		"habeas writ detention prisoner",
+		"habeas writ",
+		"prisoner detention",
+		"contract breach damages",
+		"contract breach",
+		"appeal deadline",
+		"appeal court",
+	} {
+		corpus.AddDocument(Document{Fields: map[Field]string{FieldBody: body}})
+	}
+	return corpus
+}
+
+func TestExpansionModel_Related(t *testing.T) {
+	model := expansionCorpus().BuildExpansionModel(2)
+
+	related := model.Related("habeas", 5)
+	if len(related) == 0 || related[0].Term != "writ" {
+		t.Fatalf("Related(habeas) = %+v, want writ first", related)
+	}
+	for _, r := range related {
+		if r.PMI <= 0 {
+			t.Errorf("related term %q has PMI %v, want positive", r.Term, r.PMI)
+		}
+		if r.Term == "contract" || r.Term == "appeal" {
+			t.Errorf("unrelated term %q returned", r.Term)
+		}
+	}
+
+	if got := model.Related("unknown", 5); len(got) != 0 {
+		t.Errorf("Related(unknown) = %+v, want none", got)
+	}
+}
+
+func TestExpansionModel_Expand(t *testing.T) {
+	model := expansionCorpus().BuildExpansionModel(2)
+
+	expanded := model.Expand([]string{"habeas", "habeas"}, 1)
+	if len(expanded) != 2 || expanded[0] != "habeas" || expanded[1] != "writ" {
+		t.Errorf("Expand() = %v, want [habeas writ]", expanded)
+	}
+}
+
+func TestCorpus_SearchExpanded(t *testing.T) {
+	corpus := expansionCorpus()
+	model := corpus.BuildExpansionModel(2)
+
+	plain := corpus.Search("habeas", 0)
+	expanded := corpus.SearchExpanded("habeas", 0, model, 2)
+	if len(expanded) <= len(plain) {
+		t.Errorf("expanded search returned %d results, want more than %d", len(expanded), len(plain))
+	}
+
+	// a nil model behaves like Search
+	if got := corpus.SearchExpanded("habeas", 0, nil, 2); len(got) != len(plain) {
+		t.Errorf("SearchExpanded(nil model) returned %d results, want %d", len(got), len(plain))
+	}
+}
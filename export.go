@@ -0,0 +1,133 @@
+package bm25md
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// exportedIndex is the JSON written by ExportJSON
+type exportedIndex struct {
+	Documents int              `json:"documents"` // live documents
+	Deleted   int              `json:"deleted"`   // removed documents awaiting Compact
+	Params    exportedParams   `json:"params"`
+	Fields    []exportedField  `json:"fields"`
+	Terms     []exportedTerm   `json:"terms"`
+	Docs      []exportedLength `json:"docs"`
+}
+
+// exportedParams are BM25 parameters as exported
+type exportedParams struct {
+	K1    float64 `json:"k1"`
+	B     float64 `json:"b"`
+	Delta float64 `json:"delta,omitempty"`
+}
+
+// exportedField is the scoring configuration and length statistics of a field
+type exportedField struct {
+	Field       Field          `json:"field"`
+	Weight      float64        `json:"weight"`
+	Params      exportedParams `json:"params"`
+	Documents   int            `json:"documents"` // documents with content in the field
+	Terms       int            `json:"terms"`     // distinct words in the field
+	TotalLength int            `json:"total_length"`
+	AvgLength   float64        `json:"avg_length"`
+}
+
+// exportedTerm is an entry of the term dictionary
+type exportedTerm struct {
+	Term      string        `json:"term"`
+	Documents int           `json:"documents"` // documents containing the term as a word in any field
+	Fields    map[Field]int `json:"fields"`    // documents containing the term as a word, per field
+}
+
+// exportedLength is the length in tokens of each field of a document
+type exportedLength struct {
+	ID         int           `json:"id"`
+	ExternalID string        `json:"external_id,omitempty"`
+	Lengths    map[Field]int `json:"lengths"` // fields with content only
+}
+
+// ExportJSON writes the index statistics that drive scoring as indented JSON,
+// to diagnose tokenization and weighting: each field's weight, BM25 parameters,
+// and average length; the term dictionary in sorted order with each term's
+// document frequency overall and per field; and the token count of each field
+// of every live document. The dictionary holds words only: shingles are left
+// out, and so are edge n-grams, except where the n-gram is also a word of some
+// document, whose frequencies then count the documents where it is a word.
+// Document contents are not included
+func (c *Corpus) ExportJSON(w io.Writer) error {
+	fields := slices.Sorted(maps.Keys(c.fieldScorers))
+	export := exportedIndex{
+		Documents: c.liveDocuments(),
+		Deleted:   len(c.deleted),
+		Params:    exportedParams(c.params),
+		Fields:    make([]exportedField, 0, len(fields)),
+		Terms:     make([]exportedTerm, 0, len(c.postings)),
+		Docs:      make([]exportedLength, 0, c.liveDocuments()),
+	}
+
+	fieldTerms := make(map[Field]int) // distinct words per field
+	for _, term := range c.sortedTerms() {
+		if strings.Contains(term, ShingleSeparator) {
+			continue
+		}
+		entry := exportedTerm{Term: term, Documents: c.wordDocFreq(term), Fields: make(map[Field]int)}
+		if entry.Documents == 0 {
+			continue
+		}
+		ngram := c.edgeNGrams.indexed(term)
+		for _, field := range fields {
+			df := c.fieldScorers[field].docFrequencies[term]
+			if ngram && df > 0 {
+				df = 0
+				for docIndex, docFields := range c.postings[term] {
+					if docFields[field] > 0 && c.occursAsWord(term, docIndex, field) {
+						df++
+					}
+				}
+			}
+			if df > 0 {
+				entry.Fields[field] = df
+				fieldTerms[field]++
+			}
+		}
+		export.Terms = append(export.Terms, entry)
+	}
+
+	for _, field := range fields {
+		scorer := c.fieldScorers[field]
+		export.Fields = append(export.Fields, exportedField{
+			Field:       field,
+			Weight:      scorer.weight,
+			Params:      exportedParams(scorer.params),
+			Documents:   scorer.fieldDocs,
+			Terms:       fieldTerms[field],
+			TotalLength: scorer.totalLength,
+			AvgLength:   scorer.avgDocLength,
+		})
+	}
+
+	for id, doc := range c.documents {
+		if c.deleted[id] {
+			continue
+		}
+		entry := exportedLength{ID: id, ExternalID: doc.ExternalID, Lengths: make(map[Field]int)}
+		for _, field := range fields {
+			if lengths := c.fieldScorers[field].docLengths; id < len(lengths) && lengths[id] > 0 {
+				entry.Lengths[field] = lengths[id]
+			}
+		}
+		export.Docs = append(export.Docs, entry)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(export); err != nil {
+		return fmt.Errorf("bm25md: exporting index: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package bm25md
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCorpus_ExportJSON(t *testing.T) {
+	corpus := NewCorpus(WithFieldWeights(map[Field]float64{FieldH1: 3, FieldBody: 1}))
+	corpus.AddDocuments([]Document{
+		{ExternalID: "writ", Fields: map[Field]string{FieldH1: "Habeas Corpus", FieldBody: "the writ of habeas corpus"}},
+		{Fields: map[Field]string{FieldBody: "habeas petitions"}},
+		{ExternalID: "gone", Fields: map[Field]string{FieldBody: "jury trial"}},
+	})
+	if err := corpus.RemoveDocument(2); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := corpus.ExportJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(buf.String(), "{\n  \"documents\": 2,") {
+		t.Errorf("export is not indented JSON:\n%s", buf.String())
+	}
+
+	var export exportedIndex
+	if err := json.Unmarshal(buf.Bytes(), &export); err != nil {
+		t.Fatal(err)
+	}
+	if export.Documents != 2 || export.Deleted != 1 {
+		t.Errorf("documents = %d, deleted = %d, want 2 and 1", export.Documents, export.Deleted)
+	}
+	if len(export.Fields) != 2 || export.Fields[0].Field != FieldBody || export.Fields[1].Weight != 3 || export.Fields[1].Documents != 1 {
+		t.Errorf("fields = %+v, want body then h1 with weight 3 in 1 document", export.Fields)
+	}
+
+	terms := make(map[string]exportedTerm)
+	var order []string
+	for _, term := range export.Terms {
+		terms[term.Term] = term
+		order = append(order, term.Term)
+	}
+	habeas := corpus.tokenize("habeas")[0]
+	if want := (exportedTerm{Term: habeas, Documents: 2, Fields: map[Field]int{FieldBody: 2, FieldH1: 1}}); !reflect.DeepEqual(terms[habeas], want) {
+		t.Errorf("term %q = %+v, want %+v", habeas, terms[habeas], want)
+	}
+	if jury := corpus.tokenize("jury")[0]; terms[jury].Term != "" {
+		t.Errorf("terms of removed documents are exported: %v", order)
+	}
+	if !reflect.DeepEqual(order, corpus.sortedTerms()) {
+		t.Errorf("terms = %v, want the sorted dictionary", order)
+	}
+
+	wantDocs := []exportedLength{
+		{ID: 0, ExternalID: "writ", Lengths: map[Field]int{FieldH1: 2, FieldBody: len(corpus.tokenizeField(corpus.documents[0], FieldBody))}},
+		{ID: 1, Lengths: map[Field]int{FieldBody: 2}},
+	}
+	if !reflect.DeepEqual(export.Docs, wantDocs) {
+		t.Errorf("docs = %+v, want %+v", export.Docs, wantDocs)
+	}
+}
+
+func TestCorpus_ExportJSON_NGrams(t *testing.T) {
+	corpus := NewCorpus(WithEdgeNGrams(2, 10), WithShingles(2, 2))
+	corpus.AddDocuments([]Document{
+		{Fields: map[Field]string{FieldBody: "constitution and constitutional review"}},
+		{Fields: map[Field]string{FieldBody: "cons and pros"}},
+	})
+
+	var buf bytes.Buffer
+	if err := corpus.ExportJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var export exportedIndex
+	if err := json.Unmarshal(buf.Bytes(), &export); err != nil {
+		t.Fatal(err)
+	}
+
+	// n-grams such as "con" and shingles such as "cons and" are not words
+	var words []string
+	terms := make(map[string]exportedTerm)
+	for _, term := range export.Terms {
+		words = append(words, term.Term)
+		terms[term.Term] = term
+	}
+	if want := []string{"and", "cons", "constitution", "constitutional", "pros", "review"}; !reflect.DeepEqual(words, want) {
+		t.Errorf("terms = %v, want %v", words, want)
+	}
+	// a word in document 1, only an n-gram in document 0
+	if want := (exportedTerm{Term: "cons", Documents: 1, Fields: map[Field]int{FieldBody: 1}}); !reflect.DeepEqual(terms["cons"], want) {
+		t.Errorf("term cons = %+v, want %+v", terms["cons"], want)
+	}
+	for _, field := range export.Fields {
+		if field.Field == FieldBody && field.Terms != len(words) {
+			t.Errorf("body has %d terms, want %d", field.Terms, len(words))
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package bm25md
+
+import (
+	"fmt"
+	"sort"
+)
+
+// FacetCount is the number of matching documents with a metadata value
+type FacetCount struct {
+	Value string
+	Count int
+}
+
+// Facets counts the values of each metadata key across results, most common
+// first (ties in value order). A list value such as tags counts once for each
+// distinct element; results lacking a key are not counted for it
+func Facets(results []SearchResult, keys ...string) map[string][]FacetCount {
+	facets := make(map[string][]FacetCount, len(keys))
+	for _, key := range keys {
+		counts := make(map[string]int)
+		for _, result := range results {
+			for _, value := range facetValues(result.Document.Metadata[key]) {
+				counts[value]++
+			}
+		}
+
+		values := make([]FacetCount, 0, len(counts))
+		for value, count := range counts {
+			values = append(values, FacetCount{Value: value, Count: count})
+		}
+		sort.Slice(values, func(i, j int) bool {
+			if values[i].Count != values[j].Count {
+				return values[i].Count > values[j].Count
+			}
+			return values[i].Value < values[j].Value
+		})
+		facets[key] = values
+	}
+	return facets
+}
+
+// facetValues returns the distinct values of a metadata value as strings,
+// expanding lists
+func facetValues(value any) []string {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case string:
+		return []string{v}
+	case []string:
+		return distinct(v)
+	case []any:
+		values := make([]string, len(v))
+		for i, element := range v {
+			values[i] = fmt.Sprint(element)
+		}
+		return distinct(values)
+	default:
+		return []string{fmt.Sprint(v)}
+	}
+}
+
+// distinct returns values without repeats, in first-seen order
+func distinct(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	kept := make([]string, 0, len(values))
+	for _, value := range values {
+		if !seen[value] {
+			seen[value] = true
+			kept = append(kept, value)
+		}
+	}
+	return kept
+}
+
+// SearchFaceted performs a search like SearchWithOptions and also counts the
+// values of the given metadata keys (eg tags, directory, language) across every
+// matching document, not just the returned page, for rendering filter sidebars
+func (c *Corpus) SearchFaceted(query string, opts SearchOptions, keys ...string) ([]SearchResult, map[string][]FacetCount) {
+	page := opts
+	opts.Limit, opts.Offset = 0, 0
+	results := c.SearchWithOptions(query, opts)
+	return page.page(results), Facets(results, keys...)
+}
+
+// SearchFaceted performs a search with facet counts like Corpus.SearchFaceted
+func (ix *Index) SearchFaceted(query string, opts SearchOptions, keys ...string) ([]SearchResult, map[string][]FacetCount) {
+	page := opts
+	opts.Limit, opts.Offset = 0, 0
+	results := ix.SearchWithOptions(query, opts)
+	return page.page(results), Facets(results, keys...)
+}
@@ -0,0 +1,61 @@
+package bm25md
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestFacetValues(t *testing.T) {
+	tests := []struct {
+		value    any
+		expected []string
+	}{
+		{value: nil, expected: nil},
+		{value: "en", expected: []string{"en"}},
+		{value: []string{"law", "tax", "law"}, expected: []string{"law", "tax"}},
+		{value: []any{"law", 2}, expected: []string{"law", "2"}},
+		{value: true, expected: []string{"true"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.value), func(t *testing.T) {
+			if got := facetValues(tt.value); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("facetValues(%v) = %q, want %q", tt.value, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCorpus_SearchFaceted(t *testing.T) {
+	docs := []Document{
+		{Fields: map[Field]string{FieldBody: "habeas corpus"}, Metadata: map[string]any{"dir": "cases", "tags": []string{"law", "appeal"}}},
+		{Fields: map[Field]string{FieldBody: "habeas petition"}, Metadata: map[string]any{"dir": "cases", "tags": []any{"law"}}},
+		{Fields: map[Field]string{FieldBody: "habeas review"}, Metadata: map[string]any{"dir": "notes"}},
+		{Fields: map[Field]string{FieldBody: "court calendar"}, Metadata: map[string]any{"dir": "notes", "tags": []string{"law"}}},
+	}
+	for i := range 10 {
+		docs = append(docs, Document{Fields: map[Field]string{FieldBody: fmt.Sprintf("filler document %d about calendars", i)}})
+	}
+	corpus := NewCorpus()
+	corpus.AddDocuments(docs)
+
+	expected := map[string][]FacetCount{
+		"dir":     {{Value: "cases", Count: 2}, {Value: "notes", Count: 1}},
+		"tags":    {{Value: "law", Count: 2}, {Value: "appeal", Count: 1}},
+		"missing": {},
+	}
+	results, facets := corpus.SearchFaceted("habeas", SearchOptions{Limit: 1}, "dir", "tags", "missing")
+	if len(results) != 1 {
+		t.Errorf("SearchFaceted() returned %d results, want the 1 requested", len(results))
+	}
+	// counts cover every match, not just the returned page
+	if !reflect.DeepEqual(facets, expected) {
+		t.Errorf("SearchFaceted() facets = %v, want %v", facets, expected)
+	}
+
+	_, frozen := corpus.Freeze().SearchFaceted("habeas", SearchOptions{Limit: 1}, "dir", "tags", "missing")
+	if !reflect.DeepEqual(frozen, expected) {
+		t.Errorf("Index.SearchFaceted() facets = %v, want %v", frozen, expected)
+	}
+}
@@ -0,0 +1,95 @@
+package bm25md
+
+import (
+	"fmt"
+	"math"
+)
+
+// DefaultFeedbackWeight controls how strongly recorded feedback shifts scores
+const DefaultFeedbackWeight = 0.1
+
+// feedbackCounts tallies positive and negative selections of a document
+type feedbackCounts struct {
+	positive int
+	negative int
+}
+
+// prior returns the multiplicative score prior for the counts; documents with
+// more positive than negative feedback get a prior above 1 and vice versa
+func (f feedbackCounts) prior(weight float64) float64 {
+	// add-one smoothing keeps a single click from dominating
+	ratio := float64(f.positive+1) / float64(f.negative+1)
+	return math.Pow(ratio, weight)
+}
+
+// WithFeedbackWeight sets how strongly recorded feedback is blended into scores
+// (0 disables feedback, default DefaultFeedbackWeight)
+func WithFeedbackWeight(weight float64) CorpusOption {
+	return func(c *Corpus) {
+		c.feedbackWeight = weight
+	}
+}
+
+// RecordFeedback records that a user selected (positive) or rejected a document
+// for a query, so frequently chosen results gradually rise in later searches
+func (c *Corpus) RecordFeedback(query string, docID int, positive bool) error {
+	if !c.isLive(docID) {
+		return fmt.Errorf("bm25md: feedback for unknown document %d", docID)
+	}
+
+	c.feedbackMu.Lock()
+	defer c.feedbackMu.Unlock()
+
+	if c.feedback == nil {
+		c.feedback = make(map[int]feedbackCounts)
+	}
+	counts := c.feedback[docID]
+	if positive {
+		counts.positive++
+	} else {
+		counts.negative++
+	}
+	c.feedback[docID] = counts
+
+	c.logger().Debug("Recorded BM25md feedback", "query", query, "docID", docID, "positive", positive)
+	return nil
+}
+
+// ResetFeedback discards all recorded feedback
+func (c *Corpus) ResetFeedback() {
+	c.feedbackMu.Lock()
+	defer c.feedbackMu.Unlock()
+	c.feedback = nil
+}
+
+// remapFeedback moves feedback to renumbered document IDs, dropping removed ones
+func (c *Corpus) remapFeedback(remap map[int]int) {
+	c.feedbackMu.Lock()
+	defer c.feedbackMu.Unlock()
+
+	if c.feedback == nil {
+		return
+	}
+	feedback := make(map[int]feedbackCounts, len(c.feedback))
+	for docID, counts := range c.feedback {
+		if newID, exists := remap[docID]; exists {
+			feedback[newID] = counts
+		}
+	}
+	c.feedback = feedback
+}
+
+// feedbackPrior returns the feedback prior for a document (1 when there is none)
+func (c *Corpus) feedbackPrior(docIndex int) float64 {
+	if c.feedbackWeight == 0 {
+		return 1.0
+	}
+
+	c.feedbackMu.RLock()
+	counts, exists := c.feedback[docIndex]
+	c.feedbackMu.RUnlock()
+	if !exists {
+		return 1.0
+	}
+	return counts.prior(c.feedbackWeight)
+}
@@ -0,0 +1,71 @@
+package bm25md
+
+import "testing"
+
+func TestCorpus_RecordFeedback(t *testing.T) {
+	newCorpus := func(opts ...CorpusOption) *Corpus {
+		corpus := NewCorpus(opts...)
+		for _, body := range []string{
+			"habeas corpus petition",
+			"habeas corpus petition",
+			"filing deadlines",
+			"appeal rules",
+			"court calendar",
+		} {
+			corpus.AddDocument(Document{Fields: map[Field]string{FieldBody: body}})
+		}
+		return corpus
+	}
+
+	t.Run("positive feedback raises a document", func(t *testing.T) {
+		corpus := newCorpus()
+		before := corpus.Score("habeas", 1)
+		for i := 0; i < 3; i++ {
+			if err := corpus.RecordFeedback("habeas", 1, true); err != nil {
+				t.Fatalf("RecordFeedback() error = %v", err)
+			}
+		}
+		if after := corpus.Score("habeas", 1); after <= before {
+			t.Errorf("score after positive feedback = %v, want > %v", after, before)
+		}
+		results := corpus.Search("habeas", 1)
+		if len(results) != 1 || results[0].Index != 1 {
+			t.Errorf("top result = %+v, want document 1", results)
+		}
+	})
+
+	t.Run("negative feedback lowers a document", func(t *testing.T) {
+		corpus := newCorpus()
+		before := corpus.Score("habeas", 0)
+		_ = corpus.RecordFeedback("habeas", 0, false)
+		if after := corpus.Score("habeas", 0); after >= before || after <= 0 {
+			t.Errorf("score after negative feedback = %v, want in (0, %v)", after, before)
+		}
+	})
+
+	t.Run("zero weight disables feedback", func(t *testing.T) {
+		corpus := newCorpus(WithFeedbackWeight(0))
+		before := corpus.Score("habeas", 1)
+		_ = corpus.RecordFeedback("habeas", 1, true)
+		if after := corpus.Score("habeas", 1); after != before {
+			t.Errorf("score = %v, want unchanged %v", after, before)
+		}
+	})
+
+	t.Run("reset discards feedback", func(t *testing.T) {
+		corpus := newCorpus()
+		before := corpus.Score("habeas", 1)
+		_ = corpus.RecordFeedback("habeas", 1, true)
+		corpus.ResetFeedback()
+		if after := corpus.Score("habeas", 1); after != before {
+			t.Errorf("score after reset = %v, want %v", after, before)
+		}
+	})
+
+	t.Run("unknown document", func(t *testing.T) {
+		corpus := newCorpus()
+		if err := corpus.RecordFeedback("habeas", 99, true); err == nil {
+			t.Error("RecordFeedback() error = nil, want error for unknown document")
+		}
+	})
+}
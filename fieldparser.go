@@ -0,0 +1,17 @@
+package bm25md
+
+// FieldParser extracts field-separated content from documents in one format, so
+// documents of different formats (markdown, HTML, plain text) can share a corpus
+type FieldParser interface {
+	ParseDocument(content string) map[Field]string
+}
+
+// PlainTextFieldParser indexes plain text documents entirely into body
+type PlainTextFieldParser struct{}
+
+// ParseDocument returns content as body text
+func (PlainTextFieldParser) ParseDocument(content string) map[Field]string {
+	return JoinOccurrences(map[Field][]Occurrence{
+		FieldBody: {{Text: content, Start: 0, End: len(content)}},
+	})
+}
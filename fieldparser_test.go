@@ -0,0 +1,16 @@
+package bm25md
+
+import "testing"
+
+func TestPlainTextFieldParser(t *testing.T) {
+	fields := PlainTextFieldParser{}.ParseDocument("# Not a heading\n**not bold**")
+
+	if got := fields[FieldBody]; got != "# Not a heading\n**not bold**" {
+		t.Errorf("body = %q, want the whole text", got)
+	}
+	for _, field := range []Field{FieldH1, FieldBold} {
+		if fields[field] != "" {
+			t.Errorf("%s = %q, want empty", field, fields[field])
+		}
+	}
+}
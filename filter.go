@@ -0,0 +1,129 @@
+package bm25md
+
+import (
+	"reflect"
+	"time"
+)
+
+// Filter reports whether a document may appear in search results
+type Filter func(doc Document) bool
+
+// MetadataEquals matches documents whose metadata value for key equals value.
+// List values ([]string, []any) match when any element equals value, so
+// MetadataEquals("tags", "appeals") works on tag lists.
+func MetadataEquals(key string, value any) Filter {
+	return func(doc Document) bool {
+		return metadataMatches(doc.Metadata[key], value)
+	}
+}
+
+// MetadataIn matches documents whose metadata value for key equals any of values
+func MetadataIn(key string, values ...any) Filter {
+	return func(doc Document) bool {
+		for _, value := range values {
+			if metadataMatches(doc.Metadata[key], value) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// AllOf matches documents accepted by every filter
+func AllOf(filters ...Filter) Filter {
+	return func(doc Document) bool {
+		for _, filter := range filters {
+			if filter != nil && !filter(doc) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// metadataMatches compares a stored metadata value to a wanted value, looking
+// inside lists and treating numbers of different types as equal
+func metadataMatches(stored, want any) bool {
+	switch v := stored.(type) {
+	case nil:
+		return false
+	case []string:
+		for _, item := range v {
+			if metadataMatches(item, want) {
+				return true
+			}
+		}
+		return false
+	case []any:
+		for _, item := range v {
+			if metadataMatches(item, want) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if a, ok := stored.(time.Time); ok {
+		b, ok := want.(time.Time)
+		return ok && a.Equal(b)
+	}
+	if a, ok := toFloat(stored); ok {
+		if b, ok := toFloat(want); ok {
+			return a == b
+		}
+	}
+	if reflect.TypeOf(stored).Comparable() && reflect.TypeOf(want) != nil && reflect.TypeOf(want).Comparable() {
+		return stored == want
+	}
+	return reflect.DeepEqual(stored, want)
+}
+
+// toFloat converts numeric metadata values (eg int from Go, float64 from JSON) to float64
+func toFloat(value any) (float64, bool) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case float32:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
+
+// filterDocuments keeps the candidate documents in the options' document set
+// and ranges and accepted by their filter
+func (c *Corpus) filterDocuments(docs []int, opts SearchOptions) []int {
+	if opts.Filter == nil && opts.Docs == nil && len(opts.Ranges) == 0 {
+		return docs
+	}
+	kept := docs[:0]
+	for _, docIndex := range docs {
+		if opts.accepts(docIndex, c.documents[docIndex]) {
+			kept = append(kept, docIndex)
+		}
+	}
+	return kept
+}
+
+// accepts reports whether a document passes the options' document set, ranges,
+// and filter
+func (opts SearchOptions) accepts(docIndex int, doc Document) bool {
+	if opts.Docs != nil && !opts.Docs.Contains(docIndex) {
+		return false
+	}
+	for _, r := range opts.Ranges {
+		if !r.contains(doc) {
+			return false
+		}
+	}
+	return opts.Filter == nil || opts.Filter(doc)
+}
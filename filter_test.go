@@ -0,0 +1,67 @@
+package bm25md
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFilters(t *testing.T) {
+	date := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	doc := Document{Metadata: map[string]any{
+		"source": "guide.md",
+		"tags":   []string{"appeals", "deadlines"},
+		"labels": []any{"draft", 3.0},
+		"year":   2024,
+		"date":   date,
+	}}
+
+	tests := []struct {
+		name     string
+		filter   Filter
+		expected bool
+	}{
+		{"string match", MetadataEquals("source", "guide.md"), true},
+		{"string mismatch", MetadataEquals("source", "other.md"), false},
+		{"missing key", MetadataEquals("author", "ann"), false},
+		{"string list", MetadataEquals("tags", "appeals"), true},
+		{"any list", MetadataEquals("labels", "draft"), true},
+		{"numbers across types", MetadataEquals("year", 2024.0), true},
+		{"numbers inside lists", MetadataEquals("labels", 3), true},
+		{"times", MetadataEquals("date", date.In(time.FixedZone("EST", -5*3600))), true},
+		{"in", MetadataIn("source", "a.md", "guide.md"), true},
+		{"in mismatch", MetadataIn("source", "a.md", "b.md"), false},
+		{"all of", AllOf(MetadataEquals("tags", "deadlines"), MetadataEquals("year", 2024)), true},
+		{"all of mismatch", AllOf(MetadataEquals("tags", "deadlines"), MetadataEquals("year", 2023)), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter(doc); got != tt.expected {
+				t.Errorf("filter() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCorpus_SearchFilter(t *testing.T) {
+	corpus := NewCorpus()
+	for i, author := range []string{"ann", "bob", "ann", "cy"} {
+		corpus.AddDocument(Document{
+			Fields:   map[Field]string{FieldBody: "writ of habeas corpus"},
+			Metadata: map[string]any{"author": author, "index": i},
+		})
+	}
+	for _, body := range []string{"court calendar", "filing deadlines", "appeal rules", "judge assignments", "motion practice"} {
+		corpus.AddDocument(Document{Fields: map[Field]string{FieldBody: body}})
+	}
+
+	results := corpus.SearchWithOptions("habeas", SearchOptions{Filter: MetadataEquals("author", "ann")})
+	if len(results) != 2 {
+		t.Fatalf("got %d results, want 2: %+v", len(results), results)
+	}
+	for _, result := range results {
+		if result.Document.Metadata["author"] != "ann" {
+			t.Errorf("result %d has author %v", result.Index, result.Document.Metadata["author"])
+		}
+	}
+}
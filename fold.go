@@ -0,0 +1,52 @@
+//go:build !bm25md_noparser
+
+package bm25md
+
+import (
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// DiacriticFoldingFilter removes diacritics from tokens by Unicode compatibility
+// decomposition (NFKD) and mark stripping, so "café" matches "cafe" and "naïve"
+// matches "naive" in any script. Compatibility forms are normalized too ("ﬁ" ->
+// "fi", fullwidth "Ａ" -> "A"), and letters without a decomposition (ø, ł, æ, ß)
+// are folded like ASCIIFoldingFilter. Unlike ASCIIFoldingFilter, it is left out
+// of bm25md_noparser builds, since it depends on golang.org/x/text
+func DiacriticFoldingFilter() TokenFilter {
+	return TokenFilterFunc(func(tokens []string) []string {
+		return mapTokens(tokens, foldDiacritics)
+	})
+}
+
+// DiacriticFoldingCharFilter folds diacritics like DiacriticFoldingFilter before
+// tokenizing, for tokenizers that split on non-ASCII letters (eg DefaultTokenizer)
+func DiacriticFoldingCharFilter() CharFilter {
+	return CharFilterFunc(foldDiacritics)
+}
+
+// foldDiacritics strips combining marks from the NFKD form of s
+func foldDiacritics(s string) string {
+	ascii := true
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			ascii = false
+			break
+		}
+	}
+	if ascii {
+		return s
+	}
+
+	// transformers keep state, so each call builds its own chain
+	t := transform.Chain(norm.NFKD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	folded, _, err := transform.String(t, s)
+	if err != nil {
+		folded = s
+	}
+	return foldASCII(folded)
+}
@@ -0,0 +1,55 @@
+//go:build !bm25md_noparser
+
+package bm25md
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiacriticFoldingFilter(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"café", "cafe"},
+		{"naïve", "naive"},
+		{"Ångström", "Angstrom"},
+		{"São", "Sao"},
+		{"Việt", "Viet"},     // stacked marks
+		{"Ελλάδα", "Ελλαδα"}, // non-Latin scripts lose marks too
+		{"ﬁling", "filing"},
+		{"Ｗｒｉｔ", "Writ"},
+		{"Łódź", "Lodz"},
+		{"straße", "strasse"},
+		{"plain", "plain"},
+	}
+
+	filter := DiacriticFoldingFilter()
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := filter.Filter([]string{tt.input}); !reflect.DeepEqual(got, []string{tt.expected}) {
+				t.Errorf("Filter(%q) = %q, want %q", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestDiacriticFoldingCharFilter(t *testing.T) {
+	analyzer := Analyzer{
+		CharFilters: []CharFilter{DiacriticFoldingCharFilter()},
+		Tokenizer:   DefaultTokenizer{},
+	}
+	corpus := NewCorpus(WithAnalyzer(analyzer))
+	corpus.AddDocuments([]Document{
+		{Fields: map[Field]string{FieldBody: "Le café naïve du palais de justice"}},
+		{Fields: map[Field]string{FieldBody: "Court calendar"}},
+		{Fields: map[Field]string{FieldBody: "Filing deadlines"}},
+	})
+
+	for _, query := range []string{"cafe naive", "café naïve"} {
+		if results := corpus.Search(query, 10); len(results) != 1 || results[0].Document.ID != 0 {
+			t.Errorf("Search(%q) = %+v, want document 0", query, results)
+		}
+	}
+}
@@ -0,0 +1,156 @@
+//go:build !bm25md_noparser
+
+package bm25md
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// FrontMatter holds decoded front matter key/values from the top of a markdown document
+type FrontMatter map[string]any
+
+// ParseFrontMatter splits leading front matter from markdown content and decodes it.
+// YAML (---), TOML (+++), and JSON ({ ... }) front matter are recognized; content
+// without front matter is returned unchanged with a nil FrontMatter.
+func ParseFrontMatter(content string) (FrontMatter, string, error) {
+	raw, body, format := splitFrontMatter(content)
+	if format == "" {
+		return nil, content, nil
+	}
+
+	fm := make(FrontMatter)
+	var err error
+	switch format {
+	case "yaml":
+		err = yaml.Unmarshal([]byte(raw), &fm)
+	case "toml":
+		_, err = toml.Decode(raw, &fm)
+	case "json":
+		err = json.Unmarshal([]byte(raw), &fm)
+	}
+	if err != nil {
+		return nil, body, fmt.Errorf("bm25md: decoding %s front matter: %w", format, err)
+	}
+
+	return fm, body, nil
+}
+
+// splitFrontMatter separates raw front matter from the document body and names its format
+func splitFrontMatter(content string) (raw, body, format string) {
+	// tolerate a byte order mark and leading blank lines
+	trimmed := strings.TrimLeft(strings.TrimPrefix(content, "\ufeff"), "\r\n")
+
+	var delimiter string
+	switch {
+	case strings.HasPrefix(trimmed, "---"):
+		delimiter, format = "---", "yaml"
+	case strings.HasPrefix(trimmed, "+++"):
+		delimiter, format = "+++", "toml"
+	case strings.HasPrefix(trimmed, "{"):
+		return splitJSONFrontMatter(trimmed)
+	default:
+		return "", content, ""
+	}
+
+	// the opening delimiter must stand alone on its line
+	firstLine, rest, found := strings.Cut(trimmed, "\n")
+	if !found || strings.TrimSpace(firstLine) != delimiter {
+		return "", content, ""
+	}
+
+	// find the closing delimiter (YAML also allows "..." as a document end marker)
+	offset := 0
+	for offset <= len(rest) {
+		line, _, _ := strings.Cut(rest[offset:], "\n")
+		marker := strings.TrimSpace(line)
+		if marker == delimiter || (format == "yaml" && marker == "...") {
+			raw = rest[:offset]
+			body = rest[min(offset+len(line)+1, len(rest)):]
+			return raw, body, format
+		}
+		offset += len(line) + 1
+	}
+
+	return "", content, ""
+}
+
+// splitJSONFrontMatter extracts a leading JSON object used as Hugo-style front matter
+func splitJSONFrontMatter(content string) (raw, body, format string) {
+	decoder := json.NewDecoder(strings.NewReader(content))
+	var probe map[string]any
+	if err := decoder.Decode(&probe); err != nil {
+		return "", content, ""
+	}
+	end := int(decoder.InputOffset())
+	return content[:end], strings.TrimLeft(content[end:], "\r\n"), "json"
+}
+
+// String returns the value for key as text, or "" when absent
+func (fm FrontMatter) String(key string) string {
+	return plainText(fm[key])
+}
+
+// Strings returns the value for key as a list, accepting both scalars and lists
+func (fm FrontMatter) Strings(key string) []string {
+	switch v := fm[key].(type) {
+	case nil:
+		return nil
+	case []any:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			if text := plainText(item); text != "" {
+				values = append(values, text)
+			}
+		}
+		return values
+	case []string:
+		return v
+	default:
+		if text := plainText(v); text != "" {
+			return []string{text}
+		}
+		return nil
+	}
+}
+
+// Bool returns the value for key as a boolean (accepting "true"/"false" strings)
+func (fm FrontMatter) Bool(key string) bool {
+	switch v := fm[key].(type) {
+	case bool:
+		return v
+	case string:
+		return strings.EqualFold(strings.TrimSpace(v), "true")
+	default:
+		return false
+	}
+}
+
+// frontMatterTimeLayouts lists the date formats commonly found in front matter
+var frontMatterTimeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05 -0700",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+// Time returns the value for key as a time, parsing common string layouts
+func (fm FrontMatter) Time(key string) (time.Time, bool) {
+	switch v := fm[key].(type) {
+	case time.Time:
+		return v, true
+	case string:
+		for _, layout := range frontMatterTimeLayouts {
+			if t, err := time.Parse(layout, strings.TrimSpace(v)); err == nil {
+				return t, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
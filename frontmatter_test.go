@@ -0,0 +1,91 @@
+//go:build !bm25md_noparser
+
+package bm25md
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseFrontMatter(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		wantTitle string
+		wantTags  []string
+		wantBody  string
+	}{
+		{
+			name:      "yaml",
+			input:     "---\ntitle: Lady Lazarus\ntags: [poetry, confessional]\n---\n# Dying\nIs an art.",
+			wantTitle: "Lady Lazarus",
+			wantTags:  []string{"poetry", "confessional"},
+			wantBody:  "# Dying\nIs an art.",
+		},
+		{
+			name:      "toml",
+			input:     "+++\ntitle = \"Daddy\"\ntags = [\"poetry\"]\n+++\nBody",
+			wantTitle: "Daddy",
+			wantTags:  []string{"poetry"},
+			wantBody:  "Body",
+		},
+		{
+			name:      "json",
+			input:     "{\"title\": \"Tulips\", \"tags\": \"flowers\"}\nBody",
+			wantTitle: "Tulips",
+			wantTags:  []string{"flowers"},
+			wantBody:  "Body",
+		},
+		{
+			name:     "none",
+			input:    "# Heading\n\n---\n\nText",
+			wantBody: "# Heading\n\n---\n\nText",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fm, body, err := ParseFrontMatter(tt.input)
+			if err != nil {
+				t.Fatalf("ParseFrontMatter() error = %v", err)
+			}
+			if body != tt.wantBody {
+				t.Errorf("body = %q, want %q", body, tt.wantBody)
+			}
+			if got := fm.String("title"); got != tt.wantTitle {
+				t.Errorf("title = %q, want %q", got, tt.wantTitle)
+			}
+			tags := fm.Strings("tags")
+			if len(tags) != len(tt.wantTags) {
+				t.Fatalf("tags = %v, want %v", tags, tt.wantTags)
+			}
+			for i := range tags {
+				if tags[i] != tt.wantTags[i] {
+					t.Errorf("tags[%d] = %q, want %q", i, tags[i], tt.wantTags[i])
+				}
+			}
+		})
+	}
+}
+
+func TestFrontMatter_Accessors(t *testing.T) {
+	fm, _, err := ParseFrontMatter("---\ndraft: true\npublished: \"false\"\ndate: 2023-01-02\n---\n")
+	if err != nil {
+		t.Fatalf("ParseFrontMatter() error = %v", err)
+	}
+
+	if !fm.Bool("draft") {
+		t.Error("Bool(draft) = false, want true")
+	}
+	if fm.Bool("published") {
+		t.Error("Bool(published) = true, want false")
+	}
+	date, ok := fm.Time("date")
+	if !ok || !date.Equal(time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Time(date) = %v, %v", date, ok)
+	}
+
+	if _, _, err := ParseFrontMatter("---\ntitle: [unclosed\n---\n"); err == nil {
+		t.Error("expected error for malformed YAML")
+	}
+}
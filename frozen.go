@@ -0,0 +1,564 @@
+package bm25md
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Index is an immutable, read-optimized snapshot of a corpus for query-heavy
+// workloads: postings are compact arrays sorted by document, field length norms
+// are precomputed, and queries merge postings in document order instead of going
+// through maps. Searches rank exactly like the corpus it was frozen from and are
+// safe to run concurrently. Build one with a CorpusBuilder or Corpus.Freeze
+type Index struct {
+	config      *Corpus              // settings and documents (its scorers and postings are unused)
+	fields      []Field              // indexed fields, sorted; postings refer to them by position
+	weights     []float64            // weight of each field
+	params      []BM25Parameters     // BM25 parameters of each field
+	avgLengths  []float64            // average length of each field
+	lengths     [][]uint32           // length of each field in each document
+	norms       [][]float64          // BM25 length normalization of each field in each document
+	positions   [][]map[string][]int // token positions of each field in each document (for phrases)
+	terms       []string             // sorted term dictionary
+	postings    []indexPostings      // postings of each term in terms
+	sets        []*DocSet            // documents of each term in terms
+	totalTokens int                  // tokens across all documents and fields
+}
+
+// Snapshot returns an immutable point-in-time view of the corpus for searching,
+// like Freeze. Publish it through a LiveIndex to replace the index served to
+// searches atomically
+func (c *Corpus) Snapshot() *Index {
+	return c.Freeze()
+}
+
+// indexPostings holds a term's postings as parallel arrays in document order;
+// the field entries of docs[i] are fields[starts[i]:starts[i+1]] (and freqs alike)
+type indexPostings struct {
+	docs   []uint32
+	starts []uint32
+	fields []uint16
+	freqs  []uint32
+
+	// score bounds for Block-Max WAND (dictionary terms only)
+	blockMax  []float64 // highest impact in each block of impactBlockSize postings
+	blockLast []uint32  // last document of each block
+	maxImpact float64   // highest impact overall
+}
+
+// entries returns the field positions and frequencies of the i-th posting
+func (p *indexPostings) entries(i int) ([]uint16, []uint32) {
+	from, to := p.starts[i], p.starts[i+1]
+	return p.fields[from:to], p.freqs[from:to]
+}
+
+// seek returns the first posting at or after from whose document is at least doc
+func (p *indexPostings) seek(from int, doc uint32) int {
+	if from < len(p.docs) && p.docs[from] >= doc {
+		return from
+	}
+	return from + sort.Search(len(p.docs)-from, func(k int) bool { return p.docs[from+k] >= doc })
+}
+
+// CorpusBuilder accumulates documents and builds an immutable Index from them
+type CorpusBuilder struct {
+	opts   []CorpusOption
+	corpus *Corpus
+}
+
+// NewCorpusBuilder creates a builder whose indexes use the given corpus options
+func NewCorpusBuilder(opts ...CorpusOption) *CorpusBuilder {
+	return &CorpusBuilder{opts: opts, corpus: NewCorpus(opts...)}
+}
+
+// Add adds documents (in parallel, like Corpus.AddDocuments)
+func (b *CorpusBuilder) Add(docs ...Document) {
+	b.corpus.AddDocuments(docs)
+}
+
+// Build returns an Index of the documents added so far and resets the builder
+func (b *CorpusBuilder) Build() *Index {
+	index := b.corpus.Freeze()
+	b.corpus = NewCorpus(b.opts...)
+	return index
+}
+
+// Freeze returns an immutable Index of the corpus as it is now; later changes to
+// the corpus do not affect the index
+func (c *Corpus) Freeze() *Index {
+	config := &Corpus{
+		documents:      slices.Clone(c.documents),
+		deleted:        maps.Clone(c.deleted),
+		externalIDs:    maps.Clone(c.externalIDs),
+		fieldWeights:   c.fieldWeights,
+		params:         c.params,
+		tokenizer:      c.tokenizer,
+		tokenizers:     c.tokenizers,
+		edgeNGrams:     c.edgeNGrams,
+		shingles:       c.shingles,
+		languages:      c.languages,
+		indexAnalyzer:  c.indexAnalyzer,
+		queryAnalyzer:  c.queryAnalyzer,
+		proximity:      c.proximity,
+		stopwords:      c.stopwords,
+		fieldParams:    c.fieldParams,
+		maxTokens:      c.maxTokens,
+		tokenLimits:    c.tokenLimits,
+		searchHook:     c.searchHook,
+		calibrator:     c.calibrator,
+		similarity:     c.similarity,
+		decay:          c.decay,
+		now:            c.now,
+		log:            c.log,
+		tracer:         c.tracer,
+		parallelMin:    c.parallelMin,
+		maxWorkers:     c.maxWorkers,
+		maxExpansions:  c.maxExpansions,
+		maxRegexpScan:  c.maxRegexpScan,
+		feedbackWeight: c.feedbackWeight,
+	}
+	c.feedbackMu.RLock()
+	config.feedback = maps.Clone(c.feedback)
+	c.feedbackMu.RUnlock()
+
+	ix := &Index{config: config, fields: slices.Sorted(maps.Keys(c.fieldScorers))}
+	for _, field := range ix.fields {
+		scorer := c.fieldScorers[field]
+		lengths := make([]uint32, len(scorer.docLengths))
+		norms := make([]float64, len(scorer.docLengths))
+		for doc, length := range scorer.docLengths {
+			lengths[doc] = uint32(length)
+			norms[doc] = FieldMatch{Length: length, AvgLength: scorer.avgDocLength, Params: scorer.params}.lengthNorm()
+		}
+		ix.weights = append(ix.weights, c.fieldWeights[field])
+		ix.params = append(ix.params, scorer.params)
+		ix.avgLengths = append(ix.avgLengths, scorer.avgDocLength)
+		ix.lengths = append(ix.lengths, lengths)
+		ix.norms = append(ix.norms, norms)
+		// position maps are replaced rather than modified on update, so they can be shared
+		ix.positions = append(ix.positions, slices.Clone(scorer.positions))
+		ix.totalTokens += scorer.totalLength
+	}
+
+	ix.terms = slices.Clone(c.sortedTerms())
+	ix.postings = make([]indexPostings, len(ix.terms))
+	ix.sets = make([]*DocSet, len(ix.terms))
+	for i, term := range ix.terms {
+		ix.postings[i] = ix.compact(c.postings[term])
+		ix.computeImpacts(&ix.postings[i], term)
+		ix.sets[i] = ix.postings[i].docSet()
+	}
+	return ix
+}
+
+// compact converts a posting list to arrays in document and field order
+func (ix *Index) compact(list postingList) indexPostings {
+	p := indexPostings{
+		docs:   make([]uint32, 0, len(list)),
+		starts: make([]uint32, 1, len(list)+1),
+	}
+	for _, doc := range slices.Sorted(maps.Keys(list)) {
+		p.docs = append(p.docs, uint32(doc))
+		for pos, field := range ix.fields {
+			if tf := list[doc][field]; tf > 0 {
+				p.fields = append(p.fields, uint16(pos))
+				p.freqs = append(p.freqs, uint32(tf))
+			}
+		}
+		p.starts = append(p.starts, uint32(len(p.fields)))
+	}
+	return p
+}
+
+// lookup returns the postings of an index term
+func (ix *Index) lookup(term string) (indexPostings, bool) {
+	i, found := slices.BinarySearch(ix.terms, term)
+	if !found {
+		return indexPostings{}, false
+	}
+	return ix.postings[i], true
+}
+
+// lookupSet returns the document set of an index term, or nil
+func (ix *Index) lookupSet(term string) *DocSet {
+	if i, found := slices.BinarySearch(ix.terms, term); found {
+		return ix.sets[i]
+	}
+	return nil
+}
+
+// Documents returns the indexed (non-removed) documents in index order
+func (ix *Index) Documents() []Document {
+	return ix.config.Documents()
+}
+
+// Document returns the indexed document with the given internal ID, like
+// Corpus.Document
+func (ix *Index) Document(id int) (Document, bool) {
+	return ix.config.Document(id)
+}
+
+// LookupID returns the internal ID of the document with the given external ID
+func (ix *Index) LookupID(externalID string) (int, bool) {
+	return ix.config.LookupID(externalID)
+}
+
+// Search performs a search like Corpus.Search
+func (ix *Index) Search(query string, limit int) []SearchResult {
+	results, _ := ix.search(context.Background(), query, SearchOptions{Limit: limit})
+	return results
+}
+
+// SearchContext performs a search like Corpus.SearchContext
+func (ix *Index) SearchContext(ctx context.Context, query string, limit int) ([]SearchResult, error) {
+	return ix.search(ctx, query, SearchOptions{Limit: limit})
+}
+
+// SearchWithOptions performs a search like Corpus.SearchWithOptions
+func (ix *Index) SearchWithOptions(query string, opts SearchOptions) []SearchResult {
+	results, _ := ix.search(context.Background(), query, opts)
+	return results
+}
+
+// indexTerm is a query clause resolved against an Index
+type indexTerm struct {
+	postings indexPostings
+	scorer   TermScorer
+	occur    occur
+	set      *DocSet // documents containing the term (built on demand for computed postings)
+	cursor   int     // current posting during a search
+}
+
+// search resolves the query, then scores matching documents in document order
+func (ix *Index) search(ctx context.Context, query string, opts SearchOptions) ([]SearchResult, error) {
+	start := time.Now()
+	c := ix.config
+	ctx, span := c.startSpan(ctx, "bm25md.Search", start)
+	defer span.End()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	_, tokenizeSpan := c.startSpan(ctx, "bm25md.tokenize", start)
+	queryTokens, terms := ix.prepareQuery(query, opts)
+	tokenizeSpan.SetAttribute("bm25md.query_terms", len(queryTokens))
+	tokenizeSpan.End()
+
+	top := newTopResults(opts.resultCap(), opts.MinScore)
+	_, scoreSpan := c.startSpan(ctx, "bm25md.score", time.Now())
+	wand := ix.canUseWAND(terms, top.k)
+	if wand {
+		ix.collectWAND(ctx, terms, opts, top)
+	} else {
+		ix.collect(ctx, terms, opts, top)
+	}
+	scoreSpan.SetAttribute("bm25md.candidates", top.candidates)
+	scoreSpan.SetAttribute("bm25md.scored", top.scored)
+	scoreSpan.SetAttribute("bm25md.wand", wand)
+	scoreSpan.End()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	results := c.finishSearch(ctx, query, queryTokens, top, opts, start, false)
+	span.SetAttribute("bm25md.hits", top.hits)
+	return results, nil
+}
+
+// prepareQuery resolves the clauses of a query against the index, restricting
+// postings to the options' fields
+func (ix *Index) prepareQuery(query string, opts SearchOptions) ([]string, []indexTerm) {
+	var allowed []bool
+	if len(opts.Fields) > 0 {
+		allowed = make([]bool, len(ix.fields))
+		for pos, field := range ix.fields {
+			allowed[pos] = slices.Contains(opts.Fields, field)
+		}
+	}
+
+	var queryTokens []string
+	var terms []indexTerm
+	for _, clause := range ix.config.parseQuery(query) {
+		queryTokens = append(queryTokens, clause.tokens...)
+		var postings indexPostings
+		var name string
+		switch {
+		case clause.regexp != nil:
+			name, postings = clause.tokens[0], ix.prepareRegexp(clause.regexp)
+		case clause.prefix:
+			name, postings = clause.tokens[0]+"*", ix.preparePrefix(clause.tokens[0])
+		case len(clause.tokens) == 1:
+			name = clause.tokens[0]
+			postings, _ = ix.lookup(name)
+		default:
+			name, postings = phraseName(clause.tokens, clause.near), ix.preparePhrase(clause.tokens, clause.near)
+		}
+
+		// missing required terms still apply (matching nothing); missing others are dropped
+		if len(postings.docs) == 0 && clause.occur != occurMust {
+			continue
+		}
+		// statistics come from every field, even when matching is restricted to some
+		scorer := clause.scale(ix.config.similarity.TermScorer(ix.termStats(name, postings), ix.config.params))
+		term := indexTerm{postings: postings, scorer: scorer, occur: clause.occur}
+		if allowed != nil {
+			term.postings = postings.restrict(allowed)
+		} else if clause.isTerm() {
+			term.set = ix.lookupSet(name)
+		}
+		terms = append(terms, term)
+	}
+	return queryTokens, terms
+}
+
+// termStats returns the corpus statistics of a term with the given postings
+func (ix *Index) termStats(term string, postings indexPostings) TermStats {
+	stats := TermStats{
+		Term:        term,
+		DocFreq:     len(postings.docs),
+		TotalDocs:   ix.config.liveDocuments(),
+		TotalTokens: ix.totalTokens,
+	}
+	for _, tf := range postings.freqs {
+		stats.TotalTermFreq += int(tf)
+	}
+	return stats
+}
+
+// collect scores every document that satisfies the query, walking in document
+// order the intersection of the required terms' sets (with several required
+// terms or a document set), the one required term, or else the optional terms
+func (ix *Index) collect(ctx context.Context, terms []indexTerm, opts SearchOptions, top *topResults) {
+	var drivers []int
+	for i, t := range terms {
+		if t.occur == occurMust {
+			drivers = append(drivers, i)
+		}
+	}
+
+	// intersecting compressed sets up front leaves only documents with every required term
+	var candidates []int
+	if len(drivers) > 1 || (len(drivers) == 1 && opts.Docs != nil) {
+		set := opts.Docs
+		for _, i := range drivers {
+			if set == nil {
+				set = terms[i].docSet()
+			} else {
+				set = set.And(terms[i].docSet())
+			}
+		}
+		candidates = set.IDs()
+	}
+	if len(drivers) == 0 {
+		for i, t := range terms {
+			if t.occur == occurShould {
+				drivers = append(drivers, i)
+			}
+		}
+	}
+
+	var matches []FieldMatch
+	for scored := 0; ; scored++ {
+		if scored%cancelCheckInterval == 0 && ctx.Err() != nil {
+			return
+		}
+
+		// the next candidate is the next intersected document, or the lowest any driver is on
+		doc, found := uint32(0), false
+		if candidates != nil {
+			if scored < len(candidates) {
+				doc, found = uint32(candidates[scored]), true
+			}
+		} else {
+			for _, i := range drivers {
+				t := &terms[i]
+				if t.cursor < len(t.postings.docs) && (!found || t.postings.docs[t.cursor] < doc) {
+					doc, found = t.postings.docs[t.cursor], true
+				}
+			}
+		}
+		if !found {
+			return
+		}
+
+		var score float64
+		matched := true
+		for i := range terms {
+			t := &terms[i]
+			t.cursor = t.postings.seek(t.cursor, doc)
+			present := t.cursor < len(t.postings.docs) && t.postings.docs[t.cursor] == doc
+			switch {
+			case t.occur == occurMust && !present, t.occur == occurMustNot && present:
+				matched = false
+			case present && t.occur != occurMustNot && matched:
+				matches = ix.fieldMatches(matches[:0], &t.postings, t.cursor, int(doc))
+				score += t.scorer(matches)
+			}
+		}
+
+		if matched {
+			top.candidates++
+		}
+		if matched && opts.accepts(int(doc), ix.config.documents[doc]) {
+			c := ix.config
+			top.push(SearchResult{Score: score * c.documents[doc].boost() * c.feedbackPrior(int(doc)) * c.recency(int(doc)), Index: int(doc)})
+		}
+
+		// step past the candidate
+		for _, i := range drivers {
+			t := &terms[i]
+			if t.cursor < len(t.postings.docs) && t.postings.docs[t.cursor] == doc {
+				t.cursor++
+			}
+		}
+	}
+}
+
+// docSet returns the term's documents as a compressed set
+func (t *indexTerm) docSet() *DocSet {
+	if t.set == nil {
+		t.set = t.postings.docSet()
+	}
+	return t.set
+}
+
+// docSet returns the documents of a postings list as a compressed set
+func (p *indexPostings) docSet() *DocSet {
+	set := &DocSet{}
+	for _, doc := range p.docs {
+		set.Add(int(doc))
+	}
+	return set
+}
+
+// fieldMatches appends the field matches of the i-th posting of a document
+func (ix *Index) fieldMatches(matches []FieldMatch, p *indexPostings, i, doc int) []FieldMatch {
+	fields, freqs := p.entries(i)
+	for j, pos := range fields {
+		matches = append(matches, FieldMatch{
+			Field:     ix.fields[pos],
+			Freq:      int(freqs[j]),
+			Length:    int(ix.lengths[pos][doc]),
+			AvgLength: ix.avgLengths[pos],
+			Weight:    ix.weights[pos],
+			Params:    ix.params[pos],
+			norm:      ix.norms[pos][doc],
+		})
+	}
+	return matches
+}
+
+// restrict keeps only the entries in allowed fields, dropping documents left without any
+func (p indexPostings) restrict(allowed []bool) indexPostings {
+	kept := indexPostings{starts: []uint32{0}}
+	for i, doc := range p.docs {
+		fields, freqs := p.entries(i)
+		for j, pos := range fields {
+			if allowed[pos] {
+				kept.fields = append(kept.fields, pos)
+				kept.freqs = append(kept.freqs, freqs[j])
+			}
+		}
+		if int(kept.starts[len(kept.starts)-1]) < len(kept.fields) {
+			kept.docs = append(kept.docs, doc)
+			kept.starts = append(kept.starts, uint32(len(kept.fields)))
+		}
+	}
+	return kept
+}
+
+// preparePrefix combines the postings of the terms starting with prefix, keeping
+// the most common ones when there are more than the expansion cap
+func (ix *Index) preparePrefix(prefix string) indexPostings {
+	// an indexed edge n-gram already combines every term it begins
+	if ix.config.edgeNGrams.indexed(prefix) {
+		postings, _ := ix.lookup(prefix)
+		return postings
+	}
+	first, _ := slices.BinarySearch(ix.terms, prefix)
+	last := first
+	for last < len(ix.terms) && strings.HasPrefix(ix.terms[last], prefix) {
+		last++
+	}
+	expansions := make([]int, 0, last-first)
+	for i := first; i < last; i++ {
+		expansions = append(expansions, i)
+	}
+
+	return ix.combine(expansions)
+}
+
+// combine merges the postings of the terms at the given dictionary positions,
+// keeping the most common terms when there are more than the expansion cap
+func (ix *Index) combine(expansions []int) indexPostings {
+	limit := ix.config.maxExpansions
+	if limit <= 0 {
+		limit = DefaultMaxPrefixExpansions
+	}
+	if len(expansions) > limit {
+		sort.SliceStable(expansions, func(a, b int) bool {
+			return len(ix.postings[expansions[a]].docs) > len(ix.postings[expansions[b]].docs)
+		})
+		expansions = expansions[:limit]
+	}
+
+	combined := make(postingList)
+	for _, i := range expansions {
+		p := &ix.postings[i]
+		for j, doc := range p.docs {
+			fields, freqs := p.entries(j)
+			entry := combined[int(doc)]
+			if entry == nil {
+				entry = make(map[Field]int, len(fields))
+				combined[int(doc)] = entry
+			}
+			for k, pos := range fields {
+				entry[ix.fields[pos]] += int(freqs[k])
+			}
+		}
+	}
+	return ix.compact(combined)
+}
+
+// preparePhrase finds the documents and fields where tokens appear consecutively
+// (or, for NEAR, within near positions)
+func (ix *Index) preparePhrase(tokens []string, near int) indexPostings {
+	lists := make([]indexPostings, len(tokens))
+	for i, token := range tokens {
+		p, ok := ix.lookup(token)
+		if !ok {
+			return indexPostings{}
+		}
+		lists[i] = p
+	}
+	slices.SortFunc(lists, func(a, b indexPostings) int { return len(a.docs) - len(b.docs) })
+
+	phrases := make(postingList)
+	cursors := make([]int, len(lists))
+	for _, doc := range lists[0].docs {
+		// only documents containing every token can contain the phrase
+		inAll := true
+		for i := 1; i < len(lists); i++ {
+			cursors[i] = lists[i].seek(cursors[i], doc)
+			if cursors[i] == len(lists[i].docs) || lists[i].docs[cursors[i]] != doc {
+				inAll = false
+				break
+			}
+		}
+		if !inAll {
+			continue
+		}
+		for pos, field := range ix.fields {
+			if freq := phraseFrequency(ix.positions[pos][doc], tokens, near); freq > 0 {
+				if phrases[int(doc)] == nil {
+					phrases[int(doc)] = make(map[Field]int, 1)
+				}
+				phrases[int(doc)][field] = freq
+			}
+		}
+	}
+	return ix.compact(phrases)
+}
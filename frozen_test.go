@@ -0,0 +1,171 @@
+package bm25md
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// randomCorpus builds a corpus of n documents over a small legal vocabulary
+func randomCorpus(n int, opts ...CorpusOption) *Corpus {
+	vocabulary := strings.Fields("habeas corpus writ petition court federal state appeal judge " +
+		"jury trial motion filing deadline custody detention review statute constitution constitutional")
+	rng := rand.New(rand.NewSource(1))
+	words := func(count int) string {
+		parts := make([]string, count)
+		for i := range parts {
+			// skew toward the start of the vocabulary, so later words are rare
+			parts[i] = vocabulary[int(float64(len(vocabulary))*math.Pow(rng.Float64(), 3))]
+		}
+		return strings.Join(parts, " ")
+	}
+
+	corpus := NewCorpus(opts...)
+	for i := 0; i < n; i++ {
+		doc := Document{
+			ExternalID: fmt.Sprintf("doc-%d", i),
+			Fields: map[Field]string{
+				FieldH1:   words(1 + rng.Intn(3)),
+				FieldBody: words(2 + rng.Intn(12)),
+			},
+			Metadata: map[string]any{"even": i%2 == 0, "n": i},
+		}
+		if i%3 == 0 {
+			doc.Fields[FieldCode] = words(1 + rng.Intn(5))
+		}
+		if i%7 == 0 {
+			doc.Boost = 1.5
+		}
+		corpus.AddDocument(doc)
+	}
+	return corpus
+}
+
+// sameResults reports whether two rankings agree, allowing reordering of ties
+func sameResults(t *testing.T, got, want []SearchResult) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("got %d results, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if math.Abs(got[i].Score-want[i].Score) > 1e-9 {
+			t.Fatalf("result %d score = %v, want %v", i, got[i].Score, want[i].Score)
+		}
+		if got[i].Index != want[i].Index && (i == 0 || math.Abs(want[i].Score-want[i-1].Score) > 1e-9) &&
+			(i == len(want)-1 || math.Abs(want[i].Score-want[i+1].Score) > 1e-9) {
+			t.Fatalf("result %d = document %d, want %d", i, got[i].Index, want[i].Index)
+		}
+		if got[i].ExternalID != want[i].ExternalID && got[i].Index == want[i].Index {
+			t.Fatalf("result %d ExternalID = %q, want %q", i, got[i].ExternalID, want[i].ExternalID)
+		}
+	}
+}
+
+func TestIndex_MatchesCorpus(t *testing.T) {
+	queries := []string{
+		"habeas",
+		"habeas corpus petition",
+		"+federal court -state",
+		"appeal AND judge",
+		`"habeas corpus" review`,
+		"habeas NEAR/3 petition",
+		"constitu*",
+		"+missing court",
+		"nothing matches this",
+	}
+	options := []SearchOptions{
+		{Limit: 10},
+		{Limit: 5, Offset: 3},
+		{Limit: 10, Fields: []Field{FieldH1}},
+		{MinScore: 2},
+		{Limit: 20, Filter: MetadataEquals("even", true)},
+		{Limit: 10, Ranges: []Range{{Key: "n", Min: 100, Max: 199}}},
+		{Limit: 10, Dedupe: 0.3},
+	}
+
+	for _, similarity := range []Similarity{BM25FSimilarity{}, TFIDFSimilarity{}, LMDirichletSimilarity{}} {
+		corpus := randomCorpus(300, WithSimilarity(similarity))
+		_ = corpus.RemoveDocument(4)
+		index := corpus.Freeze()
+
+		for _, query := range queries {
+			for _, opts := range options {
+				t.Run(fmt.Sprintf("%T/%s/%+v", similarity, query, opts.Fields), func(t *testing.T) {
+					sameResults(t, index.SearchWithOptions(query, opts), corpus.SearchWithOptions(query, opts))
+				})
+			}
+		}
+	}
+}
+
+func TestIndex_Immutable(t *testing.T) {
+	corpus := randomCorpus(50)
+	index := corpus.Freeze()
+	before := index.Search("custody", 10)
+
+	// later corpus changes do not leak into the index
+	corpus.AddDocument(Document{Fields: map[Field]string{FieldH1: "custody custody custody"}})
+	_ = corpus.SetDocumentBoost(before[0].Index, 0.1)
+	_ = corpus.RemoveDocument(before[1].Index)
+	corpus.Compact()
+
+	sameResults(t, index.Search("custody", 10), before)
+	if got := len(index.Documents()); got != 50 {
+		t.Errorf("Documents() = %d documents, want 50", got)
+	}
+	if id, ok := index.LookupID("doc-7"); !ok || id != 7 {
+		t.Errorf("LookupID(doc-7) = %d, %v", id, ok)
+	}
+}
+
+func TestCorpusBuilder(t *testing.T) {
+	builder := NewCorpusBuilder(WithPreset("docs"))
+	builder.Add(
+		Document{ExternalID: "a", Fields: map[Field]string{FieldH1: "habeas corpus"}},
+		Document{ExternalID: "b", Fields: map[Field]string{FieldBody: "court calendar"}},
+		Document{ExternalID: "c", Fields: map[Field]string{FieldBody: "appeal rules"}},
+	)
+	index := builder.Build()
+
+	results := index.Search("habeas", 10)
+	if len(results) != 1 || results[0].ExternalID != "a" {
+		t.Errorf("Search() = %+v, want a", results)
+	}
+
+	// the builder starts over after Build
+	builder.Add(Document{ExternalID: "d", Fields: map[Field]string{FieldBody: "jury duty"}})
+	if got := len(builder.Build().Documents()); got != 1 {
+		t.Errorf("second Build() has %d documents, want 1", got)
+	}
+	if got := len(index.Documents()); got != 3 {
+		t.Errorf("first index has %d documents, want 3", got)
+	}
+}
+
+func TestIndex_Concurrent(t *testing.T) {
+	index := randomCorpus(200).Freeze()
+	want := index.Search("federal habeas", 10)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			got, err := index.SearchContext(context.Background(), "federal habeas", 10)
+			if err != nil || len(got) != len(want) || got[0].Index != want[0].Index {
+				t.Errorf("concurrent search = %v, %v", got, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := index.SearchContext(ctx, "habeas", 10); err == nil {
+		t.Error("SearchContext() with a cancelled context succeeded")
+	}
+}
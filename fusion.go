@@ -0,0 +1,64 @@
+package bm25md
+
+import "sort"
+
+// DefaultFusionConstant is the rank offset of reciprocal rank fusion, which
+// damps the advantage of the very top ranks (60 in the original paper)
+const DefaultFusionConstant = 60
+
+// DefaultFusionDepth is how many results of each query formulation are fused
+// when the caller passes zero
+const DefaultFusionDepth = 100
+
+// FuseRankings merges rankings of the same documents, such as results for
+// several formulations of one query, with reciprocal rank fusion: each document
+// scores the sum over rankings of 1/(constant + rank), counting ranks from 1.
+// Documents ranked highly by several rankings rise to the top, and the raw
+// scores of each ranking, which are not comparable, are ignored. A constant of
+// zero uses DefaultFusionConstant. Result scores are the fused scores; ties
+// keep the order in which documents were first ranked
+func FuseRankings(rankings [][]SearchResult, constant int) []SearchResult {
+	if constant <= 0 {
+		constant = DefaultFusionConstant
+	}
+	var fused []SearchResult
+	position := make(map[int]int) // document index to position in fused
+	for _, ranking := range rankings {
+		for rank, result := range ranking {
+			i, exists := position[result.Index]
+			if !exists {
+				i = len(fused)
+				position[result.Index] = i
+				result.Score = 0
+				fused = append(fused, result)
+			}
+			fused[i].Score += 1 / float64(constant+rank+1)
+		}
+	}
+	sort.SliceStable(fused, func(i, j int) bool {
+		return fused[i].Score > fused[j].Score
+	})
+	return fused
+}
+
+// SearchFused runs several formulations of the same information need (eg the
+// original query, a stemmed one, and a synonym-expanded one) and fuses their
+// top depth results (DefaultFusionDepth when zero, and at least limit) with
+// FuseRankings, improving recall on short, ambiguous queries. It returns the
+// best limit results (0 = all), scored by fused score
+func (c *Corpus) SearchFused(queries []string, limit, depth int) []SearchResult {
+	if depth <= 0 {
+		depth = DefaultFusionDepth
+	}
+	depth = max(depth, limit)
+
+	rankings := make([][]SearchResult, len(queries))
+	for i, query := range queries {
+		rankings[i] = c.Search(query, depth)
+	}
+	results := FuseRankings(rankings, DefaultFusionConstant)
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results
+}
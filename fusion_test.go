@@ -0,0 +1,74 @@
+package bm25md
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestFuseRankings(t *testing.T) {
+	ranking := func(indexes ...int) []SearchResult {
+		results := make([]SearchResult, len(indexes))
+		for i, index := range indexes {
+			results[i] = SearchResult{Index: index, Score: float64(100 - i)}
+		}
+		return results
+	}
+
+	tests := []struct {
+		name     string
+		rankings [][]SearchResult
+		constant int
+		expected []int
+	}{
+		{name: "agreement wins", rankings: [][]SearchResult{ranking(1, 2, 3), ranking(2, 3, 1), ranking(2)}, expected: []int{2, 1, 3}},
+		{name: "ties keep first ranked", rankings: [][]SearchResult{ranking(1, 2), ranking(2, 1)}, expected: []int{1, 2}},
+		{name: "single ranking", rankings: [][]SearchResult{ranking(4, 5)}, constant: 1, expected: []int{4, 5}},
+		{name: "empty", rankings: nil, expected: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fused := FuseRankings(tt.rankings, tt.constant)
+			var got []int
+			for _, result := range fused {
+				got = append(got, result.Index)
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("FuseRankings() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+
+	fused := FuseRankings([][]SearchResult{ranking(7), ranking(8, 7)}, 0)
+	if want := 1.0/61 + 1.0/62; math.Abs(fused[0].Score-want) > 1e-12 {
+		t.Errorf("fused score = %v, want %v", fused[0].Score, want)
+	}
+}
+
+func TestCorpus_SearchFused(t *testing.T) {
+	docs := []Document{
+		{Fields: map[Field]string{FieldBody: "petition for a writ of certiorari"}},
+		{Fields: map[Field]string{FieldBody: "the writ was issued"}},
+		{Fields: map[Field]string{FieldBody: "certiorari denied"}},
+		{Fields: map[Field]string{FieldBody: "writs and petitions in appellate practice"}},
+	}
+	for i := range 10 {
+		docs = append(docs, Document{Fields: map[Field]string{FieldBody: fmt.Sprintf("filler document %d about calendars", i)}})
+	}
+	corpus := NewCorpus()
+	corpus.AddDocuments(docs)
+
+	results := corpus.SearchFused([]string{"writ", "certiorari", "writs petitions"}, 2, 0)
+	if len(results) != 2 {
+		t.Fatalf("SearchFused() returned %d results, want 2", len(results))
+	}
+	// the document matching two formulations ranks first
+	if results[0].Index != 0 {
+		t.Errorf("top result = document %d, want 0", results[0].Index)
+	}
+	if results[0].Document.ID != 0 {
+		t.Errorf("top result document = %+v, want document 0 attached", results[0].Document)
+	}
+}
@@ -0,0 +1,171 @@
+//go:build !bm25md_noparser
+
+// Package gitindex builds bm25md documents from markdown files stored in a git
+// repository at a given ref, so documentation can be searched as of any branch,
+// tag, or commit (eg "search the docs as of release v2.3").
+package gitindex
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/chriscorrea/bm25md"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// Metadata keys attached to every indexed document
+const (
+	MetaPath         = "path"          // file path relative to the repository root
+	MetaRef          = "ref"           // ref the repository was indexed at
+	MetaCommit       = "commit"        // hash of the commit the ref resolved to
+	MetaAuthor       = "author"        // author of the commit (or last change, see Options.FileHistory)
+	MetaAuthorEmail  = "author_email"  // email of the author above
+	MetaDate         = "date"          // author date as time.Time
+	MetaLastModified = "last_modified" // hash of the last commit touching the file (FileHistory only)
+)
+
+// Options configures repository indexing
+type Options struct {
+	Ref         string                      // branch, tag, or commit; defaults to HEAD
+	Dir         string                      // only index files below this directory
+	Extensions  []string                    // file extensions to index; defaults to .md and .markdown
+	Parser      *bm25md.MarkdownFieldParser // parser for file contents; defaults to NewMarkdownFieldParser()
+	FileHistory bool                        // attribute author/date to the last commit touching each file
+}
+
+// IndexPath opens the repository at repoPath and indexes its markdown files
+func IndexPath(repoPath string, opts Options) ([]bm25md.Document, error) {
+	repo, err := git.PlainOpenWithOptions(repoPath, &git.PlainOpenOptions{DetectDotGit: true})
+	if err != nil {
+		return nil, fmt.Errorf("gitindex: opening repository: %w", err)
+	}
+	return IndexRepository(repo, opts)
+}
+
+// IndexRepository indexes markdown files from an already opened repository
+func IndexRepository(repo *git.Repository, opts Options) ([]bm25md.Document, error) {
+	if repo == nil {
+		return nil, errors.New("gitindex: nil repository")
+	}
+
+	ref := opts.Ref
+	if ref == "" {
+		ref = "HEAD"
+	}
+	extensions := opts.Extensions
+	if len(extensions) == 0 {
+		extensions = []string{".md", ".markdown"}
+	}
+	parser := opts.Parser
+	if parser == nil {
+		parser = bm25md.NewMarkdownFieldParser()
+	}
+	dir := strings.Trim(opts.Dir, "/")
+
+	// resolve the ref to a commit (handles branches, tags, and short hashes)
+	hash, err := repo.ResolveRevision(plumbing.Revision(ref))
+	if err != nil {
+		return nil, fmt.Errorf("gitindex: resolving ref %q: %w", ref, err)
+	}
+	commit, err := repo.CommitObject(*hash)
+	if err != nil {
+		return nil, fmt.Errorf("gitindex: loading commit %s: %w", hash, err)
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("gitindex: loading tree for %s: %w", hash, err)
+	}
+
+	documents := make([]bm25md.Document, 0)
+	err = tree.Files().ForEach(func(file *object.File) error {
+		if !matchesExtension(file.Name, extensions) || !withinDir(file.Name, dir) {
+			return nil
+		}
+
+		isBinary, err := file.IsBinary()
+		if err != nil || isBinary {
+			return err
+		}
+		content, err := file.Contents()
+		if err != nil {
+			return fmt.Errorf("gitindex: reading %s: %w", file.Name, err)
+		}
+
+		metadata := map[string]any{
+			MetaPath:        file.Name,
+			MetaRef:         ref,
+			MetaCommit:      commit.Hash.String(),
+			MetaAuthor:      commit.Author.Name,
+			MetaAuthorEmail: commit.Author.Email,
+			MetaDate:        commit.Author.When,
+		}
+		if opts.FileHistory {
+			if last, err := lastCommitFor(repo, *hash, file.Name); err == nil && last != nil {
+				metadata[MetaLastModified] = last.Hash.String()
+				metadata[MetaAuthor] = last.Author.Name
+				metadata[MetaAuthorEmail] = last.Author.Email
+				metadata[MetaDate] = last.Author.When
+			}
+		}
+
+		fields := parser.ParseDocument(content)
+		maps.Copy(fields, bm25md.PathFields(file.Name))
+
+		documents = append(documents, bm25md.Document{
+			ID:         len(documents),
+			ExternalID: file.Name,
+			Fields:     fields,
+			Original:   content,
+			Metadata:   metadata,
+			Timestamp:  metadata[MetaDate].(time.Time),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return documents, nil
+}
+
+// lastCommitFor returns the most recent commit reachable from start that touched filePath
+func lastCommitFor(repo *git.Repository, start plumbing.Hash, filePath string) (*object.Commit, error) {
+	iter, err := repo.Log(&git.LogOptions{
+		From:     start,
+		FileName: &filePath,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+
+	return iter.Next()
+}
+
+// matchesExtension reports whether name ends with one of the given extensions
+func matchesExtension(name string, extensions []string) bool {
+	ext := strings.ToLower(path.Ext(name))
+	for _, candidate := range extensions {
+		if ext == strings.ToLower(candidate) {
+			return true
+		}
+	}
+	return false
+}
+
+// withinDir reports whether name is located below dir (an empty dir matches everything)
+func withinDir(name, dir string) bool {
+	return dir == "" || strings.HasPrefix(name, dir+"/")
+}
+
+// DateOf returns the date recorded on a document indexed by this package
+func DateOf(doc bm25md.Document) (time.Time, bool) {
+	date, ok := doc.Metadata[MetaDate].(time.Time)
+	return date, ok
+}
@@ -0,0 +1,123 @@
+//go:build !bm25md_noparser
+
+package gitindex
+
+import (
+	"testing"
+	"time"
+
+	"github.com/chriscorrea/bm25md"
+	"github.com/go-git/go-billy/v5/memfs"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/storage/memory"
+)
+
+// commitFiles writes files into the worktree and commits them
+func commitFiles(t *testing.T, repo *git.Repository, files map[string]string, author string, when time.Time) {
+	t.Helper()
+
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range files {
+		f, err := worktree.Filesystem.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := f.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+		_ = f.Close()
+		if _, err := worktree.Add(name); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	sig := &object.Signature{Name: author, Email: author + "@example.com", When: when}
+	hash, err := worktree.Commit("update docs", &git.CommitOptions{Author: sig, Committer: sig})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.CommitObject(hash); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestIndexRepository(t *testing.T) {
+	repo, err := git.Init(memory.NewStorage(), memfs.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	released := time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC)
+	commitFiles(t, repo, map[string]string{
+		"docs/install.md": "# Install\nRun the **installer**.",
+		"README.md":       "# Project",
+		"main.go":         "package main",
+	}, "sylvia", released)
+
+	head, err := repo.Head()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.CreateTag("v2.3", head.Hash(), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	commitFiles(t, repo, map[string]string{
+		"docs/install.md": "# Installation\nUse the package manager.",
+	}, "ted", released.AddDate(0, 1, 0))
+
+	t.Run("at tag", func(t *testing.T) {
+		docs, err := IndexRepository(repo, Options{Ref: "v2.3"})
+		if err != nil {
+			t.Fatalf("IndexRepository() error = %v", err)
+		}
+		if len(docs) != 2 {
+			t.Fatalf("indexed %d documents, want 2", len(docs))
+		}
+
+		var install bm25md.Document
+		for _, doc := range docs {
+			if doc.Metadata[MetaPath] == "docs/install.md" {
+				install = doc
+			}
+		}
+		if install.Fields[bm25md.FieldH1] != "Install" {
+			t.Errorf("H1 = %q, want %q", install.Fields[bm25md.FieldH1], "Install")
+		}
+		if install.Metadata[MetaAuthor] != "sylvia" {
+			t.Errorf("author = %v, want sylvia", install.Metadata[MetaAuthor])
+		}
+		if date, ok := DateOf(install); !ok || !date.Equal(released) {
+			t.Errorf("date = %v, want %v", date, released)
+		}
+	})
+
+	t.Run("head with dir filter and file history", func(t *testing.T) {
+		docs, err := IndexRepository(repo, Options{Dir: "docs", FileHistory: true})
+		if err != nil {
+			t.Fatalf("IndexRepository() error = %v", err)
+		}
+		if len(docs) != 1 {
+			t.Fatalf("indexed %d documents, want 1", len(docs))
+		}
+		if docs[0].Fields[bm25md.FieldH1] != "Installation" {
+			t.Errorf("H1 = %q, want %q", docs[0].Fields[bm25md.FieldH1], "Installation")
+		}
+		if docs[0].Metadata[MetaAuthor] != "ted" {
+			t.Errorf("author = %v, want ted", docs[0].Metadata[MetaAuthor])
+		}
+		if docs[0].Metadata[MetaLastModified] == nil {
+			t.Error("expected last modified commit in metadata")
+		}
+	})
+
+	t.Run("unknown ref", func(t *testing.T) {
+		if _, err := IndexRepository(repo, Options{Ref: "v9.9"}); err == nil {
+			t.Error("expected error for unknown ref")
+		}
+	})
+}
@@ -0,0 +1,78 @@
+package bm25md
+
+import "fmt"
+
+// GroupOptions configures grouped search
+type GroupOptions struct {
+	Key      string // metadata key whose values group results (eg MetaFilePath)
+	PerGroup int    // best results nested in each group (0 = 1)
+}
+
+// Group is a set of search results sharing a metadata value, such as the chunks
+// of one file
+type Group struct {
+	Value   string         // the shared metadata value ("" for a document lacking the key, grouped alone)
+	Score   float64        // score of the group's best result
+	Hits    int            // matching documents in the group
+	Results []SearchResult // the group's best results, best first
+}
+
+// SearchGrouped performs a search like SearchWithOptions, then groups results
+// by a metadata value and ranks groups by their best result, so a file-level
+// view shows each file once with its best chunks nested. Limit and Offset page
+// the groups rather than the results
+func (c *Corpus) SearchGrouped(query string, group GroupOptions, opts SearchOptions) []Group {
+	limit, offset := opts.Limit, opts.Offset
+	opts.Limit, opts.Offset = 0, 0
+	return pageGroups(groupResults(c.SearchWithOptions(query, opts), group), limit, offset)
+}
+
+// SearchGrouped performs a grouped search like Corpus.SearchGrouped
+func (ix *Index) SearchGrouped(query string, group GroupOptions, opts SearchOptions) []Group {
+	limit, offset := opts.Limit, opts.Offset
+	opts.Limit, opts.Offset = 0, 0
+	return pageGroups(groupResults(ix.SearchWithOptions(query, opts), group), limit, offset)
+}
+
+// groupResults groups ranked results by metadata value, keeping groups in the
+// order of their best result
+func groupResults(results []SearchResult, opts GroupOptions) []Group {
+	perGroup := max(opts.PerGroup, 1)
+	var groups []Group
+	index := make(map[string]int) // metadata value to position in groups
+	for _, result := range results {
+		value, ok := result.Document.Metadata[opts.Key]
+		if !ok || value == nil {
+			groups = append(groups, Group{Score: result.Score, Hits: 1, Results: []SearchResult{result}})
+			continue
+		}
+		key, ok := value.(string)
+		if !ok {
+			key = fmt.Sprint(value)
+		}
+
+		i, exists := index[key]
+		if !exists {
+			i = len(groups)
+			index[key] = i
+			groups = append(groups, Group{Value: key, Score: result.Score})
+		}
+		groups[i].Hits++
+		if len(groups[i].Results) < perGroup {
+			groups[i].Results = append(groups[i].Results, result)
+		}
+	}
+	return groups
+}
+
+// pageGroups applies an offset and limit (0 = unlimited) to ranked groups
+func pageGroups(groups []Group, limit, offset int) []Group {
+	if offset >= len(groups) {
+		return []Group{}
+	}
+	groups = groups[offset:]
+	if limit > 0 && len(groups) > limit {
+		groups = groups[:limit]
+	}
+	return groups
+}
@@ -0,0 +1,78 @@
+package bm25md
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestCorpus_SearchGrouped(t *testing.T) {
+	docs := []Document{
+		{Fields: map[Field]string{FieldBody: "habeas corpus habeas petition"}, Metadata: map[string]any{"file": "a.md"}},
+		{Fields: map[Field]string{FieldBody: "a habeas ruling"}, Metadata: map[string]any{"file": "a.md"}},
+		{Fields: map[Field]string{FieldBody: "habeas habeas habeas"}, Metadata: map[string]any{"file": "b.md"}},
+		{Fields: map[Field]string{FieldBody: "habeas once in a longer passage about other matters"}},
+		{Fields: map[Field]string{FieldBody: "another habeas mention"}, Metadata: map[string]any{"file": "a.md"}},
+	}
+	for i := range 10 {
+		docs = append(docs, Document{Fields: map[Field]string{FieldBody: fmt.Sprintf("filler document %d about calendars", i)}})
+	}
+	corpus := NewCorpus()
+	corpus.AddDocuments(docs)
+
+	summarize := func(groups []Group) []string {
+		var got []string
+		for _, group := range groups {
+			ids := make([]int, len(group.Results))
+			for i, result := range group.Results {
+				ids[i] = result.Index
+			}
+			got = append(got, fmt.Sprintf("%s:%d:%v", group.Value, group.Hits, ids))
+		}
+		return got
+	}
+
+	tests := []struct {
+		name     string
+		group    GroupOptions
+		opts     SearchOptions
+		expected []string
+	}{
+		{
+			name:     "best result per file",
+			group:    GroupOptions{Key: "file"},
+			expected: []string{"b.md:1:[2]", "a.md:3:[0]", ":1:[3]"},
+		},
+		{
+			name:     "two results per file",
+			group:    GroupOptions{Key: "file", PerGroup: 2},
+			expected: []string{"b.md:1:[2]", "a.md:3:[0 1]", ":1:[3]"},
+		},
+		{
+			name:     "paged groups",
+			group:    GroupOptions{Key: "file"},
+			opts:     SearchOptions{Limit: 1, Offset: 1},
+			expected: []string{"a.md:3:[0]"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			groups := corpus.SearchGrouped("habeas", tt.group, tt.opts)
+			if got := summarize(groups); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("SearchGrouped() = %v, want %v", got, tt.expected)
+			}
+			for _, group := range groups {
+				if group.Score != group.Results[0].Score {
+					t.Errorf("group %q score = %v, want its best result's %v", group.Value, group.Score, group.Results[0].Score)
+				}
+			}
+		})
+	}
+
+	// an Index groups the same way
+	frozen := corpus.Freeze().SearchGrouped("habeas", GroupOptions{Key: "file", PerGroup: 2}, SearchOptions{})
+	if got := summarize(frozen); !reflect.DeepEqual(got, tests[1].expected) {
+		t.Errorf("Index.SearchGrouped() = %v, want %v", got, tests[1].expected)
+	}
+}
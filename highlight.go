@@ -0,0 +1,145 @@
+package bm25md
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// wordRegex matches the alphanumeric runs that highlighting considers words
+var wordRegex = regexp.MustCompile(`[\p{L}\p{N}_]+`)
+
+// HighlightOptions configures highlighting and snippet generation
+type HighlightOptions struct {
+	PreTag     string // inserted before each matched word (default "<mark>")
+	PostTag    string // inserted after each matched word (default "</mark>")
+	WindowSize int    // snippet length in words (default 30)
+	Ellipsis   string // marks text cut from either end of a snippet (default "…")
+}
+
+// withDefaults fills unset options
+func (o HighlightOptions) withDefaults() HighlightOptions {
+	if o.PreTag == "" && o.PostTag == "" {
+		o.PreTag, o.PostTag = "<mark>", "</mark>"
+	}
+	if o.WindowSize <= 0 {
+		o.WindowSize = 30
+	}
+	if o.Ellipsis == "" {
+		o.Ellipsis = "…"
+	}
+	return o
+}
+
+// wordMatch is a word in the text and the query term it matched, if any
+type wordMatch struct {
+	start, end int    // byte offsets of the word
+	term       string // matched query term ("" when the word does not match)
+}
+
+// Highlight marks every word of text that matches a query term
+func (c *Corpus) Highlight(query, text string, opts HighlightOptions) string {
+	opts = opts.withDefaults()
+	words := c.matchWords(query, text)
+	return markWords(text, words, 0, len(text), opts)
+}
+
+// Snippet returns the window of text that best matches the query, with matched
+// words marked, so results can show why they matched. The window covering the
+// most distinct query terms wins (then the most matches, then the earliest)
+func (c *Corpus) Snippet(query, text string, opts HighlightOptions) string {
+	opts = opts.withDefaults()
+	words := c.matchWords(query, text)
+	if len(words) == 0 {
+		return ""
+	}
+
+	size := min(opts.WindowSize, len(words))
+	best, bestDistinct, bestMatches := 0, -1, -1
+	for start := 0; start+size <= len(words); start++ {
+		distinct := make(map[string]bool)
+		matches := 0
+		for _, word := range words[start : start+size] {
+			if word.term != "" {
+				distinct[word.term] = true
+				matches++
+			}
+		}
+		if len(distinct) > bestDistinct || (len(distinct) == bestDistinct && matches > bestMatches) {
+			best, bestDistinct, bestMatches = start, len(distinct), matches
+		}
+	}
+
+	window := words[best : best+size]
+	from, to := window[0].start, window[len(window)-1].end
+	snippet := markWords(text, window, from, to, opts)
+	if best > 0 {
+		snippet = opts.Ellipsis + snippet
+	}
+	if best+size < len(words) {
+		snippet += opts.Ellipsis
+	}
+	return snippet
+}
+
+// SnippetFor returns a snippet of a result's original text (or body when the
+// original text was not kept)
+func (c *Corpus) SnippetFor(query string, result SearchResult, opts HighlightOptions) string {
+	text := result.Document.Original
+	if text == "" {
+		text = result.Document.Fields[FieldBody]
+	}
+	return c.Snippet(query, text, opts)
+}
+
+// matchWords splits text into words and records which query term each matches
+func (c *Corpus) matchWords(query, text string) []wordMatch {
+	// excluded terms never explain a match
+	terms := make(map[string]bool)
+	var patterns []*regexp.Regexp
+	for _, clause := range c.parseQuery(query) {
+		if clause.occur == occurMustNot {
+			continue
+		}
+		if clause.regexp != nil {
+			patterns = append(patterns, clause.regexp)
+			continue
+		}
+		for _, token := range clause.tokens {
+			terms[token] = true
+		}
+	}
+
+	// words are tokenized like the query, eg with its language's analyzer
+	tokenize := c.queryTokenizer(query)
+	spans := wordRegex.FindAllStringIndex(text, -1)
+	words := make([]wordMatch, len(spans))
+	for i, span := range spans {
+		words[i] = wordMatch{start: span[0], end: span[1]}
+		for _, token := range tokenize(text[span[0]:span[1]]) {
+			if terms[token] || slices.ContainsFunc(patterns, func(re *regexp.Regexp) bool { return re.MatchString(token) }) {
+				words[i].term = token
+				break
+			}
+		}
+	}
+	return words
+}
+
+// markWords returns text[from:to] with matched words wrapped in tags
+func markWords(text string, words []wordMatch, from, to int, opts HighlightOptions) string {
+	var b strings.Builder
+	pos := from
+	for _, word := range words {
+		if word.term == "" || word.start < from || word.end > to {
+			continue
+		}
+		b.WriteString(text[pos:word.start])
+		b.WriteString(opts.PreTag)
+		b.WriteString(text[word.start:word.end])
+		b.WriteString(opts.PostTag)
+		pos = word.end
+	}
+	b.WriteString(text[pos:to])
+	return b.String()
+}
@@ -0,0 +1,111 @@
+package bm25md
+
+import "testing"
+
+func TestCorpus_Highlight(t *testing.T) {
+	corpus := NewCorpus()
+
+	tests := []struct {
+		name     string
+		query    string
+		text     string
+		opts     HighlightOptions
+		expected string
+	}{
+		{
+			name:     "default tags",
+			query:    "habeas corpus",
+			text:     "The writ of Habeas Corpus.",
+			expected: "The writ of <mark>Habeas</mark> <mark>Corpus</mark>.",
+		},
+		{
+			name:     "custom tags",
+			query:    "writ",
+			text:     "A writ, another writ.",
+			opts:     HighlightOptions{PreTag: "**", PostTag: "**"},
+			expected: "A **writ**, another **writ**.",
+		},
+		{
+			name:     "excluded terms are not marked",
+			query:    "writ -appeal",
+			text:     "writ on appeal",
+			expected: "<mark>writ</mark> on appeal",
+		},
+		{
+			name:     "phrase tokens are marked",
+			query:    `"habeas corpus"`,
+			text:     "habeas corpus",
+			expected: "<mark>habeas</mark> <mark>corpus</mark>",
+		},
+		{
+			name:     "no match",
+			query:    "mandamus",
+			text:     "The writ of habeas corpus.",
+			expected: "The writ of habeas corpus.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := corpus.Highlight(tt.query, tt.text, tt.opts); got != tt.expected {
+				t.Errorf("Highlight() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCorpus_Snippet(t *testing.T) {
+	corpus := NewCorpus()
+	text := "Introduction to the court system. Filing deadlines vary by district. " +
+		"A habeas corpus petition challenges unlawful detention and must name the custodian. " +
+		"Appeals follow a separate schedule."
+
+	tests := []struct {
+		name     string
+		query    string
+		opts     HighlightOptions
+		expected string
+	}{
+		{
+			name:     "best window",
+			query:    "habeas detention",
+			opts:     HighlightOptions{WindowSize: 6},
+			expected: "…<mark>habeas</mark> corpus petition challenges unlawful <mark>detention</mark>…",
+		},
+		{
+			name:     "window at the start has no leading ellipsis",
+			query:    "introduction",
+			opts:     HighlightOptions{WindowSize: 3, Ellipsis: "..."},
+			expected: "<mark>Introduction</mark> to the...",
+		},
+		{
+			name:     "window at the end has no trailing ellipsis",
+			query:    "schedule",
+			opts:     HighlightOptions{WindowSize: 4},
+			expected: "…follow a separate <mark>schedule</mark>",
+		},
+		{
+			name:     "no match falls back to the opening window",
+			query:    "mandamus",
+			opts:     HighlightOptions{WindowSize: 4},
+			expected: "Introduction to the court…",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := corpus.Snippet(tt.query, text, tt.opts); got != tt.expected {
+				t.Errorf("Snippet() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+
+	if got := corpus.Snippet("habeas", "", HighlightOptions{}); got != "" {
+		t.Errorf("Snippet() of empty text = %q, want empty", got)
+	}
+
+	result := SearchResult{Document: Document{Fields: map[Field]string{FieldBody: "the habeas writ"}}}
+	if got := corpus.SnippetFor("habeas", result, HighlightOptions{}); got != "the <mark>habeas</mark> writ" {
+		t.Errorf("SnippetFor() = %q, want body fallback", got)
+	}
+}
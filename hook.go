@@ -0,0 +1,32 @@
+package bm25md
+
+import "time"
+
+// SearchStats describes a single search, for query logging and analytics
+type SearchStats struct {
+	QueryTerms []string      // tokenized query terms
+	TotalHits  int           // matching documents before the limit was applied (a lower bound when an Index skips documents that cannot rank)
+	Candidates int           // documents matching the query before filters (likewise a lower bound)
+	Scored     int           // documents scored, after filters and pruning
+	Documents  int           // documents in the corpus at search time
+	Duration   time.Duration // time spent tokenizing, scoring, and ranking
+	Parallel   bool          // whether scoring ran in parallel
+}
+
+// ZeroResults reports whether the search matched no documents
+func (s SearchStats) ZeroResults() bool {
+	return s.TotalHits == 0
+}
+
+// SearchHook is called after each search with the raw query, the returned
+// (limited) results, and stats about the search
+type SearchHook func(query string, results []SearchResult, stats SearchStats)
+
+// WithSearchHook registers a callback invoked after each search, eg to collect
+// query logs, zero-result rates, or click-through data for relevance tuning.
+// The hook runs synchronously, so slow hooks should hand work off elsewhere
+func WithSearchHook(hook SearchHook) CorpusOption {
+	return func(c *Corpus) {
+		c.searchHook = hook
+	}
+}
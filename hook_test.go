@@ -0,0 +1,69 @@
+package bm25md
+
+import "testing"
+
+func TestWithSearchHook(t *testing.T) {
+	type call struct {
+		query   string
+		results int
+		stats   SearchStats
+	}
+	var calls []call
+
+	corpus := NewCorpus(WithSearchHook(func(query string, results []SearchResult, stats SearchStats) {
+		calls = append(calls, call{query: query, results: len(results), stats: stats})
+	}))
+	corpus.AddDocument(Document{Fields: map[Field]string{FieldBody: "habeas corpus petition"}})
+	corpus.AddDocument(Document{Fields: map[Field]string{FieldBody: "corpus of case law"}})
+	for _, body := range []string{"unrelated text", "filing deadlines", "appeal rules", "court calendar"} {
+		corpus.AddDocument(Document{Fields: map[Field]string{FieldBody: body}})
+	}
+
+	corpus.Search("corpus", 1)
+	corpus.Search("missing", 10)
+	corpus.Search("", 10)
+
+	if len(calls) != 3 {
+		t.Fatalf("hook called %d times, want 3", len(calls))
+	}
+
+	tests := []struct {
+		name      string
+		call      call
+		query     string
+		results   int
+		totalHits int
+		terms     int
+	}{
+		{"limited results", calls[0], "corpus", 1, 2, 1},
+		{"zero results", calls[1], "missing", 0, 0, 1},
+		{"empty query", calls[2], "", 0, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.call.query != tt.query {
+				t.Errorf("query = %q, want %q", tt.call.query, tt.query)
+			}
+			if tt.call.results != tt.results {
+				t.Errorf("results = %d, want %d", tt.call.results, tt.results)
+			}
+			if tt.call.stats.TotalHits != tt.totalHits {
+				t.Errorf("TotalHits = %d, want %d", tt.call.stats.TotalHits, tt.totalHits)
+			}
+			if len(tt.call.stats.QueryTerms) != tt.terms {
+				t.Errorf("QueryTerms = %v, want %d terms", tt.call.stats.QueryTerms, tt.terms)
+			}
+			if tt.call.stats.ZeroResults() != (tt.totalHits == 0) {
+				t.Errorf("ZeroResults() = %v, want %v", tt.call.stats.ZeroResults(), tt.totalHits == 0)
+			}
+			// every candidate is scored, since no filter applies
+			if tt.call.stats.Candidates != tt.totalHits || tt.call.stats.Scored != tt.totalHits {
+				t.Errorf("Candidates, Scored = %d, %d, want %d", tt.call.stats.Candidates, tt.call.stats.Scored, tt.totalHits)
+			}
+			if tt.call.stats.Documents != 6 {
+				t.Errorf("Documents = %d, want 6", tt.call.stats.Documents)
+			}
+		})
+	}
+}
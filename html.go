@@ -0,0 +1,239 @@
+//go:build !bm25md_noparser
+
+package bm25md
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+	"golang.org/x/net/html"
+)
+
+// HTMLFieldParser extracts fields from HTML documents, mapping tags to the fields
+// of their markdown equivalents: <title> and <meta name="description"> to the
+// front matter fields, headings, <b>/<strong>, <i>/<em>, <code>/<pre>, table
+// cells, links, and image alt text. Scripts and styles are dropped
+type HTMLFieldParser struct{}
+
+var _ FieldParser = HTMLFieldParser{}
+
+// ParseDocument extracts field content from an HTML document
+func (HTMLFieldParser) ParseDocument(content string) map[Field]string {
+	occurrences := make(map[Field][]Occurrence)
+	state := &htmlState{emphasis: true}
+	state.feed([]byte(content), func(field Field, text string) {
+		occurrences[field] = append(occurrences[field], Occurrence{Text: text, Start: -1, End: -1})
+	})
+	return JoinOccurrences(occurrences)
+}
+
+// htmlState tracks open HTML tags while raw HTML is extracted, so text between
+// tags (eg <h2>text</h2> or <b>text</b>) can be routed to the right field
+type htmlState struct {
+	emphasis bool                // map <b>/<strong> and <i>/<em> to bold/italic fields
+	url      func(string) string // converts link destinations to indexed text
+	bold     int                 // depth of open bold tags
+	italic   int                 // depth of open italic tags
+	skip     int                 // depth of open tags whose content is not text (script, style)
+	heading  Field               // field of the open heading tag ("" when none)
+	code     int                 // depth of open code tags
+	cell     int                 // depth of open table cell tags
+	link     int                 // depth of open anchor tags
+	title    int                 // depth of open title tags
+}
+
+// htmlHeadingFields maps heading tags to their fields
+var htmlHeadingFields = map[string]Field{
+	"h1": FieldH1, "h2": FieldH2, "h3": FieldH3,
+	"h4": FieldH4, "h5": FieldH5, "h6": FieldH6,
+}
+
+// feed tokenizes a fragment of raw HTML, updating open tags and emitting its text
+func (s *htmlState) feed(raw []byte, emit func(Field, string)) {
+	tokenizer := html.NewTokenizer(bytes.NewReader(raw))
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			// io.EOF or a malformed fragment, either way there is nothing more to read
+			return
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, hasAttr := tokenizer.TagName()
+			switch string(name) {
+			case "img":
+				if hasAttr && s.skip == 0 {
+					s.imageText(tokenizer, emit)
+				}
+				continue
+			case "a":
+				if hasAttr && s.skip == 0 {
+					s.linkURL(tokenizer, emit)
+				}
+			case "meta":
+				if hasAttr {
+					metaText(tokenizer, emit)
+				}
+				continue
+			}
+			s.open(string(name), 1)
+		case html.EndTagToken:
+			name, _ := tokenizer.TagName()
+			s.open(string(name), -1)
+		case html.TextToken:
+			text := strings.TrimSpace(string(tokenizer.Text()))
+			if text == "" {
+				continue
+			}
+			if field, ok := s.field(); ok {
+				emit(field, text)
+				if s.linked() {
+					emit(FieldLink, text)
+				}
+			}
+		}
+	}
+}
+
+// imageText emits the alt and title attributes of an <img> tag
+func (s *htmlState) imageText(tokenizer *html.Tokenizer, emit func(Field, string)) {
+	for {
+		key, value, more := tokenizer.TagAttr()
+		if text := strings.TrimSpace(string(value)); text != "" {
+			switch string(key) {
+			case "alt", "title":
+				emit(FieldAltText, text)
+			}
+		}
+		if !more {
+			return
+		}
+	}
+}
+
+// linkURL emits the href of an <a> tag
+func (s *htmlState) linkURL(tokenizer *html.Tokenizer, emit func(Field, string)) {
+	for {
+		key, value, more := tokenizer.TagAttr()
+		if string(key) == "href" {
+			href := string(value)
+			if s.url != nil {
+				href = s.url(href)
+			}
+			if href != "" {
+				emit(FieldURL, href)
+			}
+		}
+		if !more {
+			return
+		}
+	}
+}
+
+// metaText emits the content of <meta name="description"> and <meta name="keywords"> tags
+func metaText(tokenizer *html.Tokenizer, emit func(Field, string)) {
+	var name, content string
+	for {
+		key, value, more := tokenizer.TagAttr()
+		switch string(key) {
+		case "name":
+			name = strings.ToLower(string(value))
+		case "content":
+			content = strings.TrimSpace(string(value))
+		}
+		if !more {
+			break
+		}
+	}
+	if content == "" {
+		return
+	}
+	switch name {
+	case "description":
+		emit(FieldDescription, content)
+	case "keywords":
+		emit(FieldTags, strings.ReplaceAll(content, ",", " "))
+	}
+}
+
+// open adjusts the depth of the tag by delta (1 for start tags, -1 for end tags)
+func (s *htmlState) open(tag string, delta int) {
+	switch tag {
+	case "b", "strong":
+		s.bold = max(0, s.bold+delta)
+	case "i", "em":
+		s.italic = max(0, s.italic+delta)
+	case "script", "style", "template":
+		s.skip = max(0, s.skip+delta)
+	case "h1", "h2", "h3", "h4", "h5", "h6":
+		if delta > 0 {
+			s.heading = htmlHeadingFields[tag]
+		} else {
+			s.heading = ""
+		}
+	case "code", "pre":
+		s.code = max(0, s.code+delta)
+	case "td", "th":
+		s.cell = max(0, s.cell+delta)
+	case "a":
+		s.link = max(0, s.link+delta)
+	case "title":
+		s.title = max(0, s.title+delta)
+	}
+}
+
+// field returns the field text should currently go to; ok is false inside non-text tags
+func (s *htmlState) field() (field Field, ok bool) {
+	switch {
+	case s.skip > 0:
+		return "", false
+	case s.title > 0:
+		return FieldTitle, true
+	case s.heading != "":
+		return s.heading, true
+	case s.code > 0:
+		return FieldCode, true
+	case s.cell > 0:
+		return FieldTable, true
+	case s.emphasis && s.bold > 0:
+		return FieldBold, true
+	case s.emphasis && s.italic > 0:
+		return FieldItalic, true
+	default:
+		return FieldBody, true
+	}
+}
+
+// linked reports whether text is inside an anchor, so it also belongs in FieldLink
+func (s *htmlState) linked() bool {
+	return s.link > 0 && s.skip == 0
+}
+
+// reset closes all open tags, eg when the block containing inline HTML ends
+func (s *htmlState) reset() {
+	s.bold, s.italic, s.skip = 0, 0, 0
+	s.heading = ""
+	s.code, s.cell, s.link, s.title = 0, 0, 0, 0
+}
+
+// htmlBlockSource returns the raw source of an HTML block, including its closing line
+func htmlBlockSource(n *ast.HTMLBlock, source []byte) []byte {
+	var buf bytes.Buffer
+	for i := 0; i < n.Lines().Len(); i++ {
+		line := n.Lines().At(i)
+		buf.Write(line.Value(source))
+	}
+	if n.HasClosure() {
+		buf.Write(n.ClosureLine.Value(source))
+	}
+	return buf.Bytes()
+}
+
+// rawHTMLSource returns the raw source of an inline HTML node
+func rawHTMLSource(n *ast.RawHTML, source []byte) []byte {
+	var buf bytes.Buffer
+	for i := 0; i < n.Segments.Len(); i++ {
+		segment := n.Segments.At(i)
+		buf.Write(segment.Value(source))
+	}
+	return buf.Bytes()
+}
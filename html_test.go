@@ -0,0 +1,146 @@
+//go:build !bm25md_noparser
+
+package bm25md
+
+import "testing"
+
+func TestMarkdownFieldParser_HTML(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		emphasis bool
+		expected map[Field]string
+	}{
+		{
+			name:  "inline tags",
+			input: "Press <kbd>Ctrl</kbd> and read footnote<sup>2</sup>.",
+			expected: map[Field]string{
+				FieldBody: "Press Ctrl and read footnote 2 .",
+			},
+		},
+		{
+			name:  "html block",
+			input: "<details>\n<summary>Filing deadlines</summary>\nPetitions are due within <b>one year</b>.\n</details>",
+			expected: map[Field]string{
+				FieldBody: "Filing deadlines Petitions are due within one year .",
+				FieldBold: "",
+			},
+		},
+		{
+			name:     "html block with emphasis",
+			input:    "<div>\nPetitions are due within <b>one year</b> of <em>final judgment</em>.\n</div>",
+			emphasis: true,
+			expected: map[Field]string{
+				FieldBody:   "Petitions are due within of .",
+				FieldBold:   "one year",
+				FieldItalic: "final judgment",
+			},
+		},
+		{
+			name:     "inline emphasis tags",
+			input:    "The <strong>great writ</strong> of <i>habeas corpus</i> endures.",
+			emphasis: true,
+			expected: map[Field]string{
+				FieldBody:   "The of endures.",
+				FieldBold:   "great writ",
+				FieldItalic: "habeas corpus",
+			},
+		},
+		{
+			name:     "unclosed inline tag ends with its paragraph",
+			input:    "An <b>unclosed tag\n\nNext paragraph.",
+			emphasis: true,
+			expected: map[Field]string{
+				FieldBold: "unclosed tag",
+				FieldBody: "An Next paragraph.",
+			},
+		},
+		{
+			name:  "html headings and code",
+			input: "<h2>Filing a Petition</h2>\n<p>Run <code>bm25md index</code> first.</p>\n<pre>make build</pre>",
+			expected: map[Field]string{
+				FieldH2:   "Filing a Petition",
+				FieldCode: "bm25md index make build",
+				FieldBody: "Run first.",
+			},
+		},
+		{
+			name:  "html table",
+			input: "<table>\n<tr><th>Writ</th><th>Origin</th></tr>\n<tr><td>habeas</td><td>England</td></tr>\n</table>",
+			expected: map[Field]string{
+				FieldTable: "Writ Origin habeas England",
+				FieldBody:  "",
+			},
+		},
+		{
+			name:  "html links",
+			input: "<p>See the <a href=\"https://example.com/rules\">court rules</a>.</p>\n\nOr the <a href=\"/faq\">FAQ</a> inline.",
+			expected: map[Field]string{
+				FieldBody: "See the court rules . Or the FAQ inline.",
+				FieldLink: "court rules FAQ",
+				FieldURL:  "https://example.com/rules /faq",
+			},
+		},
+		{
+			name:  "scripts and comments are dropped",
+			input: "<script>\nvar tracking = true;\n</script>\n\nText <!-- hidden --> here.",
+			expected: map[Field]string{
+				FieldBody: "Text here.",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var opts []ParserOption
+			if tt.emphasis {
+				opts = append(opts, WithHTMLEmphasis())
+			}
+			result := NewMarkdownFieldParser(opts...).ParseDocument(tt.input)
+			for field, expectedContent := range tt.expected {
+				got := normalizeWhitespace(result[field])
+				want := normalizeWhitespace(expectedContent)
+				if got != want {
+					t.Errorf("Field %s = %q, want %q", field, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestHTMLFieldParser(t *testing.T) {
+	input := `<!DOCTYPE html>
+<html><head>
+<title>Habeas Corpus Guide</title>
+<meta name="description" content="Filing a petition">
+<meta name="keywords" content="habeas,petition">
+<style>body { color: red; }</style>
+</head><body>
+<h1>Filing</h1>
+<p>File within <strong>one year</strong> using <code>form-2241</code>.</p>
+<h3>Appeals</h3>
+<p>See the <a href="https://example.com/rules">court rules</a>. <img src="seal.png" alt="Court seal"></p>
+<script>track();</script>
+</body></html>`
+
+	fields := HTMLFieldParser{}.ParseDocument(input)
+
+	expected := map[Field]string{
+		FieldTitle:       "Habeas Corpus Guide",
+		FieldDescription: "Filing a petition",
+		FieldTags:        "habeas petition",
+		FieldH1:          "Filing",
+		FieldH3:          "Appeals",
+		FieldBold:        "one year",
+		FieldCode:        "form-2241",
+		FieldLink:        "court rules",
+		FieldURL:         "https://example.com/rules",
+		FieldAltText:     "Court seal",
+		FieldBody:        "File within using . See the court rules .",
+	}
+	for field, want := range expected {
+		if got := normalizeWhitespace(fields[field]); got != want {
+			t.Errorf("%s = %q, want %q", field, got, want)
+		}
+	}
+}
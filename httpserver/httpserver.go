@@ -0,0 +1,413 @@
+//go:build !bm25md_noparser
+
+// Package httpserver serves a bm25md corpus over HTTP with JSON endpoints for
+// indexing markdown, searching, and reading or removing documents:
+//
+//	POST   /index            index markdown documents (upserting by ID)
+//	GET    /search?q=...     search, with limit, offset, min_score, and fields
+//	GET    /documents        list document IDs
+//	GET    /documents/{id}   fetch a document
+//	DELETE /documents/{id}   remove a document
+package httpserver
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/chriscorrea/bm25md"
+)
+
+// DefaultMaxBodyBytes caps the size of /index request bodies
+const DefaultMaxBodyBytes = 10 << 20
+
+// DefaultLimit is the number of results returned when /search has no limit
+const DefaultLimit = 10
+
+// CompactRatio is the share of the corpus's document slots held by removed
+// documents at which the server compacts it (see bm25md.Corpus.Compact):
+// deleting documents, and re-indexing them as fewer chunks, leaves tombstones
+var CompactRatio = 0.25
+
+// Server is an http.Handler serving a corpus; it serializes writes against
+// concurrent searches, so the corpus must not be modified elsewhere
+type Server struct {
+	mu           sync.RWMutex
+	corpus       *bm25md.Corpus
+	parser       bm25md.FieldParser
+	chunker      *bm25md.Chunker
+	maxBodyBytes int64
+	highlight    bm25md.HighlightOptions
+	log          *slog.Logger
+	mux          *http.ServeMux
+}
+
+// Option configures a Server
+type Option func(*Server)
+
+// WithParser sets the parser for indexed documents (default NewMarkdownFieldParser()),
+// eg bm25md.HTMLFieldParser{} to index HTML. Chunked documents are always parsed as markdown
+func WithParser(parser bm25md.FieldParser) Option {
+	return func(s *Server) {
+		s.parser = parser
+	}
+}
+
+// WithChunker splits indexed markdown into sections; a document with ID "a.md"
+// is stored as "a.md#0", "a.md#1", ... and searched per section
+func WithChunker(chunker *bm25md.Chunker) Option {
+	return func(s *Server) {
+		s.chunker = chunker
+	}
+}
+
+// WithMaxBodyBytes caps the size of /index request bodies
+func WithMaxBodyBytes(n int64) Option {
+	return func(s *Server) {
+		s.maxBodyBytes = n
+	}
+}
+
+// WithHighlight sets how snippets in search results are highlighted
+func WithHighlight(opts bm25md.HighlightOptions) Option {
+	return func(s *Server) {
+		s.highlight = opts
+	}
+}
+
+// WithLogger sends the server's log messages (indexing at debug level, failed
+// responses as warnings) to logger instead of slog.Default()
+func WithLogger(logger *slog.Logger) Option {
+	return func(s *Server) {
+		s.log = logger
+	}
+}
+
+// New returns a server for corpus, creating an empty corpus when it is nil
+func New(corpus *bm25md.Corpus, opts ...Option) *Server {
+	if corpus == nil {
+		corpus = bm25md.NewCorpus()
+	}
+	s := &Server{
+		corpus:       corpus,
+		maxBodyBytes: DefaultMaxBodyBytes,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if s.parser == nil {
+		s.parser = bm25md.NewMarkdownFieldParser()
+	}
+
+	s.mux = http.NewServeMux()
+	s.mux.HandleFunc("POST /index", s.handleIndex)
+	s.mux.HandleFunc("GET /search", s.handleSearch)
+	s.mux.HandleFunc("GET /documents", s.handleList)
+	s.mux.HandleFunc("GET /documents/{id...}", s.handleGet)
+	s.mux.HandleFunc("DELETE /documents/{id...}", s.handleDelete)
+	return s
+}
+
+// logger returns the server's logger
+func (s *Server) logger() *slog.Logger {
+	if s.log != nil {
+		return s.log
+	}
+	return slog.Default()
+}
+
+// ServeHTTP implements http.Handler
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// IndexRequest is a markdown document posted to /index
+type IndexRequest struct {
+	ID       string         `json:"id"`
+	Markdown string         `json:"markdown"`
+	Metadata map[string]any `json:"metadata,omitempty"`
+	Boost    float64        `json:"boost,omitempty"`
+}
+
+// IndexResponse reports the documents stored by /index
+type IndexResponse struct {
+	IDs []string `json:"ids"`
+}
+
+// SearchResult is a search hit returned by /search
+type SearchResult struct {
+	ID       string         `json:"id"`
+	Score    float64        `json:"score"`
+	Snippet  string         `json:"snippet,omitempty"`
+	Metadata map[string]any `json:"metadata,omitempty"`
+}
+
+// SearchResponse is the body returned by /search
+type SearchResponse struct {
+	Query   string         `json:"query"`
+	Results []SearchResult `json:"results"`
+}
+
+// DocumentResponse is a document returned by /documents/{id}
+type DocumentResponse struct {
+	ID       string            `json:"id"`
+	Fields   map[string]string `json:"fields"`
+	Original string            `json:"original,omitempty"`
+	Metadata map[string]any    `json:"metadata,omitempty"`
+	Boost    float64           `json:"boost,omitempty"`
+}
+
+// errorResponse is the body of every error reply
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// handleIndex parses and upserts one document or an array of documents
+func (s *Server) handleIndex(w http.ResponseWriter, r *http.Request) {
+	body := http.MaxBytesReader(w, r.Body, s.maxBodyBytes)
+	var raw json.RawMessage
+	if err := json.NewDecoder(body).Decode(&raw); err != nil {
+		s.writeError(w, http.StatusBadRequest, fmt.Errorf("decoding request: %w", err))
+		return
+	}
+
+	var requests []IndexRequest
+	if trimmed := strings.TrimSpace(string(raw)); strings.HasPrefix(trimmed, "[") {
+		if err := json.Unmarshal(raw, &requests); err != nil {
+			s.writeError(w, http.StatusBadRequest, fmt.Errorf("decoding request: %w", err))
+			return
+		}
+	} else {
+		var req IndexRequest
+		if err := json.Unmarshal(raw, &req); err != nil {
+			s.writeError(w, http.StatusBadRequest, fmt.Errorf("decoding request: %w", err))
+			return
+		}
+		requests = []IndexRequest{req}
+	}
+	for i, req := range requests {
+		if req.ID == "" {
+			s.writeError(w, http.StatusBadRequest, fmt.Errorf("document %d has no id", i))
+			return
+		}
+	}
+
+	// parse outside the lock so searches continue meanwhile
+	docs := make([][]bm25md.Document, len(requests))
+	for i, req := range requests {
+		docs[i] = s.documents(req)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var ids []string
+	for i, req := range requests {
+		// AddDocument updates documents with the same ExternalID in place, so
+		// only chunks past the new last one are removed
+		for _, doc := range docs[i] {
+			s.corpus.AddDocument(doc)
+			ids = append(ids, doc.ExternalID)
+		}
+		if s.chunker != nil {
+			s.removeChunks(req.ID, len(docs[i]))
+		}
+	}
+	s.compact()
+
+	s.logger().Debug("Indexed documents over HTTP", "requests", len(requests), "documents", len(ids))
+	s.writeJSON(w, http.StatusOK, IndexResponse{IDs: ids})
+}
+
+// documents parses a request into one document, or one per chunk
+func (s *Server) documents(req IndexRequest) []bm25md.Document {
+	if s.chunker == nil {
+		return []bm25md.Document{{
+			ExternalID: req.ID,
+			Fields:     s.parser.ParseDocument(req.Markdown),
+			Original:   req.Markdown,
+			Metadata:   req.Metadata,
+			Boost:      req.Boost,
+		}}
+	}
+
+	chunks := s.chunker.Chunk(req.Markdown)
+	for i := range chunks {
+		chunks[i].ExternalID = fmt.Sprintf("%s#%d", req.ID, i)
+		chunks[i].Boost = req.Boost
+		for key, value := range req.Metadata {
+			chunks[i].Metadata[key] = value
+		}
+	}
+	return chunks
+}
+
+// remove deletes a document, or all of its chunks, reporting whether any existed;
+// callers must hold the write lock
+func (s *Server) remove(id string) bool {
+	removed := false
+	if docID, ok := s.corpus.LookupID(id); ok {
+		removed = s.corpus.RemoveDocument(docID) == nil
+	}
+	if s.chunker == nil {
+		return removed
+	}
+	return s.removeChunks(id, 0) || removed
+}
+
+// removeChunks deletes the chunks of a document from chunk from on, reporting
+// whether any existed; callers must hold the write lock
+func (s *Server) removeChunks(id string, from int) bool {
+	removed := false
+	for i := from; ; i++ {
+		docID, ok := s.corpus.LookupID(fmt.Sprintf("%s#%d", id, i))
+		if !ok {
+			return removed
+		}
+		removed = s.corpus.RemoveDocument(docID) == nil || removed
+	}
+}
+
+// compact compacts the corpus once removed documents make up CompactRatio of
+// its document slots; callers must hold the write lock
+func (s *Server) compact() {
+	if removed, slots := s.corpus.Tombstones(); removed > 0 && float64(removed) >= CompactRatio*float64(slots) {
+		s.corpus.Compact()
+	}
+}
+
+// handleSearch runs a query from the q parameter
+func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+	query := params.Get("q")
+	if strings.TrimSpace(query) == "" {
+		s.writeError(w, http.StatusBadRequest, errors.New("missing q parameter"))
+		return
+	}
+
+	opts := bm25md.SearchOptions{Limit: DefaultLimit}
+	var err error
+	if opts.Limit, err = intParam(params.Get("limit"), DefaultLimit); err != nil {
+		s.writeError(w, http.StatusBadRequest, fmt.Errorf("invalid limit: %w", err))
+		return
+	}
+	if opts.Limit == 0 {
+		// a zero SearchOptions.Limit returns every match
+		s.writeError(w, http.StatusBadRequest, errors.New("invalid limit: must be positive"))
+		return
+	}
+	if opts.Offset, err = intParam(params.Get("offset"), 0); err != nil {
+		s.writeError(w, http.StatusBadRequest, fmt.Errorf("invalid offset: %w", err))
+		return
+	}
+	if v := params.Get("min_score"); v != "" {
+		if opts.MinScore, err = strconv.ParseFloat(v, 64); err != nil {
+			s.writeError(w, http.StatusBadRequest, fmt.Errorf("invalid min_score: %w", err))
+			return
+		}
+	}
+	if v := params.Get("fields"); v != "" {
+		for _, field := range strings.Split(v, ",") {
+			opts.Fields = append(opts.Fields, bm25md.Field(strings.TrimSpace(field)))
+		}
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	results := s.corpus.SearchWithOptions(query, opts)
+	response := SearchResponse{Query: query, Results: make([]SearchResult, len(results))}
+	for i, result := range results {
+		response.Results[i] = SearchResult{
+			ID:       result.ExternalID,
+			Score:    result.Score,
+			Snippet:  s.corpus.SnippetFor(query, result, s.highlight),
+			Metadata: result.Document.Metadata,
+		}
+	}
+	s.writeJSON(w, http.StatusOK, response)
+}
+
+// handleList returns the IDs of all documents
+func (s *Server) handleList(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	ids := []string{}
+	for _, doc := range s.corpus.Documents() {
+		ids = append(ids, doc.ExternalID)
+	}
+	s.writeJSON(w, http.StatusOK, map[string][]string{"ids": ids})
+}
+
+// handleGet returns a stored document
+func (s *Server) handleGet(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	docID, found := s.corpus.LookupID(id)
+	var doc bm25md.Document
+	if found {
+		doc, found = s.corpus.Document(docID)
+	}
+	if !found {
+		s.writeError(w, http.StatusNotFound, fmt.Errorf("no document %q", id))
+		return
+	}
+
+	fields := make(map[string]string, len(doc.Fields))
+	for field, text := range doc.Fields {
+		fields[string(field)] = text
+	}
+	s.writeJSON(w, http.StatusOK, DocumentResponse{
+		ID:       doc.ExternalID,
+		Fields:   fields,
+		Original: doc.Original,
+		Metadata: doc.Metadata,
+		Boost:    doc.Boost,
+	})
+}
+
+// handleDelete removes a document (and its chunks)
+func (s *Server) handleDelete(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.remove(id) {
+		s.writeError(w, http.StatusNotFound, fmt.Errorf("no document %q", id))
+		return
+	}
+	s.compact()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// intParam parses a non-negative integer parameter, returning def when it is empty
+func intParam(value string, def int) (int, error) {
+	if value == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, err
+	}
+	if n < 0 {
+		return 0, errors.New("must not be negative")
+	}
+	return n, nil
+}
+
+// writeJSON writes v as a JSON response
+func (s *Server) writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		s.logger().Warn("Failed to write HTTP response", "error", err)
+	}
+}
+
+// writeError writes an error response
+func (s *Server) writeError(w http.ResponseWriter, status int, err error) {
+	s.writeJSON(w, status, errorResponse{Error: err.Error()})
+}
@@ -0,0 +1,163 @@
+//go:build !bm25md_noparser
+
+package httpserver
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/chriscorrea/bm25md"
+)
+
+// do sends a request to the server and decodes a JSON response into out
+func do(t *testing.T, s *Server, method, target, body string, out any) int {
+	t.Helper()
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, req)
+	if out != nil && rec.Code < 300 {
+		if err := json.Unmarshal(rec.Body.Bytes(), out); err != nil {
+			t.Fatalf("%s %s: decoding %q: %v", method, target, rec.Body.String(), err)
+		}
+	}
+	return rec.Code
+}
+
+func TestServer(t *testing.T) {
+	s := New(nil)
+
+	var indexed IndexResponse
+	code := do(t, s, http.MethodPost, "/index", `[
+		{"id": "notes/habeas.md", "markdown": "# Habeas Corpus\n\nA petition challenges detention.", "metadata": {"court": "federal"}},
+		{"id": "notes/calendar.md", "markdown": "# Calendar\n\nHearings are listed."},
+		{"id": "notes/filing.md", "markdown": "Filing deadlines."},
+		{"id": "notes/appeals.md", "markdown": "Appeal rules."}
+	]`, &indexed)
+	if code != http.StatusOK || len(indexed.IDs) != 4 {
+		t.Fatalf("POST /index = %d, %+v", code, indexed)
+	}
+
+	// a single object upserts
+	code = do(t, s, http.MethodPost, "/index", `{"id": "notes/calendar.md", "markdown": "# Calendar\n\nHearings and recesses."}`, &indexed)
+	if code != http.StatusOK || !reflect.DeepEqual(indexed.IDs, []string{"notes/calendar.md"}) {
+		t.Fatalf("POST /index upsert = %d, %+v", code, indexed)
+	}
+
+	var results SearchResponse
+	code = do(t, s, http.MethodGet, "/search?q="+url.QueryEscape("habeas petition")+"&limit=5", "", &results)
+	if code != http.StatusOK || len(results.Results) != 1 {
+		t.Fatalf("GET /search = %d, %+v", code, results)
+	}
+	if got := results.Results[0]; got.ID != "notes/habeas.md" || got.Metadata["court"] != "federal" || !strings.Contains(got.Snippet, "<mark>") {
+		t.Errorf("search result = %+v", got)
+	}
+
+	var list map[string][]string
+	if code := do(t, s, http.MethodGet, "/documents", "", &list); code != http.StatusOK || len(list["ids"]) != 4 {
+		t.Errorf("GET /documents = %d, %v", code, list)
+	}
+
+	var doc DocumentResponse
+	if code := do(t, s, http.MethodGet, "/documents/notes/calendar.md", "", &doc); code != http.StatusOK || !strings.Contains(doc.Fields["body"], "recesses") {
+		t.Errorf("GET /documents/notes/calendar.md = %d, %+v", code, doc)
+	}
+
+	if code := do(t, s, http.MethodDelete, "/documents/notes/habeas.md", "", nil); code != http.StatusNoContent {
+		t.Errorf("DELETE = %d, want %d", code, http.StatusNoContent)
+	}
+	if code := do(t, s, http.MethodGet, "/documents/notes/habeas.md", "", nil); code != http.StatusNotFound {
+		t.Errorf("GET deleted document = %d, want %d", code, http.StatusNotFound)
+	}
+}
+
+func TestServer_Chunker(t *testing.T) {
+	s := New(bm25md.NewCorpus(), WithChunker(bm25md.NewChunker()))
+
+	var indexed IndexResponse
+	do(t, s, http.MethodPost, "/index", `{"id": "guide.md", "markdown": "# Filing\n\nFile early.\n\n# Appeals\n\nAppeal late."}`, &indexed)
+	if !reflect.DeepEqual(indexed.IDs, []string{"guide.md#0", "guide.md#1"}) {
+		t.Fatalf("chunk IDs = %v", indexed.IDs)
+	}
+
+	// re-indexing with fewer sections drops stale chunks
+	do(t, s, http.MethodPost, "/index", `{"id": "guide.md", "markdown": "Nothing to file."}`, &indexed)
+	var list map[string][]string
+	do(t, s, http.MethodGet, "/documents", "", &list)
+	if !reflect.DeepEqual(list["ids"], []string{"guide.md#0"}) {
+		t.Errorf("documents after re-index = %v", list["ids"])
+	}
+
+	if code := do(t, s, http.MethodDelete, "/documents/guide.md", "", nil); code != http.StatusNoContent {
+		t.Errorf("DELETE chunked document = %d", code)
+	}
+}
+
+func TestServer_Reindex(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    []Option
+		bodies  []string
+		maxSlot int
+	}{
+		{"document", nil, []string{"Filing deadlines.", "Filing deadlines moved."}, 1},
+		{"chunks", []Option{WithChunker(bm25md.NewChunker())}, []string{"# Filing\n\nFile early.\n\n# Appeals\n\nAppeal late.", "Nothing to file."}, 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := New(bm25md.NewCorpus(), tt.opts...)
+			// re-indexing the same ID must not grow the corpus without bound
+			for i := range 20 {
+				body, _ := json.Marshal(IndexRequest{ID: "guide.md", Markdown: tt.bodies[i%len(tt.bodies)]})
+				if code := do(t, s, http.MethodPost, "/index", string(body), nil); code != http.StatusOK {
+					t.Fatalf("POST /index = %d", code)
+				}
+			}
+			if removed, slots := s.corpus.Tombstones(); slots > tt.maxSlot {
+				t.Errorf("corpus has %d slots (%d removed), want at most %d", slots, removed, tt.maxSlot)
+			}
+		})
+	}
+}
+
+func TestServer_Logger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	s := New(nil, WithLogger(logger))
+
+	do(t, s, http.MethodPost, "/index", `{"id": "a.md", "markdown": "Filing deadlines."}`, nil)
+	if !strings.Contains(buf.String(), "Indexed documents over HTTP") {
+		t.Errorf("log = %q", buf.String())
+	}
+}
+
+func TestServer_Errors(t *testing.T) {
+	s := New(nil, WithMaxBodyBytes(64))
+	tests := []struct {
+		name, method, target, body string
+		expected                   int
+	}{
+		{"missing query", http.MethodGet, "/search", "", http.StatusBadRequest},
+		{"bad limit", http.MethodGet, "/search?q=writ&limit=-1", "", http.StatusBadRequest},
+		{"zero limit", http.MethodGet, "/search?q=writ&limit=0", "", http.StatusBadRequest},
+		{"bad min score", http.MethodGet, "/search?q=writ&min_score=high", "", http.StatusBadRequest},
+		{"malformed body", http.MethodPost, "/index", "{", http.StatusBadRequest},
+		{"missing id", http.MethodPost, "/index", `{"markdown": "writ"}`, http.StatusBadRequest},
+		{"body too large", http.MethodPost, "/index", `{"id": "a", "markdown": "` + strings.Repeat("writ ", 20) + `"}`, http.StatusBadRequest},
+		{"unknown document", http.MethodDelete, "/documents/missing.md", "", http.StatusNotFound},
+		{"wrong method", http.MethodGet, "/index", "", http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if code := do(t, s, tt.method, tt.target, tt.body, nil); code != tt.expected {
+				t.Errorf("%s %s = %d, want %d", tt.method, tt.target, code, tt.expected)
+			}
+		})
+	}
+}
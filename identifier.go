@@ -0,0 +1,81 @@
+package bm25md
+
+import (
+	"strings"
+	"unicode"
+)
+
+// CodeTokenizer indexes source code identifiers both whole and split into their
+// camelCase, PascalCase, snake_case, and kebab-case parts, so "docFrequencies"
+// is found by "frequencies" as well as by "docfrequencies". Every token is
+// normalized by the base tokenizer, so queries tokenized by the corpus tokenizer
+// match. Use it for code with WithFieldTokenizer(FieldCode, CodeTokenizer{})
+type CodeTokenizer struct {
+	Base Tokenizer // tokenizer applied to identifiers and their parts; defaults to DefaultTokenizer
+}
+
+// Tokenize implements the Tokenizer interface
+func (t CodeTokenizer) Tokenize(text string) []string {
+	base := t.Base
+	if base == nil {
+		base = DefaultTokenizer{}
+	}
+
+	tokens := []string{}
+	identifiers := strings.FieldsFunc(text, func(r rune) bool {
+		return !isIdentifierRune(r)
+	})
+	for _, identifier := range identifiers {
+		tokens = append(tokens, base.Tokenize(identifier)...)
+		parts := splitIdentifier(identifier)
+		if len(parts) < 2 {
+			continue
+		}
+		for _, part := range parts {
+			tokens = append(tokens, base.Tokenize(part)...)
+		}
+	}
+	return tokens
+}
+
+// isIdentifierRune reports whether r can appear in an identifier (including
+// kebab-case names)
+func isIdentifierRune(r rune) bool {
+	return r == '_' || r == '-' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// splitIdentifier splits an identifier at underscores, hyphens, and case changes:
+// "parseHTTPResponse" -> "parse", "HTTP", "Response". Digits stay with the
+// preceding part ("utf8Decode" -> "utf8", "Decode")
+func splitIdentifier(identifier string) []string {
+	var parts []string
+	runes := []rune(identifier)
+	start := 0
+	flush := func(end int) {
+		if end > start {
+			parts = append(parts, string(runes[start:end]))
+		}
+		start = end
+	}
+	for i, r := range runes {
+		if r == '_' || r == '-' {
+			flush(i)
+			start = i + 1
+			continue
+		}
+		if i == start || !unicode.IsUpper(r) {
+			continue
+		}
+		prev := runes[i-1]
+		switch {
+		case unicode.IsLower(prev) || unicode.IsDigit(prev):
+			// lower to upper: "parseDocument"
+			flush(i)
+		case unicode.IsUpper(prev) && i+1 < len(runes) && unicode.IsLower(runes[i+1]):
+			// end of an acronym: "HTTPResponse"
+			flush(i)
+		}
+	}
+	flush(len(runes))
+	return parts
+}
@@ -0,0 +1,94 @@
+package bm25md
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCodeTokenizer(t *testing.T) {
+	tests := []struct {
+		name      string
+		tokenizer CodeTokenizer
+		input     string
+		expected  []string
+	}{
+		{
+			name:     "camel case",
+			input:    "parseDocument",
+			expected: []string{"parsedocument", "parse", "document"},
+		},
+		{
+			name:     "snake case",
+			input:    "doc_frequencies",
+			expected: []string{"doc_frequencies", "doc", "frequencies"},
+		},
+		{
+			name:     "kebab case",
+			input:    "max-field-tokens",
+			expected: []string{"max-field-tokens", "max", "field", "tokens"},
+		},
+		{
+			name:     "acronyms and digits",
+			input:    "parseHTTPResponse utf8Decode",
+			expected: []string{"parsehttpresponse", "parse", "http", "response", "utf8decode", "utf8", "decode"},
+		},
+		{
+			name:     "qualified call",
+			input:    "c.docFrequencies[term]++",
+			expected: []string{"docfrequencies", "doc", "frequencies", "term"},
+		},
+		{
+			name:     "plain words are not duplicated",
+			input:    "return results",
+			expected: []string{"return", "results"},
+		},
+		{
+			name:      "custom base",
+			tokenizer: CodeTokenizer{Base: UnicodeTokenizer{}},
+			input:     "newCorpus",
+			expected:  []string{"newCorpus", "new", "Corpus"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.tokenizer.Tokenize(tt.input); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Tokenize(%q) = %q, want %q", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestWithFieldTokenizer(t *testing.T) {
+	docs := []Document{
+		{Fields: map[Field]string{FieldCode: "scorer.docFrequencies[term]++"}},
+		{Fields: map[Field]string{FieldBody: "Term frequencies measure how often words appear."}},
+		{Fields: map[Field]string{FieldBody: "Court calendar."}},
+		{Fields: map[Field]string{FieldBody: "Filing deadlines."}},
+		{Fields: map[Field]string{FieldBody: "Motion practice."}},
+	}
+
+	plain := NewCorpus()
+	plain.AddDocuments(docs)
+	if results := plain.Search("frequencies", 10); len(results) != 1 || results[0].Document.ID != 1 {
+		t.Errorf("without code tokenizer, Search(frequencies) = %+v, want document 1 only", results)
+	}
+
+	corpus := NewCorpus(WithFieldTokenizer(FieldCode, CodeTokenizer{}))
+	corpus.AddDocuments(docs)
+	results := corpus.Search("frequencies", 10)
+	if len(results) != 2 {
+		t.Fatalf("Search(frequencies) returned %d results, want 2", len(results))
+	}
+	if results := corpus.Search("docFrequencies", 10); len(results) != 1 || results[0].Document.ID != 0 {
+		t.Errorf("Search(docFrequencies) = %+v, want document 0", results)
+	}
+
+	// the body field keeps the corpus tokenizer
+	if results := corpus.Search("term", 10); len(results) != 2 {
+		t.Errorf("Search(term) returned %d results, want 2", len(results))
+	}
+
+	// frozen indexes keep the field tokenizers
+	sameResults(t, corpus.Freeze().Search("frequencies", 10), results)
+}
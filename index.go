@@ -0,0 +1,171 @@
+package bm25md
+
+import "sort"
+
+// postingList maps each document containing a term to the term's frequency per field
+type postingList map[int]map[Field]int
+
+// queryTerm is a query term resolved against the inverted index
+type queryTerm struct {
+	term     string
+	scorer   TermScorer // the similarity's scorer for this term
+	postings postingList
+	stats    TermStats // corpus statistics the scorer was prepared from
+	occur    occur     // whether the term is optional, required, or excluded
+}
+
+// indexPostings adds a document's field term frequencies to the inverted index
+func (c *Corpus) indexPostings(docIndex int) {
+	if c.postings == nil {
+		c.postings = make(map[string]postingList)
+	}
+	for field, scorer := range c.fieldScorers {
+		scorer.eachTerm(docIndex, func(term string, tf int) {
+			list, exists := c.postings[term]
+			if !exists {
+				list = make(postingList)
+				c.postings[term] = list
+				c.invalidateTermDict()
+			}
+			fields, exists := list[docIndex]
+			if !exists {
+				fields = make(map[Field]int, 1)
+				list[docIndex] = fields
+			}
+			fields[field] = tf
+		})
+	}
+}
+
+// unindexPostings removes a document from the inverted index
+func (c *Corpus) unindexPostings(docIndex int) {
+	for _, scorer := range c.fieldScorers {
+		scorer.eachTerm(docIndex, func(term string, _ int) {
+			list := c.postings[term]
+			delete(list, docIndex)
+			if len(list) == 0 {
+				delete(c.postings, term)
+				c.invalidateTermDict()
+			}
+		})
+	}
+}
+
+// rebuildPostings rebuilds the inverted index from the field scorers
+func (c *Corpus) rebuildPostings() {
+	c.postings = make(map[string]postingList)
+	c.invalidateTermDict()
+	for docIndex := range c.documents {
+		c.indexPostings(docIndex)
+	}
+}
+
+// prepareQuery resolves loose query terms to their postings and IDF, dropping
+// terms that appear in no document (duplicates are kept, so they count repeatedly)
+func (c *Corpus) prepareQuery(queryTerms []string) []queryTerm {
+	terms := make([]queryTerm, 0, len(queryTerms))
+	for _, term := range queryTerms {
+		if qt, ok := c.prepareTerm(term, occurShould); ok {
+			terms = append(terms, qt)
+		}
+	}
+	return terms
+}
+
+// prepareTerm resolves a single term; ok is false when no document contains it
+func (c *Corpus) prepareTerm(term string, occur occur) (queryTerm, bool) {
+	list := c.postings[term]
+	if len(list) == 0 {
+		return queryTerm{term: term, occur: occur}, false
+	}
+	return c.newQueryTerm(term, list, occur), true
+}
+
+// newQueryTerm builds a query term over postings, preparing the similarity's
+// scorer from the term's corpus statistics
+func (c *Corpus) newQueryTerm(term string, postings postingList, occur occur) queryTerm {
+	stats := termStats(term, postings, c.liveDocuments(), c.totalTokens())
+	return queryTerm{term: term, scorer: c.similarity.TermScorer(stats, c.params), postings: postings, stats: stats, occur: occur}
+}
+
+// termStats gathers a term's statistics from its postings and the collection sizes
+func termStats(term string, postings postingList, totalDocs, totalTokens int) TermStats {
+	stats := TermStats{
+		Term:        term,
+		DocFreq:     len(postings),
+		TotalDocs:   totalDocs,
+		TotalTokens: totalTokens,
+	}
+	for _, fields := range postings {
+		for _, tf := range fields {
+			stats.TotalTermFreq += tf
+		}
+	}
+	return stats
+}
+
+// totalTokens returns the number of tokens indexed across all fields
+func (c *Corpus) totalTokens() int {
+	total := 0
+	for _, scorer := range c.fieldScorers {
+		total += scorer.totalLength
+	}
+	return total
+}
+
+// idf returns the inverse document frequency for a term found in docFreq documents
+func (c *Corpus) idf(docFreq int) float64 {
+	return bm25IDF(TermStats{DocFreq: docFreq, TotalDocs: c.liveDocuments()})
+}
+
+// matches reports whether a document satisfies the required and excluded terms
+func matches(terms []queryTerm, docIndex int) bool {
+	for _, qt := range terms {
+		_, found := qt.postings[docIndex]
+		switch {
+		case qt.occur == occurMust && !found:
+			return false
+		case qt.occur == occurMustNot && found:
+			return false
+		}
+	}
+	return true
+}
+
+// candidates returns the documents that can match the query, in index order:
+// those containing every required term, or else any optional term, minus
+// documents containing an excluded term
+func candidates(terms []queryTerm) []int {
+	// with required terms, only the shortest required postings list needs scanning
+	var shortest postingList
+	hasRequired := false
+	for _, qt := range terms {
+		if qt.occur == occurMust && (!hasRequired || len(qt.postings) < len(shortest)) {
+			shortest, hasRequired = qt.postings, true
+		}
+	}
+
+	var sources []postingList
+	if hasRequired {
+		sources = []postingList{shortest}
+	} else {
+		for _, qt := range terms {
+			if qt.occur == occurShould {
+				sources = append(sources, qt.postings)
+			}
+		}
+	}
+
+	seen := make(map[int]bool)
+	var docs []int
+	for _, list := range sources {
+		for docIndex := range list {
+			if !seen[docIndex] && matches(terms, docIndex) {
+				seen[docIndex] = true
+				docs = append(docs, docIndex)
+			}
+		}
+	}
+	sort.Ints(docs)
+	return docs
+}
@@ -0,0 +1,87 @@
+package bm25md
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestCorpus_Postings(t *testing.T) {
+	corpus := NewCorpus()
+	corpus.AddDocument(Document{Fields: map[Field]string{FieldH1: "habeas corpus", FieldBody: "the habeas writ"}})
+	corpus.AddDocument(Document{Fields: map[Field]string{FieldBody: "corpus juris"}})
+
+	want := map[Field]int{FieldH1: 1, FieldBody: 1}
+	if got := corpus.postings["habeas"][0]; !reflect.DeepEqual(got, want) {
+		t.Errorf("postings[habeas][0] = %v, want %v", got, want)
+	}
+	if got := len(corpus.postings["corpus"]); got != 2 {
+		t.Errorf("len(postings[corpus]) = %d, want 2", got)
+	}
+
+	// postings follow updates and removals
+	_ = corpus.UpdateDocument(1, Document{Fields: map[Field]string{FieldBody: "juris doctor"}})
+	if _, exists := corpus.postings["corpus"][1]; exists {
+		t.Error("postings still list updated document for a removed term")
+	}
+	_ = corpus.RemoveDocument(0)
+	if _, exists := corpus.postings["habeas"]; exists {
+		t.Error("postings still list a term only found in a removed document")
+	}
+
+	incremental := corpus.postings
+	corpus.rebuildPostings()
+	if !reflect.DeepEqual(incremental, corpus.postings) {
+		t.Errorf("incremental postings %v differ from rebuilt %v", incremental, corpus.postings)
+	}
+}
+
+func TestCorpus_SearchCandidates(t *testing.T) {
+	var parallel bool
+	// two workers, so scoring fans out even on a single CPU
+	corpus := NewCorpus(WithParallelism(0, 2), WithSearchHook(func(_ string, _ []SearchResult, stats SearchStats) {
+		parallel = stats.Parallel
+	}))
+
+	// enough matching documents to score candidates in parallel
+	for i := 0; i < 150; i++ {
+		body := fmt.Sprintf("shared term tok%03d", i%30)
+		if i%3 == 0 {
+			body += " rare"
+		}
+		corpus.AddDocument(Document{Fields: map[Field]string{FieldBody: body}})
+	}
+	for i := 0; i < 200; i++ {
+		corpus.AddDocument(Document{Fields: map[Field]string{FieldBody: "unrelated filler"}})
+	}
+
+	for _, query := range []string{"rare", "shared rare"} {
+		results := corpus.Search(query, 0)
+
+		// every result matches what scoring each document directly gives
+		for _, result := range results {
+			if want := corpus.Score(query, result.Index); math.Abs(result.Score-want) > 1e-9 {
+				t.Errorf("%q: result %d score = %v, want %v", query, result.Index, result.Score, want)
+			}
+		}
+		hits := 0
+		for i := range corpus.documents {
+			if corpus.Score(query, i) > 0 {
+				hits++
+			}
+		}
+		if len(results) != hits {
+			t.Errorf("%q: got %d results, want %d", query, len(results), hits)
+		}
+	}
+
+	corpus.Search("shared", 0)
+	if !parallel {
+		t.Error("expected parallel scoring for many candidates")
+	}
+	corpus.Search("rare", 0)
+	if parallel {
+		t.Error("expected sequential scoring for few candidates")
+	}
+}
@@ -0,0 +1,164 @@
+//go:build !bm25md_noparser
+
+package bm25md
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// JSONLMapping describes how keys in a JSON Lines record map onto a Document.
+// Keys may use dot notation (eg "meta.title") to reach into nested objects.
+type JSONLMapping struct {
+	Fields   map[string]Field // JSON keys indexed directly into a field
+	Markdown string           // optional JSON key whose value is parsed as markdown
+	Metadata []string         // JSON keys copied into Document.Metadata
+	ID       string           // optional JSON key holding the document's external ID
+}
+
+// LoadJSONL reads JSON Lines records from r and converts each into a Document
+// using the given mapping. Blank lines are skipped; malformed records abort
+// loading with an error that names the offending line.
+func LoadJSONL(r io.Reader, mapping JSONLMapping) ([]Document, error) {
+	if len(mapping.Fields) == 0 && mapping.Markdown == "" {
+		return nil, errors.New("bm25md: JSONL mapping has no fields or markdown key")
+	}
+
+	var parser *MarkdownFieldParser
+	if mapping.Markdown != "" {
+		parser = NewMarkdownFieldParser()
+	}
+
+	// sort mapped keys so concatenated fields are deterministic
+	keys := make([]string, 0, len(mapping.Fields))
+	for key := range mapping.Fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	documents := make([]Document, 0)
+	reader := bufio.NewReader(r)
+	for lineNum := 1; ; lineNum++ {
+		line, readErr := reader.ReadBytes('\n')
+		if readErr != nil && readErr != io.EOF {
+			return nil, fmt.Errorf("bm25md: reading JSONL line %d: %w", lineNum, readErr)
+		}
+
+		line = bytes.TrimSpace(line)
+		if len(line) > 0 {
+			var record map[string]any
+			if err := json.Unmarshal(line, &record); err != nil {
+				return nil, fmt.Errorf("bm25md: decoding JSONL line %d: %w", lineNum, err)
+			}
+			documents = append(documents, mapping.document(record, keys, parser, len(documents)))
+		}
+
+		if readErr == io.EOF {
+			break
+		}
+	}
+
+	return documents, nil
+}
+
+// document builds a Document from a single decoded record
+func (m JSONLMapping) document(record map[string]any, keys []string, parser *MarkdownFieldParser, id int) Document {
+	fields := make(map[Field]string)
+	var original []string
+
+	// markdown content is parsed first so directly mapped keys extend it
+	if parser != nil {
+		if content := plainText(lookupJSONKey(record, m.Markdown)); content != "" {
+			fields = parser.ParseDocument(content)
+			original = append(original, content)
+		}
+	}
+
+	for _, key := range keys {
+		content := plainText(lookupJSONKey(record, key))
+		if content == "" {
+			continue
+		}
+		appendField(fields, m.Fields[key], content)
+		if parser == nil {
+			original = append(original, content)
+		}
+	}
+
+	var metadata map[string]any
+	for _, key := range m.Metadata {
+		if value := lookupJSONKey(record, key); value != nil {
+			if metadata == nil {
+				metadata = make(map[string]any)
+			}
+			metadata[key] = value
+		}
+	}
+
+	return Document{
+		ID:         id,
+		ExternalID: plainText(lookupJSONKey(record, m.ID)),
+		Fields:     fields,
+		Original:   strings.Join(original, "\n\n"),
+		Metadata:   metadata,
+	}
+}
+
+// lookupJSONKey resolves a possibly dotted key within a decoded record
+func lookupJSONKey(record map[string]any, key string) any {
+	// exact keys win over nested lookups (keys may legitimately contain dots)
+	if value, ok := record[key]; ok {
+		return value
+	}
+
+	var current any = record
+	for _, part := range strings.Split(key, ".") {
+		obj, ok := current.(map[string]any)
+		if !ok {
+			return nil
+		}
+		current = obj[part]
+	}
+	return current
+}
+
+// plainText flattens a decoded JSON, YAML, or TOML value into indexable text
+func plainText(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case int:
+		return strconv.Itoa(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case bool:
+		return strconv.FormatBool(v)
+	case time.Time:
+		return v.Format(time.RFC3339)
+	case []string:
+		return strings.Join(v, " ")
+	case []any:
+		parts := make([]string, 0, len(v))
+		for _, item := range v {
+			if text := plainText(item); text != "" {
+				parts = append(parts, text)
+			}
+		}
+		return strings.Join(parts, " ")
+	default:
+		// nested objects are not flattened; map their keys explicitly instead
+		return ""
+	}
+}
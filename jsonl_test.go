@@ -0,0 +1,92 @@
+//go:build !bm25md_noparser
+
+package bm25md
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLoadJSONL(t *testing.T) {
+	input := `{"slug": "fires-fade", "title": "Fires Fade", "body": "I should have loved a thunderbird instead", "tags": ["poem", "villanelle"], "meta": {"author": "Plath"}}
+
+{"title": "Mad Girl's Love Song", "body": "I shut my eyes and all the world drops dead", "meta": {"author": "Plath", "year": 1953}}
+`
+	mapping := JSONLMapping{
+		Fields: map[string]Field{
+			"title": FieldH1,
+			"body":  FieldBody,
+			"tags":  FieldBold,
+		},
+		Metadata: []string{"meta.author", "meta.year"},
+		ID:       "slug",
+	}
+
+	docs, err := LoadJSONL(strings.NewReader(input), mapping)
+	if err != nil {
+		t.Fatalf("LoadJSONL() error = %v", err)
+	}
+	if len(docs) != 2 {
+		t.Fatalf("LoadJSONL() returned %d documents, want 2", len(docs))
+	}
+
+	if docs[0].Fields[FieldH1] != "Fires Fade" {
+		t.Errorf("H1 = %q, want %q", docs[0].Fields[FieldH1], "Fires Fade")
+	}
+	if docs[0].Fields[FieldBold] != "poem villanelle" {
+		t.Errorf("Bold = %q, want %q", docs[0].Fields[FieldBold], "poem villanelle")
+	}
+	if docs[1].ID != 1 {
+		t.Errorf("ID = %d, want 1", docs[1].ID)
+	}
+	if docs[0].ExternalID != "fires-fade" || docs[1].ExternalID != "" {
+		t.Errorf("ExternalIDs = %q, %q, want %q and empty", docs[0].ExternalID, docs[1].ExternalID, "fires-fade")
+	}
+	if docs[0].Metadata["meta.author"] != "Plath" {
+		t.Errorf("Metadata[meta.author] = %v, want Plath", docs[0].Metadata["meta.author"])
+	}
+	if _, ok := docs[0].Metadata["meta.year"]; ok {
+		t.Error("missing metadata keys should not be recorded")
+	}
+	if docs[1].Metadata["meta.year"] != float64(1953) {
+		t.Errorf("Metadata[meta.year] = %v, want 1953", docs[1].Metadata["meta.year"])
+	}
+}
+
+func TestLoadJSONL_Markdown(t *testing.T) {
+	input := `{"content": "# Habeas Corpus\nThe **writ** protects against unlawful detention.", "source": "notes"}`
+
+	docs, err := LoadJSONL(strings.NewReader(input), JSONLMapping{
+		Markdown: "content",
+		Fields:   map[string]Field{"source": FieldBody},
+	})
+	if err != nil {
+		t.Fatalf("LoadJSONL() error = %v", err)
+	}
+
+	doc := docs[0]
+	if doc.Fields[FieldH1] != "Habeas Corpus" {
+		t.Errorf("H1 = %q, want %q", doc.Fields[FieldH1], "Habeas Corpus")
+	}
+	if doc.Fields[FieldBold] != "writ" {
+		t.Errorf("Bold = %q, want %q", doc.Fields[FieldBold], "writ")
+	}
+	if !strings.HasSuffix(doc.Fields[FieldBody], "notes") {
+		t.Errorf("Body = %q, want mapped key appended", doc.Fields[FieldBody])
+	}
+	if !strings.HasPrefix(doc.Original, "# Habeas Corpus") {
+		t.Errorf("Original = %q, want markdown content", doc.Original)
+	}
+}
+
+func TestLoadJSONL_Errors(t *testing.T) {
+	if _, err := LoadJSONL(strings.NewReader(`{"a": 1}`), JSONLMapping{}); err == nil {
+		t.Error("expected error for empty mapping")
+	}
+
+	mapping := JSONLMapping{Fields: map[string]Field{"body": FieldBody}}
+	_, err := LoadJSONL(strings.NewReader("{\"body\": \"ok\"}\n{not json}\n"), mapping)
+	if err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("error = %v, want line 2 decoding error", err)
+	}
+}
@@ -0,0 +1,320 @@
+package bm25md
+
+import (
+	"strings"
+	"sync"
+)
+
+// MetaLanguage is the metadata key of a document's language (eg "fr"), which
+// takes precedence over language detection
+const MetaLanguage = "lang"
+
+// Stopword lists of the bundled language analyzers, registered under their
+// ISO 639-1 codes
+var (
+	FrenchStopwords = []string{
+		"au", "aux", "avec", "ce", "ces", "cette", "dans", "de", "des", "du",
+		"elle", "elles", "en", "est", "et", "eux", "il", "ils", "je", "la",
+		"le", "les", "leur", "leurs", "lui", "mais", "me", "même", "mes", "moi",
+		"mon", "ne", "nos", "notre", "nous", "on", "ou", "où", "par", "pas",
+		"pour", "qu", "que", "qui", "sa", "se", "ses", "son", "sont", "sur",
+		"ta", "te", "tes", "toi", "ton", "tu", "un", "une", "vos", "votre", "vous",
+	}
+	GermanStopwords = []string{
+		"aber", "als", "am", "an", "auch", "auf", "aus", "bei", "bin", "bis",
+		"das", "dass", "dem", "den", "der", "des", "die", "dies", "diese", "dieser",
+		"du", "ein", "eine", "einem", "einen", "einer", "eines", "er", "es", "für",
+		"hat", "ich", "ihr", "im", "in", "ist", "mit", "nach", "nicht", "noch",
+		"nur", "oder", "sich", "sie", "sind", "so", "über", "um", "und", "uns",
+		"unter", "vom", "von", "vor", "war", "was", "wie", "wir", "wird", "zu", "zum", "zur",
+	}
+	SpanishStopwords = []string{
+		"a", "al", "algo", "como", "con", "de", "del", "desde", "donde", "el",
+		"ella", "ellos", "en", "entre", "era", "es", "esta", "este", "esto", "fue",
+		"ha", "hay", "la", "las", "le", "les", "lo", "los", "más", "me",
+		"mi", "muy", "no", "nos", "o", "para", "pero", "por", "que", "se",
+		"sin", "sobre", "son", "su", "sus", "también", "te", "tu", "un", "una", "uno", "y", "ya",
+	}
+	ItalianStopwords = []string{
+		"a", "al", "alla", "anche", "che", "ci", "come", "con", "da", "dal",
+		"dei", "del", "della", "delle", "di", "e", "è", "gli", "ha", "hanno",
+		"i", "il", "in", "io", "la", "le", "lei", "lo", "loro", "lui",
+		"ma", "mi", "ne", "negli", "nel", "nella", "noi", "non", "o", "per",
+		"più", "quella", "questo", "se", "si", "sono", "su", "sua", "sul", "suo", "tra", "un", "una", "uno",
+	}
+	PortugueseStopwords = []string{
+		"a", "ao", "aos", "as", "com", "como", "da", "das", "de", "do",
+		"dos", "e", "é", "ela", "ele", "eles", "em", "entre", "era", "essa",
+		"esse", "esta", "este", "eu", "foi", "há", "isso", "já", "mais", "mas",
+		"me", "na", "nas", "no", "nos", "não", "o", "os", "ou", "para",
+		"pela", "pelo", "por", "que", "se", "sem", "seu", "sua", "são", "também", "um", "uma",
+	}
+)
+
+var (
+	analyzersMu       sync.RWMutex
+	languageAnalyzers = map[string]Analyzer{}
+)
+
+func init() {
+	bundled := []struct {
+		lang      string
+		stopwords []string
+		stem      func(string) string
+	}{
+		{"en", EnglishStopwords, englishMinimalStem},
+		{"fr", FrenchStopwords, frenchMinimalStem},
+		{"de", GermanStopwords, germanMinimalStem},
+		{"es", SpanishStopwords, spanishMinimalStem},
+		{"it", ItalianStopwords, italianMinimalStem},
+		{"pt", PortugueseStopwords, portugueseMinimalStem},
+	}
+	for _, b := range bundled {
+		RegisterStopwords(b.lang, b.stopwords)
+		RegisterAnalyzer(b.lang, Analyzer{
+			Tokenizer: UnicodeTokenizer{},
+			TokenFilters: []TokenFilter{
+				LowercaseFilter(),
+				// before folding, since the lists keep their accents
+				StopwordFilter(b.stopwords...),
+				ASCIIFoldingFilter(),
+				MinLengthFilter(3),
+				StemFilter(b.stem),
+			},
+		})
+	}
+}
+
+// RegisterAnalyzer makes an analyzer available to LanguageAnalyzer and to corpora
+// using WithLanguageDetection, replacing any analyzer registered for the language
+func RegisterAnalyzer(lang string, analyzer Analyzer) {
+	analyzersMu.Lock()
+	defer analyzersMu.Unlock()
+	languageAnalyzers[baseLanguage(lang)] = analyzer
+}
+
+// LanguageAnalyzer returns the analyzer registered for a language. Analyzers for
+// English, French, German, Spanish, Italian, and Portuguese are bundled; each
+// folds accents, drops the language's stopwords, and strips plural endings
+func LanguageAnalyzer(lang string) (Analyzer, bool) {
+	analyzersMu.RLock()
+	defer analyzersMu.RUnlock()
+	analyzer, ok := languageAnalyzers[baseLanguage(lang)]
+	return analyzer, ok
+}
+
+// baseLanguage lowercases a language tag and drops its region ("pt-BR" -> "pt")
+func baseLanguage(lang string) string {
+	lang, _, _ = strings.Cut(strings.ToLower(lang), "-")
+	lang, _, _ = strings.Cut(lang, "_")
+	return lang
+}
+
+// LanguageDetector guesses the language of a text, returning "" when unsure
+type LanguageDetector interface {
+	Detect(text string) string
+}
+
+// StopwordDetector detects the language whose registered stopwords occur most
+// often in a text, which works well from a handful of words upwards. With no
+// Languages it considers the bundled analyzer languages
+type StopwordDetector struct {
+	Languages []string // candidate languages, earlier ones winning ties
+}
+
+// bundledLanguages are the languages with bundled analyzers, in tie-break order
+var bundledLanguages = []string{"en", "fr", "de", "es", "it", "pt"}
+
+// Detect implements the LanguageDetector interface
+func (d StopwordDetector) Detect(text string) string {
+	langs := d.Languages
+	if len(langs) == 0 {
+		langs = bundledLanguages
+	}
+	words := LowercaseFilter().Filter(UnicodeTokenizer{}.Tokenize(text))
+
+	best, bestHits := "", 0
+	for _, lang := range langs {
+		stopwords, ok := LookupStopwords(lang)
+		if !ok {
+			continue
+		}
+		set := make(map[string]bool, len(stopwords))
+		for _, word := range stopwords {
+			set[word] = true
+		}
+		hits := 0
+		for _, word := range words {
+			if set[word] {
+				hits++
+			}
+		}
+		if hits > bestHits {
+			best, bestHits = lang, hits
+		}
+	}
+	return best
+}
+
+// WithLanguageDetection analyzes each document with the analyzer registered for
+// its language: the MetaLanguage metadata value when set, otherwise the language
+// detector's guess for its text (nil uses StopwordDetector). Queries are analyzed
+// for their detected language. Documents and queries in a language without an
+// analyzer use the index and query analyzers, and fields set with
+// WithFieldTokenizer keep their tokenizer
+func WithLanguageDetection(detector LanguageDetector) CorpusOption {
+	return func(c *Corpus) {
+		if detector == nil {
+			detector = StopwordDetector{}
+		}
+		c.languages = detector
+	}
+}
+
+// documentLanguage returns the language of a document ("" when unknown)
+func (c *Corpus) documentLanguage(doc Document) string {
+	if lang, ok := doc.Metadata[MetaLanguage].(string); ok && lang != "" {
+		return lang
+	}
+	if body := doc.Fields[FieldBody]; body != "" {
+		return c.languages.Detect(body)
+	}
+	texts := make([]string, 0, len(doc.Fields))
+	for _, text := range doc.Fields {
+		texts = append(texts, text)
+	}
+	return c.languages.Detect(strings.Join(texts, " "))
+}
+
+// languageTokenize returns a func tokenizing text with the analyzer of lang,
+// removing corpus stopwords; it falls back to fallback for unknown languages
+func (c *Corpus) languageTokenize(lang string, fallback func(string) []string) func(string) []string {
+	if lang != "" {
+		if analyzer, ok := LanguageAnalyzer(lang); ok {
+			return func(text string) []string {
+				return c.filterStopwords(analyzer.Tokenize(text))
+			}
+		}
+	}
+	return fallback
+}
+
+// queryTokenizer returns the func tokenizing the words of query, which follows
+// the query's detected language when WithLanguageDetection is set
+func (c *Corpus) queryTokenizer(query string) func(string) []string {
+	if c.languages == nil {
+		return c.tokenize
+	}
+	return c.languageTokenize(c.languages.Detect(query), c.tokenize)
+}
+
+// phraseTokenizer returns the func tokenizing the phrases of query, like
+// queryTokenizer but without synonym expansion
+func (c *Corpus) phraseTokenizer(query string) func(string) []string {
+	if c.languages == nil {
+		return c.tokenizePhrase
+	}
+	return c.languageTokenize(c.languages.Detect(query), c.tokenizePhrase)
+}
+
+// frenchMinimalStem removes French plural and feminine endings
+// ("chevaux" -> "cheval", "contrats" -> "contrat")
+func frenchMinimalStem(token string) string {
+	s := []rune(token)
+	n := len(s)
+	if n < 6 {
+		return token
+	}
+	if s[n-1] == 'x' {
+		if s[n-3] == 'a' && s[n-2] == 'u' {
+			s[n-2] = 'l'
+		}
+		return string(s[:n-1])
+	}
+	for _, r := range []rune{'s', 'r', 'e'} {
+		if s[n-1] == r {
+			n--
+		}
+	}
+	if s[n-1] == s[n-2] {
+		n--
+	}
+	return string(s[:n])
+}
+
+// germanMinimalStem removes common German plural and case endings
+// ("verträgen" -> "vertrag" once accents are folded)
+func germanMinimalStem(token string) string {
+	n := len(token)
+	if n < 5 {
+		return token
+	}
+	if n > 6 && strings.HasSuffix(token, "nen") {
+		return token[:n-3]
+	}
+	if n > 5 {
+		for _, suffix := range []string{"en", "se", "es", "er"} {
+			if strings.HasSuffix(token, suffix) {
+				return token[:n-2]
+			}
+		}
+	}
+	switch token[n-1] {
+	case 'n', 'e', 's', 'r':
+		return token[:n-1]
+	}
+	return token
+}
+
+// spanishMinimalStem removes Spanish plural endings
+// ("leyes" -> "ley", "luces" -> "luz", "contratos" -> "contrato")
+func spanishMinimalStem(token string) string {
+	n := len(token)
+	if n < 4 || token[n-1] != 's' {
+		return token
+	}
+	switch {
+	case strings.HasSuffix(token, "ces"):
+		return token[:n-3] + "z"
+	case strings.HasSuffix(token, "es") && !isVowel(token[n-3]):
+		return token[:n-2]
+	}
+	return token[:n-1]
+}
+
+// italianMinimalStem removes the final vowel of Italian words, which carries
+// gender and number ("contratti", "contratto" -> "contratt"; "banche" -> "banc")
+func italianMinimalStem(token string) string {
+	n := len(token)
+	if n < 5 || !isVowel(token[n-1]) {
+		return token
+	}
+	token = token[:n-1]
+	if strings.HasSuffix(token, "ch") || strings.HasSuffix(token, "gh") {
+		token = token[:len(token)-1]
+	}
+	return token
+}
+
+// portugueseMinimalStem removes Portuguese plural endings, once accents are
+// folded ("limoes" -> "limao", "jornais" -> "jornal", "contratos" -> "contrato")
+func portugueseMinimalStem(token string) string {
+	n := len(token)
+	if n < 4 || token[n-1] != 's' {
+		return token
+	}
+	for _, rule := range [][2]string{
+		{"oes", "ao"}, {"aes", "ao"}, {"ais", "al"}, {"eis", "el"}, {"ois", "ol"},
+		{"res", "r"}, {"zes", "z"}, {"ns", "m"},
+	} {
+		if strings.HasSuffix(token, rule[0]) {
+			return strings.TrimSuffix(token, rule[0]) + rule[1]
+		}
+	}
+	return token[:n-1]
+}
+
+// isVowel reports whether an ASCII byte is a lowercase vowel
+func isVowel(b byte) bool {
+	return strings.IndexByte("aeiou", b) >= 0
+}
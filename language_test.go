@@ -0,0 +1,103 @@
+package bm25md
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestLanguageAnalyzer(t *testing.T) {
+	tests := []struct {
+		lang     string
+		input    string
+		expected []string
+	}{
+		{lang: "en", input: "The writs of the courts", expected: []string{"writ", "court"}},
+		{lang: "fr", input: "Les chevaux et les contrats de travail", expected: []string{"cheval", "contrat", "travail"}},
+		{lang: "de", input: "Die Verträgen und die Gerichte", expected: []string{"vertrag", "gericht"}},
+		{lang: "es", input: "Las leyes y los contratos", expected: []string{"ley", "contrato"}},
+		{lang: "it", input: "Le banche e i contratti", expected: []string{"banc", "contratt"}},
+		{lang: "pt-BR", input: "Os jornais e os limões", expected: []string{"jornal", "limao"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.lang, func(t *testing.T) {
+			analyzer, ok := LanguageAnalyzer(tt.lang)
+			if !ok {
+				t.Fatalf("LanguageAnalyzer(%q) not found", tt.lang)
+			}
+			if got := analyzer.Tokenize(tt.input); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Tokenize(%q) = %q, want %q", tt.input, got, tt.expected)
+			}
+		})
+	}
+
+	if _, ok := LanguageAnalyzer("xx"); ok {
+		t.Error("LanguageAnalyzer(xx) found an analyzer")
+	}
+	if _, ok := LookupStopwords("fr"); !ok {
+		t.Error("French stopwords are not registered")
+	}
+}
+
+func TestStopwordDetector(t *testing.T) {
+	tests := []struct {
+		text     string
+		expected string
+	}{
+		{text: "The court granted the petition for a writ", expected: "en"},
+		{text: "Le tribunal a rejeté la demande du requérant", expected: "fr"},
+		{text: "Das Gericht hat die Klage abgewiesen", expected: "de"},
+		{text: "El tribunal rechazó la demanda del recurrente", expected: "es"},
+		{text: "Il tribunale ha respinto il ricorso della società", expected: "it"},
+		{text: "O tribunal não aceitou o recurso da empresa", expected: "pt"},
+		{text: "habeas corpus", expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			if got := (StopwordDetector{}).Detect(tt.text); got != tt.expected {
+				t.Errorf("Detect(%q) = %q, want %q", tt.text, got, tt.expected)
+			}
+		})
+	}
+
+	if got := (StopwordDetector{Languages: []string{"de"}}).Detect("the court and the writ"); got != "" {
+		t.Errorf("Detect with only German = %q, want none", got)
+	}
+}
+
+func TestWithLanguageDetection(t *testing.T) {
+	docs := []Document{
+		{Fields: map[Field]string{FieldBody: "Les contrats de travail sont signés par les parties."}},
+		{Fields: map[Field]string{FieldBody: "Die Verträge werden von den Parteien unterschrieben."}},
+		{Fields: map[Field]string{FieldBody: "Contracts are signed by both of the parties."}},
+		{Fields: map[Field]string{FieldBody: "Vertragen"}, Metadata: map[string]any{MetaLanguage: "de"}},
+	}
+	for i := range 10 {
+		docs = append(docs, Document{Fields: map[Field]string{FieldBody: fmt.Sprintf("Filler document %d about the calendars.", i)}})
+	}
+
+	corpus := NewCorpus(WithLanguageDetection(nil))
+	corpus.AddDocuments(docs)
+
+	// the French query is stemmed like the French document
+	if results := corpus.Search("le contrat de travail", 10); len(results) != 1 || results[0].Document.ID != 0 {
+		t.Errorf("Search(le contrat de travail) = %+v, want document 0", results)
+	}
+	// metadata overrides detection: a lone word is analyzed as German
+	if results := corpus.Search("die Vertrag", 10); len(results) != 2 {
+		t.Errorf("Search(die Vertrag) returned %d results, want 2", len(results))
+	}
+	// highlighting follows the query's analyzer
+	if got := corpus.Highlight("le contrat", "Les contrats de travail", HighlightOptions{}); got != "Les <mark>contrats</mark> de travail" {
+		t.Errorf("Highlight = %q", got)
+	}
+
+	// without detection, stems do not match
+	plain := NewCorpus()
+	plain.AddDocuments(docs)
+	if results := plain.Search("le contrat de travail", 10); len(results) != 1 {
+		t.Errorf("without detection, Search returned %d results, want only the travail match", len(results))
+	}
+}
@@ -0,0 +1,52 @@
+package bm25md
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// LiveIndex serves searches from an Index that can be swapped atomically, so a
+// background rebuild (eg with a CorpusBuilder or Corpus.Snapshot) can replace the
+// live index without blocking searches. Searches in flight during a swap finish
+// on the index they started with
+type LiveIndex struct {
+	current atomic.Pointer[Index]
+}
+
+// NewLiveIndex creates a live index serving index (an empty index when nil)
+func NewLiveIndex(index *Index) *LiveIndex {
+	if index == nil {
+		index = NewCorpus().Freeze()
+	}
+	l := &LiveIndex{}
+	l.current.Store(index)
+	return l
+}
+
+// Load returns the index currently served
+func (l *LiveIndex) Load() *Index {
+	return l.current.Load()
+}
+
+// Swap replaces the served index, returning the previous one. A nil index is ignored
+func (l *LiveIndex) Swap(index *Index) *Index {
+	if index == nil {
+		return l.Load()
+	}
+	return l.current.Swap(index)
+}
+
+// Search searches the current index
+func (l *LiveIndex) Search(query string, limit int) []SearchResult {
+	return l.Load().Search(query, limit)
+}
+
+// SearchContext searches the current index, stopping early when ctx is done
+func (l *LiveIndex) SearchContext(ctx context.Context, query string, limit int) ([]SearchResult, error) {
+	return l.Load().SearchContext(ctx, query, limit)
+}
+
+// SearchWithOptions searches the current index with options
+func (l *LiveIndex) SearchWithOptions(query string, opts SearchOptions) []SearchResult {
+	return l.Load().SearchWithOptions(query, opts)
+}
@@ -0,0 +1,85 @@
+package bm25md
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestCorpus_Snapshot(t *testing.T) {
+	corpus := randomCorpus(50)
+	snapshot := corpus.Snapshot()
+
+	// later changes to the corpus do not reach the snapshot
+	corpus.AddDocument(Document{Fields: map[Field]string{FieldBody: "zyzzyva"}})
+	if results := snapshot.Search("zyzzyva", 10); len(results) != 0 {
+		t.Errorf("snapshot found a document added after it: %+v", results)
+	}
+	if got, want := len(snapshot.Documents()), 50; got != want {
+		t.Errorf("snapshot has %d documents, want %d", got, want)
+	}
+}
+
+func TestLiveIndex_Swap(t *testing.T) {
+	build := func(generation int) *Index {
+		builder := NewCorpusBuilder()
+		for i := 0; i < 10; i++ {
+			builder.Add(Document{Fields: map[Field]string{
+				FieldBody: fmt.Sprintf("generation%d shared document%d", generation, i),
+			}})
+		}
+		// filler keeps the shared terms selective
+		for i := 0; i < 30; i++ {
+			builder.Add(Document{Fields: map[Field]string{FieldBody: fmt.Sprintf("other text %d", i)}})
+		}
+		return builder.Build()
+	}
+
+	live := NewLiveIndex(build(0))
+	if results := live.Search("generation0", 20); len(results) != 10 {
+		t.Fatalf("Search(generation0) = %d results, want 10", len(results))
+	}
+
+	// searches keep returning complete results from one generation while indexes are swapped
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	for w := 0; w < 4; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				results := live.SearchWithOptions("shared", SearchOptions{Limit: 20})
+				if len(results) != 10 {
+					t.Errorf("search during swaps returned %d results, want 10", len(results))
+					return
+				}
+			}
+		}()
+	}
+	for generation := 1; generation <= 20; generation++ {
+		previous := live.Swap(build(generation))
+		if previous == nil || previous == live.Load() {
+			t.Fatalf("Swap() returned %v, want the previous index", previous)
+		}
+	}
+	close(stop)
+	wg.Wait()
+
+	if results := live.Search("generation20", 20); len(results) != 10 {
+		t.Errorf("Search(generation20) = %d results, want 10", len(results))
+	}
+	if results := live.Search("generation0", 20); len(results) != 0 {
+		t.Errorf("swapped-out generation still searched: %d results", len(results))
+	}
+	if live.Swap(nil) != live.Load() {
+		t.Error("Swap(nil) replaced the index")
+	}
+	if results := NewLiveIndex(nil).Search("anything", 10); len(results) != 0 {
+		t.Errorf("empty live index returned %d results", len(results))
+	}
+}
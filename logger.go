@@ -0,0 +1,21 @@
+package bm25md
+
+import "log/slog"
+
+// WithLogger sends the corpus's log messages (document updates at debug level,
+// search timing and parallel fan-out at debug level, configuration warnings) to
+// logger instead of slog.Default(). Give it before other options so their
+// warnings use it too
+func WithLogger(logger *slog.Logger) CorpusOption {
+	return func(c *Corpus) {
+		c.log = logger
+	}
+}
+
+// logger returns the corpus's logger
+func (c *Corpus) logger() *slog.Logger {
+	if c.log != nil {
+		return c.log
+	}
+	return slog.Default()
+}
@@ -0,0 +1,29 @@
+package bm25md
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestWithLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	corpus := randomCorpus(300, WithLogger(logger), WithPreset("no-such-preset"), WithParallelism(0, 2))
+	corpus.Search("habeas", 10)
+	corpus.Freeze().Search("court", 10)
+
+	for _, want := range []string{
+		"Unknown bm25md preset",
+		"Added document to BM25md corpus",
+		"Scoring BM25md candidates in parallel",
+		`msg="Searched BM25md corpus" query=habeas`,
+		`msg="Searched BM25md corpus" query=court`,
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("log is missing %q", want)
+		}
+	}
+}
@@ -0,0 +1,217 @@
+//go:build !bm25md_noparser
+
+package bm25md
+
+import (
+	"bytes"
+	"reflect"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/util"
+)
+
+// mdxParser builds a goldmark parser for MDX. MDX has no indented code blocks,
+// which matters because JSX children are commonly indented: indented lines are
+// parsed as paragraphs instead
+func mdxParser(extensions []goldmark.Extender) parser.Parser {
+	codeBlock := reflect.TypeOf(parser.NewCodeBlockParser())
+	paragraph := reflect.TypeOf(parser.NewParagraphParser())
+	var blocks []util.PrioritizedValue
+	for _, block := range parser.DefaultBlockParsers() {
+		switch reflect.TypeOf(block.Value) {
+		case codeBlock:
+		case paragraph:
+			blocks = append(blocks, util.Prioritized(indentedParagraphParser{parser.NewParagraphParser()}, block.Priority))
+		default:
+			blocks = append(blocks, block)
+		}
+	}
+
+	base := parser.NewParser(
+		parser.WithBlockParsers(blocks...),
+		parser.WithInlineParsers(parser.DefaultInlineParsers()...),
+		parser.WithParagraphTransformers(parser.DefaultParagraphTransformers()...),
+	)
+	return goldmark.New(goldmark.WithParser(base), goldmark.WithExtensions(extensions...)).Parser()
+}
+
+// indentedParagraphParser is a paragraph parser that also opens paragraphs on
+// lines indented four or more spaces
+type indentedParagraphParser struct {
+	parser.BlockParser
+}
+
+func (indentedParagraphParser) CanAcceptIndentedLine() bool {
+	return true
+}
+
+// stripMDX blanks out MDX syntax in source: JSX component tags (capitalized or
+// fragments, so lowercase HTML tags are still extracted), {expressions}, and
+// import/export lines. Text between component tags is kept, and bytes are replaced
+// with spaces (keeping newlines) so offsets into the source still hold. Code spans
+// and fenced code blocks are left alone
+func stripMDX(source []byte) {
+	var fence []byte // marker of the open fenced code block
+	lineStart := true
+
+	for i := 0; i < len(source); {
+		if lineStart {
+			end := bytes.IndexByte(source[i:], '\n')
+			if end < 0 {
+				end = len(source)
+			} else {
+				end += i
+			}
+			line := source[i:end]
+			trimmed := bytes.TrimLeft(line, " \t")
+
+			switch {
+			case fence != nil:
+				if bytes.HasPrefix(trimmed, fence) && len(bytes.TrimSpace(bytes.TrimLeft(trimmed, string(fence[:1])))) == 0 {
+					fence = nil
+				}
+				i = end + 1
+				continue
+			case fenceMarker(trimmed) != nil:
+				fence = fenceMarker(trimmed)
+				i = end + 1
+				continue
+			case len(trimmed) == len(line) && (bytes.HasPrefix(line, []byte("import ")) || bytes.HasPrefix(line, []byte("export "))):
+				// ESM statements run until a blank line
+				i = blankESM(source, i)
+				continue
+			}
+			lineStart = false
+		}
+
+		switch c := source[i]; {
+		case c == '\n':
+			lineStart = true
+			i++
+		case c == '`':
+			i = skipCodeSpan(source, i)
+		case c == '{':
+			end := jsxEnd(source, i+1, '}')
+			if end < 0 {
+				i++
+				continue
+			}
+			blank(source[i:end])
+			i = end
+		case c == '<' && isJSXTag(source[i+1:]):
+			end := jsxEnd(source, i+1, '>')
+			if end < 0 {
+				i++
+				continue
+			}
+			blank(source[i:end])
+			i = end
+		default:
+			i++
+		}
+	}
+}
+
+// fenceMarker returns the opening ``` or ~~~ run of a fenced code block line
+func fenceMarker(line []byte) []byte {
+	if len(line) < 3 || (line[0] != '`' && line[0] != '~') {
+		return nil
+	}
+	n := 0
+	for n < len(line) && line[n] == line[0] {
+		n++
+	}
+	if n < 3 {
+		return nil
+	}
+	return line[:n]
+}
+
+// isJSXTag reports whether the text after a < starts a JSX component tag or fragment
+func isJSXTag(rest []byte) bool {
+	rest = bytes.TrimPrefix(rest, []byte("/"))
+	return len(rest) > 0 && (rest[0] == '>' || ('A' <= rest[0] && rest[0] <= 'Z'))
+}
+
+// jsxEnd returns the offset just past the close byte that ends a tag or expression
+// starting at i, skipping quoted strings and nested braces (-1 if it never closes)
+func jsxEnd(source []byte, i int, close byte) int {
+	depth := 0
+	for ; i < len(source); i++ {
+		switch c := source[i]; c {
+		case '"', '\'', '`':
+			end := bytes.IndexByte(source[i+1:], c)
+			if end < 0 {
+				return -1
+			}
+			i += end + 1
+		case '{':
+			depth++
+		case '}':
+			if depth == 0 && close == '}' {
+				return i + 1
+			}
+			depth--
+		case '>':
+			if depth == 0 && close == '>' {
+				return i + 1
+			}
+		}
+	}
+	return -1
+}
+
+// skipCodeSpan returns the offset past the code span opening at i, or past its
+// backticks when they are never closed
+func skipCodeSpan(source []byte, i int) int {
+	n := 0
+	for i+n < len(source) && source[i+n] == '`' {
+		n++
+	}
+	marker := source[i : i+n]
+	for j := i + n; j < len(source); {
+		k := bytes.Index(source[j:], marker)
+		if k < 0 {
+			break
+		}
+		j += k
+		end := j + n
+		for end < len(source) && source[end] == '`' {
+			end++
+		}
+		if end-j == n {
+			return end
+		}
+		j = end
+	}
+	return i + n
+}
+
+// blankESM blanks an import/export statement starting at i, through the next
+// blank line, returning the offset after it
+func blankESM(source []byte, i int) int {
+	for i < len(source) {
+		end := bytes.IndexByte(source[i:], '\n')
+		if end < 0 {
+			end = len(source)
+		} else {
+			end += i
+		}
+		if len(bytes.TrimSpace(source[i:end])) == 0 {
+			return end + 1
+		}
+		blank(source[i:end])
+		i = end + 1
+	}
+	return i
+}
+
+// blank replaces bytes with spaces, keeping newlines
+func blank(b []byte) {
+	for i := range b {
+		if b[i] != '\n' {
+			b[i] = ' '
+		}
+	}
+}
@@ -0,0 +1,89 @@
+//go:build !bm25md_noparser
+
+package bm25md
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestStripMDX(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "component tags keep children",
+			input:    "<Tabs groupId=\"os\">\n  <TabItem value=\"linux\" label={`Linux`}>\n    Install it.\n  </TabItem>\n</Tabs>",
+			expected: "Install it.",
+		},
+		{
+			name:     "self-closing and fragments",
+			input:    "See <Badge text=\"new\" onClick={() => track(\"x > y\")} /> and <>inline</> text.",
+			expected: "See and inline text.",
+		},
+		{
+			name:     "expressions",
+			input:    "Version {props.version} ships {new Date().getFullYear()}.",
+			expected: "Version ships .",
+		},
+		{
+			name:     "imports and exports",
+			input:    "import Tabs from '@theme/Tabs'\nimport {\n  TabItem,\n} from '@theme/TabItem'\n\nexport const meta = {title: 'x'}\n\nBody text.",
+			expected: "Body text.",
+		},
+		{
+			name:     "code is kept",
+			input:    "Use `<Tabs>` and `{x}`.\n\n```jsx\n<Tabs>{items}</Tabs>\n```",
+			expected: "Use `<Tabs>` and `{x}`. ```jsx <Tabs>{items}</Tabs> ```",
+		},
+		{
+			name:     "html tags are kept",
+			input:    "<details>\n<summary>More</summary>\n</details>",
+			expected: "<details> <summary>More</summary> </details>",
+		},
+		{
+			name:     "unclosed tag is kept",
+			input:    "a <Broken prop=\"x",
+			expected: "a <Broken prop=\"x",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := []byte(tt.input)
+			stripMDX(source)
+			if len(source) != len(tt.input) {
+				t.Fatalf("length changed from %d to %d", len(tt.input), len(source))
+			}
+			if strings.Count(string(source), "\n") != strings.Count(tt.input, "\n") {
+				t.Errorf("newlines were not kept: %q", source)
+			}
+			if got := normalizeWhitespace(string(source)); got != tt.expected {
+				t.Errorf("stripMDX() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestMarkdownFieldParser_MDX(t *testing.T) {
+	input := "---\ntitle: Setup\n---\nimport Tabs from '@theme/Tabs'\n\n# Install <Badge text=\"beta\" />\n\n" +
+		"<Tabs>\n  <TabItem value=\"linux\">\n    Run **make install** on {os}.\n  </TabItem>\n</Tabs>\n\n| Flag | Use |\n|---|---|\n| -v | verbose |"
+
+	fields := NewMarkdownFieldParser(WithParserMode(ModeMDX)).ParseDocument(input)
+
+	expected := map[Field]string{
+		FieldTitle: "Setup",
+		FieldH1:    "Install",
+		FieldBold:  "make install",
+		FieldBody:  "Run on .",
+		FieldCode:  "", // indented JSX children are not code blocks
+		FieldTable: "Flag Use -v verbose",
+	}
+	for field, want := range expected {
+		if got := normalizeWhitespace(fields[field]); got != want {
+			t.Errorf("%s = %q, want %q", field, got, want)
+		}
+	}
+}
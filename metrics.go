@@ -0,0 +1,109 @@
+package bm25md
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// DefaultLatencyBuckets are the upper bounds of the search latency histogram
+var DefaultLatencyBuckets = []time.Duration{
+	100 * time.Microsecond, 500 * time.Microsecond,
+	time.Millisecond, 5 * time.Millisecond, 10 * time.Millisecond, 50 * time.Millisecond,
+	100 * time.Millisecond, 500 * time.Millisecond, time.Second,
+}
+
+// SearchMetrics accumulates statistics of every search it records, for
+// monitoring a search service. Register its Record method as the search hook
+// (combined with other hooks by MultiHook); it is safe for concurrent use and
+// implements expvar.Var, so expvar.Publish("bm25md", metrics) exports it
+type SearchMetrics struct {
+	mu       sync.Mutex
+	snapshot MetricsSnapshot
+}
+
+// MetricsSnapshot is a point-in-time copy of SearchMetrics. Counters only grow,
+// in the style of Prometheus counters. Filter cache hits are not counted: the
+// package keeps no filter cache, as FilterSet hands its DocSet to the caller to
+// keep, so whoever caches sets is the one to count their reuse
+type MetricsSnapshot struct {
+	Searches    int64           `json:"searches"`
+	ZeroResults int64           `json:"zero_results"`    // searches matching nothing
+	Hits        int64           `json:"hits"`            // matching documents, summed over searches
+	Candidates  int64           `json:"candidates"`      // documents matching query terms before filters
+	Scored      int64           `json:"scored"`          // documents scored
+	Latency     time.Duration   `json:"latency_ns"`      // total time spent searching
+	MaxLatency  time.Duration   `json:"max_latency_ns"`  // slowest search
+	Buckets     []LatencyBucket `json:"latency_buckets"` // cumulative latency histogram
+}
+
+// LatencyBucket counts the searches that took at most Le, like a Prometheus
+// histogram bucket
+type LatencyBucket struct {
+	Le    time.Duration `json:"le_ns"`
+	Count int64         `json:"count"`
+}
+
+// NewSearchMetrics creates metrics with a latency histogram over buckets
+// (DefaultLatencyBuckets when none are given, in ascending order)
+func NewSearchMetrics(buckets ...time.Duration) *SearchMetrics {
+	if len(buckets) == 0 {
+		buckets = DefaultLatencyBuckets
+	}
+	m := &SearchMetrics{}
+	for _, le := range buckets {
+		m.snapshot.Buckets = append(m.snapshot.Buckets, LatencyBucket{Le: le})
+	}
+	return m
+}
+
+// Record adds a search to the metrics; it has the SearchHook signature
+func (m *SearchMetrics) Record(_ string, _ []SearchResult, stats SearchStats) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s := &m.snapshot
+	s.Searches++
+	if stats.ZeroResults() {
+		s.ZeroResults++
+	}
+	s.Hits += int64(stats.TotalHits)
+	s.Candidates += int64(stats.Candidates)
+	s.Scored += int64(stats.Scored)
+	s.Latency += stats.Duration
+	s.MaxLatency = max(s.MaxLatency, stats.Duration)
+	for i := range s.Buckets {
+		if stats.Duration <= s.Buckets[i].Le {
+			s.Buckets[i].Count++
+		}
+	}
+}
+
+// Snapshot returns the metrics recorded so far
+func (m *SearchMetrics) Snapshot() MetricsSnapshot {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	snapshot := m.snapshot
+	snapshot.Buckets = append([]LatencyBucket(nil), m.snapshot.Buckets...)
+	return snapshot
+}
+
+// String returns the snapshot as JSON, implementing expvar.Var
+func (m *SearchMetrics) String() string {
+	data, err := json.Marshal(m.Snapshot())
+	if err != nil {
+		return "{}"
+	}
+	return string(data)
+}
+
+// MultiHook combines search hooks into one that calls each in order, eg to
+// record metrics and log queries
+func MultiHook(hooks ...SearchHook) SearchHook {
+	return func(query string, results []SearchResult, stats SearchStats) {
+		for _, hook := range hooks {
+			if hook != nil {
+				hook(query, results, stats)
+			}
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package bm25md
+
+import (
+	"encoding/json"
+	"expvar"
+	"testing"
+	"time"
+)
+
+func TestSearchMetrics(t *testing.T) {
+	metrics := NewSearchMetrics(time.Millisecond, time.Second)
+	metrics.Record("a", nil, SearchStats{TotalHits: 3, Candidates: 5, Scored: 4, Duration: 500 * time.Microsecond})
+	metrics.Record("b", nil, SearchStats{Duration: 2 * time.Millisecond})
+	metrics.Record("c", nil, SearchStats{TotalHits: 1, Candidates: 1, Scored: 1, Duration: 2 * time.Second})
+
+	got := metrics.Snapshot()
+	want := MetricsSnapshot{
+		Searches:    3,
+		ZeroResults: 1,
+		Hits:        4,
+		Candidates:  6,
+		Scored:      5,
+		Latency:     2*time.Second + 2500*time.Microsecond,
+		MaxLatency:  2 * time.Second,
+		Buckets:     []LatencyBucket{{Le: time.Millisecond, Count: 1}, {Le: time.Second, Count: 2}},
+	}
+	if got.Searches != want.Searches || got.ZeroResults != want.ZeroResults || got.Hits != want.Hits ||
+		got.Candidates != want.Candidates || got.Scored != want.Scored || got.Latency != want.Latency || got.MaxLatency != want.MaxLatency {
+		t.Errorf("Snapshot() = %+v, want %+v", got, want)
+	}
+	for i, bucket := range want.Buckets {
+		if got.Buckets[i] != bucket {
+			t.Errorf("bucket %d = %+v, want %+v", i, got.Buckets[i], bucket)
+		}
+	}
+
+	// the expvar form is the snapshot as JSON
+	var _ expvar.Var = metrics
+	var decoded MetricsSnapshot
+	if err := json.Unmarshal([]byte(metrics.String()), &decoded); err != nil || decoded.Searches != 3 {
+		t.Errorf("String() = %s (%v), want the snapshot as JSON", metrics.String(), err)
+	}
+}
+
+func TestSearchMetrics_Hook(t *testing.T) {
+	metrics := NewSearchMetrics()
+	var queries []string
+	corpus := randomCorpus(200, WithSearchHook(MultiHook(metrics.Record, func(query string, _ []SearchResult, _ SearchStats) {
+		queries = append(queries, query)
+	})))
+
+	corpus.SearchWithOptions("jury", SearchOptions{Limit: 5, Filter: MetadataEquals("even", true)})
+	corpus.Search("missing", 5)
+
+	got := metrics.Snapshot()
+	if got.Searches != 2 || got.ZeroResults != 1 || len(queries) != 2 {
+		t.Fatalf("Snapshot() = %+v after %v, want 2 searches, 1 without results", got, queries)
+	}
+	// the filter keeps only half the candidates for scoring
+	if got.Scored == 0 || got.Scored >= got.Candidates || got.Hits > got.Scored {
+		t.Errorf("Candidates, Scored, Hits = %d, %d, %d, want filtering to reduce them", got.Candidates, got.Scored, got.Hits)
+	}
+}
@@ -0,0 +1,70 @@
+package bm25md
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+)
+
+// moreLikeThisTerms is how many of a document's most distinctive terms form the query
+const moreLikeThisTerms = 25
+
+// MoreLikeThis finds documents related to the document with the given ID, using its
+// most distinctive terms (by field-weighted TF-IDF) as the query. The source
+// document itself is never returned; unknown or removed IDs return no results.
+func (c *Corpus) MoreLikeThis(docID int, limit int) []SearchResult {
+	start := time.Now()
+	if !c.isLive(docID) {
+		return []SearchResult{}
+	}
+
+	queryTerms := c.distinctiveTerms(docID, moreLikeThisTerms)
+	opts := SearchOptions{
+		Limit:  limit,
+		Filter: func(doc Document) bool { return doc.ID != docID },
+	}
+	results, _ := c.search(context.Background(), strings.Join(queryTerms, " "), queryTerms, c.prepareQuery(queryTerms), opts, start)
+	return results
+}
+
+// distinctiveTerms returns up to n of a document's terms with the highest
+// field-weighted TF-IDF, skipping terms no other document shares
+func (c *Corpus) distinctiveTerms(docID int, n int) []string {
+	weights := make(map[string]float64)
+	for field, scorer := range c.fieldScorers {
+		scorer.eachTerm(docID, func(term string, tf int) {
+			weights[term] += c.fieldWeights[field] * float64(tf)
+		})
+	}
+
+	type weightedTerm struct {
+		term   string
+		weight float64
+	}
+	terms := make([]weightedTerm, 0, len(weights))
+	for term, weight := range weights {
+		docFreq := len(c.postings[term])
+		if docFreq < 2 {
+			continue
+		}
+		if idf := c.idf(docFreq); idf > 0 {
+			terms = append(terms, weightedTerm{term, weight * idf})
+		}
+	}
+	sort.Slice(terms, func(i, j int) bool {
+		if terms[i].weight != terms[j].weight {
+			return terms[i].weight > terms[j].weight
+		}
+		return terms[i].term < terms[j].term
+	})
+
+	if len(terms) > n {
+		terms = terms[:n]
+	}
+	result := make([]string, len(terms))
+	for i, wt := range terms {
+		result[i] = wt.term
+	}
+	return result
+}
@@ -0,0 +1,59 @@
+package bm25md
+
+import "testing"
+
+func TestCorpus_MoreLikeThis(t *testing.T) {
+	corpus := NewCorpus()
+	bodies := []string{
+		"habeas corpus petition filed in federal court",   // 0
+		"federal habeas corpus petition denied on appeal", // 1
+		"petition for habeas corpus relief",               // 2
+		"court calendar for the spring term",              // 3
+		"parking rules near the courthouse",               // 4
+		"library opening hours",                           // 5
+		"cafeteria menu",                                  // 6
+		"staff directory",                                 // 7
+	}
+	for _, body := range bodies {
+		corpus.AddDocument(Document{Fields: map[Field]string{FieldBody: body}})
+	}
+
+	results := corpus.MoreLikeThis(0, 2)
+	if len(results) != 2 {
+		t.Fatalf("MoreLikeThis(0) returned %d results, want 2: %+v", len(results), results)
+	}
+	for _, result := range results {
+		if result.Index == 0 {
+			t.Error("MoreLikeThis() returned the source document")
+		}
+		if result.Index != 1 && result.Index != 2 {
+			t.Errorf("MoreLikeThis(0) returned unrelated document %d", result.Index)
+		}
+	}
+
+	// unknown and removed documents have no related documents
+	if results := corpus.MoreLikeThis(99, 5); len(results) != 0 {
+		t.Errorf("MoreLikeThis(99) = %+v, want none", results)
+	}
+	if err := corpus.RemoveDocument(2); err != nil {
+		t.Fatal(err)
+	}
+	if results := corpus.MoreLikeThis(2, 5); len(results) != 0 {
+		t.Errorf("MoreLikeThis(removed) = %+v, want none", results)
+	}
+}
+
+func TestCorpus_DistinctiveTerms(t *testing.T) {
+	corpus := NewCorpus()
+	corpus.AddDocument(Document{Fields: map[Field]string{FieldH1: "habeas", FieldBody: "petition unique"}})
+	corpus.AddDocument(Document{Fields: map[Field]string{FieldBody: "habeas petition"}})
+	for i := 0; i < 6; i++ {
+		corpus.AddDocument(Document{Fields: map[Field]string{FieldBody: "filler text"}})
+	}
+
+	// header terms outweigh body terms, and terms only this document has are skipped
+	got := corpus.distinctiveTerms(0, 10)
+	if len(got) != 2 || got[0] != "habeas" || got[1] != "petition" {
+		t.Errorf("distinctiveTerms() = %v, want [habeas petition]", got)
+	}
+}
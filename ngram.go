@@ -0,0 +1,93 @@
+package bm25md
+
+import (
+	"context"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// edgeNGramSize is the range of prefix lengths indexed by WithEdgeNGrams
+type edgeNGramSize struct {
+	min, max int
+}
+
+// EdgeNGramFilter follows each token with its leading n-grams of minGram to
+// maxGram characters ("writ" -> "writ", "wr", "wri" for 2 to 3). Used in a query
+// analyzer it would also split queries, so for search-as-you-type prefer
+// WithEdgeNGrams, which only applies at index time
+func EdgeNGramFilter(minGram, maxGram int) TokenFilter {
+	size := edgeNGramSize{min: max(minGram, 1), max: maxGram}
+	return TokenFilterFunc(func(tokens []string) []string {
+		out := make([]string, 0, len(tokens))
+		for _, token := range tokens {
+			out = append(out, token)
+			out = size.appendGrams(out, token)
+		}
+		return out
+	})
+}
+
+// WithEdgeNGrams indexes the leading n-grams of minGram to maxGram characters of
+// every token, so a partially typed word ("consti") matches documents containing
+// words it begins ("constitution") without expanding a prefix query. Queries are
+// not split into n-grams; see SearchAsYouType
+func WithEdgeNGrams(minGram, maxGram int) CorpusOption {
+	return func(c *Corpus) {
+		c.edgeNGrams = edgeNGramSize{min: max(minGram, 1), max: maxGram}
+	}
+}
+
+// enabled reports whether edge n-grams are indexed
+func (s edgeNGramSize) enabled() bool {
+	return s.max > 0 && s.max >= s.min
+}
+
+// appendGrams appends the proper prefixes of token in the size range to grams
+func (s edgeNGramSize) appendGrams(grams []string, token string) []string {
+	n := 0
+	for i := range token {
+		if n >= s.min && n <= s.max {
+			grams = append(grams, token[:i])
+		}
+		n++
+	}
+	return grams
+}
+
+// withEdgeNGrams appends the edge n-grams of tokens after the tokens themselves,
+// so the positions of the original tokens (and so phrase matching) are unchanged
+func (s edgeNGramSize) withEdgeNGrams(tokens []string) []string {
+	if !s.enabled() {
+		return tokens
+	}
+	out := append(make([]string, 0, 2*len(tokens)), tokens...)
+	for _, token := range tokens {
+		out = s.appendGrams(out, token)
+	}
+	return out
+}
+
+// indexed reports whether prefix is one of the indexed n-gram lengths
+func (s edgeNGramSize) indexed(prefix string) bool {
+	n := utf8.RuneCountInString(prefix)
+	return s.enabled() && n >= s.min && n <= s.max
+}
+
+// SearchAsYouType searches a query as it is being typed into a search box: every
+// word but the last matches as usual, and the last word (unless followed by a
+// space) matches the words it begins, like a term* query. With WithEdgeNGrams
+// the partial word is looked up directly among the indexed n-grams instead of
+// being expanded to every matching term
+func (c *Corpus) SearchAsYouType(query string, limit int) []SearchResult {
+	start := time.Now()
+	clauses := c.parseQuery(query)
+	typing := query != "" && !unicode.IsSpace(rune(query[len(query)-1])) && !strings.HasSuffix(query, `"`)
+	if n := len(clauses); typing && n > 0 && clauses[n-1].isTerm() && clauses[n-1].occur != occurMustNot {
+		clauses[n-1].prefix = true
+	}
+	queryTerms, terms := c.prepareClauses(clauses)
+	results, _ := c.search(context.Background(), query, queryTerms, terms, SearchOptions{Limit: limit}, start)
+	return results
+}
@@ -0,0 +1,150 @@
+package bm25md
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestEdgeNGramFilter(t *testing.T) {
+	tests := []struct {
+		name     string
+		min, max int
+		input    []string
+		expected []string
+	}{
+		{
+			name:     "prefixes in range",
+			min:      2,
+			max:      3,
+			input:    []string{"writ"},
+			expected: []string{"writ", "wr", "wri"},
+		},
+		{
+			name:     "short tokens are kept whole",
+			min:      3,
+			max:      5,
+			input:    []string{"of", "law"},
+			expected: []string{"of", "law"},
+		},
+		{
+			name:     "multibyte characters",
+			min:      1,
+			max:      2,
+			input:    []string{"été"},
+			expected: []string{"été", "é", "ét"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := EdgeNGramFilter(tt.min, tt.max).Filter(tt.input); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Filter(%q) = %q, want %q", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
+
+// asYouTypeDocs returns documents about constitutions among unrelated filler
+func asYouTypeDocs() []Document {
+	docs := []Document{
+		{Fields: map[Field]string{FieldH1: "Constitution", FieldBody: "The constitution of the republic."}},
+		{Fields: map[Field]string{FieldBody: "Constitutional challenges to the statute."}},
+		{Fields: map[Field]string{FieldBody: "Habeas corpus petitions in federal court."}},
+	}
+	for i := range 10 {
+		docs = append(docs, Document{Fields: map[Field]string{FieldBody: fmt.Sprintf("Filler document %d about calendars.", i)}})
+	}
+	return docs
+}
+
+func TestSearchAsYouType(t *testing.T) {
+	for _, ngrams := range []bool{false, true} {
+		t.Run(fmt.Sprintf("ngrams=%v", ngrams), func(t *testing.T) {
+			var opts []CorpusOption
+			if ngrams {
+				opts = append(opts, WithEdgeNGrams(3, 10))
+			}
+			corpus := NewCorpus(opts...)
+			corpus.AddDocuments(asYouTypeDocs())
+
+			results := corpus.SearchAsYouType("consti", 10)
+			if len(results) != 2 || results[0].Document.ID != 0 {
+				t.Errorf("SearchAsYouType(consti) = %+v, want documents 0 and 1 ranked 0 first", results)
+			}
+
+			// a partial word longer than the longest n-gram still matches
+			if results := corpus.SearchAsYouType("constitutio", 10); len(results) != 2 {
+				t.Errorf("SearchAsYouType(constitutio) returned %d results, want 2", len(results))
+			}
+
+			// earlier words are complete
+			if results := corpus.SearchAsYouType("habeas cor", 10); len(results) != 1 || results[0].Document.ID != 2 {
+				t.Errorf("SearchAsYouType(habeas cor) = %+v, want document 2", results)
+			}
+
+			// a trailing space completes the last word
+			if results := corpus.SearchAsYouType("consti ", 10); ngrams != (len(results) == 2) {
+				t.Errorf("SearchAsYouType(consti ) returned %d results", len(results))
+			}
+		})
+	}
+}
+
+func TestWithEdgeNGrams_PhrasesAndLengths(t *testing.T) {
+	corpus := NewCorpus(WithEdgeNGrams(3, 5))
+	corpus.AddDocuments(asYouTypeDocs())
+
+	// n-grams follow the original tokens, so phrases still match
+	if results := corpus.Search(`"habeas corpus"`, 10); len(results) != 1 || results[0].Document.ID != 2 {
+		t.Errorf(`Search("habeas corpus") = %+v, want document 2`, results)
+	}
+
+	// a plain search for a partial word matches the indexed n-gram
+	if results := corpus.Search("const", 10); len(results) != 2 {
+		t.Errorf("Search(const) returned %d results, want 2", len(results))
+	}
+
+	// prefixes longer than the longest n-gram are not indexed
+	if results := corpus.Search("consti", 10); len(results) != 0 {
+		t.Errorf("Search(consti) returned %d results, want 0", len(results))
+	}
+}
+
+func TestWithEdgeNGrams_FullWordScores(t *testing.T) {
+	plain := NewCorpus()
+	plain.AddDocuments(asYouTypeDocs())
+	ngrams := NewCorpus(WithEdgeNGrams(2, 10))
+	ngrams.AddDocuments(asYouTypeDocs())
+
+	// n-grams are extra postings, so field lengths count words only
+	if got, want := ngrams.fieldScorers[FieldBody].docLengths, plain.fieldScorers[FieldBody].docLengths; !reflect.DeepEqual(got, want) {
+		t.Errorf("body lengths with n-grams = %v, want %v", got, want)
+	}
+
+	for _, query := range []string{"constitution", "habeas corpus", "republic statute", "filler calendars"} {
+		want := plain.Search(query, 20)
+		sameResults(t, ngrams.Search(query, 20), want)
+		sameResults(t, ngrams.Freeze().Search(query, 20), want)
+	}
+}
+
+func TestWithEdgeNGrams_Engines(t *testing.T) {
+	corpus := NewCorpus(WithEdgeNGrams(3, 10))
+	corpus.AddDocuments(asYouTypeDocs())
+	stored := NewStoredCorpus(NewMemoryStorage(), WithEdgeNGrams(3, 10))
+	if err := stored.AddDocuments(context.Background(), asYouTypeDocs()); err != nil {
+		t.Fatalf("AddDocuments() error = %v", err)
+	}
+
+	for _, query := range []string{"consti*", "constitutio*", "habeas cor*"} {
+		want := corpus.Search(query, 10)
+		sameResults(t, corpus.Freeze().Search(query, 10), want)
+		got, err := stored.SearchWithOptions(context.Background(), query, SearchOptions{Limit: 10})
+		if err != nil {
+			t.Fatalf("SearchWithOptions() error = %v", err)
+		}
+		sameResults(t, got, want)
+	}
+}
@@ -0,0 +1,288 @@
+//go:build !bm25md_noparser
+
+package bm25md
+
+import (
+	"fmt"
+	"io/fs"
+	"math"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/text"
+)
+
+// DefaultBacklinkBoost scales log(1+backlinks) into a note's score multiplier
+const DefaultBacklinkBoost = 0.2
+
+// Metadata keys attached to documents loaded from an Obsidian vault
+const (
+	MetaNotePath  = "path"      // note path relative to the vault root
+	MetaAliases   = "aliases"   // alternative note names from front matter
+	MetaNoteTags  = "tags"      // front matter and inline #tags
+	MetaLinks     = "links"     // wikilink targets referenced by the note
+	MetaBacklinks = "backlinks" // number of distinct notes linking to this note
+)
+
+// VaultOptions configures Obsidian vault ingestion
+type VaultOptions struct {
+	Parser               *MarkdownFieldParser // defaults to NewMarkdownFieldParser()
+	BacklinkBoost        float64              // defaults to DefaultBacklinkBoost
+	DisableBacklinkBoost bool                 // leave document boosts untouched
+}
+
+var (
+	// wikiLinkRegex matches [[links]] and ![[embeds]]
+	wikiLinkRegex = regexp.MustCompile(`(!?)\[\[([^\[\]]+)\]\]`)
+	// hashtagRegex matches inline #tags, including nested #tags/like/this
+	hashtagRegex = regexp.MustCompile(`(^|[\s(])#([\p{L}\p{N}_/-]+)`)
+)
+
+// vaultNote holds the intermediate state of a note while the vault is loaded
+type vaultNote struct {
+	path  string
+	doc   Document
+	links []string
+}
+
+// LoadVault reads every note in an Obsidian vault and returns one Document per note.
+// Note names and front matter aliases are indexed as titles, front matter and inline
+// tags as tags, wikilinks are reduced to their display text, and notes that many
+// other notes link to receive a score boost.
+func LoadVault(fsys fs.FS, opts VaultOptions) ([]Document, error) {
+	parser := opts.Parser
+	if parser == nil {
+		parser = NewMarkdownFieldParser()
+	}
+
+	var paths []string
+	err := fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		// skip .obsidian, .trash, and other hidden directories
+		if d.IsDir() && p != "." && strings.HasPrefix(d.Name(), ".") {
+			return fs.SkipDir
+		}
+		if !d.IsDir() && strings.EqualFold(path.Ext(p), ".md") {
+			paths = append(paths, p)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("bm25md: walking vault: %w", err)
+	}
+	sort.Strings(paths)
+
+	notes := make([]vaultNote, 0, len(paths))
+	for _, p := range paths {
+		content, err := fs.ReadFile(fsys, p)
+		if err != nil {
+			return nil, fmt.Errorf("bm25md: reading %s: %w", p, err)
+		}
+		note, err := loadVaultNote(parser, p, string(content))
+		if err != nil {
+			return nil, err
+		}
+		notes = append(notes, note)
+	}
+
+	backlinks := countBacklinks(notes)
+
+	boost := opts.BacklinkBoost
+	if boost == 0 {
+		boost = DefaultBacklinkBoost
+	}
+
+	documents := make([]Document, len(notes))
+	for i, note := range notes {
+		doc := note.doc
+		doc.ID = i
+		doc.Metadata[MetaBacklinks] = backlinks[i]
+		if !opts.DisableBacklinkBoost && backlinks[i] > 0 {
+			doc.Boost = doc.boost() * (1 + boost*math.Log1p(float64(backlinks[i])))
+		}
+		documents[i] = doc
+	}
+
+	return documents, nil
+}
+
+// loadVaultNote parses a single note and collects its outgoing links
+func loadVaultNote(parser *MarkdownFieldParser, notePath, content string) (vaultNote, error) {
+	fm, body, err := ParseFrontMatter(content)
+	if err != nil {
+		return vaultNote{}, fmt.Errorf("bm25md: %s: %w", notePath, err)
+	}
+
+	// code is left as written, so #include or [[x]] in a code block is not a tag or link
+	var links []string
+	tags := append([]string{}, fm.Strings("tags")...)
+	body = outsideCode(body, func(text string) string {
+		// replace wikilinks with their display text, dropping embeds entirely
+		text = wikiLinkRegex.ReplaceAllStringFunc(text, func(match string) string {
+			parts := wikiLinkRegex.FindStringSubmatch(match)
+			target, display := splitWikiLink(parts[2])
+			if target != "" {
+				links = append(links, target)
+			}
+			if parts[1] == "!" {
+				return ""
+			}
+			return display
+		})
+
+		// lift inline tags out of the text
+		return hashtagRegex.ReplaceAllStringFunc(text, func(match string) string {
+			parts := hashtagRegex.FindStringSubmatch(match)
+			if !isVaultTag(parts[2]) {
+				return match
+			}
+			tags = append(tags, parts[2])
+			return parts[1]
+		})
+	})
+
+	fields := parser.ParseDocument(body)
+
+	name := strings.TrimSuffix(path.Base(notePath), path.Ext(notePath))
+	aliases := fm.Strings("aliases")
+	if len(aliases) == 0 {
+		aliases = fm.Strings("alias")
+	}
+	titles := append([]string{name}, aliases...)
+	if title := fm.String("title"); title != "" {
+		titles = append(titles, title)
+	}
+	fields[FieldTitle] = strings.Join(titles, " ")
+
+	// nested tags (project/alpha) index as their individual segments
+	tagWords := make([]string, len(tags))
+	for i, tag := range tags {
+		tagWords[i] = tagText(tag)
+	}
+	fields[FieldTags] = strings.Join(tagWords, " ")
+
+	metadata := make(map[string]any, len(fm)+4)
+	for key, value := range fm {
+		metadata[key] = value
+	}
+	metadata[MetaNotePath] = notePath
+	metadata[MetaAliases] = aliases
+	metadata[MetaNoteTags] = tags
+	metadata[MetaLinks] = links
+
+	return vaultNote{
+		path: notePath,
+		doc: Document{
+			ExternalID: notePath,
+			Fields:     fields,
+			Original:   content,
+			Metadata:   metadata,
+		},
+		links: links,
+	}, nil
+}
+
+// outsideCode rewrites markdown with fn applied to each stretch of text outside
+// code spans and code blocks, found by parsing it, leaving the code unchanged
+func outsideCode(markdown string, fn func(string) string) string {
+	source := []byte(markdown)
+	var code [][2]int // byte ranges of code, in order
+	doc := goldmark.DefaultParser().Parse(text.NewReader(source))
+	_ = ast.Walk(doc, func(node ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch n := node.(type) {
+		case *ast.FencedCodeBlock, *ast.CodeBlock:
+			if lines := n.Lines(); lines.Len() > 0 {
+				code = append(code, [2]int{lines.At(0).Start, lines.At(lines.Len() - 1).Stop})
+			}
+			return ast.WalkSkipChildren, nil
+		case *ast.CodeSpan:
+			first, firstOK := n.FirstChild().(*ast.Text)
+			last, lastOK := n.LastChild().(*ast.Text)
+			if firstOK && lastOK {
+				code = append(code, [2]int{first.Segment.Start, last.Segment.Stop})
+			}
+			return ast.WalkSkipChildren, nil
+		}
+		return ast.WalkContinue, nil
+	})
+
+	var b strings.Builder
+	b.Grow(len(markdown))
+	pos := 0
+	for _, r := range code {
+		b.WriteString(fn(markdown[pos:r[0]]))
+		b.WriteString(markdown[r[0]:r[1]])
+		pos = r[1]
+	}
+	b.WriteString(fn(markdown[pos:]))
+	return b.String()
+}
+
+// splitWikiLink splits "Target#Heading|Alias" into its target note and display text
+func splitWikiLink(inner string) (target, display string) {
+	link, alias, hasAlias := strings.Cut(inner, "|")
+	target, heading, _ := strings.Cut(link, "#")
+	target = strings.TrimSpace(target)
+	heading = strings.TrimPrefix(strings.TrimSpace(heading), "^")
+
+	switch {
+	case hasAlias:
+		display = strings.TrimSpace(alias)
+	case heading != "":
+		display = strings.TrimSpace(target + " " + heading)
+	default:
+		display = target
+	}
+	return target, display
+}
+
+// isVaultTag reports whether text is a valid Obsidian tag (tags cannot be purely numeric)
+func isVaultTag(text string) bool {
+	for _, r := range text {
+		if !unicode.IsDigit(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// countBacklinks returns, per note, the number of distinct other notes linking to it
+func countBacklinks(notes []vaultNote) []int {
+	// notes resolve by full path (without extension) first, then by base name
+	byPath := make(map[string]int, len(notes))
+	byName := make(map[string]int, len(notes))
+	for i, note := range notes {
+		key := strings.ToLower(strings.TrimSuffix(note.path, path.Ext(note.path)))
+		byPath[key] = i
+		if _, exists := byName[path.Base(key)]; !exists {
+			byName[path.Base(key)] = i
+		}
+	}
+
+	counts := make([]int, len(notes))
+	for source, note := range notes {
+		seen := make(map[int]bool)
+		for _, link := range note.links {
+			key := strings.ToLower(strings.TrimSuffix(link, ".md"))
+			target, ok := byPath[key]
+			if !ok {
+				target, ok = byName[path.Base(key)]
+			}
+			if ok && target != source && !seen[target] {
+				seen[target] = true
+				counts[target]++
+			}
+		}
+	}
+	return counts
+}
@@ -0,0 +1,144 @@
+//go:build !bm25md_noparser
+
+package bm25md
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLoadVault(t *testing.T) {
+	fsys := fstest.MapFS{
+		"Habeas Corpus.md": {Data: []byte("---\naliases: [Great Writ]\ntags: [law]\n---\n" +
+			"The writ guards against unlawful detention. #constitution/article-one")},
+		"cases/Boumediene.md": {Data: []byte("Extended [[Habeas Corpus|the writ]] to Guantanamo. ![[diagram.png]] #2008")},
+		"cases/Rasul.md":      {Data: []byte("See [[Habeas Corpus#History]] and [[Boumediene]].")},
+		"Daily.md":            {Data: []byte("Linked to [[habeas corpus]] twice: [[Habeas Corpus]].")},
+		".obsidian/app.md":    {Data: []byte("Config")},
+	}
+
+	docs, err := LoadVault(fsys, VaultOptions{})
+	if err != nil {
+		t.Fatalf("LoadVault() error = %v", err)
+	}
+	if len(docs) != 4 {
+		t.Fatalf("LoadVault() returned %d documents, want 4", len(docs))
+	}
+
+	byPath := make(map[string]Document)
+	for _, doc := range docs {
+		byPath[doc.Metadata[MetaNotePath].(string)] = doc
+	}
+
+	habeas := byPath["Habeas Corpus.md"]
+	if habeas.Fields[FieldTitle] != "Habeas Corpus Great Writ" {
+		t.Errorf("title = %q, want %q", habeas.Fields[FieldTitle], "Habeas Corpus Great Writ")
+	}
+	if habeas.Fields[FieldTags] != "law constitution article-one" {
+		t.Errorf("tags = %q, want %q", habeas.Fields[FieldTags], "law constitution article-one")
+	}
+	if habeas.Metadata[MetaBacklinks] != 3 {
+		t.Errorf("backlinks = %v, want 3", habeas.Metadata[MetaBacklinks])
+	}
+	if habeas.Boost <= 1 {
+		t.Errorf("boost = %f, want > 1 for linked note", habeas.Boost)
+	}
+
+	boumediene := byPath["cases/Boumediene.md"]
+	want := "Extended the writ to Guantanamo. #2008"
+	if got := normalizeWhitespace(boumediene.Fields[FieldBody]); got != want {
+		t.Errorf("body = %q, want %q", got, want)
+	}
+	if boumediene.Metadata[MetaBacklinks] != 1 {
+		t.Errorf("backlinks = %v, want 1", boumediene.Metadata[MetaBacklinks])
+	}
+
+	rasul := byPath["cases/Rasul.md"]
+	if got := normalizeWhitespace(rasul.Fields[FieldBody]); got != "See Habeas Corpus History and Boumediene." {
+		t.Errorf("body = %q", got)
+	}
+	if rasul.Boost != 0 {
+		t.Errorf("boost = %f, want 0 for unlinked note", rasul.Boost)
+	}
+
+	unboosted, err := LoadVault(fsys, VaultOptions{DisableBacklinkBoost: true})
+	if err != nil {
+		t.Fatalf("LoadVault() error = %v", err)
+	}
+	for _, doc := range unboosted {
+		if doc.Boost != 0 {
+			t.Errorf("boost = %f, want 0 when disabled", doc.Boost)
+		}
+	}
+}
+
+func TestLoadVault_BacklinkRanking(t *testing.T) {
+	fsys := fstest.MapFS{
+		"Popular.md":  {Data: []byte("Notes about detention review.")},
+		"Obscure.md":  {Data: []byte("Notes about detention review.")},
+		"Index.md":    {Data: []byte("[[Popular]]")},
+		"Reading.md":  {Data: []byte("[[Popular]]")},
+		"Filler1.md":  {Data: []byte("Unrelated gardening notes.")},
+		"Filler2.md":  {Data: []byte("Unrelated cooking notes.")},
+		"Filler3.md":  {Data: []byte("Unrelated travel notes.")},
+		"Filler4.md":  {Data: []byte("Unrelated music notes.")},
+		"Filler5.md":  {Data: []byte("Unrelated poetry notes.")},
+		"Filler6.md":  {Data: []byte("Unrelated history notes.")},
+		"Filler7.md":  {Data: []byte("Unrelated physics notes.")},
+		"Filler8.md":  {Data: []byte("Unrelated finance notes.")},
+		"Filler9.md":  {Data: []byte("Unrelated health notes.")},
+		"Filler10.md": {Data: []byte("Unrelated sports notes.")},
+	}
+
+	docs, err := LoadVault(fsys, VaultOptions{})
+	if err != nil {
+		t.Fatalf("LoadVault() error = %v", err)
+	}
+
+	corpus := NewCorpus(WithPreset("obsidian"))
+	for _, doc := range docs {
+		corpus.AddDocument(doc)
+	}
+
+	results := corpus.Search("detention review", 2)
+	if len(results) != 2 {
+		t.Fatalf("Search returned %d results, want 2", len(results))
+	}
+	if results[0].Document.Metadata[MetaNotePath] != "Popular.md" {
+		t.Errorf("top result = %v, want Popular.md", results[0].Document.Metadata[MetaNotePath])
+	}
+	if results[0].Score <= results[1].Score {
+		t.Errorf("boosted score %f should exceed unboosted score %f", results[0].Score, results[1].Score)
+	}
+}
+
+func TestLoadVault_Code(t *testing.T) {
+	fsys := fstest.MapFS{
+		"Build.md": {Data: []byte("Compile notes #cpp and [[Toolchain]].\n\n```c\n#include <stdio.h>\nint x[[2]];\n```\n\n" +
+			"Use `#pragma once` or `[[nodiscard]]` in headers.\n")},
+	}
+
+	docs, err := LoadVault(fsys, VaultOptions{})
+	if err != nil {
+		t.Fatalf("LoadVault() error = %v", err)
+	}
+	doc := docs[0]
+
+	if got := doc.Metadata[MetaNoteTags].([]string); !reflect.DeepEqual(got, []string{"cpp"}) {
+		t.Errorf("tags = %q, want [cpp]", got)
+	}
+	if got := doc.Metadata[MetaLinks].([]string); !reflect.DeepEqual(got, []string{"Toolchain"}) {
+		t.Errorf("links = %q, want [Toolchain]", got)
+	}
+	if doc.Fields[FieldTags] != "cpp" {
+		t.Errorf("tags field = %q, want %q", doc.Fields[FieldTags], "cpp")
+	}
+	if code := doc.Fields[FieldCode]; !strings.Contains(code, "#include <stdio.h>") || !strings.Contains(code, "int x[[2]];") {
+		t.Errorf("code = %q, want the code block unchanged", code)
+	}
+	if code := doc.Fields[FieldCode]; !strings.Contains(code, "#pragma once") || !strings.Contains(code, "[[nodiscard]]") {
+		t.Errorf("code = %q, want the code spans unchanged", code)
+	}
+}
@@ -0,0 +1,79 @@
+//go:build !bm25md_noparser
+
+// Package oteltrace reports bm25md searches and indexing as OpenTelemetry spans,
+// so they show up in the distributed traces of the services calling them.
+package oteltrace
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/chriscorrea/bm25md"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ScopeName is the instrumentation scope of spans from the global tracer provider
+const ScopeName = "github.com/chriscorrea/bm25md"
+
+// Tracer adapts an OpenTelemetry tracer to bm25md.Tracer
+type Tracer struct {
+	tracer trace.Tracer
+}
+
+var _ bm25md.Tracer = Tracer{}
+
+// New adapts tracer for use with bm25md.WithTracer; a nil tracer uses the global
+// tracer provider
+func New(tracer trace.Tracer) Tracer {
+	if tracer == nil {
+		tracer = otel.Tracer(ScopeName)
+	}
+	return Tracer{tracer: tracer}
+}
+
+// WithTracing traces a corpus with the global tracer provider
+func WithTracing() bm25md.CorpusOption {
+	return bm25md.WithTracer(New(nil))
+}
+
+// Start implements bm25md.Tracer
+func (t Tracer) Start(ctx context.Context, name string, start time.Time) (context.Context, bm25md.Span) {
+	ctx, span := t.tracer.Start(ctx, name, trace.WithTimestamp(start))
+	return ctx, Span{span: span}
+}
+
+// Span adapts an OpenTelemetry span to bm25md.Span
+type Span struct {
+	span trace.Span
+}
+
+// SetAttribute implements bm25md.Span
+func (s Span) SetAttribute(key string, value any) {
+	s.span.SetAttributes(attributeOf(key, value))
+}
+
+// End implements bm25md.Span
+func (s Span) End() {
+	s.span.End()
+}
+
+// attributeOf converts a bm25md span attribute to an OpenTelemetry attribute
+func attributeOf(key string, value any) attribute.KeyValue {
+	switch v := value.(type) {
+	case string:
+		return attribute.String(key, v)
+	case bool:
+		return attribute.Bool(key, v)
+	case int:
+		return attribute.Int(key, v)
+	case int64:
+		return attribute.Int64(key, v)
+	case float64:
+		return attribute.Float64(key, v)
+	default:
+		return attribute.String(key, fmt.Sprint(v))
+	}
+}
@@ -0,0 +1,90 @@
+//go:build !bm25md_noparser
+
+package oteltrace
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/chriscorrea/bm25md"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestTracer(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	tracer := provider.Tracer("test")
+
+	corpus := bm25md.NewCorpus(bm25md.WithTracer(New(tracer)))
+	docs := []bm25md.Document{{Fields: map[bm25md.Field]string{bm25md.FieldBody: "habeas corpus petition"}}}
+	for i := range 5 {
+		docs = append(docs, bm25md.Document{Fields: map[bm25md.Field]string{bm25md.FieldBody: fmt.Sprintf("filler %d about calendars", i)}})
+	}
+	corpus.AddDocuments(docs)
+
+	ctx, parent := tracer.Start(context.Background(), "request")
+	if _, err := corpus.SearchContext(ctx, "habeas corpus", 10); err != nil {
+		t.Fatal(err)
+	}
+	parent.End()
+
+	spans := make(map[string]sdktrace.ReadOnlySpan)
+	for _, span := range recorder.Ended() {
+		spans[span.Name()] = span
+	}
+	for _, name := range []string{"bm25md.AddDocuments", "bm25md.analyze", "bm25md.Search", "bm25md.tokenize", "bm25md.score", "bm25md.sort"} {
+		if _, ok := spans[name]; !ok {
+			t.Errorf("no %s span among %v", name, recorder.Ended())
+		}
+	}
+
+	// the search joins the caller's trace, with its stages as children
+	search := spans["bm25md.Search"]
+	if search.Parent().SpanID() != parent.SpanContext().SpanID() {
+		t.Errorf("search span parent = %v, want the request span", search.Parent().SpanID())
+	}
+	for _, name := range []string{"bm25md.tokenize", "bm25md.score", "bm25md.sort"} {
+		if spans[name].Parent().SpanID() != search.SpanContext().SpanID() {
+			t.Errorf("%s span is not a child of the search span", name)
+		}
+	}
+	want := map[attribute.Key]attribute.Value{
+		"bm25md.hits": attribute.IntValue(1),
+	}
+	for _, kv := range search.Attributes() {
+		if value, ok := want[kv.Key]; ok && value != kv.Value {
+			t.Errorf("search span %s = %v, want %v", kv.Key, kv.Value.Emit(), value.Emit())
+		}
+		delete(want, kv.Key)
+	}
+	if len(want) > 0 {
+		t.Errorf("search span is missing attributes %v", want)
+	}
+	if got := spans["bm25md.tokenize"].Attributes(); len(got) != 1 || got[0] != attribute.Int("bm25md.query_terms", 2) {
+		t.Errorf("tokenize span attributes = %v, want 2 query terms", got)
+	}
+}
+
+func TestAttributeOf(t *testing.T) {
+	tests := []struct {
+		value    any
+		expected attribute.KeyValue
+	}{
+		{value: "q", expected: attribute.String("k", "q")},
+		{value: true, expected: attribute.Bool("k", true)},
+		{value: 3, expected: attribute.Int("k", 3)},
+		{value: 1.5, expected: attribute.Float64("k", 1.5)},
+		{value: []int{1}, expected: attribute.String("k", "[1]")},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.value), func(t *testing.T) {
+			if got := attributeOf("k", tt.value); got != tt.expected {
+				t.Errorf("attributeOf(%v) = %v, want %v", tt.value, got, tt.expected)
+			}
+		})
+	}
+}
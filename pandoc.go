@@ -0,0 +1,221 @@
+//go:build !bm25md_noparser
+
+package bm25md
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// pandocExtension adds goldmark parsers for Pandoc fenced divs and citations
+type pandocExtension struct{}
+
+// Extend implements goldmark.Extender
+func (pandocExtension) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(
+		// fenced divs must be tried before definition lists, which also trigger on ':'
+		parser.WithBlockParsers(util.Prioritized(&fencedDivParser{}, 50)),
+		// citations must be tried before links, which also trigger on '['
+		parser.WithInlineParsers(util.Prioritized(&citationParser{}, 150)),
+	)
+}
+
+var (
+	kindFencedDiv = ast.NewNodeKind("FencedDiv")
+	kindCitation  = ast.NewNodeKind("Citation")
+
+	// bracketCitationRegex matches a bracketed citation group like [see @doe99, p. 33; @roe]
+	bracketCitationRegex = regexp.MustCompile(`^\[([^\[\]\n]*@[^\[\]\n]*)\]`)
+	// citationKeyRegex matches individual citation keys within a group
+	citationKeyRegex = regexp.MustCompile(`(?:^|[\s;-])@([\p{L}\p{N}_][\p{L}\p{N}_:.#$%&+?<>~/-]*)`)
+)
+
+// fencedDivNode is a Pandoc ::: container block; its children are indexed normally
+type fencedDivNode struct {
+	ast.BaseBlock
+	closed bool
+}
+
+// Kind implements ast.Node
+func (n *fencedDivNode) Kind() ast.NodeKind {
+	return kindFencedDiv
+}
+
+// Dump implements ast.Node
+func (n *fencedDivNode) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, nil, nil)
+}
+
+// citationNode is an inline Pandoc citation holding one or more citation keys
+type citationNode struct {
+	ast.BaseInline
+	keys       []string
+	start, end int // byte range of the citation in the source
+}
+
+// Kind implements ast.Node
+func (n *citationNode) Kind() ast.NodeKind {
+	return kindCitation
+}
+
+// Dump implements ast.Node
+func (n *citationNode) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{"Keys": strings.Join(n.keys, ",")}, nil)
+}
+
+// fencedDivParser parses ::: {.class} ... ::: blocks
+type fencedDivParser struct{}
+
+// colonFence returns the fence length of a ::: line and the text following it
+func colonFence(line []byte, offset int) (length int, rest []byte) {
+	w, pos := util.IndentWidth(line, offset)
+	if w > 3 {
+		return 0, nil
+	}
+	i := pos
+	for i < len(line) && line[i] == ':' {
+		i++
+	}
+	if i-pos < 3 {
+		return 0, nil
+	}
+	return i - pos, util.TrimRightSpace(util.TrimLeftSpace(line[i:]))
+}
+
+// Trigger implements parser.BlockParser
+func (b *fencedDivParser) Trigger() []byte {
+	return []byte{':'}
+}
+
+// Open implements parser.BlockParser
+func (b *fencedDivParser) Open(parent ast.Node, reader text.Reader, pc parser.Context) (ast.Node, parser.State) {
+	line, segment := reader.PeekLine()
+	length, attributes := colonFence(line, reader.LineOffset())
+	// an opening fence needs attributes (eg {.warning} or a bare class name)
+	if length == 0 || len(attributes) == 0 {
+		return nil, parser.NoChildren
+	}
+	advanceLine(reader, line, segment)
+	return &fencedDivNode{}, parser.HasChildren
+}
+
+// Continue implements parser.BlockParser
+func (b *fencedDivParser) Continue(node ast.Node, reader text.Reader, pc parser.Context) parser.State {
+	line, segment := reader.PeekLine()
+	length, attributes := colonFence(line, reader.LineOffset())
+	if length == 0 || len(attributes) > 0 {
+		return parser.Continue | parser.HasChildren
+	}
+
+	// a bare fence closes the innermost open div, so defer to a nested one if present
+	if inner, ok := node.LastChild().(*fencedDivNode); ok && !inner.closed {
+		return parser.Continue | parser.HasChildren
+	}
+	advanceLine(reader, line, segment)
+	return parser.Close
+}
+
+// advanceLine consumes the rest of the current line, leaving its newline
+func advanceLine(reader text.Reader, line []byte, segment text.Segment) {
+	newline := 0
+	if len(line) > 0 && line[len(line)-1] == '\n' {
+		newline = 1
+	}
+	reader.Advance(segment.Len() - newline + segment.Padding)
+}
+
+// Close implements parser.BlockParser
+func (b *fencedDivParser) Close(node ast.Node, reader text.Reader, pc parser.Context) {
+	node.(*fencedDivNode).closed = true
+}
+
+// CanInterruptParagraph implements parser.BlockParser
+func (b *fencedDivParser) CanInterruptParagraph() bool {
+	return true
+}
+
+// CanAcceptIndentedLine implements parser.BlockParser
+func (b *fencedDivParser) CanAcceptIndentedLine() bool {
+	return false
+}
+
+// citationParser parses bracketed [@key] groups and in-text @key citations
+type citationParser struct{}
+
+// Trigger implements parser.InlineParser
+func (c *citationParser) Trigger() []byte {
+	return []byte{'[', '@'}
+}
+
+// Parse implements parser.InlineParser
+func (c *citationParser) Parse(parent ast.Node, block text.Reader, pc parser.Context) ast.Node {
+	line, segment := block.PeekLine()
+	if len(line) == 0 {
+		return nil
+	}
+
+	if line[0] == '@' {
+		// in-text citations must not be part of a word (eg an email address);
+		// a preceding '[' means the bracketed form was rejected, eg a [@link](url)
+		prev := block.PrecendingCharacter()
+		if unicode.IsLetter(prev) || unicode.IsDigit(prev) || prev == '[' {
+			return nil
+		}
+		end := citationKeyEnd(line)
+		if end == 0 {
+			return nil
+		}
+		block.Advance(end)
+		keys := citationKeys(line[:end])
+		return &citationNode{keys: keys, start: segment.Start, end: segment.Start + end}
+	}
+
+	match := bracketCitationRegex.FindSubmatchIndex(line)
+	if match == nil {
+		return nil
+	}
+	// [@key](url) and [@key][ref] are links, not citations
+	if match[1] < len(line) && (line[match[1]] == '(' || line[match[1]] == '[') {
+		return nil
+	}
+	keys := citationKeys(line[match[2]:match[3]])
+	if len(keys) == 0 {
+		return nil
+	}
+	block.Advance(match[1])
+	return &citationNode{keys: keys, start: segment.Start, end: segment.Start + match[1]}
+}
+
+// citationKeyEnd returns the length of an in-text @key at the start of line
+func citationKeyEnd(line []byte) int {
+	loc := citationKeyRegex.FindIndex(line)
+	if loc == nil || loc[0] != 0 {
+		return 0
+	}
+	end := loc[1]
+	// trailing punctuation belongs to the sentence, not the key
+	for end > 1 && strings.ContainsRune(".:?,;", rune(line[end-1])) {
+		end--
+	}
+	return end
+}
+
+// citationKeys extracts the citation keys from a citation group
+func citationKeys(group []byte) []string {
+	matches := citationKeyRegex.FindAllSubmatch(group, -1)
+	keys := make([]string, 0, len(matches))
+	for _, match := range matches {
+		key := strings.TrimRight(string(match[1]), ".:?,;")
+		if key != "" {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
@@ -0,0 +1,84 @@
+//go:build !bm25md_noparser
+
+package bm25md
+
+import "testing"
+
+func TestMarkdownFieldParser_Pandoc(t *testing.T) {
+	parser := NewMarkdownFieldParser(WithPandoc())
+
+	tests := []struct {
+		name     string
+		input    string
+		expected map[Field]string
+	}{
+		{
+			name:  "definition list",
+			input: "Habeas corpus\n:   A writ requiring a detained person to be brought before a court.",
+			expected: map[Field]string{
+				FieldTerm: "Habeas corpus",
+				FieldBody: "A writ requiring a detained person to be brought before a court.",
+			},
+		},
+		{
+			name:  "fenced div",
+			input: "::: {.warning}\nDetention must be *lawful*.\n:::\n\nAfter the div.",
+			expected: map[Field]string{
+				FieldItalic: "lawful",
+				FieldBody:   "Detention must be . After the div.",
+			},
+		},
+		{
+			name:  "nested fenced divs",
+			input: "::: outer\n::: inner\nInner text.\n:::\nOuter text.\n:::",
+			expected: map[Field]string{
+				FieldBody: "Inner text. Outer text.",
+			},
+		},
+		{
+			name:  "bracketed citations",
+			input: "The writ is ancient [see @blackstone1765, p. 131; -@holdsworth1922].",
+			expected: map[Field]string{
+				FieldCitation: "blackstone1765 holdsworth1922",
+				FieldBody:     "The writ is ancient .",
+			},
+		},
+		{
+			name:  "in-text citation and email",
+			input: "As @halliday2010: argues, write to clerk@example.com.",
+			expected: map[Field]string{
+				FieldCitation: "halliday2010",
+			},
+		},
+		{
+			name:  "links are not citations",
+			input: "Ask [@court](http://example.com) for records.",
+			expected: map[Field]string{
+				FieldCitation: "",
+				FieldBody:     "Ask @court for records.",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := parser.ParseDocument(tt.input)
+			for field, expectedContent := range tt.expected {
+				got := normalizeWhitespace(result[field])
+				want := normalizeWhitespace(expectedContent)
+				if got != want {
+					t.Errorf("Field %s = %q, want %q", field, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestMarkdownFieldParser_PandocDisabledByDefault(t *testing.T) {
+	parser := NewMarkdownFieldParser()
+
+	result := parser.ParseDocument("See [@doe99].")
+	if result[FieldCitation] != "" {
+		t.Errorf("Citation field = %q, want empty without WithPandoc", result[FieldCitation])
+	}
+}
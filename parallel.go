@@ -0,0 +1,123 @@
+package bm25md
+
+import (
+	"math"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// DefaultParallelThreshold is the fewest candidate documents a search scores in
+// parallel; below it, handing work to other goroutines costs more than it saves
+const DefaultParallelThreshold = 100
+
+// parallelChunk is how many candidates a scoring worker claims at a time
+const parallelChunk = 64
+
+// WithParallelism tunes parallel work: searches score candidates in parallel
+// once there are at least threshold of them (0 = DefaultParallelThreshold, < 0 =
+// never), and searches and AddDocuments use at most workers goroutines (0 = one
+// per CPU). The goroutines come from a pool shared by every corpus, so parallel
+// searches don't start goroutines of their own. BenchmarkScoring shows where
+// parallel scoring pays off on given hardware
+func WithParallelism(threshold, workers int) CorpusOption {
+	return func(c *Corpus) {
+		c.parallelMin = threshold
+		c.maxWorkers = workers
+	}
+}
+
+// parallelThreshold returns the fewest candidates scored in parallel
+func (c *Corpus) parallelThreshold() int {
+	switch {
+	case c.parallelMin < 0:
+		return math.MaxInt
+	case c.parallelMin == 0:
+		return DefaultParallelThreshold
+	default:
+		return c.parallelMin
+	}
+}
+
+// workerCount returns how many goroutines should share n items of work
+func (c *Corpus) workerCount(n int) int {
+	workers := c.maxWorkers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	return max(1, min(workers, n))
+}
+
+// pool runs the parallel work of every corpus, with up to four workers per CPU
+var pool = &workerPool{tasks: make(chan func()), limit: 4 * runtime.NumCPU()}
+
+// workerPool runs tasks on long-lived goroutines, started as needed up to limit
+// and then reused
+type workerPool struct {
+	tasks chan func() // unbuffered, so a send succeeds only for an idle worker
+	mu    sync.Mutex
+	size  int // started workers
+	limit int
+}
+
+// forEach calls fn with consecutive ranges of [0, n), at most chunk long, on up
+// to workers goroutines including the caller. Each range goes to one goroutine,
+// identified by a worker number below workers, and forEach returns once all are done
+func (p *workerPool) forEach(n, workers, chunk int, fn func(worker, start, end int)) {
+	var next atomic.Int64
+	p.run(workers, func(worker int) {
+		for {
+			start := int(next.Add(int64(chunk))) - chunk
+			if start >= n {
+				return
+			}
+			fn(worker, start, min(start+chunk, n))
+		}
+	})
+}
+
+// run calls task on the caller as worker 0 and on up to workers-1 pool workers,
+// then waits for all of them. When the pool is busy and full, fewer workers run,
+// so tasks must share their work rather than each owning a part of it
+func (p *workerPool) run(workers int, task func(worker int)) {
+	var wg sync.WaitGroup
+	for worker := 1; worker < workers; worker++ {
+		wg.Add(1)
+		if !p.submit(func() {
+			defer wg.Done()
+			task(worker)
+		}) {
+			wg.Done()
+			break
+		}
+	}
+	task(0)
+	wg.Wait()
+}
+
+// submit hands fn to an idle worker, or to a new one while the pool is below its
+// limit; it reports false when neither is available
+func (p *workerPool) submit(fn func()) bool {
+	select {
+	case p.tasks <- fn:
+		return true
+	default:
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.size >= p.limit {
+		return false
+	}
+	p.size++
+	go p.work(fn)
+	return true
+}
+
+// work runs fn, then every task sent to the pool
+func (p *workerPool) work(fn func()) {
+	fn()
+	for fn := range p.tasks {
+		fn()
+	}
+}
@@ -0,0 +1,66 @@
+package bm25md
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestWithParallelism(t *testing.T) {
+	tests := []struct {
+		name      string
+		threshold int
+		workers   int
+		parallel  bool
+	}{
+		{name: "default threshold", threshold: 0, workers: 4, parallel: true},
+		{name: "threshold above candidates", threshold: 10_000, workers: 4, parallel: false},
+		{name: "never", threshold: -1, workers: 4, parallel: false},
+		{name: "one worker", threshold: 10, workers: 1, parallel: false},
+		{name: "more workers than candidates", threshold: 1, workers: 1_000, parallel: true},
+	}
+
+	want := randomCorpus(300, WithParallelism(-1, 0)).Search("habeas corpus", 20)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stats SearchStats
+			corpus := randomCorpus(300, WithParallelism(tt.threshold, tt.workers), WithSearchHook(func(_ string, _ []SearchResult, s SearchStats) {
+				stats = s
+			}))
+
+			got := corpus.Search("habeas corpus", 20)
+			if stats.Parallel != tt.parallel {
+				t.Errorf("parallel = %v, want %v (%d candidates)", stats.Parallel, tt.parallel, stats.Candidates)
+			}
+			sameResults(t, got, want)
+		})
+	}
+}
+
+func TestWorkerPoolForEach(t *testing.T) {
+	p := &workerPool{tasks: make(chan func()), limit: 2}
+
+	for range 3 {
+		var mu sync.Mutex
+		seen := make(map[int]int)
+		p.forEach(1_000, 8, 7, func(worker, start, end int) {
+			if worker < 0 || worker >= 8 {
+				t.Errorf("worker = %d, want below 8", worker)
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			for i := start; i < end; i++ {
+				seen[i]++
+			}
+		})
+		for i := range 1_000 {
+			if seen[i] != 1 {
+				t.Fatalf("item %d handled %d times, want once", i, seen[i])
+			}
+		}
+	}
+
+	// the pool keeps its workers between calls and never exceeds its limit
+	if p.size > p.limit {
+		t.Errorf("pool started %d workers, want at most %d", p.size, p.limit)
+	}
+}
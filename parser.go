@@ -1,59 +1,317 @@
+//go:build !bm25md_noparser
+
 package bm25md
 
 import (
 	"bytes"
+	"errors"
+	"fmt"
+	"log/slog"
+	"runtime"
+	"sort"
 	"strings"
+	"sync"
 
 	"github.com/yuin/goldmark"
 	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	east "github.com/yuin/goldmark/extension/ast"
 	"github.com/yuin/goldmark/parser"
 	"github.com/yuin/goldmark/text"
 )
 
+// ParserMode selects the markdown dialect understood by the parser
+type ParserMode int
+
+const (
+	ModeCommonMark ParserMode = iota // strict CommonMark (default)
+	ModeGFM                          // GitHub Flavored Markdown: tables, strikethrough, autolinks, task lists
+	ModeMDX                          // GFM with MDX's JSX components, expressions, and import/export lines stripped
+)
+
+// StrikethroughMode selects how ~~deleted~~ text is indexed
+type StrikethroughMode int
+
+const (
+	StrikethroughBody    StrikethroughMode = iota // index as body text (default)
+	StrikethroughField                            // index into the low-weight FieldStrikethrough
+	StrikethroughExclude                          // leave out of the index
+)
+
 // MarkdownFieldParser extracts content from markdown documents
 type MarkdownFieldParser struct {
-	parser parser.Parser
+	parser       parser.Parser
+	custom       parser.Parser // caller-supplied goldmark parser, used as is
+	mode         ParserMode
+	extensions   []goldmark.Extender    // goldmark extensions enabled by options
+	tables       bool                   // parse pipe tables outside GFM mode
+	urlSegments  bool                   // index URLs as host and path segment words
+	htmlEmphasis bool                   // route text in <b>/<i> tags to bold/italic fields
+	blockquotes  bool                   // route blockquote text to FieldBlockquote
+	listItems    bool                   // route list item text to FieldList
+	strike       StrikethroughMode      // handling of ~~deleted~~ text
+	excludedLang map[string]bool        // fenced code languages left out of the index
+	frontMatter  map[string]Field       // front matter keys indexed into fields
+	nodeFields   map[ast.NodeKind]Field // custom node kinds indexed into fields
+	log          *slog.Logger           // destination of log messages (nil = slog.Default())
+}
+
+var _ FieldParser = (*MarkdownFieldParser)(nil)
+
+// DiagramLanguages lists fenced code languages used for diagrams rather than code
+var DiagramLanguages = []string{"mermaid", "plantuml", "puml", "dot", "graphviz", "d2", "ditaa"}
+
+// ParserOption defines a function that configures a MarkdownFieldParser
+type ParserOption func(*MarkdownFieldParser)
+
+// WithParserMode selects strict CommonMark or GFM parsing
+func WithParserMode(mode ParserMode) ParserOption {
+	return func(p *MarkdownFieldParser) {
+		p.mode = mode
+	}
+}
+
+// WithExcludedCodeLanguages drops fenced code blocks in the given languages
+// (eg DiagramLanguages...) so their DSL keywords do not pollute FieldCode
+func WithExcludedCodeLanguages(languages ...string) ParserOption {
+	return func(p *MarkdownFieldParser) {
+		if p.excludedLang == nil {
+			p.excludedLang = make(map[string]bool, len(languages))
+		}
+		for _, lang := range languages {
+			p.excludedLang[strings.ToLower(lang)] = true
+		}
+	}
+}
+
+// WithObsidian enables Obsidian syntax: [[wikilinks]] index their display text
+// into body and FieldLink and their target note into FieldURL, ![[embeds]] index
+// only their target into FieldURL, and inline #tags go to FieldTags
+func WithObsidian() ParserOption {
+	return func(p *MarkdownFieldParser) {
+		p.extensions = append(p.extensions, obsidianExtension{})
+	}
+}
+
+// WithTables enables GFM pipe tables in CommonMark mode (ModeGFM always parses them);
+// table cell text is indexed into FieldTable instead of body
+func WithTables() ParserOption {
+	return func(p *MarkdownFieldParser) {
+		p.tables = true
+	}
+}
+
+// WithURLSegments indexes URLs as the words of their host and path segments
+// (https://example.com/habeas-corpus becomes "example com habeas corpus")
+// instead of the raw URL
+func WithURLSegments() ParserOption {
+	return func(p *MarkdownFieldParser) {
+		p.urlSegments = true
+	}
+}
+
+// WithHTMLEmphasis routes text inside raw HTML <b>/<strong> and <i>/<em> tags
+// to the bold and italic fields instead of body
+func WithHTMLEmphasis() ParserOption {
+	return func(p *MarkdownFieldParser) {
+		p.htmlEmphasis = true
+	}
+}
+
+// WithStrikethrough parses ~~deleted~~ text (in CommonMark mode too) and indexes it
+// according to mode, so deleted text need not rank like live body content
+func WithStrikethrough(mode StrikethroughMode) ParserOption {
+	return func(p *MarkdownFieldParser) {
+		p.strike = mode
+	}
+}
+
+// WithBlockquoteField indexes text inside blockquotes into FieldBlockquote instead of body
+func WithBlockquoteField() ParserOption {
+	return func(p *MarkdownFieldParser) {
+		p.blockquotes = true
+	}
+}
+
+// WithListField indexes text inside list items into FieldList instead of body, since
+// list items in documentation often carry the key facts
+func WithListField() ParserOption {
+	return func(p *MarkdownFieldParser) {
+		p.listItems = true
+	}
+}
+
+// WithPandoc enables Pandoc markdown constructs common in academic writing:
+// definition lists (terms go to FieldTerm), fenced divs (::: blocks, whose
+// fences are dropped), and citations ([@key] and @key, keys go to FieldCitation)
+func WithPandoc() ParserOption {
+	return func(p *MarkdownFieldParser) {
+		p.extensions = append(p.extensions, extension.DefinitionList, pandocExtension{})
+	}
+}
+
+// WithFrontMatterFields sets which front matter keys are indexed and into which
+// fields (defaults to DefaultFrontMatterFields); other keys are left out of the index
+func WithFrontMatterFields(mapping map[string]Field) ParserOption {
+	return func(p *MarkdownFieldParser) {
+		p.frontMatter = mapping
+	}
+}
+
+// WithGoldmarkExtensions enables additional goldmark extensions (eg
+// extension.Footnote or extension.DefinitionList). Text in nodes the field
+// extractor does not recognize is indexed into body
+func WithGoldmarkExtensions(extensions ...goldmark.Extender) ParserOption {
+	return func(p *MarkdownFieldParser) {
+		p.extensions = append(p.extensions, extensions...)
+	}
+}
+
+// WithGoldmarkParser uses a preconfigured goldmark parser (eg goldmark.New(...).Parser())
+// instead of building one. Options that enable goldmark extensions (WithTables,
+// WithObsidian, WithPandoc, WithGoldmarkExtensions) and ParserMode's choice of
+// extensions are then ignored, so the parser must already include any it needs
+func WithGoldmarkParser(parser parser.Parser) ParserOption {
+	return func(p *MarkdownFieldParser) {
+		p.custom = parser
+	}
+}
+
+// WithNodeField indexes the text of AST nodes of the given kind (eg a custom
+// admonition extension's node) into field, instead of body. Mappings take
+// precedence over the built-in handling of a kind. Give the field a weight with
+// WithFieldWeight, since corpora ignore fields without one
+func WithNodeField(kind ast.NodeKind, field Field) ParserOption {
+	return func(p *MarkdownFieldParser) {
+		if p.nodeFields == nil {
+			p.nodeFields = make(map[ast.NodeKind]Field)
+		}
+		p.nodeFields[kind] = field
+	}
+}
+
+// WithParserLogger sends the parser's debug messages (malformed front matter,
+// parallel parsing) to logger instead of slog.Default()
+func WithParserLogger(logger *slog.Logger) ParserOption {
+	return func(p *MarkdownFieldParser) {
+		p.log = logger
+	}
 }
 
-// NewMarkdownFieldParser creates new AST-based parser instance
-func NewMarkdownFieldParser() *MarkdownFieldParser {
-	return &MarkdownFieldParser{
-		parser: goldmark.DefaultParser(),
+// logger returns the parser's logger
+func (p *MarkdownFieldParser) logger() *slog.Logger {
+	if p.log != nil {
+		return p.log
 	}
+	return slog.Default()
+}
+
+// NewMarkdownFieldParser creates new AST-based parser instance with optional configuration
+func NewMarkdownFieldParser(opts ...ParserOption) *MarkdownFieldParser {
+	p := &MarkdownFieldParser{}
+
+	// apply user options
+	for _, opt := range opts {
+		opt(p)
+	}
+	if p.frontMatter == nil {
+		p.frontMatter = DefaultFrontMatterFields
+	}
+
+	if p.custom != nil {
+		p.parser = p.custom
+		return p
+	}
+
+	extensions := p.extensions
+	if p.mode == ModeGFM || p.mode == ModeMDX {
+		extensions = append([]goldmark.Extender{extension.GFM}, extensions...)
+	} else {
+		if p.tables {
+			extensions = append([]goldmark.Extender{extension.Table}, extensions...)
+		}
+		if p.strike != StrikethroughBody {
+			extensions = append([]goldmark.Extender{extension.Strikethrough}, extensions...)
+		}
+	}
+
+	if p.mode == ModeMDX {
+		p.parser = mdxParser(extensions)
+	} else if len(extensions) == 0 {
+		p.parser = goldmark.DefaultParser()
+	} else {
+		p.parser = goldmark.New(goldmark.WithExtensions(extensions...)).Parser()
+	}
+
+	return p
 }
 
 // ParseDocument extracts field-specific content using AST traversal
 func (p *MarkdownFieldParser) ParseDocument(content string) map[Field]string {
-	// Initialize all fields with empty strings
-	fields := make(map[Field]string)
-	for field := range DefaultFieldWeights {
-		fields[field] = ""
+	return JoinOccurrences(p.ParseOccurrences(content))
+}
+
+// ParseOccurrences extracts each field occurrence separately (each H2, each bold
+// span, ...) so callers can work with accurate boundaries instead of joined text
+func (p *MarkdownFieldParser) ParseOccurrences(content string) map[Field][]Occurrence {
+	occurrences, _, _, _ := p.parseOccurrences(content)
+	return occurrences
+}
+
+// parseOccurrences parses content and collects field occurrences, also returning
+// the AST and source so callers can derive more from the same parse. The error
+// reports malformed front matter (left unindexed) or a failed AST walk (all
+// content falls back to body); the occurrences are usable either way
+func (p *MarkdownFieldParser) parseOccurrences(content string) (map[Field][]Occurrence, ast.Node, []byte, error) {
+	source := []byte(content)
+
+	// storage for collected occurrences by field type
+	occurrences := make(map[Field][]Occurrence)
+	add := func(field Field, text string, start, end int) {
+		start, end = trimSpan(source, start, end)
+		occurrences[field] = append(occurrences[field], Occurrence{Text: text, Start: start, End: end})
+	}
+
+	// index front matter into its fields, then blank it out so raw YAML never reaches body
+	fmErr := p.extractFrontMatter(content, source, add)
+	if p.mode == ModeMDX {
+		stripMDX(source)
 	}
 
 	// parse markdown to AST
-	source := []byte(content)
 	reader := text.NewReader(source)
 	doc := p.parser.Parse(reader)
-
-	// storage for collected text by field type
-	fieldTexts := make(map[Field][]string)
-	for field := range DefaultFieldWeights {
-		fieldTexts[field] = make([]string, 0)
+	addNode := func(field Field, text string, node ast.Node) {
+		start, end := nodeSpan(node)
+		add(field, text, start, end)
 	}
 
+	// inline HTML tags stay open until their closing tag or the end of the block
+	htmlTags := &htmlState{emphasis: p.htmlEmphasis, url: p.urlText}
+
 	// walk the AST and extract text based on node type
 	err := ast.Walk(doc, func(node ast.Node, entering bool) (ast.WalkStatus, error) {
 		if !entering {
+			if node.Type() == ast.TypeBlock {
+				htmlTags.reset()
+			}
 			return ast.WalkContinue, nil
 		}
 
+		// custom mappings win over the built-in node handling
+		if field, ok := p.nodeFields[node.Kind()]; ok {
+			if text := p.extractTextFromChildren(node, source); text != "" {
+				addNode(field, text, node)
+			}
+			return ast.WalkSkipChildren, nil
+		}
+
 		switch n := node.(type) {
 		case *ast.Heading:
 			// extract header text based on level
 			text := p.extractTextFromChildren(n, source)
 			if text != "" {
-				field := p.getHeaderField(n.Level)
-				fieldTexts[field] = append(fieldTexts[field], text)
+				addNode(p.getHeaderField(n.Level), text, n)
 			}
 			// skip children
 			return ast.WalkSkipChildren, nil
@@ -62,16 +320,20 @@ func (p *MarkdownFieldParser) ParseDocument(content string) map[Field]string {
 			// extract inline code
 			text := p.extractTextFromChildren(n, source)
 			if text != "" {
-				fieldTexts[FieldCode] = append(fieldTexts[FieldCode], text)
+				addNode(FieldCode, text, n)
 			}
 			// skip children
 			return ast.WalkSkipChildren, nil
 
 		case *ast.FencedCodeBlock:
+			// skip excluded languages (eg diagrams) entirely
+			if p.isExcludedLanguage(n, source) {
+				return ast.WalkSkipChildren, nil
+			}
 			// extract fenced code block content
 			text := p.extractCodeBlockText(n, source)
 			if text != "" {
-				fieldTexts[FieldCode] = append(fieldTexts[FieldCode], text)
+				addNode(FieldCode, text, n)
 			}
 			// skip children
 			return ast.WalkSkipChildren, nil
@@ -80,17 +342,121 @@ func (p *MarkdownFieldParser) ParseDocument(content string) map[Field]string {
 			// extract indented code block content
 			text := p.extractCodeBlockText(n, source)
 			if text != "" {
-				fieldTexts[FieldCode] = append(fieldTexts[FieldCode], text)
+				addNode(FieldCode, text, n)
 			}
 			// Skip children as we've already processed them
 			return ast.WalkSkipChildren, nil
 
+		case *east.TableCell:
+			// extract each cell (header or body row) without the pipe delimiters
+			text := p.extractTextFromChildren(n, source)
+			if text != "" {
+				addNode(FieldTable, text, n)
+			}
+			return ast.WalkSkipChildren, nil
+
+		case *east.Strikethrough:
+			switch p.strike {
+			case StrikethroughExclude:
+				return ast.WalkSkipChildren, nil
+			case StrikethroughField:
+				text := p.extractTextFromChildren(n, source)
+				if text != "" {
+					addNode(FieldStrikethrough, text, n)
+				}
+				return ast.WalkSkipChildren, nil
+			}
+
+		case *east.DefinitionTerm:
+			// extract the term being defined (descriptions stay in body)
+			text := p.extractTextFromChildren(n, source)
+			if text != "" {
+				addNode(FieldTerm, text, n)
+			}
+			return ast.WalkSkipChildren, nil
+
+		case *citationNode:
+			// extract citation keys
+			for _, key := range n.keys {
+				add(FieldCitation, key, n.start, n.end)
+			}
+			return ast.WalkSkipChildren, nil
+
+		case *ast.Link:
+			// anchor text also goes to FieldLink (it stays in body so sentences read
+			// naturally) and the destination to FieldURL
+			text := p.extractTextFromChildren(n, source)
+			if text != "" {
+				addNode(FieldLink, text, n)
+			}
+			if url := p.urlText(string(n.Destination)); url != "" {
+				add(FieldURL, url, -1, -1)
+			}
+
+		case *ast.Image:
+			// alt text and titles describe the image rather than being body text
+			text := p.extractTextFromChildren(n, source)
+			if text != "" {
+				addNode(FieldAltText, text, n)
+			}
+			if title := strings.TrimSpace(string(n.Title)); title != "" {
+				add(FieldAltText, title, -1, -1)
+			}
+			return ast.WalkSkipChildren, nil
+
+		case *wikiLinkNode:
+			if !n.embed && n.display != "" {
+				add(FieldBody, n.display, n.start, n.end)
+				add(FieldLink, n.display, n.start, n.end)
+			}
+			if n.target != "" {
+				add(FieldURL, n.target, n.start, n.end)
+			}
+			return ast.WalkSkipChildren, nil
+
+		case *tagNode:
+			add(FieldTags, tagText(n.tag), n.start, n.end)
+			return ast.WalkSkipChildren, nil
+
+		case *ast.AutoLink:
+			// autolinks have no text children, so take the URL label itself
+			if !p.isInsideSpecialElement(node) {
+				add(FieldBody, string(n.Label(source)), -1, -1)
+				if url := p.urlText(string(n.URL(source))); url != "" {
+					add(FieldURL, url, -1, -1)
+				}
+			}
+			return ast.WalkSkipChildren, nil
+
+		case *ast.HTMLBlock:
+			// extract text from raw HTML blocks (eg <details>), each with its own tag state
+			blockTags := &htmlState{emphasis: p.htmlEmphasis, url: p.urlText}
+			blockTags.feed(htmlBlockSource(n, source), func(field Field, text string) {
+				addNode(field, text, n)
+			})
+			return ast.WalkSkipChildren, nil
+
+		case *ast.RawHTML:
+			// track inline tags so the text that follows lands in the right field
+			if !p.isInsideSpecialElement(node) {
+				htmlTags.feed(rawHTMLSource(n, source), func(field Field, text string) {
+					addNode(field, text, n)
+				})
+			}
+			return ast.WalkSkipChildren, nil
+
 		case *ast.Text:
 			// only extract text if it's not inside a special element
 			if !p.isInsideSpecialElement(node) {
 				text := strings.TrimSpace(string(n.Segment.Value(source)))
-				if text != "" {
-					fieldTexts[FieldBody] = append(fieldTexts[FieldBody], text)
+				if field, ok := htmlTags.field(); ok && text != "" {
+					if field == FieldBody {
+						field = p.blockField(node)
+					}
+					add(field, text, n.Segment.Start, n.Segment.Stop)
+					if htmlTags.linked() {
+						add(FieldLink, text, n.Segment.Start, n.Segment.Stop)
+					}
 				}
 			}
 
@@ -102,9 +468,9 @@ func (p *MarkdownFieldParser) ParseDocument(content string) map[Field]string {
 					text := p.extractTextFromChildren(n, source)
 					if text != "" {
 						if n.Level == 2 { // ** or __
-							fieldTexts[FieldBold] = append(fieldTexts[FieldBold], text)
+							addNode(FieldBold, text, n)
 						} else if n.Level == 1 { // * or _
-							fieldTexts[FieldItalic] = append(fieldTexts[FieldItalic], text)
+							addNode(FieldItalic, text, n)
 						}
 					}
 					// skip children
@@ -118,18 +484,140 @@ func (p *MarkdownFieldParser) ParseDocument(content string) map[Field]string {
 
 	if err != nil {
 		// if there's an error, fall back to original content in body
-		fields[FieldBody] = content
-		return fields
+		return map[Field][]Occurrence{
+			FieldBody: {{Text: content, Start: 0, End: len(content)}},
+		}, doc, source, errors.Join(fmErr, fmt.Errorf("bm25md: walking markdown: %w", err))
 	}
 
-	// join collected texts for each field
-	for field, texts := range fieldTexts {
-		if len(texts) > 0 {
-			fields[field] = strings.Join(texts, " ")
+	return occurrences, doc, source, fmErr
+}
+
+// extractFrontMatter adds mapped front matter values as occurrences and blanks the
+// front matter in source (keeping newlines so offsets and line numbers hold). It
+// returns the error of malformed front matter, which is left as it is
+func (p *MarkdownFieldParser) extractFrontMatter(content string, source []byte, add func(Field, string, int, int)) error {
+	fm, body, err := ParseFrontMatter(content)
+	if err != nil {
+		p.logger().Debug("bm25md: ignoring malformed front matter", "error", err)
+		return err
+	}
+	if fm == nil {
+		return nil
+	}
+
+	end := len(content) - len(body)
+	raw := content[:end]
+
+	// sort keys so fields fed by several keys are deterministic
+	keys := make([]string, 0, len(p.frontMatter))
+	for key := range p.frontMatter {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		for _, value := range fm.Strings(key) {
+			value = strings.TrimSpace(value)
+			if value == "" {
+				continue
+			}
+			// point at the value itself when it appears verbatim, else the whole block
+			if start := strings.Index(raw, value); start >= 0 {
+				add(p.frontMatter[key], value, start, start+len(value))
+			} else {
+				add(p.frontMatter[key], value, 0, end)
+			}
 		}
 	}
 
-	return fields
+	for i := 0; i < end; i++ {
+		if source[i] != '\n' {
+			source[i] = ' '
+		}
+	}
+	return nil
+}
+
+// urlText returns a URL as indexed text, split into words when URL segments are enabled
+func (p *MarkdownFieldParser) urlText(raw string) string {
+	raw = strings.TrimSpace(raw)
+	if !p.urlSegments || raw == "" {
+		return raw
+	}
+
+	// drop the scheme, query, and fragment, then split host and path into words
+	if _, rest, found := strings.Cut(raw, "://"); found {
+		raw = rest
+	} else {
+		raw = strings.TrimPrefix(raw, "mailto:")
+	}
+	if i := strings.IndexAny(raw, "?#"); i >= 0 {
+		raw = raw[:i]
+	}
+	words := strings.FieldsFunc(raw, func(r rune) bool {
+		return r == '/' || r == '.' || r == '-' || r == '_' || r == ':' || r == '@' || r == '+' || r == '~'
+	})
+	// "www" and file extensions like "html" carry no meaning
+	kept := words[:0]
+	for _, word := range words {
+		switch strings.ToLower(word) {
+		case "www", "html", "htm", "md", "php":
+			continue
+		}
+		kept = append(kept, word)
+	}
+	return strings.Join(kept, " ")
+}
+
+// nodeSpan returns the byte range a node covers in the source (-1, -1 when unknown)
+func nodeSpan(node ast.Node) (start, end int) {
+	switch n := node.(type) {
+	case *ast.RawHTML:
+		if n.Segments.Len() > 0 {
+			return n.Segments.At(0).Start, n.Segments.At(n.Segments.Len() - 1).Stop
+		}
+	case *citationNode:
+		return n.start, n.end
+	}
+
+	// blocks know their lines; inlines span their text descendants
+	if node.Type() == ast.TypeBlock && node.Lines().Len() > 0 {
+		lines := node.Lines()
+		return lines.At(0).Start, lines.At(lines.Len() - 1).Stop
+	}
+
+	start, end = -1, -1
+	_ = ast.Walk(node, func(child ast.Node, entering bool) (ast.WalkStatus, error) {
+		if t, ok := child.(*ast.Text); ok && entering {
+			if start < 0 || t.Segment.Start < start {
+				start = t.Segment.Start
+			}
+			if t.Segment.Stop > end {
+				end = t.Segment.Stop
+			}
+		}
+		return ast.WalkContinue, nil
+	})
+	return start, end
+}
+
+// trimSpan narrows a byte range to exclude surrounding whitespace
+func trimSpan(source []byte, start, end int) (int, int) {
+	if start < 0 || end > len(source) || start >= end {
+		return start, end
+	}
+	for start < end && isSpaceByte(source[start]) {
+		start++
+	}
+	for end > start && isSpaceByte(source[end-1]) {
+		end--
+	}
+	return start, end
+}
+
+// isSpaceByte reports whether b is ASCII whitespace
+func isSpaceByte(b byte) bool {
+	return b == ' ' || b == '\t' || b == '\n' || b == '\r'
 }
 
 // getHeaderField returns the appropriate field for a header level
@@ -223,6 +711,17 @@ func (p *MarkdownFieldParser) extractCodeBlockText(node ast.Node, source []byte)
 	return strings.TrimSpace(result)
 }
 
+// isExcludedLanguage reports whether a fenced code block's language was excluded by options
+func (p *MarkdownFieldParser) isExcludedLanguage(n *ast.FencedCodeBlock, source []byte) bool {
+	if len(p.excludedLang) == 0 {
+		return false
+	}
+	// accept both ```mermaid and Pandoc-style ```{.mermaid}
+	lang := strings.TrimLeft(string(n.Language(source)), "{.")
+	lang = strings.TrimRight(lang, "}")
+	return p.excludedLang[strings.ToLower(lang)]
+}
+
 // isInsideSpecialElement checks if a node is inside a heading, code, or emphasis element
 func (p *MarkdownFieldParser) isInsideSpecialElement(node ast.Node) bool {
 	parent := node.Parent()
@@ -240,18 +739,143 @@ func (p *MarkdownFieldParser) isInsideSpecialElement(node ast.Node) bool {
 	return false
 }
 
+// blockField returns the field for body text in node, taking the innermost enabled
+// blockquote or list item containing it (FieldBody when there is none)
+func (p *MarkdownFieldParser) blockField(node ast.Node) Field {
+	if !p.blockquotes && !p.listItems {
+		return FieldBody
+	}
+	for parent := node.Parent(); parent != nil; parent = parent.Parent() {
+		switch parent.Kind() {
+		case ast.KindBlockquote:
+			if p.blockquotes {
+				return FieldBlockquote
+			}
+		case ast.KindListItem:
+			if p.listItems {
+				return FieldList
+			}
+		}
+	}
+	return FieldBody
+}
+
 // ParseDocuments parses multiple markdown documents into BM25md Documents
 func (p *MarkdownFieldParser) ParseDocuments(contents []string) []Document {
 	documents := make([]Document, len(contents))
 
 	for i, content := range contents {
-		fields := p.ParseDocument(content)
-		documents[i] = Document{
-			ID:       i,
-			Fields:   fields,
-			Original: content,
-		}
+		documents[i], _ = p.parseDocument(i, content)
 	}
 
 	return documents
 }
+
+// ParseResult is the outcome of parsing one document with ParseDocumentsParallel.
+// Err reports why parsing failed or fell short: when a goldmark extension
+// panics, Document is empty; when the front matter is malformed (and so not
+// indexed) or the AST walk fails (leaving all content in body), Document holds
+// what was parsed
+type ParseResult struct {
+	Document Document
+	Err      error
+}
+
+// ParseDocumentsParallel parses documents like ParseDocuments across workers
+// goroutines (0 = one per CPU), so large corpora parse faster. Results are in
+// input order, and a document that fails to parse reports its error instead of
+// stopping the others
+func (p *MarkdownFieldParser) ParseDocumentsParallel(contents []string, workers int) []ParseResult {
+	results := make([]ParseResult, len(contents))
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	workers = min(workers, len(contents))
+
+	next := make(chan int, len(contents))
+	for i := range contents {
+		next <- i
+	}
+	close(next)
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				results[i] = p.safeParseDocument(i, contents[i])
+			}
+		}()
+	}
+	wg.Wait()
+
+	failed := 0
+	for _, result := range results {
+		if result.Err != nil {
+			failed++
+		}
+	}
+	p.logger().Debug("Parsed BM25md documents", "documents", len(contents), "failed", failed, "workers", workers)
+	return results
+}
+
+// safeParseDocument parses a document, turning a parser panic into an error
+func (p *MarkdownFieldParser) safeParseDocument(i int, content string) (result ParseResult) {
+	defer func() {
+		if r := recover(); r != nil {
+			result = ParseResult{Err: fmt.Errorf("bm25md: parsing document %d: %v", i, r)}
+		}
+	}()
+	doc, err := p.parseDocument(i, content)
+	if err != nil {
+		err = fmt.Errorf("bm25md: parsing document %d: %w", i, err)
+	}
+	return ParseResult{Document: doc, Err: err}
+}
+
+// parseDocument parses content into a document with the given ID, along with
+// any error parseOccurrences reports
+func (p *MarkdownFieldParser) parseDocument(id int, content string) (Document, error) {
+	occurrences, doc, _, err := p.parseOccurrences(content)
+	return Document{
+		ID:          id,
+		Fields:      JoinOccurrences(occurrences),
+		Original:    content,
+		Occurrences: occurrences,
+		Stats:       documentStats(occurrences, doc),
+	}, err
+}
+
+// ParseStats counts field occurrences, code blocks, and links in markdown content
+func (p *MarkdownFieldParser) ParseStats(content string) DocumentStats {
+	occurrences, doc, _, _ := p.parseOccurrences(content)
+	return documentStats(occurrences, doc)
+}
+
+// documentStats builds stats from parsed occurrences and the document AST
+func documentStats(occurrences map[Field][]Occurrence, doc ast.Node) DocumentStats {
+	stats := DocumentStats{Fields: make(map[Field]int, len(occurrences))}
+	for field, occs := range occurrences {
+		stats.Fields[field] = len(occs)
+	}
+
+	_ = ast.Walk(doc, func(node ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch n := node.(type) {
+		case *ast.FencedCodeBlock, *ast.CodeBlock:
+			stats.CodeBlocks++
+		case *ast.Link, *ast.AutoLink:
+			stats.Links++
+		case *wikiLinkNode:
+			if !n.embed {
+				stats.Links++
+			}
+		}
+		return ast.WalkContinue, nil
+	})
+
+	return stats
+}
@@ -1,8 +1,20 @@
+//go:build !bm25md_noparser
+
 package bm25md
 
 import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"reflect"
 	"strings"
 	"testing"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
 )
 
 func TestMarkdownFieldParser_ParseDocument(t *testing.T) {
@@ -162,7 +174,7 @@ func TestMarkdownFieldParser_BodyCleaning(t *testing.T) {
 		{
 			name:     "images",
 			input:    "![alt text](image.png) caption",
-			expected: "alt text caption",
+			expected: "caption", // alt text goes to FieldAltText
 		},
 		{
 			name:     "horizontal rules",
@@ -215,6 +227,92 @@ func TestMarkdownFieldParser_ParseDocuments(t *testing.T) {
 	}
 }
 
+// panickyParser is a goldmark parser that panics on documents mentioning "boom"
+type panickyParser struct {
+	parser.Parser
+}
+
+func (p panickyParser) Parse(reader text.Reader, opts ...parser.ParseOption) ast.Node {
+	if strings.Contains(string(reader.Source()), "boom") {
+		panic("boom")
+	}
+	return p.Parser.Parse(reader, opts...)
+}
+
+func TestMarkdownFieldParser_ParseDocumentsParallel(t *testing.T) {
+	contents := make([]string, 50)
+	for i := range contents {
+		contents[i] = fmt.Sprintf("# Doc %d\n\nBody of **document** %d", i, i)
+	}
+	contents[17] = "# boom"
+
+	p := NewMarkdownFieldParser(WithGoldmarkParser(panickyParser{goldmark.DefaultParser()}))
+	for _, workers := range []int{0, 1, 4, 100} {
+		t.Run(fmt.Sprint(workers), func(t *testing.T) {
+			results := p.ParseDocumentsParallel(contents, workers)
+			if len(results) != len(contents) {
+				t.Fatalf("got %d results, want %d", len(results), len(contents))
+			}
+			for i, result := range results {
+				if i == 17 {
+					if result.Err == nil || !strings.Contains(result.Err.Error(), "document 17") {
+						t.Errorf("result 17 error = %v, want a parse failure", result.Err)
+					}
+					continue
+				}
+				if result.Err != nil {
+					t.Errorf("result %d error = %v", i, result.Err)
+				}
+				if want := p.ParseDocuments(contents[i : i+1])[0]; !reflect.DeepEqual(result.Document.Fields, want.Fields) || result.Document.ID != i {
+					t.Errorf("result %d = %+v, want fields %+v", i, result.Document, want.Fields)
+				}
+			}
+		})
+	}
+
+	if results := p.ParseDocumentsParallel(nil, 0); len(results) != 0 {
+		t.Errorf("got %d results for no documents", len(results))
+	}
+}
+
+func TestMarkdownFieldParser_ParseDocumentsParallel_FrontMatter(t *testing.T) {
+	contents := []string{
+		"---\ntitle: Habeas Corpus\n---\n# Heading",
+		"---\ntitle: [unclosed\n---\n# Heading",
+		"+++\ntitle = \"unterminated\n+++\n# Heading",
+	}
+
+	results := NewMarkdownFieldParser().ParseDocumentsParallel(contents, 2)
+	if results[0].Err != nil {
+		t.Errorf("result 0 error = %v, want nil", results[0].Err)
+	}
+	for i := 1; i < len(results); i++ {
+		result := results[i]
+		if result.Err == nil || !strings.Contains(result.Err.Error(), fmt.Sprintf("document %d", i)) ||
+			!strings.Contains(result.Err.Error(), "front matter") {
+			t.Errorf("result %d error = %v, want a front matter error", i, result.Err)
+		}
+		// the rest of the document is still parsed
+		if got := result.Document.Fields[FieldH1]; got != "Heading" {
+			t.Errorf("result %d H1 = %q, want %q", i, got, "Heading")
+		}
+	}
+}
+
+func TestWithParserLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	p := NewMarkdownFieldParser(WithParserLogger(logger))
+	p.ParseDocument("---\ntitle: [unclosed\n---\n# Heading")
+	p.ParseDocumentsParallel([]string{"# One", "# Two"}, 2)
+	for _, want := range []string{"ignoring malformed front matter", "Parsed BM25md documents"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("log is missing %q", want)
+		}
+	}
+}
+
 func TestMarkdownFieldParser_ComplexDocument(t *testing.T) {
 	parser := NewMarkdownFieldParser()
 
@@ -284,6 +382,539 @@ Check doneness by inserting a sword.
 	}
 }
 
+func TestMarkdownFieldParser_ParserMode(t *testing.T) {
+	tests := []struct {
+		name           string
+		input          string
+		wantCommonMark string
+		wantGFM        string
+	}{
+		{
+			name:           "table",
+			input:          "| Writ | Origin |\n|---|---|\n| habeas | England |",
+			wantCommonMark: "| Writ | Origin | |---|---| | habeas | England |",
+			wantGFM:        "", // cells go to FieldTable
+		},
+		{
+			name:           "strikethrough",
+			input:          "The ~~royal~~ writ",
+			wantCommonMark: "The ~~royal~~ writ",
+			wantGFM:        "The royal writ",
+		},
+		{
+			name:           "task list",
+			input:          "- [x] file petition\n- [ ] await hearing",
+			wantCommonMark: "[ x ] file petition [ ] await hearing",
+			wantGFM:        "file petition await hearing",
+		},
+		{
+			name:           "autolinks",
+			input:          "See <https://example.com/writ> and www.example.org today",
+			wantCommonMark: "See https://example.com/writ and www.example.org today",
+			wantGFM:        "See https://example.com/writ and www.example.org today",
+		},
+	}
+
+	commonMark := NewMarkdownFieldParser(WithParserMode(ModeCommonMark))
+	gfm := NewMarkdownFieldParser(WithParserMode(ModeGFM))
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := normalizeWhitespace(commonMark.ParseDocument(tt.input)[FieldBody]); got != tt.wantCommonMark {
+				t.Errorf("CommonMark body = %q, want %q", got, tt.wantCommonMark)
+			}
+			if got := normalizeWhitespace(gfm.ParseDocument(tt.input)[FieldBody]); got != tt.wantGFM {
+				t.Errorf("GFM body = %q, want %q", got, tt.wantGFM)
+			}
+		})
+	}
+}
+
+func TestMarkdownFieldParser_GoldmarkOptions(t *testing.T) {
+	input := "The writ[^1] issued.\n\n| Writ |\n|---|\n| habeas |\n\n[^1]: Granted in 1679."
+
+	tests := []struct {
+		name      string
+		parser    *MarkdownFieldParser
+		wantBody  string
+		wantTable string
+	}{
+		{
+			name:     "default",
+			parser:   NewMarkdownFieldParser(),
+			wantBody: "The writ[ ^1 ] issued. | Writ | |---| | habeas | [ ^1 ]: Granted in 1679.",
+		},
+		{
+			name:      "extensions",
+			parser:    NewMarkdownFieldParser(WithGoldmarkExtensions(extension.Footnote, extension.Table)),
+			wantBody:  "The writ issued. Granted in 1679.",
+			wantTable: "Writ habeas",
+		},
+		{
+			name: "injected parser",
+			parser: NewMarkdownFieldParser(
+				WithGoldmarkParser(goldmark.New(goldmark.WithExtensions(extension.GFM, extension.Footnote)).Parser()),
+			),
+			wantBody:  "The writ issued. Granted in 1679.",
+			wantTable: "Writ habeas",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fields := tt.parser.ParseDocument(input)
+			if got := normalizeWhitespace(fields[FieldBody]); got != tt.wantBody {
+				t.Errorf("body = %q, want %q", got, tt.wantBody)
+			}
+			if got := normalizeWhitespace(fields[FieldTable]); got != tt.wantTable {
+				t.Errorf("table = %q, want %q", got, tt.wantTable)
+			}
+		})
+	}
+}
+
+func TestMarkdownFieldParser_NodeFields(t *testing.T) {
+	quote := Field("quote")
+	identifier := Field("identifier")
+	parser := NewMarkdownFieldParser(
+		WithNodeField(ast.KindBlockquote, quote),
+		WithNodeField(ast.KindCodeSpan, identifier),
+	)
+
+	fields := parser.ParseDocument("Filed `habeas` today.\n\n> The writ shall **not** be suspended.")
+
+	expected := map[Field]string{
+		FieldBody:  "Filed today.",
+		quote:      "The writ shall not be suspended.",
+		identifier: "habeas",
+	}
+	for field, want := range expected {
+		if got := normalizeWhitespace(fields[field]); got != want {
+			t.Errorf("%s = %q, want %q", field, got, want)
+		}
+	}
+	// mapped nodes are handled whole, so nested emphasis and code spans are not split out
+	for _, field := range []Field{FieldBold, FieldCode} {
+		if fields[field] != "" {
+			t.Errorf("%s = %q, want empty", field, fields[field])
+		}
+	}
+}
+
+func TestMarkdownFieldParser_BlockFields(t *testing.T) {
+	input := "Intro text.\n\n> Quoted **ruling** here.\n\n- File within thirty days\n- Serve the respondent\n\n" +
+		"> - quoted item"
+
+	tests := []struct {
+		name     string
+		opts     []ParserOption
+		expected map[Field]string
+	}{
+		{
+			name: "disabled",
+			expected: map[Field]string{
+				FieldBody: "Intro text. Quoted here. File within thirty days Serve the respondent quoted item",
+				FieldBold: "ruling",
+			},
+		},
+		{
+			name: "blockquotes",
+			opts: []ParserOption{WithBlockquoteField()},
+			expected: map[Field]string{
+				FieldBody:       "Intro text. File within thirty days Serve the respondent",
+				FieldBlockquote: "Quoted here. quoted item",
+				FieldBold:       "ruling",
+			},
+		},
+		{
+			name: "lists",
+			opts: []ParserOption{WithListField()},
+			expected: map[Field]string{
+				FieldBody: "Intro text. Quoted here.",
+				FieldList: "File within thirty days Serve the respondent quoted item",
+				FieldBold: "ruling",
+			},
+		},
+		{
+			name: "both, innermost wins",
+			opts: []ParserOption{WithBlockquoteField(), WithListField()},
+			expected: map[Field]string{
+				FieldBody:       "Intro text.",
+				FieldBlockquote: "Quoted here.",
+				FieldList:       "File within thirty days Serve the respondent quoted item",
+				FieldBold:       "ruling",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fields := NewMarkdownFieldParser(tt.opts...).ParseDocument(input)
+			for _, field := range []Field{FieldBody, FieldBlockquote, FieldList, FieldBold} {
+				if got := normalizeWhitespace(fields[field]); got != tt.expected[field] {
+					t.Errorf("%s = %q, want %q", field, got, tt.expected[field])
+				}
+			}
+		})
+	}
+}
+
+func TestMarkdownFieldParser_AltText(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "alt text",
+			input:    "See ![Court *org* chart](chart.png) below.",
+			expected: "Court org chart",
+		},
+		{
+			name:     "title",
+			input:    "![Sequence diagram](flow.svg \"Petition lifecycle\")",
+			expected: "Sequence diagram Petition lifecycle",
+		},
+		{
+			name:     "linked image",
+			input:    "[![Build status](badge.svg)](https://ci.example.com)",
+			expected: "Build status",
+		},
+		{
+			name:     "html image",
+			input:    "<p><img src=\"seal.png\" alt=\"Court seal\" title=\"Official seal\"/></p>",
+			expected: "Court seal Official seal",
+		},
+		{
+			name:     "inline html image",
+			input:    "The <img src=\"seal.png\" alt=\"seal\"> of the court",
+			expected: "seal",
+		},
+	}
+
+	parser := NewMarkdownFieldParser()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fields := parser.ParseDocument(tt.input)
+			if got := normalizeWhitespace(fields[FieldAltText]); got != tt.expected {
+				t.Errorf("alt = %q, want %q", got, tt.expected)
+			}
+			for _, word := range strings.Fields(tt.expected) {
+				if strings.Contains(fields[FieldBody], word) {
+					t.Errorf("body %q contains alt text %q", fields[FieldBody], word)
+				}
+			}
+		})
+	}
+}
+
+func TestMarkdownFieldParser_Strikethrough(t *testing.T) {
+	input := "The ~~royal~~ federal writ"
+
+	tests := []struct {
+		name       string
+		opts       []ParserOption
+		wantBody   string
+		wantStrike string
+	}{
+		{
+			name:     "commonmark default",
+			wantBody: "The ~~royal~~ federal writ",
+		},
+		{
+			name:     "gfm default",
+			opts:     []ParserOption{WithParserMode(ModeGFM)},
+			wantBody: "The royal federal writ",
+		},
+		{
+			name:       "field",
+			opts:       []ParserOption{WithStrikethrough(StrikethroughField)},
+			wantBody:   "The federal writ",
+			wantStrike: "royal",
+		},
+		{
+			name:     "exclude",
+			opts:     []ParserOption{WithParserMode(ModeGFM), WithStrikethrough(StrikethroughExclude)},
+			wantBody: "The federal writ",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fields := NewMarkdownFieldParser(tt.opts...).ParseDocument(input)
+			if got := normalizeWhitespace(fields[FieldBody]); got != tt.wantBody {
+				t.Errorf("body = %q, want %q", got, tt.wantBody)
+			}
+			if got := fields[FieldStrikethrough]; got != tt.wantStrike {
+				t.Errorf("strikethrough = %q, want %q", got, tt.wantStrike)
+			}
+		})
+	}
+}
+
+func TestMarkdownFieldParser_Tables(t *testing.T) {
+	input := "Writs by origin:\n\n| Writ | Origin |\n|---|---|\n| **habeas** | `England` |\n| mandamus | Rome |"
+
+	tests := []struct {
+		name      string
+		opts      []ParserOption
+		wantTable string
+		wantBody  string
+	}{
+		{
+			name:     "commonmark leaves tables as text",
+			wantBody: "Writs by origin: | Writ | Origin | |---|---| | | | | mandamus | Rome |",
+		},
+		{
+			name:      "with tables",
+			opts:      []ParserOption{WithTables()},
+			wantTable: "Writ Origin habeas England mandamus Rome",
+			wantBody:  "Writs by origin:",
+		},
+		{
+			name:      "gfm mode",
+			opts:      []ParserOption{WithParserMode(ModeGFM), WithTables()},
+			wantTable: "Writ Origin habeas England mandamus Rome",
+			wantBody:  "Writs by origin:",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := NewMarkdownFieldParser(tt.opts...).ParseDocument(input)
+			if got := normalizeWhitespace(result[FieldTable]); got != tt.wantTable {
+				t.Errorf("Table field = %q, want %q", got, tt.wantTable)
+			}
+			if got := normalizeWhitespace(result[FieldBody]); got != tt.wantBody {
+				t.Errorf("Body field = %q, want %q", got, tt.wantBody)
+			}
+		})
+	}
+
+	// each cell is its own occurrence pointing at the cell text
+	occurrences := NewMarkdownFieldParser(WithTables()).ParseOccurrences(input)
+	if len(occurrences[FieldTable]) != 6 {
+		t.Fatalf("got %d table occurrences, want 6", len(occurrences[FieldTable]))
+	}
+	for _, occ := range occurrences[FieldTable] {
+		if occ.Start < 0 || !strings.Contains(input[occ.Start:occ.End], occ.Text) {
+			t.Errorf("occurrence %q has span [%d, %d)", occ.Text, occ.Start, occ.End)
+		}
+	}
+}
+
+func TestMarkdownFieldParser_Links(t *testing.T) {
+	input := "Read [the habeas guide](https://example.com/docs/habeas-corpus.html?lang=en#filing) " +
+		"or [appeals][ref], and see <https://www.courts.gov/appeal_rules>.\n\n[ref]: /guides/appeals"
+
+	tests := []struct {
+		name     string
+		opts     []ParserOption
+		wantLink string
+		wantURL  string
+	}{
+		{
+			name:     "raw urls",
+			wantLink: "the habeas guide appeals",
+			wantURL:  "https://example.com/docs/habeas-corpus.html?lang=en#filing /guides/appeals https://www.courts.gov/appeal_rules",
+		},
+		{
+			name:     "url segments",
+			opts:     []ParserOption{WithURLSegments()},
+			wantLink: "the habeas guide appeals",
+			wantURL:  "example com docs habeas corpus guides appeals courts gov appeal rules",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := NewMarkdownFieldParser(tt.opts...).ParseDocument(input)
+			if got := normalizeWhitespace(result[FieldLink]); got != tt.wantLink {
+				t.Errorf("Link field = %q, want %q", got, tt.wantLink)
+			}
+			if got := normalizeWhitespace(result[FieldURL]); got != tt.wantURL {
+				t.Errorf("URL field = %q, want %q", got, tt.wantURL)
+			}
+			// anchor text still reads naturally in body
+			if !strings.Contains(result[FieldBody], "the habeas guide") {
+				t.Errorf("Body field = %q, want anchor text kept", result[FieldBody])
+			}
+		})
+	}
+}
+
+func TestMarkdownFieldParser_ExcludedCodeLanguages(t *testing.T) {
+	input := "Architecture:\n```mermaid\ngraph TD\n  subgraph court\n  end\n```\n" +
+		"```{.plantuml}\n@startuml\n@enduml\n```\n```go\nfunc main() {}\n```"
+
+	parser := NewMarkdownFieldParser(WithExcludedCodeLanguages(DiagramLanguages...))
+	result := parser.ParseDocument(input)
+	if got := normalizeWhitespace(result[FieldCode]); got != "func main() {}" {
+		t.Errorf("Code field = %q, want %q", got, "func main() {}")
+	}
+	if got := normalizeWhitespace(result[FieldBody]); got != "Architecture:" {
+		t.Errorf("Body field = %q, want %q", got, "Architecture:")
+	}
+
+	// diagrams are indexed when no languages are excluded
+	result = NewMarkdownFieldParser().ParseDocument(input)
+	if !strings.Contains(result[FieldCode], "subgraph") {
+		t.Errorf("Code field = %q, want diagram source by default", result[FieldCode])
+	}
+}
+
+func TestMarkdownFieldParser_FrontMatter(t *testing.T) {
+	input := "---\ntitle: Filing Guide\ntags: [appeals, deadlines]\ndescription: How to file\ndraft: true\n---\n# Overview\n\nFile early."
+
+	tests := []struct {
+		name     string
+		opts     []ParserOption
+		expected map[Field]string
+	}{
+		{
+			name: "default mapping",
+			expected: map[Field]string{
+				FieldTitle:       "Filing Guide",
+				FieldTags:        "appeals deadlines",
+				FieldDescription: "How to file",
+				FieldH1:          "Overview",
+				FieldBody:        "File early.",
+			},
+		},
+		{
+			name: "custom mapping",
+			opts: []ParserOption{WithFrontMatterFields(map[string]Field{"title": FieldH1})},
+			expected: map[Field]string{
+				FieldTitle: "",
+				FieldTags:  "",
+				FieldH1:    "Filing Guide Overview",
+				FieldBody:  "File early.",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := NewMarkdownFieldParser(tt.opts...).ParseDocument(input)
+			for field, expected := range tt.expected {
+				if got := normalizeWhitespace(result[field]); got != expected {
+					t.Errorf("%s field = %q, want %q", field, got, expected)
+				}
+			}
+		})
+	}
+
+	// occurrences point at the front matter values, and the body keeps its offsets
+	occurrences := NewMarkdownFieldParser().ParseOccurrences(input)
+	for _, field := range []Field{FieldTitle, FieldH1} {
+		for _, occ := range occurrences[field] {
+			if span := input[occ.Start:occ.End]; !strings.Contains(span, occ.Text) {
+				t.Errorf("%s occurrence span = %q, want it to contain %q", field, span, occ.Text)
+			}
+		}
+	}
+}
+
+func TestMarkdownFieldParser_ParseOccurrences(t *testing.T) {
+	parser := NewMarkdownFieldParser()
+	input := "# Guide\n\n## Filing\n\nFile **early** and **often**.\n\n## Appeals\n\nSee `appeal.go`.\n\n```\nreturn nil\n```"
+
+	occurrences := parser.ParseOccurrences(input)
+
+	tests := []struct {
+		field    Field
+		expected []string
+	}{
+		{FieldH1, []string{"Guide"}},
+		{FieldH2, []string{"Filing", "Appeals"}},
+		{FieldBold, []string{"early", "often"}},
+		{FieldCode, []string{"appeal.go", "return nil"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.field), func(t *testing.T) {
+			occs := occurrences[tt.field]
+			if len(occs) != len(tt.expected) {
+				t.Fatalf("got %d occurrences, want %d: %+v", len(occs), len(tt.expected), occs)
+			}
+			for i, occ := range occs {
+				if occ.Text != tt.expected[i] {
+					t.Errorf("occurrence %d text = %q, want %q", i, occ.Text, tt.expected[i])
+				}
+				if occ.Start < 0 || occ.End > len(input) || occ.Start > occ.End {
+					t.Fatalf("occurrence %d has invalid span [%d, %d)", i, occ.Start, occ.End)
+				}
+				if span := input[occ.Start:occ.End]; !strings.Contains(span, tt.expected[i]) {
+					t.Errorf("occurrence %d span = %q, want it to contain %q", i, span, tt.expected[i])
+				}
+			}
+		})
+	}
+
+	// joined occurrences match ParseDocument
+	joined := JoinOccurrences(occurrences)
+	fields := parser.ParseDocument(input)
+	for field, content := range fields {
+		if joined[field] != content {
+			t.Errorf("joined field %s = %q, want %q", field, joined[field], content)
+		}
+	}
+}
+
+// basic markdown parser tests
+func TestMarkdownFieldParser_BasicFields(t *testing.T) {
+	parser := NewMarkdownFieldParser()
+
+	markdown := `# Main Title
+This is **bold** and *italic* text.
+Here is some ` + "`code`" + ` and regular content.`
+
+	fields := parser.ParseDocument(markdown)
+
+	if fields[FieldH1] != "Main Title" {
+		t.Errorf("H1 field = %q, want %q", fields[FieldH1], "Main Title")
+	}
+	if fields[FieldBold] != "bold" {
+		t.Errorf("Bold field = %q, want %q", fields[FieldBold], "bold")
+	}
+	if fields[FieldItalic] != "italic" {
+		t.Errorf("Italic field = %q, want %q", fields[FieldItalic], "italic")
+	}
+	if fields[FieldCode] != "code" {
+		t.Errorf("Code field = %q, want %q", fields[FieldCode], "code")
+	}
+	if len(fields[FieldBody]) == 0 {
+		t.Error("Body field should not be empty")
+	}
+}
+
+func TestMarkdownFieldParser_NestedFormatting(t *testing.T) {
+	parser := NewMarkdownFieldParser()
+
+	markdown := `This is **bold _italic_** text.`
+	fields := parser.ParseDocument(markdown)
+
+	// parser extracts nested content with spaces preserved
+	if fields[FieldBold] != "bold  italic" {
+		t.Errorf("Bold field = %q, want %q", fields[FieldBold], "bold  italic")
+	}
+	// italicized content appears within bold, may not be separately extracted
+	if len(fields[FieldItalic]) > 0 && fields[FieldItalic] != "italic" {
+		t.Errorf("Italic field = %q, want %q", fields[FieldItalic], "italic")
+	}
+}
+
+func TestMarkdownFieldParser_CodeBlocks(t *testing.T) {
+	parser := NewMarkdownFieldParser()
+
+	markdown := "```go\nfmt.Println(\"hello\")\n```"
+	fields := parser.ParseDocument(markdown)
+
+	if fields[FieldCode] != "fmt.Println(\"hello\")" {
+		t.Errorf("Code field = %q, want %q", fields[FieldCode], "fmt.Println(\"hello\")")
+	}
+}
+
 // normalizeWhitespace helps with test comparisons by normalizing whitespace
 func normalizeWhitespace(s string) string {
 	// replace multiple spaces with single space
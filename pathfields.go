@@ -0,0 +1,39 @@
+package bm25md
+
+import (
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// PathFields returns the file name (without extension) and directory of a file
+// path as FieldFileName and FieldPath text, split into words at path separators,
+// dots, dashes, and underscores ("notes/court-filings/habeas_corpus.md" gives
+// filename "habeas corpus" and path "notes court filings"). Empty fields are omitted
+func PathFields(p string) map[Field]string {
+	p = path.Clean(filepath.ToSlash(p))
+	fields := make(map[Field]string, 2)
+
+	name := path.Base(p)
+	name = strings.TrimSuffix(name, path.Ext(name))
+	if words := pathWords(name); words != "" {
+		fields[FieldFileName] = words
+	}
+	if dir := path.Dir(p); dir != "." && dir != "/" {
+		if words := pathWords(dir); words != "" {
+			fields[FieldPath] = words
+		}
+	}
+	return fields
+}
+
+// pathWords splits a path into space-separated words
+func pathWords(p string) string {
+	return strings.Join(strings.FieldsFunc(p, func(r rune) bool {
+		switch r {
+		case '/', '.', '-', '_', ' ':
+			return true
+		}
+		return false
+	}), " ")
+}
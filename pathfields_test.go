@@ -0,0 +1,27 @@
+package bm25md
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPathFields(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected map[Field]string
+	}{
+		{"habeas-corpus.md", map[Field]string{FieldFileName: "habeas corpus"}},
+		{"notes/court-filings/habeas_corpus.md", map[Field]string{FieldFileName: "habeas corpus", FieldPath: "notes court filings"}},
+		{"/var/notes/v1.2/README", map[Field]string{FieldFileName: "README", FieldPath: "var notes v1 2"}},
+		{"./drafts/appeal.md", map[Field]string{FieldFileName: "appeal", FieldPath: "drafts"}},
+		{".md", map[Field]string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if got := PathFields(tt.path); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("PathFields(%q) = %v, want %v", tt.path, got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,203 @@
+package bm25md
+
+import (
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// indexFormatVersion is bumped whenever the saved index layout changes
+const indexFormatVersion = 3
+
+// indexMagic identifies a saved bm25md index
+const indexMagic = "bm25md-index"
+
+func init() {
+	// common metadata value types held in interfaces (eg decoded front matter)
+	gob.Register([]any{})
+	gob.Register(map[string]any{})
+	gob.Register([]string{})
+	gob.Register(time.Time{})
+}
+
+// indexHeader precedes the saved index so incompatible files fail fast
+type indexHeader struct {
+	Magic   string
+	Version int
+}
+
+// savedField is the persisted state of a field scorer
+type savedField struct {
+	Weight          float64
+	Params          BM25Parameters
+	TermFrequencies []map[string]int
+	Positions       []map[string][]int
+	DocFrequencies  map[string]int
+	DocLengths      []int
+	AvgDocLength    float64
+	TotalDocs       int
+}
+
+// savedIndex is the persisted state of a corpus
+type savedIndex struct {
+	Documents    []Document
+	Deleted      map[int]bool
+	FieldWeights map[Field]float64
+	Params       BM25Parameters
+	FieldParams  map[Field]BM25Parameters
+	MaxTokens    int
+	TokenLimits  map[Field]int
+	Stopwords    map[string]bool
+	Fields       map[Field]savedField
+	Feedback     map[int][2]int // positive and negative counts per document
+	EdgeNGrams   [2]int         // minimum and maximum n-gram length (zero = none)
+	Shingles     [2]int         // minimum and maximum shingle words (zero = none)
+	Similarity   string         // describes the ranking function, see similarityName
+}
+
+// Save serializes the index (documents, field statistics, and scoring settings,
+// including edge n-gram and shingle sizes) so it can be reloaded with LoadCorpus
+// instead of re-parsing and re-tokenizing. The tokenizer, hooks, and calibrator
+// are not saved and must be supplied on load. Nor is the similarity, which may be
+// any type, but it is recorded so loading with a different one fails
+func (c *Corpus) Save(w io.Writer) error {
+	index := savedIndex{
+		Documents:    c.documents,
+		Deleted:      c.deleted,
+		FieldWeights: c.fieldWeights,
+		Params:       c.params,
+		FieldParams:  c.fieldParams,
+		MaxTokens:    c.maxTokens,
+		TokenLimits:  c.tokenLimits,
+		Stopwords:    c.stopwords,
+		Fields:       make(map[Field]savedField, len(c.fieldScorers)),
+		EdgeNGrams:   [2]int{c.edgeNGrams.min, c.edgeNGrams.max},
+		Shingles:     [2]int{c.shingles.min, c.shingles.max},
+		Similarity:   similarityName(c.similarity),
+	}
+	for field, scorer := range c.fieldScorers {
+		// term vectors are saved as plain maps, keeping the format independent of term IDs
+		termFrequencies := make([]map[string]int, len(scorer.termFrequencies))
+		for i := range termFrequencies {
+			termFrequencies[i] = scorer.termMap(i)
+		}
+		index.Fields[field] = savedField{
+			Weight:          scorer.weight,
+			Params:          scorer.params,
+			TermFrequencies: termFrequencies,
+			Positions:       scorer.positions,
+			DocFrequencies:  scorer.docFrequencies,
+			DocLengths:      scorer.docLengths,
+			AvgDocLength:    scorer.avgDocLength,
+			TotalDocs:       scorer.totalDocs,
+		}
+	}
+
+	c.feedbackMu.RLock()
+	if len(c.feedback) > 0 {
+		index.Feedback = make(map[int][2]int, len(c.feedback))
+		for docID, counts := range c.feedback {
+			index.Feedback[docID] = [2]int{counts.positive, counts.negative}
+		}
+	}
+	c.feedbackMu.RUnlock()
+
+	encoder := gob.NewEncoder(w)
+	if err := encoder.Encode(indexHeader{Magic: indexMagic, Version: indexFormatVersion}); err != nil {
+		return fmt.Errorf("bm25md: writing index header: %w", err)
+	}
+	if err := encoder.Encode(index); err != nil {
+		return fmt.Errorf("bm25md: writing index: %w", err)
+	}
+	return nil
+}
+
+// LoadCorpus reads an index written by Corpus.Save. Index settings (field weights,
+// BM25 parameters, token limits, stopwords, edge n-grams, and shingles) come from
+// the saved index; options supply settings that are not saved, such as the
+// tokenizer, which must match the one used to build it. The similarity must match
+// too: an index saved with WithSimilarity must be loaded with an equal one
+func LoadCorpus(r io.Reader, opts ...CorpusOption) (*Corpus, error) {
+	decoder := gob.NewDecoder(r)
+
+	var header indexHeader
+	if err := decoder.Decode(&header); err != nil {
+		return nil, fmt.Errorf("bm25md: reading index header: %w", err)
+	}
+	if header.Magic != indexMagic {
+		return nil, errors.New("bm25md: not a bm25md index")
+	}
+	if header.Version != indexFormatVersion {
+		return nil, fmt.Errorf("bm25md: unsupported index version %d (want %d)", header.Version, indexFormatVersion)
+	}
+
+	var index savedIndex
+	if err := decoder.Decode(&index); err != nil {
+		return nil, fmt.Errorf("bm25md: reading index: %w", err)
+	}
+
+	corpus := NewCorpus(opts...)
+	if name := similarityName(corpus.similarity); name != index.Similarity {
+		return nil, fmt.Errorf("bm25md: index was saved with similarity %s, not %s", index.Similarity, name)
+	}
+	corpus.documents = index.Documents
+	if corpus.documents == nil {
+		corpus.documents = make([]Document, 0)
+	}
+	corpus.deleted = index.Deleted
+	corpus.fieldWeights = index.FieldWeights
+	corpus.params = index.Params
+	corpus.fieldParams = index.FieldParams
+	corpus.maxTokens = index.MaxTokens
+	corpus.tokenLimits = index.TokenLimits
+	if index.Stopwords != nil {
+		corpus.stopwords = index.Stopwords
+	}
+	corpus.edgeNGrams = edgeNGramSize{min: index.EdgeNGrams[0], max: index.EdgeNGrams[1]}
+	corpus.shingles = shingleSize{min: index.Shingles[0], max: index.Shingles[1]}
+
+	corpus.fieldScorers = make(map[Field]*fieldBM25, len(index.Fields))
+	vocab := newVocabulary()
+	for field, saved := range index.Fields {
+		if len(saved.TermFrequencies) != len(corpus.documents) || len(saved.Positions) != len(corpus.documents) ||
+			len(saved.DocLengths) != len(corpus.documents) {
+			return nil, fmt.Errorf("bm25md: corrupt index: field %s has %d entries for %d documents", field, len(saved.TermFrequencies), len(corpus.documents))
+		}
+
+		// re-store each slot so terms are interned (this also recounts doc frequencies)
+		scorer := newFieldBM25(field, saved.Weight, saved.Params, vocab)
+		scorer.termFrequencies = make([]termVector, len(corpus.documents))
+		scorer.positions = make([]map[string][]int, len(corpus.documents))
+		scorer.docLengths = make([]int, len(corpus.documents))
+		for i := range corpus.documents {
+			scorer.storeDocument(i, fieldAnalysis{tf: saved.TermFrequencies[i], positions: saved.Positions[i], length: saved.DocLengths[i]})
+			scorer.totalLength += saved.DocLengths[i]
+			if saved.DocLengths[i] > 0 {
+				scorer.fieldDocs++
+			}
+		}
+		scorer.avgDocLength = saved.AvgDocLength
+		scorer.totalDocs = saved.TotalDocs
+		corpus.fieldScorers[field] = scorer
+	}
+
+	corpus.rebuildPostings()
+	corpus.rebuildExternalIDs()
+
+	if len(index.Feedback) > 0 {
+		corpus.feedback = make(map[int]feedbackCounts, len(index.Feedback))
+		for docID, counts := range index.Feedback {
+			corpus.feedback[docID] = feedbackCounts{positive: counts[0], negative: counts[1]}
+		}
+	}
+
+	return corpus, nil
+}
+
+// similarityName describes a similarity by its type and parameters, eg
+// "bm25md.BM25LSimilarity{Delta:0.5}"
+func similarityName(similarity Similarity) string {
+	return fmt.Sprintf("%T%+v", similarity, similarity)
+}
@@ -0,0 +1,110 @@
+package bm25md
+
+import (
+	"bytes"
+	"encoding/gob"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCorpus_SaveLoad(t *testing.T) {
+	corpus, docs := createTestCorpus()
+	corpus.documents[0].Metadata = map[string]any{"path": "poem.md", "tags": []any{"villanelle"}}
+	_ = corpus.RemoveDocument(2)
+	_ = corpus.RecordFeedback("dead", 0, true)
+
+	var buf bytes.Buffer
+	if err := corpus.Save(&buf); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	loaded, err := LoadCorpus(&buf)
+	if err != nil {
+		t.Fatalf("LoadCorpus() error = %v", err)
+	}
+
+	for _, query := range []string{"dead world", "stars waltzing", "think made"} {
+		for i := range docs {
+			if got, want := loaded.Score(query, i), corpus.Score(query, i); math.Abs(got-want) > 1e-9 {
+				t.Errorf("Score(%q, %d) = %v, want %v", query, i, got, want)
+			}
+		}
+	}
+
+	if got := loaded.Documents()[0].Metadata["path"]; got != "poem.md" {
+		t.Errorf("metadata path = %v, want poem.md", got)
+	}
+	if len(loaded.Documents()) != len(docs)-1 {
+		t.Errorf("loaded %d documents, want %d", len(loaded.Documents()), len(docs)-1)
+	}
+
+	// the loaded corpus keeps accepting documents
+	loaded.AddDocument(Document{Fields: map[Field]string{FieldBody: "Waltzing stars again"}})
+	if results := loaded.Search("waltzing", 10); len(results) != 2 {
+		t.Errorf("Search() after AddDocument returned %d results, want 2", len(results))
+	}
+}
+
+func TestCorpus_SaveLoad_Analysis(t *testing.T) {
+	corpus := NewCorpus(WithEdgeNGrams(2, 6), WithShingles(2, 2), WithSimilarity(BM25LSimilarity{}))
+	for _, body := range []string{"the constitution of the state", "habeas corpus review", "filing deadlines"} {
+		corpus.AddDocument(Document{Fields: map[Field]string{FieldBody: body}})
+	}
+	var buf bytes.Buffer
+	if err := corpus.Save(&buf); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	saved := buf.Bytes()
+
+	if _, err := LoadCorpus(bytes.NewReader(saved)); err == nil || !strings.Contains(err.Error(), "similarity") {
+		t.Errorf("LoadCorpus() with another similarity error = %v", err)
+	}
+
+	// n-gram and shingle sizes come from the index, not the load options
+	loaded, err := LoadCorpus(bytes.NewReader(saved), WithSimilarity(BM25LSimilarity{}))
+	if err != nil {
+		t.Fatalf("LoadCorpus() error = %v", err)
+	}
+	if loaded.edgeNGrams != corpus.edgeNGrams || loaded.shingles != corpus.shingles {
+		t.Errorf("loaded n-grams %v and shingles %v, want %v and %v", loaded.edgeNGrams, loaded.shingles, corpus.edgeNGrams, corpus.shingles)
+	}
+	if got, want := loaded.CompleteTerm("con", 5), corpus.CompleteTerm("con", 5); !reflect.DeepEqual(got, want) {
+		t.Errorf("CompleteTerm(con) after load = %+v, want %+v", got, want)
+	}
+
+	// documents added after loading are indexed like the saved ones
+	loaded.AddDocument(Document{Fields: map[Field]string{FieldBody: "constitutional review"}})
+	if results := loaded.Search("consti", 10); len(results) != 2 {
+		t.Errorf("Search(consti) after AddDocument returned %d results, want 2", len(results))
+	}
+}
+
+func TestLoadCorpus_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		header indexHeader
+		errMsg string
+	}{
+		{"wrong magic", indexHeader{Magic: "other", Version: indexFormatVersion}, "not a bm25md index"},
+		{"future version", indexHeader{Magic: indexMagic, Version: indexFormatVersion + 1}, "unsupported index version"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := gob.NewEncoder(&buf).Encode(tt.header); err != nil {
+				t.Fatal(err)
+			}
+			_, err := LoadCorpus(&buf)
+			if err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+				t.Errorf("LoadCorpus() error = %v, want %q", err, tt.errMsg)
+			}
+		})
+	}
+
+	if _, err := LoadCorpus(strings.NewReader("garbage")); err == nil {
+		t.Error("LoadCorpus() of garbage should fail")
+	}
+}
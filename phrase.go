@@ -0,0 +1,117 @@
+package bm25md
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// preparePhrase resolves a phrase to the documents and fields where its tokens
+// appear consecutively (or, for NEAR, within near positions); the phrase is then
+// scored like a single term
+func (c *Corpus) preparePhrase(tokens []string, near int, occur occur) (queryTerm, bool) {
+	// only documents containing every token can contain the phrase
+	lists := make([]postingList, len(tokens))
+	for i, token := range tokens {
+		lists[i] = c.postings[token]
+		if len(lists[i]) == 0 {
+			return queryTerm{occur: occur}, false
+		}
+	}
+	sort.Slice(lists, func(i, j int) bool { return len(lists[i]) < len(lists[j]) })
+
+	postings := make(postingList)
+	for docIndex := range lists[0] {
+		for field, scorer := range c.fieldScorers {
+			if freq := scorer.phraseFrequency(tokens, near, docIndex); freq > 0 {
+				if postings[docIndex] == nil {
+					postings[docIndex] = make(map[Field]int, 1)
+				}
+				postings[docIndex][field] = freq
+			}
+		}
+	}
+
+	if len(postings) == 0 {
+		return queryTerm{occur: occur}, false
+	}
+	return c.newQueryTerm(phraseName(tokens, near), postings, occur), true
+}
+
+// phraseName describes a phrase or NEAR clause, eg for TermStats
+func phraseName(tokens []string, near int) string {
+	if near > 0 {
+		return strings.Join(tokens, fmt.Sprintf(" NEAR/%d ", near))
+	}
+	return strings.Join(tokens, " ")
+}
+
+// phraseFrequency counts how often tokens appear consecutively (or within near
+// positions) in a document field
+func (f *fieldBM25) phraseFrequency(tokens []string, near, docIndex int) int {
+	if docIndex >= len(f.positions) {
+		return 0
+	}
+	return phraseFrequency(f.positions[docIndex], tokens, near)
+}
+
+// phraseFrequency counts how often tokens appear consecutively given the token
+// positions of one document field; with near > 0 it counts instead how often
+// they all appear within a span of near positions, in any order
+func phraseFrequency(positions map[string][]int, tokens []string, near int) int {
+	starts := positions[tokens[0]]
+	if len(starts) == 0 {
+		return 0
+	}
+	if near > 0 {
+		return nearFrequency(positions, tokens, near)
+	}
+
+	count := 0
+	for _, start := range starts {
+		matched := true
+		for offset, token := range tokens[1:] {
+			// positions are stored in ascending order
+			list := positions[token]
+			want := start + offset + 1
+			i := sort.SearchInts(list, want)
+			if i == len(list) || list[i] != want {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			count++
+		}
+	}
+	return count
+}
+
+// nearFrequency counts the occurrences of the first token around which every
+// other token appears, all within a window of near+1 positions
+func nearFrequency(positions map[string][]int, tokens []string, near int) int {
+	count := 0
+	for _, start := range positions[tokens[0]] {
+		// try each window [from, from+near] that contains start
+		for from := max(start-near, 0); from <= start; from++ {
+			if withinWindow(positions, tokens[1:], from, from+near) {
+				count++
+				break
+			}
+		}
+	}
+	return count
+}
+
+// withinWindow reports whether every token has a position in [from, to]
+func withinWindow(positions map[string][]int, tokens []string, from, to int) bool {
+	for _, token := range tokens {
+		// positions are stored in ascending order
+		list := positions[token]
+		i := sort.SearchInts(list, from)
+		if i == len(list) || list[i] > to {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,63 @@
+package bm25md
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestCorpus_PhraseSearch(t *testing.T) {
+	corpus := NewCorpus()
+	docs := []string{
+		"the writ of habeas corpus protects liberty",           // phrase
+		"corpus linguistics and the habeas filing",             // both words, not a phrase
+		"habeas corpus habeas corpus is repeated here",         // phrase twice
+		"unrelated appeal rules",                               // neither
+		"the court calendar lists sessions",                    // neither
+		"habeas appears alone in this document without corpus", // both words, apart
+	}
+	for _, body := range docs {
+		corpus.AddDocument(Document{Fields: map[Field]string{FieldBody: body}})
+	}
+
+	results := corpus.Search(`"habeas corpus"`, 10)
+	got := make([]int, len(results))
+	for i, result := range results {
+		got[i] = result.Index
+	}
+	if want := []int{2, 0}; !reflect.DeepEqual(got, want) {
+		t.Errorf("phrase results = %v, want %v", got, want)
+	}
+
+	if score := corpus.Score(`"habeas corpus"`, 1); score != 0 {
+		t.Errorf("Score() of non-adjacent terms = %v, want 0", score)
+	}
+	if corpus.Score("habeas filing", 1) == 0 {
+		t.Error("Score() of unquoted terms = 0, want > 0")
+	}
+
+	// phrases must appear within a single field
+	split := NewCorpus()
+	split.AddDocument(Document{Fields: map[Field]string{FieldH1: "habeas", FieldBody: "corpus"}})
+	split.AddDocument(Document{Fields: map[Field]string{FieldBody: "other text"}})
+	split.AddDocument(Document{Fields: map[Field]string{FieldBody: "more text"}})
+	if results := split.Search(`"habeas corpus"`, 10); len(results) != 0 {
+		t.Errorf("phrase across fields matched: %+v", results)
+	}
+
+	// positions survive updates, compaction, and persistence
+	_ = corpus.UpdateDocument(3, Document{Fields: map[Field]string{FieldBody: "a habeas corpus appeal"}})
+	_ = corpus.RemoveDocument(0)
+	corpus.Compact()
+	var buf bytes.Buffer
+	if err := corpus.Save(&buf); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	loaded, err := LoadCorpus(&buf)
+	if err != nil {
+		t.Fatalf("LoadCorpus() error = %v", err)
+	}
+	if results := loaded.Search(`"habeas corpus"`, 10); len(results) != 2 {
+		t.Errorf("phrase results after reload = %+v, want 2", results)
+	}
+}
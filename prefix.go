@@ -0,0 +1,103 @@
+package bm25md
+
+import (
+	"sort"
+	"strings"
+)
+
+// DefaultMaxPrefixExpansions caps how many index terms a prefix query expands to
+const DefaultMaxPrefixExpansions = 64
+
+// WithMaxPrefixExpansions caps how many index terms a trailing-wildcard query
+// (eg constitu*) expands to; the most common matching terms are kept
+func WithMaxPrefixExpansions(n int) CorpusOption {
+	return func(c *Corpus) {
+		c.maxExpansions = n
+	}
+}
+
+// sortedTerms returns the index terms in sorted order, rebuilding the term
+// dictionary if documents changed since it was last built
+func (c *Corpus) sortedTerms() []string {
+	c.termDictMu.Lock()
+	defer c.termDictMu.Unlock()
+
+	if c.termDictDirty || c.termDict == nil {
+		terms := make([]string, 0, len(c.postings))
+		for term := range c.postings {
+			terms = append(terms, term)
+		}
+		sort.Strings(terms)
+		c.termDict = terms
+		c.termDictDirty = false
+	}
+	return c.termDict
+}
+
+// invalidateTermDict marks the term dictionary stale after terms are added or removed
+func (c *Corpus) invalidateTermDict() {
+	c.termDictMu.Lock()
+	c.termDictDirty = true
+	c.termDictMu.Unlock()
+}
+
+// expandPrefix returns the index terms starting with prefix, keeping the most
+// common ones when there are more than the expansion cap
+func (c *Corpus) expandPrefix(prefix string) []string {
+	terms := c.sortedTerms()
+	first := sort.SearchStrings(terms, prefix)
+	last := first
+	for last < len(terms) && strings.HasPrefix(terms[last], prefix) {
+		last++
+	}
+	return c.capExpansions(append([]string(nil), terms[first:last]...))
+}
+
+// capExpansions keeps the most common of the matched terms when there are more
+// than the expansion cap
+func (c *Corpus) capExpansions(matches []string) []string {
+	limit := c.maxExpansions
+	if limit <= 0 {
+		limit = DefaultMaxPrefixExpansions
+	}
+	if len(matches) > limit {
+		sort.SliceStable(matches, func(i, j int) bool {
+			return len(c.postings[matches[i]]) > len(c.postings[matches[j]])
+		})
+		matches = matches[:limit]
+	}
+	return matches
+}
+
+// preparePrefix resolves a prefix query to a single query term whose postings
+// combine every expanded term, so prefix* matches like one term would
+func (c *Corpus) preparePrefix(prefix string, occur occur) (queryTerm, bool) {
+	// an indexed edge n-gram already combines every term it begins
+	if c.edgeNGrams.indexed(prefix) {
+		return c.prepareTerm(prefix, occur)
+	}
+	return c.prepareExpansions(prefix+"*", c.expandPrefix(prefix), occur)
+}
+
+// prepareExpansions resolves index terms matched by a prefix or pattern to a
+// single query term combining their postings
+func (c *Corpus) prepareExpansions(name string, expansions []string, occur occur) (queryTerm, bool) {
+	if len(expansions) == 0 {
+		return queryTerm{term: name, occur: occur}, false
+	}
+
+	postings := make(postingList)
+	for _, term := range expansions {
+		for docIndex, fields := range c.postings[term] {
+			combined := postings[docIndex]
+			if combined == nil {
+				combined = make(map[Field]int, len(fields))
+				postings[docIndex] = combined
+			}
+			for field, tf := range fields {
+				combined[field] += tf
+			}
+		}
+	}
+	return c.newQueryTerm(name, postings, occur), true
+}
@@ -0,0 +1,75 @@
+package bm25md
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestCorpus_PrefixSearch(t *testing.T) {
+	corpus := NewCorpus()
+	bodies := []string{
+		"constitutional rights",    // 0
+		"the constitution applies", // 1
+		"constituent services",     // 2
+		"court calendar",           // 3
+		"federal court",            // 4
+		"filing deadlines",         // 5
+		"appeal rules",             // 6
+		"judge assignments",        // 7
+	}
+	for _, body := range bodies {
+		corpus.AddDocument(Document{Fields: map[Field]string{FieldBody: body}})
+	}
+
+	tests := []struct {
+		query    string
+		expected []int
+	}{
+		{"constitu*", []int{0, 1, 2}},
+		{"constitution*", []int{0, 1}},
+		{"constitu* -constituent", []int{0, 1}},
+		{"+cour* federal", []int{3, 4}},
+		{"zzz*", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			var got []int
+			for _, result := range corpus.Search(tt.query, 0) {
+				got = append(got, result.Index)
+			}
+			sort.Ints(got)
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Search(%q) = %v, want %v", tt.query, got, tt.expected)
+			}
+		})
+	}
+
+	// the term dictionary follows additions and removals
+	corpus.AddDocument(Document{Fields: map[Field]string{FieldBody: "constituency maps"}})
+	if got := corpus.expandPrefix("constitue"); !reflect.DeepEqual(got, []string{"constituency", "constituent"}) {
+		t.Errorf("expandPrefix() after add = %v", got)
+	}
+	if err := corpus.RemoveDocument(2); err != nil {
+		t.Fatal(err)
+	}
+	if got := corpus.expandPrefix("constitue"); !reflect.DeepEqual(got, []string{"constituency"}) {
+		t.Errorf("expandPrefix() after remove = %v", got)
+	}
+}
+
+func TestCorpus_MaxPrefixExpansions(t *testing.T) {
+	corpus := NewCorpus(WithMaxPrefixExpansions(2))
+	// term%d appears in (i+1) documents, so the most common expansions win
+	for i := 0; i < 4; i++ {
+		for j := 0; j <= i; j++ {
+			corpus.AddDocument(Document{Fields: map[Field]string{FieldBody: fmt.Sprintf("term%d", i)}})
+		}
+	}
+
+	if got := corpus.expandPrefix("term"); !reflect.DeepEqual(got, []string{"term3", "term2"}) {
+		t.Errorf("expandPrefix() = %v, want [term3 term2]", got)
+	}
+}
@@ -0,0 +1,183 @@
+package bm25md
+
+import (
+	"maps"
+	"sort"
+	"sync"
+)
+
+// Preset bundles field weights and BM25 parameters tuned for a type of corpus
+type Preset struct {
+	Name         string
+	FieldWeights map[Field]float64
+	FieldParams  map[Field]BM25Parameters // optional per-field parameters
+	Params       BM25Parameters
+}
+
+var (
+	presetsMu sync.RWMutex
+	presets   = map[string]Preset{}
+)
+
+func init() {
+	RegisterPreset(Preset{
+		Name:         "default",
+		FieldWeights: DefaultFieldWeights,
+		Params:       DefaultBM25Parameters(),
+	})
+
+	// technical documentation: headings and nav titles carry the page structure,
+	// code spans name the APIs people search for
+	docs := Preset{
+		Name: "docs",
+		FieldWeights: map[Field]float64{
+			FieldTitle:       7.0, // page/nav title (front matter title, sidebar_label, linkTitle)
+			FieldH1:          6.0,
+			FieldH2:          4.0,
+			FieldH3:          3.0,
+			FieldH4:          2.0,
+			FieldH5:          1.5,
+			FieldH6:          1.5,
+			FieldDescription: 2.0,
+			FieldTags:        2.5,
+			FieldBold:        1.3,
+			FieldItalic:      1.1,
+			FieldCode:        1.5,
+			FieldTable:       1.3, // reference tables (options, flags, error codes)
+			FieldLink:        1.2, // "see also" link text names related pages
+			FieldURL:         0.5,
+			FieldBody:        1.0,
+
+			FieldTerm:          2.0, // glossary terms are what people look up
+			FieldCitation:      0.5,
+			FieldStrikethrough: 0.3,
+			FieldBlockquote:    1.0, // notes and warnings
+			FieldList:          1.2,
+			FieldAltText:       1.0,
+			FieldBreadcrumb:    3.0, // section path within the page
+			FieldFileName:      2.0, // page slug
+			FieldPath:          1.0, // docs section directory
+		},
+		FieldParams: DefaultFieldBM25Parameters(),
+		Params:      DefaultBM25Parameters(),
+	}
+	// identifiers repeat in code samples without adding much evidence, so saturate early
+	docs.FieldParams[FieldCode] = BM25Parameters{K1: 0.9, B: 0.4}
+	RegisterPreset(docs)
+
+	// MkDocs pages are often long single-page guides, so length matters less
+	mkdocs := clonePreset(docs)
+	mkdocs.Name = "mkdocs"
+	mkdocs.FieldParams[FieldBody] = BM25Parameters{K1: 1.5, B: 0.6}
+	RegisterPreset(mkdocs)
+
+	// Docusaurus relies heavily on front matter titles and sidebar labels
+	docusaurus := clonePreset(docs)
+	docusaurus.Name = "docusaurus"
+	docusaurus.FieldWeights[FieldTitle] = 8.0
+	docusaurus.FieldWeights[FieldDescription] = 2.5
+	RegisterPreset(docusaurus)
+
+	// Obsidian vaults: note names and aliases identify notes, tags group them,
+	// and notes are short enough that headings carry less signal than in docs
+	RegisterPreset(Preset{
+		Name: "obsidian",
+		FieldWeights: map[Field]float64{
+			FieldTitle:       6.0, // note name and front matter aliases
+			FieldTags:        3.0,
+			FieldH1:          4.0,
+			FieldH2:          2.5,
+			FieldH3:          2.0,
+			FieldH4:          1.5,
+			FieldH5:          1.5,
+			FieldH6:          1.5,
+			FieldDescription: 2.0,
+			FieldBold:        1.5,
+			FieldItalic:      1.2,
+			FieldCode:        0.8,
+			FieldTable:       1.0,
+			FieldLink:        1.3,
+			FieldURL:         0.3,
+			FieldBody:        1.0,
+
+			FieldTerm:          1.2,
+			FieldCitation:      0.8, // literature notes cite their sources
+			FieldStrikethrough: 0.3, // done tasks and struck-out drafts
+			FieldBlockquote:    1.0, // callouts and quotes
+			FieldList:          1.2, // task lists and outlines
+			FieldAltText:       1.0,
+			FieldBreadcrumb:    1.5,
+			FieldFileName:      5.0, // the note name, as typed in [[links]]
+			FieldPath:          1.5, // vault folder
+		},
+		FieldParams: DefaultFieldBM25Parameters(),
+		Params:      DefaultBM25Parameters(),
+	})
+}
+
+// RegisterPreset makes a preset available to WithPreset, replacing any preset with the same name
+func RegisterPreset(preset Preset) {
+	presetsMu.Lock()
+	defer presetsMu.Unlock()
+	presets[preset.Name] = clonePreset(preset)
+}
+
+// LookupPreset returns a copy of the named preset
+func LookupPreset(name string) (Preset, bool) {
+	presetsMu.RLock()
+	defer presetsMu.RUnlock()
+	preset, ok := presets[name]
+	if !ok {
+		return Preset{}, false
+	}
+	return clonePreset(preset), true
+}
+
+// Presets returns the names of all registered presets in sorted order
+func Presets() []string {
+	presetsMu.RLock()
+	defer presetsMu.RUnlock()
+	names := make([]string, 0, len(presets))
+	for name := range presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// WithPreset configures the corpus from a named preset (eg "docs"); options given
+// after it can still override individual weights or parameters. A preset adjusts
+// weights rather than deciding what is indexed: fields it leaves out keep their
+// DefaultFieldWeights weight (a preset excludes a field by weighting it 0)
+func WithPreset(name string) CorpusOption {
+	return func(c *Corpus) {
+		preset, ok := LookupPreset(name)
+		if !ok {
+			c.logger().Warn("Unknown bm25md preset, keeping current configuration", "preset", name)
+			return
+		}
+		weights := maps.Clone(DefaultFieldWeights)
+		maps.Copy(weights, preset.FieldWeights)
+		c.fieldWeights = weights
+		c.fieldParams = preset.FieldParams
+		c.params = preset.Params
+	}
+}
+
+// clonePreset deep-copies a preset so callers cannot mutate registered maps
+func clonePreset(preset Preset) Preset {
+	clone := preset
+	if preset.FieldWeights != nil {
+		clone.FieldWeights = make(map[Field]float64, len(preset.FieldWeights))
+		for field, weight := range preset.FieldWeights {
+			clone.FieldWeights[field] = weight
+		}
+	}
+	if preset.FieldParams != nil {
+		clone.FieldParams = make(map[Field]BM25Parameters, len(preset.FieldParams))
+		for field, params := range preset.FieldParams {
+			clone.FieldParams[field] = params
+		}
+	}
+	return clone
+}
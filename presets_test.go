@@ -0,0 +1,79 @@
+package bm25md
+
+import "testing"
+
+func TestWithPreset(t *testing.T) {
+	corpus := NewCorpus(WithPreset("docs"))
+
+	if corpus.fieldWeights[FieldTitle] != 7.0 {
+		t.Errorf("title weight = %f, want 7.0", corpus.fieldWeights[FieldTitle])
+	}
+	if corpus.fieldScorers[FieldCode].params.K1 != 0.9 {
+		t.Errorf("code K1 = %f, want 0.9", corpus.fieldScorers[FieldCode].params.K1)
+	}
+
+	// later options override the preset
+	overridden := NewCorpus(WithPreset("docs"), WithFieldWeights(map[Field]float64{FieldBody: 1.0}))
+	if len(overridden.fieldWeights) != 1 {
+		t.Errorf("field weights = %v, want only body", overridden.fieldWeights)
+	}
+
+	// unknown presets leave the defaults in place
+	unknown := NewCorpus(WithPreset("no-such-preset"))
+	if unknown.fieldWeights[FieldH1] != DefaultFieldWeights[FieldH1] {
+		t.Errorf("H1 weight = %f, want default", unknown.fieldWeights[FieldH1])
+	}
+}
+
+func TestRegisterPreset(t *testing.T) {
+	weights := map[Field]float64{FieldBody: 2.0}
+	RegisterPreset(Preset{Name: "test-notes", FieldWeights: weights, Params: DefaultBM25Parameters()})
+
+	// mutating the caller's map must not affect the registered preset
+	weights[FieldBody] = 9.0
+	preset, ok := LookupPreset("test-notes")
+	if !ok {
+		t.Fatal("LookupPreset() did not find registered preset")
+	}
+	if preset.FieldWeights[FieldBody] != 2.0 {
+		t.Errorf("body weight = %f, want 2.0", preset.FieldWeights[FieldBody])
+	}
+
+	// fields the preset leaves out keep their default weights, so they stay indexed
+	corpus := NewCorpus(WithPreset("test-notes"))
+	if corpus.fieldWeights[FieldBody] != 2.0 || corpus.fieldWeights[FieldFileName] != DefaultFieldWeights[FieldFileName] {
+		t.Errorf("field weights = %v, want body 2.0 over the defaults", corpus.fieldWeights)
+	}
+	corpus.AddDocument(Document{Fields: map[Field]string{FieldFileName: "kubernetes setup", FieldBody: "cluster notes"}})
+	for _, filler := range []string{"jury trial", "court calendar", "filing fees"} {
+		corpus.AddDocument(Document{Fields: map[Field]string{FieldBody: filler}})
+	}
+	if got := len(corpus.Search("kubernetes", 10)); got != 1 {
+		t.Errorf("file name search got %d results, want 1", got)
+	}
+
+	found := false
+	for _, name := range Presets() {
+		if name == "test-notes" {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("Presets() should list registered preset")
+	}
+}
+
+func TestPresetsCoverDefaultFields(t *testing.T) {
+	// a preset missing a field would drop that field from the index
+	for _, name := range []string{"default", "docs", "mkdocs", "docusaurus", "obsidian"} {
+		preset, ok := LookupPreset(name)
+		if !ok {
+			t.Fatalf("preset %q is not registered", name)
+		}
+		for field := range DefaultFieldWeights {
+			if _, exists := preset.FieldWeights[field]; !exists {
+				t.Errorf("preset %q has no weight for field %q", name, field)
+			}
+		}
+	}
+}
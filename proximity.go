@@ -0,0 +1,45 @@
+package bm25md
+
+import "slices"
+
+// DefaultNearDistance is the distance of a NEAR operator written without /k
+const DefaultNearDistance = 5
+
+// proximityBonus configures the optional clause rewarding query words that
+// appear close together
+type proximityBonus struct {
+	weight float64
+	window int
+}
+
+// WithProximityBonus adds to multi-word queries an optional clause matching
+// documents where all of the query's words appear within window positions of
+// each other, scored like a phrase and scaled by weight. Adjacency strongly
+// indicates relevance in prose, so this ranks "the writ of habeas corpus" above
+// a document mentioning writs and corpus paragraphs apart
+func WithProximityBonus(weight float64, window int) CorpusOption {
+	return func(c *Corpus) {
+		c.proximity = proximityBonus{weight: weight, window: window}
+	}
+}
+
+// bonusClauses appends the proximity clause for the distinct words of the
+// optional and required single-word clauses, when there are at least two
+func (p proximityBonus) bonusClauses(clauses []queryClause) []queryClause {
+	if p.weight <= 0 || p.window <= 0 {
+		return clauses
+	}
+	var tokens []string
+	for _, clause := range clauses {
+		if !clause.isTerm() || clause.occur == occurMustNot {
+			continue
+		}
+		if !slices.Contains(tokens, clause.tokens[0]) {
+			tokens = append(tokens, clause.tokens[0])
+		}
+	}
+	if len(tokens) < 2 {
+		return clauses
+	}
+	return append(clauses, queryClause{tokens: tokens, occur: occurShould, near: p.window, boost: p.weight})
+}
@@ -0,0 +1,150 @@
+package bm25md
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestNearFrequency(t *testing.T) {
+	positions := analyzeTokens([]string{"writ", "of", "habeas", "corpus", "filed", "corpus", "habeas"}).positions
+	tests := []struct {
+		tokens   []string
+		near     int
+		expected int
+	}{
+		{tokens: []string{"habeas", "corpus"}, near: 1, expected: 2},
+		{tokens: []string{"corpus", "habeas"}, near: 1, expected: 2},
+		{tokens: []string{"writ", "corpus"}, near: 2, expected: 0},
+		{tokens: []string{"writ", "corpus"}, near: 3, expected: 1},
+		{tokens: []string{"writ", "habeas", "filed"}, near: 4, expected: 1},
+		{tokens: []string{"writ", "habeas", "filed"}, near: 3, expected: 0},
+		{tokens: []string{"writ", "missing"}, near: 10, expected: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%v/%d", tt.tokens, tt.near), func(t *testing.T) {
+			if got := phraseFrequency(positions, tt.tokens, tt.near); got != tt.expected {
+				t.Errorf("phraseFrequency(%q, %d) = %d, want %d", tt.tokens, tt.near, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseQuery_Near(t *testing.T) {
+	corpus := NewCorpus()
+	tests := []struct {
+		query    string
+		expected []queryClause
+	}{
+		{
+			query:    "habeas NEAR/3 corpus",
+			expected: []queryClause{{tokens: []string{"habeas", "corpus"}, near: 3}},
+		},
+		{
+			query:    "habeas NEAR corpus",
+			expected: []queryClause{{tokens: []string{"habeas", "corpus"}, near: DefaultNearDistance}},
+		},
+		{
+			query: "+writ habeas NEAR/2 corpus petition",
+			expected: []queryClause{
+				{tokens: []string{"writ"}, occur: occurMust},
+				{tokens: []string{"habeas", "corpus"}, near: 2},
+				{tokens: []string{"petition"}},
+			},
+		},
+		{
+			query:    "NEAR/2 corpus",
+			expected: []queryClause{{tokens: []string{"corpus"}}},
+		},
+		{
+			// NEAR/0 joins its operands as a phrase rather than leaving them separate
+			query:    "habeas NEAR/0 corpus",
+			expected: []queryClause{{tokens: []string{"habeas", "corpus"}}},
+		},
+		{
+			// a distance out of range is not an operator, so its word is searched
+			query: "habeas NEAR/99999999999999999999 corpus",
+			expected: []queryClause{
+				{tokens: []string{"habeas"}},
+				{tokens: []string{"near"}},
+				{tokens: []string{"99999999999999999999"}},
+				{tokens: []string{"corpus"}},
+			},
+		},
+		{
+			query:    "habeas NEAR/x corpus",
+			expected: []queryClause{{tokens: []string{"habeas"}}, {tokens: []string{"near"}}, {tokens: []string{"corpus"}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			if got := corpus.parseQuery(tt.query); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("parseQuery(%q) = %+v, want %+v", tt.query, got, tt.expected)
+			}
+		})
+	}
+}
+
+// proximityDocs returns documents mentioning habeas and corpus close together
+// and apart, among unrelated filler
+func proximityDocs() []Document {
+	docs := []Document{
+		{Fields: map[Field]string{FieldBody: "habeas filings review the petition and the corpus of evidence"}},
+		{Fields: map[Field]string{FieldBody: "a petition for habeas corpus"}},
+		{Fields: map[Field]string{FieldBody: "corpus delicti and the writ of habeas"}},
+	}
+	for i := range 10 {
+		docs = append(docs, Document{Fields: map[Field]string{FieldBody: fmt.Sprintf("filler document %d about calendars", i)}})
+	}
+	return docs
+}
+
+func TestCorpus_NearSearch(t *testing.T) {
+	corpus := NewCorpus()
+	corpus.AddDocuments(proximityDocs())
+
+	// document 2 has the words five positions apart, in reverse order
+	results := corpus.Search("habeas NEAR/5 corpus", 10)
+	got := make([]int, len(results))
+	for i, result := range results {
+		got[i] = result.Index
+	}
+	slices.Sort(got)
+	if want := []int{1, 2}; !reflect.DeepEqual(got, want) {
+		t.Errorf("NEAR/5 results = %v, want %v", got, want)
+	}
+	if results := corpus.Search("habeas NEAR/1 corpus", 10); len(results) != 1 || results[0].Index != 1 {
+		t.Errorf("NEAR/1 results = %+v, want document 1", results)
+	}
+	// NEAR/0 is a phrase, so order matters
+	if results := corpus.Search("habeas NEAR/0 corpus", 10); len(results) != 1 || results[0].Index != 1 {
+		t.Errorf("NEAR/0 results = %+v, want document 1", results)
+	}
+	if results := corpus.Search("corpus NEAR/0 habeas", 10); len(results) != 0 {
+		t.Errorf("reversed NEAR/0 results = %+v, want none", results)
+	}
+}
+
+func TestWithProximityBonus(t *testing.T) {
+	plain := NewCorpus()
+	plain.AddDocuments(proximityDocs())
+	bonus := NewCorpus(WithProximityBonus(1, 2))
+	bonus.AddDocuments(proximityDocs())
+
+	apart := plain.Score("habeas corpus", 0)
+	if got := bonus.Score("habeas corpus", 0); got != apart {
+		t.Errorf("distant words scored %v with the bonus, want %v", got, apart)
+	}
+	adjacent := plain.Score("habeas corpus", 1)
+	if got := bonus.Score("habeas corpus", 1); got <= adjacent {
+		t.Errorf("adjacent words scored %v with the bonus, want more than %v", got, adjacent)
+	}
+
+	// single words get no bonus clause
+	if got := bonus.parseQuery("habeas"); len(got) != 1 {
+		t.Errorf("parseQuery(habeas) = %+v, want one clause", got)
+	}
+}
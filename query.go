@@ -0,0 +1,204 @@
+package bm25md
+
+import (
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// occur says how a query clause affects matching
+type occur int
+
+const (
+	occurShould  occur = iota // optional; contributes to the score when present
+	occurMust                 // required (+term, AND)
+	occurMustNot              // excluded (-term, NOT)
+)
+
+// queryClause is a parsed query term, prefix (term*), or phrase (more than one token)
+type queryClause struct {
+	tokens []string
+	occur  occur
+	prefix bool           // the single token matches every index term it begins
+	near   int            // phrase tokens may appear in any order within near positions (0 = consecutive)
+	boost  float64        // multiplier of the clause's score (0 = 1)
+	regexp *regexp.Regexp // matches whole index terms; the single token is the /pattern/
+}
+
+// isTerm reports whether the clause matches a single index term
+func (q queryClause) isTerm() bool {
+	return len(q.tokens) == 1 && !q.prefix && q.regexp == nil
+}
+
+// scale applies the clause's boost to a term scorer
+func (q queryClause) scale(scorer TermScorer) TermScorer {
+	if q.boost == 0 || scorer == nil {
+		return scorer
+	}
+	return func(matches []FieldMatch) float64 {
+		return q.boost * scorer(matches)
+	}
+}
+
+// queryItemRegex matches optionally prefixed quoted phrases, or whitespace-separated words
+var queryItemRegex = regexp.MustCompile(`([+-]?)"([^"]*)"|\S+`)
+
+// nearRegex matches the NEAR and NEAR/k proximity operators
+var nearRegex = regexp.MustCompile(`^NEAR(?:/(\d+))?$`)
+
+// nearDistance returns the distance of a NEAR or NEAR/k operator; ok is false
+// when k does not fit in an int
+func nearDistance(operator string) (int, bool) {
+	k := nearRegex.FindStringSubmatch(operator)[1]
+	if k == "" {
+		return DefaultNearDistance, true
+	}
+	near, err := strconv.Atoi(k)
+	return near, err == nil
+}
+
+// parseQuery parses query syntax into clauses. Terms are optional by default;
+// +term and AND make terms required, -term and NOT exclude them, OR keeps the
+// default, double-quoted text is matched as a phrase, and a trailing * matches
+// every term with that prefix. /pattern/ matches every index term the regular
+// expression matches in full, ignoring case. a NEAR/k b matches a and b within
+// k positions of each other, in either order (NEAR alone uses
+// DefaultNearDistance, and NEAR/0 matches a b as a phrase). With WithShingles,
+// shingles of adjacent words are added as optional clauses, and with
+// WithProximityBonus a clause rewarding query words found close together.
+// Phrases and NEAR operands skip the synonyms of a SynonymFilter, which would
+// take positions between their words
+func (c *Corpus) parseQuery(query string) []queryClause {
+	var clauses []queryClause
+	next := occurShould // occur forced onto the next operand by AND or NOT
+	near := -1          // distance of a pending NEAR (-1 = none), which joins the next operand to the last clause
+	tokenize, tokenizePhrase := c.queryTokenizer(query), c.phraseTokenizer(query)
+	last, lastPhrase := 0, []string(nil) // first clause and phrase tokens of the last operand, for NEAR
+
+	for _, loc := range queryItemRegex.FindAllStringSubmatchIndex(query, -1) {
+		item := query[loc[0]:loc[1]]
+		quoted := loc[4] >= 0
+
+		var prefix, text string
+		switch {
+		case quoted:
+			prefix, text = query[loc[2]:loc[3]], query[loc[4]:loc[5]]
+		case item == "AND":
+			// both operands of AND are required
+			if n := len(clauses); n > 0 && clauses[n-1].occur == occurShould {
+				clauses[n-1].occur = occurMust
+			}
+			next = occurMust
+			continue
+		case item == "OR":
+			next = occurShould
+			continue
+		case item == "NOT":
+			next = occurMustNot
+			continue
+		case nearRegex.MatchString(item):
+			if k, ok := nearDistance(item); ok {
+				near = k
+				continue
+			}
+			// a distance too large to parse leaves NEAR/k as words, like NEAR/x
+			text = item
+		case len(item) > 1 && (item[0] == '+' || item[0] == '-'):
+			prefix, text = item[:1], item[1:]
+		default:
+			text = item
+		}
+
+		occur := next
+		switch prefix {
+		case "+":
+			occur = occurMust
+		case "-":
+			occur = occurMustNot
+		}
+
+		if re := c.parseRegexp(text); re != nil && !quoted {
+			clauses = append(clauses, queryClause{tokens: []string{text}, occur: occur, regexp: re})
+			next, near = occurShould, -1
+			continue
+		}
+
+		// a trailing wildcard on a single word makes a prefix query
+		prefixed := !quoted && len(text) > 1 && strings.HasSuffix(text, "*")
+		if prefixed {
+			text = strings.TrimSuffix(text, "*")
+		}
+
+		phrase := tokenizePhrase(text)
+		tokens := phrase
+		if !quoted {
+			tokens = tokenize(text)
+		}
+		if len(tokens) == 0 {
+			// stopped or too-short words leave pending operators for the next operand
+			continue
+		}
+		if n := len(clauses); near >= 0 && n > 0 && !clauses[n-1].prefix && clauses[n-1].regexp == nil && !prefixed {
+			// the joined operands replace the last operand's clauses, including any synonyms
+			joined := queryClause{tokens: append(slices.Clone(lastPhrase), phrase...), occur: clauses[last].occur, near: near}
+			clauses = append(clauses[:last], joined)
+			lastPhrase = joined.tokens
+			near = -1
+			next = occurShould
+			continue
+		}
+		near = -1
+		last, lastPhrase = len(clauses), phrase
+
+		switch {
+		case prefixed && len(tokens) == 1:
+			clauses = append(clauses, queryClause{tokens: tokens, occur: occur, prefix: true})
+		case quoted && len(tokens) > 1:
+			// a quoted phrase keeps its tokens together
+			clauses = append(clauses, queryClause{tokens: tokens, occur: occur})
+		default:
+			for _, token := range tokens {
+				clauses = append(clauses, queryClause{tokens: []string{token}, occur: occur})
+			}
+		}
+		next = occurShould
+	}
+
+	return c.shingles.shingleClauses(c.proximity.bonusClauses(clauses))
+}
+
+// prepareQueryString parses a query and resolves its clauses against the index;
+// it also returns every query token, for reporting
+func (c *Corpus) prepareQueryString(query string) ([]string, []queryTerm) {
+	return c.prepareClauses(c.parseQuery(query))
+}
+
+// prepareClauses resolves parsed query clauses against the index
+func (c *Corpus) prepareClauses(clauses []queryClause) ([]string, []queryTerm) {
+	var tokens []string
+	var prepared []queryTerm
+	for _, clause := range clauses {
+		tokens = append(tokens, clause.tokens...)
+
+		var qt queryTerm
+		var found bool
+		switch {
+		case clause.regexp != nil:
+			qt, found = c.prepareRegexp(clause.tokens[0], clause.regexp, clause.occur)
+		case clause.prefix:
+			qt, found = c.preparePrefix(clause.tokens[0], clause.occur)
+		case len(clause.tokens) == 1:
+			qt, found = c.prepareTerm(clause.tokens[0], clause.occur)
+		default:
+			qt, found = c.preparePhrase(clause.tokens, clause.near, clause.occur)
+		}
+		qt.scorer = clause.scale(qt.scorer)
+
+		// missing required terms still apply (matching nothing); missing others are dropped
+		if found || clause.occur == occurMust {
+			prepared = append(prepared, qt)
+		}
+	}
+	return tokens, prepared
+}
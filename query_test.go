@@ -0,0 +1,137 @@
+package bm25md
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestCorpus_ParseQuery(t *testing.T) {
+	corpus := NewCorpus()
+	term := func(token string, occur occur) queryClause {
+		return queryClause{tokens: []string{token}, occur: occur}
+	}
+
+	tests := []struct {
+		name     string
+		query    string
+		expected []queryClause
+	}{
+		{
+			name:     "plain terms",
+			query:    "habeas corpus",
+			expected: []queryClause{term("habeas", occurShould), term("corpus", occurShould)},
+		},
+		{
+			name:     "phrase and term",
+			query:    `"habeas corpus" petition`,
+			expected: []queryClause{{tokens: []string{"habeas", "corpus"}}, term("petition", occurShould)},
+		},
+		{
+			name:     "single token phrase",
+			query:    `"habeas" writ`,
+			expected: []queryClause{term("habeas", occurShould), term("writ", occurShould)},
+		},
+		{
+			name:     "unbalanced quote",
+			query:    `"habeas corpus`,
+			expected: []queryClause{term("habeas", occurShould), term("corpus", occurShould)},
+		},
+		{
+			name:     "required and excluded prefixes",
+			query:    "habeas -appeal +federal",
+			expected: []queryClause{term("habeas", occurShould), term("appeal", occurMustNot), term("federal", occurMust)},
+		},
+		{
+			name:     "prefixed phrase",
+			query:    `-"court calendar" habeas`,
+			expected: []queryClause{{tokens: []string{"court", "calendar"}, occur: occurMustNot}, term("habeas", occurShould)},
+		},
+		{
+			name:     "AND requires both operands",
+			query:    "habeas AND federal OR state",
+			expected: []queryClause{term("habeas", occurMust), term("federal", occurMust), term("state", occurShould)},
+		},
+		{
+			name:     "NOT excludes the next operand",
+			query:    "habeas NOT appeal",
+			expected: []queryClause{term("habeas", occurShould), term("appeal", occurMustNot)},
+		},
+		{
+			name:     "operators skip dropped words",
+			query:    "habeas NOT of appeal",
+			expected: []queryClause{term("habeas", occurShould), term("appeal", occurMustNot)},
+		},
+		{
+			name:     "trailing wildcard",
+			query:    "constitu* +fed* -*",
+			expected: []queryClause{{tokens: []string{"constitu"}, prefix: true}, {tokens: []string{"fed"}, occur: occurMust, prefix: true}},
+		},
+		{
+			name:     "lowercase operators are terms",
+			query:    "habeas and corpus",
+			expected: []queryClause{term("habeas", occurShould), term("and", occurShould), term("corpus", occurShould)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := corpus.parseQuery(tt.query); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("parseQuery(%q) = %+v, want %+v", tt.query, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCorpus_BooleanSearch(t *testing.T) {
+	corpus := NewCorpus()
+	for _, body := range []string{
+		"habeas petition in federal court",  // 0
+		"habeas appeal in federal court",    // 1
+		"habeas petition in state court",    // 2
+		"federal appeal without the writ",   // 3
+		"unrelated calendar of sessions",    // 4
+		"another unrelated filler document", // 5
+		"notes on jury selection",           // 6
+		"sentencing guidelines overview",    // 7
+		"discovery motions explained",       // 8
+		"evidence rules summary",            // 9
+	} {
+		corpus.AddDocument(Document{Fields: map[Field]string{FieldBody: body}})
+	}
+
+	tests := []struct {
+		query    string
+		expected []int
+	}{
+		{"habeas -appeal +federal", []int{0}},
+		{"+federal", []int{0, 1, 3}},
+		{"habeas AND federal", []int{0, 1}},
+		{"habeas NOT appeal", []int{0, 2}},
+		{`+habeas -"state court"`, []int{0, 1}},
+		{"+missing habeas", nil},
+		{"habeas -missing", []int{0, 1, 2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			var got []int
+			for _, result := range corpus.Search(tt.query, 0) {
+				got = append(got, result.Index)
+			}
+			sort.Ints(got)
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Search(%q) = %v, want %v", tt.query, got, tt.expected)
+			}
+			for _, docIndex := range got {
+				if corpus.Score(tt.query, docIndex) == 0 {
+					t.Errorf("Score(%q, %d) = 0 for a matching document", tt.query, docIndex)
+				}
+			}
+		})
+	}
+
+	if score := corpus.Score("habeas -appeal", 1); score != 0 {
+		t.Errorf("Score() of an excluded document = %v, want 0", score)
+	}
+}
@@ -0,0 +1,98 @@
+package bm25md
+
+import "time"
+
+// Range bounds a numeric or date metadata value, inclusively. Bounds are numbers
+// or dates (time.Time, or strings in RFC 3339 or 2006-01-02 form); a nil bound
+// is open. Metadata lists match when any element falls in the range
+type Range struct {
+	Key string // metadata key, eg "modified" or "year"
+	Min any    // lowest accepted value (nil = unbounded)
+	Max any    // highest accepted value (nil = unbounded)
+}
+
+// MetadataRange matches documents whose metadata value for key falls between
+// low and high inclusive (nil = unbounded), like a Range in SearchOptions.Ranges
+func MetadataRange(key string, low, high any) Filter {
+	r := Range{Key: key, Min: low, Max: high}
+	return r.contains
+}
+
+// contains reports whether a document's metadata value falls in the range
+func (r Range) contains(doc Document) bool {
+	switch v := doc.Metadata[r.Key].(type) {
+	case nil:
+		return false
+	case []string:
+		for _, item := range v {
+			if r.containsValue(item) {
+				return true
+			}
+		}
+		return false
+	case []any:
+		for _, item := range v {
+			if r.containsValue(item) {
+				return true
+			}
+		}
+		return false
+	default:
+		return r.containsValue(v)
+	}
+}
+
+// containsValue reports whether a single metadata value falls in the range
+func (r Range) containsValue(value any) bool {
+	if r.Min != nil {
+		if order, ok := compareMetadata(value, r.Min); !ok || order < 0 {
+			return false
+		}
+	}
+	if r.Max != nil {
+		if order, ok := compareMetadata(value, r.Max); !ok || order > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// compareMetadata orders a metadata value against a bound, returning -1, 0, or
+// 1; ok is false when the value is not comparable to the bound (eg text against
+// a number), which falls outside every range
+func compareMetadata(value, bound any) (order int, ok bool) {
+	if a, ok := toFloat(value); ok {
+		if b, ok := toFloat(bound); ok {
+			switch {
+			case a < b:
+				return -1, true
+			case a > b:
+				return 1, true
+			}
+			return 0, true
+		}
+		return 0, false
+	}
+	if a, ok := toTime(value); ok {
+		if b, ok := toTime(bound); ok {
+			return a.Compare(b), true
+		}
+	}
+	return 0, false
+}
+
+// toTime converts date metadata values (time.Time, or RFC 3339 and 2006-01-02
+// strings as found in front matter) to a time
+func toTime(value any) (time.Time, bool) {
+	switch v := value.(type) {
+	case time.Time:
+		return v, true
+	case string:
+		for _, layout := range []string{time.RFC3339, time.DateOnly} {
+			if t, err := time.Parse(layout, v); err == nil {
+				return t, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
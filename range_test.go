@@ -0,0 +1,76 @@
+package bm25md
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMetadataRange(t *testing.T) {
+	date := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	doc := Document{Metadata: map[string]any{
+		"year":     2024,
+		"score":    4.5,
+		"date":     date,
+		"modified": "2023-06-15",
+		"versions": []any{1, 7},
+		"title":    "guide",
+	}}
+
+	tests := []struct {
+		name     string
+		filter   Filter
+		expected bool
+	}{
+		{"within", MetadataRange("year", 2020, 2025), true},
+		{"inclusive bounds", MetadataRange("year", 2024, 2024.0), true},
+		{"below", MetadataRange("score", 5, nil), false},
+		{"open low bound", MetadataRange("score", nil, 5), true},
+		{"time bounds", MetadataRange("date", time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), nil), true},
+		{"date string bounds", MetadataRange("date", nil, "2024-04-30"), false},
+		{"date string value", MetadataRange("modified", "2023-01-01", "2023-12-31T23:59:59Z"), true},
+		{"list element", MetadataRange("versions", 5, 10), true},
+		{"list outside", MetadataRange("versions", 2, 6), false},
+		{"text value", MetadataRange("title", 0, nil), false},
+		{"text value below high bound only", MetadataRange("title", nil, 2020), false},
+		{"number against date", MetadataRange("year", "2023-01-01", nil), false},
+		{"number against date high bound only", MetadataRange("year", nil, "2030-01-01"), false},
+		{"high bound only", MetadataRange("year", nil, 2030), true},
+		{"above high bound only", MetadataRange("year", nil, 2020), false},
+		{"missing key", MetadataRange("author", nil, nil), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter(doc); got != tt.expected {
+				t.Errorf("filter() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCorpus_SearchRanges(t *testing.T) {
+	corpus := NewCorpus()
+	for _, modified := range []string{"2022-03-01", "2023-02-01", "2023-09-01", "2024-01-15"} {
+		corpus.AddDocument(Document{
+			Fields:   map[Field]string{FieldBody: "writ of habeas corpus"},
+			Metadata: map[string]any{"modified": modified},
+		})
+	}
+	for _, body := range []string{"court calendar", "filing deadlines", "appeal rules", "judge assignments", "motion practice"} {
+		corpus.AddDocument(Document{Fields: map[Field]string{FieldBody: body}})
+	}
+	opts := SearchOptions{Ranges: []Range{{Key: "modified", Min: "2023-01-01"}, {Key: "modified", Max: "2023-12-31"}}}
+
+	results := corpus.SearchWithOptions("habeas", opts)
+	if len(results) != 2 {
+		t.Fatalf("got %d results, want 2: %+v", len(results), results)
+	}
+	for _, result := range results {
+		if result.Index != 1 && result.Index != 2 {
+			t.Errorf("result %d modified %v is outside 2023", result.Index, result.Document.Metadata["modified"])
+		}
+	}
+	if got := corpus.Freeze().SearchWithOptions("habeas", opts); len(got) != 2 {
+		t.Errorf("Index returned %d results, want 2", len(got))
+	}
+}
@@ -0,0 +1,20 @@
+//go:build !bm25md_noparser
+
+package bm25md
+
+// Rank ranks markdown texts for a query without keeping a corpus: it parses and
+// indexes docs into a temporary corpus configured by opts and returns the
+// matching documents, best first. Result indexes are positions in docs, which
+// suits reranking a small candidate set from another retriever. Term statistics
+// come from docs alone, so by default scores use BM25LSimilarity, whose IDF stays
+// positive for terms in half or more of a small set; WithSimilarity overrides it
+func Rank(query string, docs []string, opts ...CorpusOption) []SearchResult {
+	corpus := NewCorpus(append([]CorpusOption{WithSimilarity(BM25LSimilarity{})}, opts...)...)
+	parser := corpus.fieldParser()
+	parsed := make([]Document, len(docs))
+	for i, content := range docs {
+		parsed[i] = Document{Fields: parser.ParseDocument(content), Original: content}
+	}
+	corpus.AddDocuments(parsed)
+	return corpus.Search(query, 0)
+}
@@ -0,0 +1,39 @@
+//go:build !bm25md_noparser
+
+package bm25md
+
+import (
+	"testing"
+)
+
+func TestRank(t *testing.T) {
+	docs := []string{
+		"# Court calendar\n\nHearings are listed weekly.",
+		"# Habeas corpus\n\nA petition for habeas corpus challenges detention.",
+		"Filing deadlines for appeals.",
+		"Notes mention habeas once.",
+	}
+
+	results := Rank("habeas corpus", docs)
+	if len(results) != 2 {
+		t.Fatalf("Rank() returned %d results, want 2: %+v", len(results), results)
+	}
+	if results[0].Index != 1 || results[1].Index != 3 {
+		t.Errorf("Rank() order = %d, %d, want 1, 3", results[0].Index, results[1].Index)
+	}
+	if results[0].Document.Original != docs[1] {
+		t.Errorf("Rank() document = %q, want the original text", results[0].Document.Original)
+	}
+	if results[0].Document.Fields[FieldH1] != "Habeas corpus" {
+		t.Errorf("Rank() did not parse headings: %+v", results[0].Document.Fields)
+	}
+
+	// corpus options apply to the temporary corpus: with plain BM25, a term in
+	// half the documents has no weight
+	if results := Rank("habeas", docs, WithSimilarity(BM25FSimilarity{})); len(results) != 0 {
+		t.Errorf("Rank() with BM25F returned %d results, want none", len(results))
+	}
+	if results := Rank("habeas", nil); len(results) != 0 {
+		t.Errorf("Rank() without documents returned %d results", len(results))
+	}
+}
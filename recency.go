@@ -0,0 +1,57 @@
+package bm25md
+
+import (
+	"math"
+	"time"
+)
+
+// DecayFunc maps a document's age to a score multiplier, usually in (0, 1]
+type DecayFunc func(age time.Duration) float64
+
+// ExponentialDecay halves scores every halfLife
+func ExponentialDecay(halfLife time.Duration) DecayFunc {
+	return func(age time.Duration) float64 {
+		if halfLife <= 0 {
+			return 1
+		}
+		return math.Exp2(-float64(age) / float64(halfLife))
+	}
+}
+
+// LinearDecay lowers scores linearly from 1 for new documents to floor for
+// documents scale old (or older)
+func LinearDecay(scale time.Duration, floor float64) DecayFunc {
+	return func(age time.Duration) float64 {
+		if scale <= 0 || age >= scale {
+			return floor
+		}
+		return 1 - (1-floor)*float64(age)/float64(scale)
+	}
+}
+
+// WithRecencyDecay multiplies the score of each document with a Timestamp by
+// decay(age), so newer documents (eg release notes) outrank stale ones with the
+// same textual relevance. Documents without a Timestamp are not decayed, and
+// future timestamps count as age zero
+func WithRecencyDecay(decay DecayFunc) CorpusOption {
+	return func(c *Corpus) {
+		c.decay = decay
+	}
+}
+
+// recency returns the decay multiplier for a document
+func (c *Corpus) recency(docIndex int) float64 {
+	return c.recencyAt(c.documents[docIndex].Timestamp)
+}
+
+// recencyAt returns the decay multiplier for a document with a timestamp
+func (c *Corpus) recencyAt(timestamp time.Time) float64 {
+	if c.decay == nil || timestamp.IsZero() {
+		return 1.0
+	}
+	now := time.Now()
+	if c.now != nil {
+		now = c.now()
+	}
+	return c.decay(max(now.Sub(timestamp), 0))
+}
@@ -0,0 +1,65 @@
+package bm25md
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestDecayFuncs(t *testing.T) {
+	day := 24 * time.Hour
+	tests := []struct {
+		name     string
+		decay    DecayFunc
+		age      time.Duration
+		expected float64
+	}{
+		{"exponential at zero", ExponentialDecay(30 * day), 0, 1},
+		{"exponential at half-life", ExponentialDecay(30 * day), 30 * day, 0.5},
+		{"exponential at two half-lives", ExponentialDecay(30 * day), 60 * day, 0.25},
+		{"exponential without half-life", ExponentialDecay(0), 60 * day, 1},
+		{"linear at zero", LinearDecay(100*day, 0.2), 0, 1},
+		{"linear midway", LinearDecay(100*day, 0.2), 50 * day, 0.6},
+		{"linear past scale", LinearDecay(100*day, 0.2), 400 * day, 0.2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.decay(tt.age); math.Abs(got-tt.expected) > 1e-9 {
+				t.Errorf("decay(%v) = %v, want %v", tt.age, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCorpus_RecencyDecay(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	corpus := NewCorpus(WithRecencyDecay(ExponentialDecay(30 * 24 * time.Hour)))
+	corpus.now = func() time.Time { return now }
+
+	corpus.AddDocument(Document{Fields: map[Field]string{FieldBody: "release notes"}, Timestamp: now.AddDate(0, -2, 0)})
+	corpus.AddDocument(Document{Fields: map[Field]string{FieldBody: "release notes"}, Timestamp: now.AddDate(0, 0, -1)})
+	corpus.AddDocument(Document{Fields: map[Field]string{FieldBody: "release notes"}})
+	corpus.AddDocument(Document{Fields: map[Field]string{FieldBody: "release notes"}, Timestamp: now.AddDate(0, 0, 7)})
+	for _, body := range []string{"court calendar", "appeal rules", "filing deadlines", "judge assignments", "clerk contacts", "jury duty"} {
+		corpus.AddDocument(Document{Fields: map[Field]string{FieldBody: body}})
+	}
+
+	results := corpus.Search("release", 4)
+	order := make([]int, len(results))
+	for i, result := range results {
+		order[i] = result.Index
+	}
+	// undated and future documents are undecayed, then newest first
+	if len(order) != 4 || order[0] != 2 || order[1] != 3 || order[2] != 1 || order[3] != 0 {
+		t.Errorf("result order = %v, want [2 3 1 0]", order)
+	}
+
+	plain := NewCorpus()
+	for _, doc := range corpus.Documents() {
+		plain.AddDocument(doc)
+	}
+	if a, b := plain.Score("release", 0), plain.Score("release", 1); a != b {
+		t.Errorf("scores without decay = %v, %v, want equal", a, b)
+	}
+}
@@ -0,0 +1,78 @@
+package bm25md
+
+import (
+	"regexp"
+	"strings"
+)
+
+// DefaultMaxRegexpScan caps how many dictionary terms a regexp query tests
+const DefaultMaxRegexpScan = 100_000
+
+// WithMaxRegexpScan caps how many dictionary terms a /regexp/ query tests, in
+// sorted order, bounding its cost on large vocabularies; matches are then capped
+// like prefix expansions (see WithMaxPrefixExpansions)
+func WithMaxRegexpScan(n int) CorpusOption {
+	return func(c *Corpus) {
+		c.maxRegexpScan = n
+	}
+}
+
+// parseRegexp compiles a /pattern/ query word to match whole index terms, ignoring
+// case since tokenizers usually lowercase. It returns nil for other words and
+// invalid patterns, which are then searched as plain words
+func (c *Corpus) parseRegexp(text string) *regexp.Regexp {
+	if len(text) < 3 || !strings.HasPrefix(text, "/") || !strings.HasSuffix(text, "/") {
+		return nil
+	}
+	re, err := regexp.Compile("(?i)^(?:" + text[1:len(text)-1] + ")$")
+	if err != nil {
+		c.logger().Debug("Invalid bm25md regexp query, searching it as words", "pattern", text, "error", err)
+		return nil
+	}
+	return re
+}
+
+// regexpScanLimit returns the number of dictionary terms a regexp query tests
+func (c *Corpus) regexpScanLimit() int {
+	if c.maxRegexpScan > 0 {
+		return c.maxRegexpScan
+	}
+	return DefaultMaxRegexpScan
+}
+
+// matchTerms returns the terms matching re among the first scan-limit terms
+func (c *Corpus) matchTerms(terms []string, re *regexp.Regexp) []string {
+	var matches []string
+	for _, term := range terms[:min(len(terms), c.regexpScanLimit())] {
+		if re.MatchString(term) {
+			matches = append(matches, term)
+		}
+	}
+	return matches
+}
+
+// prepareRegexp resolves a regexp query to a single query term combining the
+// postings of every matching index term
+func (c *Corpus) prepareRegexp(name string, re *regexp.Regexp, occur occur) (queryTerm, bool) {
+	return c.prepareExpansions(name, c.capExpansions(c.matchTerms(c.sortedTerms(), re)), occur)
+}
+
+// prepareRegexp combines the postings of the dictionary terms matching re
+func (ix *Index) prepareRegexp(re *regexp.Regexp) indexPostings {
+	var expansions []int
+	for i, term := range ix.terms[:min(len(ix.terms), ix.config.regexpScanLimit())] {
+		if re.MatchString(term) {
+			expansions = append(expansions, i)
+		}
+	}
+	return ix.combine(expansions)
+}
+
+// regexpPostings combines the postings of the stored terms matching re
+func (s *StoredCorpus) regexpPostings(tx StorageTx, re *regexp.Regexp) (postingList, error) {
+	terms, err := tx.Terms("")
+	if err != nil {
+		return nil, err
+	}
+	return s.combinePostings(tx, s.config.matchTerms(terms, re))
+}
@@ -0,0 +1,88 @@
+package bm25md
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"testing"
+)
+
+// errorCodeDocs returns documents mentioning error codes among unrelated filler
+func errorCodeDocs() []Document {
+	docs := []Document{
+		{Fields: map[Field]string{FieldBody: "The request failed with ERR_42_TIMEOUT after retries."}},
+		{Fields: map[Field]string{FieldCode: "return ERR_7_TIMEOUT"}},
+		{Fields: map[Field]string{FieldBody: "ERR_42_REFUSED means the connection was refused."}},
+	}
+	for i := range 10 {
+		docs = append(docs, Document{Fields: map[Field]string{FieldBody: fmt.Sprintf("Filler document %d about calendars.", i)}})
+	}
+	return docs
+}
+
+func TestCorpus_RegexpSearch(t *testing.T) {
+	corpus := NewCorpus()
+	corpus.AddDocuments(errorCodeDocs())
+
+	tests := []struct {
+		query    string
+		expected []int
+	}{
+		{query: `/ERR_\d+_TIMEOUT/`, expected: []int{0, 1}},
+		{query: `/err_42_.*/`, expected: []int{0, 2}},
+		{query: `/err_\d+_timeout/ -retries`, expected: []int{1}},
+		{query: `+/err_\d+_refused/ connection`, expected: []int{2}},
+		{query: `/TIMEOUT/`, expected: nil},
+		{query: `/[unclosed/`, expected: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			var got []int
+			for _, result := range corpus.Search(tt.query, 10) {
+				got = append(got, result.Index)
+			}
+			slices.Sort(got)
+			if !slices.Equal(got, tt.expected) {
+				t.Errorf("Search(%s) = %v, want %v", tt.query, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRegexpSearch_Engines(t *testing.T) {
+	ctx := context.Background()
+	corpus := NewCorpus()
+	corpus.AddDocuments(errorCodeDocs())
+	stored := NewStoredCorpus(NewMemoryStorage())
+	if err := stored.AddDocuments(ctx, errorCodeDocs()); err != nil {
+		t.Fatalf("AddDocuments() error = %v", err)
+	}
+
+	for _, query := range []string{`/err_\d+_timeout/`, `/err_.*/ refused`} {
+		want := corpus.Search(query, 10)
+		sameResults(t, corpus.Freeze().Search(query, 10), want)
+		got, err := stored.SearchWithOptions(ctx, query, SearchOptions{Limit: 10})
+		if err != nil {
+			t.Fatalf("SearchWithOptions() error = %v", err)
+		}
+		sameResults(t, got, want)
+	}
+}
+
+func TestWithMaxRegexpScan(t *testing.T) {
+	// only the first 3 dictionary terms are tested
+	corpus := NewCorpus(WithMaxRegexpScan(3))
+	corpus.AddDocuments(errorCodeDocs())
+	if got := corpus.matchTerms(corpus.sortedTerms(), corpus.parseRegexp(`/.*/`)); len(got) != 3 {
+		t.Errorf("matchTerms() = %q, want the first 3 terms", got)
+	}
+}
+
+func TestHighlight_Regexp(t *testing.T) {
+	corpus := NewCorpus()
+	got := corpus.Highlight(`/err_\d+_timeout/`, "Failed with ERR_42_TIMEOUT today", HighlightOptions{})
+	if want := "Failed with <mark>ERR_42_TIMEOUT</mark> today"; got != want {
+		t.Errorf("Highlight() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,65 @@
+package bm25md
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+// DefaultRerankCandidates is how many BM25 results are reranked when the caller
+// does not say
+const DefaultRerankCandidates = 50
+
+// Reranker rescores first-stage candidates for a query, eg with a cross-encoder
+// model behind an HTTP API. It returns one score per document, in order; higher
+// scores rank first
+type Reranker interface {
+	Rerank(ctx context.Context, query string, docs []Document) ([]float64, error)
+}
+
+// RerankerFunc adapts a function to the Reranker interface
+type RerankerFunc func(ctx context.Context, query string, docs []Document) ([]float64, error)
+
+// Rerank implements Reranker
+func (f RerankerFunc) Rerank(ctx context.Context, query string, docs []Document) ([]float64, error) {
+	return f(ctx, query, docs)
+}
+
+// SearchWithReranker retrieves the top candidates results (DefaultRerankCandidates
+// when zero, and at least limit) with BM25, then reorders them by the reranker's
+// scores and returns the best limit. Result scores are the reranker's; ties keep
+// their BM25 order
+func (c *Corpus) SearchWithReranker(ctx context.Context, query string, limit, candidates int, reranker Reranker) ([]SearchResult, error) {
+	if candidates <= 0 {
+		candidates = DefaultRerankCandidates
+	}
+	candidates = max(candidates, limit)
+
+	results, err := c.SearchContext(ctx, query, candidates)
+	if err != nil || len(results) == 0 {
+		return results, err
+	}
+
+	docs := make([]Document, len(results))
+	for i, result := range results {
+		docs[i] = result.Document
+	}
+	scores, err := reranker.Rerank(ctx, query, docs)
+	if err != nil {
+		return nil, fmt.Errorf("bm25md: reranking: %w", err)
+	}
+	if len(scores) != len(results) {
+		return nil, fmt.Errorf("bm25md: reranker returned %d scores for %d documents", len(scores), len(results))
+	}
+
+	for i := range results {
+		results[i].Score = scores[i]
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results, nil
+}
@@ -0,0 +1,65 @@
+package bm25md
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCorpus_SearchWithReranker(t *testing.T) {
+	corpus := NewCorpus()
+	for _, body := range []string{
+		"habeas corpus habeas corpus petition",
+		"habeas corpus appeal to the federal court",
+		"habeas corpus filing deadlines",
+		"court calendar",
+		"appeal rules",
+		"judge assignments",
+		"clerk contacts",
+	} {
+		corpus.AddDocument(Document{Fields: map[Field]string{FieldBody: body}})
+	}
+
+	// prefer documents mentioning federal courts, as a cross-encoder might
+	var seen int
+	federal := RerankerFunc(func(_ context.Context, query string, docs []Document) ([]float64, error) {
+		seen = len(docs)
+		scores := make([]float64, len(docs))
+		for i, doc := range docs {
+			if strings.Contains(doc.Fields[FieldBody], "federal") {
+				scores[i] = 1
+			}
+		}
+		return scores, nil
+	})
+
+	results, err := corpus.SearchWithReranker(context.Background(), "habeas", 2, 0, federal)
+	if err != nil {
+		t.Fatalf("SearchWithReranker() error = %v", err)
+	}
+	if seen != 3 {
+		t.Errorf("reranker saw %d candidates, want 3", seen)
+	}
+	if len(results) != 2 || results[0].Index != 1 || results[0].Score != 1 {
+		t.Fatalf("results = %+v, want document 1 first with the reranker's score", results)
+	}
+	// ties keep their BM25 order
+	if first := corpus.Search("habeas", 1); results[1].Index != first[0].Index {
+		t.Errorf("second result = %d, want BM25's top document %d", results[1].Index, first[0].Index)
+	}
+
+	failing := RerankerFunc(func(context.Context, string, []Document) ([]float64, error) {
+		return nil, errors.New("model unavailable")
+	})
+	if _, err := corpus.SearchWithReranker(context.Background(), "habeas", 2, 5, failing); err == nil {
+		t.Error("SearchWithReranker() with a failing reranker succeeded")
+	}
+
+	short := RerankerFunc(func(context.Context, string, []Document) ([]float64, error) {
+		return []float64{1}, nil
+	})
+	if _, err := corpus.SearchWithReranker(context.Background(), "habeas", 2, 5, short); err == nil {
+		t.Error("SearchWithReranker() with missing scores succeeded")
+	}
+}
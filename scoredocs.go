@@ -0,0 +1,28 @@
+package bm25md
+
+import (
+	"context"
+	"time"
+)
+
+// ScoreDocuments scores only the documents with the given external IDs for a
+// query, such as candidates from a vector store, and returns them ranked best
+// first without visiting the rest of the corpus. Unknown IDs and candidates
+// that do not match the query are omitted
+func (c *Corpus) ScoreDocuments(query string, externalIDs []string) []SearchResult {
+	start := time.Now()
+	queryTerms, terms := c.prepareQueryString(query)
+
+	docs := make([]int, 0, len(externalIDs))
+	seen := make(map[int]bool, len(externalIDs))
+	for _, externalID := range externalIDs {
+		if id, exists := c.LookupID(externalID); exists && c.isLive(id) && !seen[id] {
+			seen[id] = true
+			docs = append(docs, id)
+		}
+	}
+
+	top := newTopResults(0, 0)
+	c.searchSequential(context.Background(), terms, docs, top)
+	return c.finishSearch(context.Background(), query, queryTerms, top, SearchOptions{}, start, false)
+}
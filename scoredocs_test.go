@@ -0,0 +1,40 @@
+package bm25md
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestCorpus_ScoreDocuments(t *testing.T) {
+	corpus := randomCorpus(200)
+	all := corpus.Search("jury trial", 0)
+	if len(all) < 10 {
+		t.Fatalf("Search() returned %d results, want at least 10", len(all))
+	}
+
+	// candidates in arbitrary order, with a repeat, an unknown ID, and a non-match
+	var nonMatch string
+	matched := make(map[string]bool)
+	for _, result := range all {
+		matched[result.ExternalID] = true
+	}
+	for i := range 200 {
+		if id := fmt.Sprintf("doc-%d", i); !matched[id] {
+			nonMatch = id
+			break
+		}
+	}
+	candidates := []string{all[7].ExternalID, all[2].ExternalID, "missing", all[9].ExternalID, nonMatch, all[2].ExternalID}
+
+	got := corpus.ScoreDocuments("jury trial", candidates)
+	sameResults(t, got, []SearchResult{all[2], all[7], all[9]})
+	for _, result := range got {
+		if result.Document.ExternalID != result.ExternalID {
+			t.Errorf("result %d has document %q, want %q", result.Index, result.Document.ExternalID, result.ExternalID)
+		}
+	}
+
+	if got := corpus.ScoreDocuments("jury trial", nil); len(got) != 0 {
+		t.Errorf("ScoreDocuments() without candidates returned %d results", len(got))
+	}
+}
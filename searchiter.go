@@ -0,0 +1,100 @@
+package bm25md
+
+import (
+	"container/heap"
+	"context"
+	"iter"
+)
+
+// searchIterBatch is how many results Index.SearchIter retrieves first; each
+// later batch doubles it
+const searchIterBatch = 10
+
+// SearchIter returns an iterator over the results of a search, best first.
+// Scoring is eager: every matching document is scored before the first result
+// is yielded, and only ordering and assembling results happen as the loop asks
+// for them. For retrieval that stops scoring early, iterate a frozen Index
+// instead. The search hook is not called
+func (c *Corpus) SearchIter(query string) iter.Seq[SearchResult] {
+	return func(yield func(SearchResult) bool) {
+		_, terms := c.prepareQueryString(query)
+		top := newTopResults(0, 0)
+		c.collect(context.Background(), terms, SearchOptions{}, top, true)
+		c.yieldRanked(top.results, yield)
+	}
+}
+
+// SearchIter returns an iterator over the results of a search, best first.
+// Results are retrieved in batches of growing size (10, 20, 40, ...). Where
+// Block-Max WAND applies, each batch skips documents that cannot reach it, so a
+// loop that stops after the first few hits scores a fraction of the matches.
+// Each batch searches again, so a loop reading every result is better served by
+// Search(query, 0). Otherwise, like Corpus.SearchIter, every match is scored up
+// front. The search hook is not called
+func (ix *Index) SearchIter(query string) iter.Seq[SearchResult] {
+	return func(yield func(SearchResult) bool) {
+		_, terms := ix.prepareQuery(query, SearchOptions{})
+		if !ix.canUseWAND(terms, searchIterBatch) {
+			top := newTopResults(0, 0)
+			ix.collect(context.Background(), terms, SearchOptions{}, top)
+			ix.config.yieldRanked(top.results, yield)
+			return
+		}
+
+		// results rank in a total order, so each batch extends the previous one
+		yielded := 0
+		for k := searchIterBatch; ; k *= 2 {
+			if yielded > 0 {
+				_, terms = ix.prepareQuery(query, SearchOptions{})
+			}
+			top := newTopResults(k, 0)
+			ix.collectWAND(context.Background(), terms, SearchOptions{}, top)
+			results := top.sorted()
+			for _, result := range results[yielded:] {
+				if !yield(ix.config.assemble(result)) {
+					return
+				}
+			}
+			if len(results) < k {
+				return
+			}
+			yielded = len(results)
+		}
+	}
+}
+
+// yieldRanked yields results best first, attaching documents and calibrated
+// probabilities, until yield returns false
+func (c *Corpus) yieldRanked(results []SearchResult, yield func(SearchResult) bool) {
+	ranked := bestFirst(results)
+	heap.Init(&ranked)
+	for ranked.Len() > 0 {
+		if !yield(c.assemble(heap.Pop(&ranked).(SearchResult))) {
+			return
+		}
+	}
+}
+
+// assemble attaches a result's document and calibrated probability
+func (c *Corpus) assemble(result SearchResult) SearchResult {
+	result.Document = c.documents[result.Index]
+	result.ExternalID = result.Document.ExternalID
+	if c.calibrator != nil {
+		result.Probability = c.calibrator.Probability(result.Score)
+	}
+	return result
+}
+
+// bestFirst is a max-heap of results with the best-ranked result on top
+type bestFirst []SearchResult
+
+func (h bestFirst) Len() int           { return len(h) }
+func (h bestFirst) Less(i, j int) bool { return ranksBefore(h[i], h[j]) }
+func (h bestFirst) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *bestFirst) Push(x any)        { *h = append(*h, x.(SearchResult)) }
+func (h *bestFirst) Pop() any {
+	old := *h
+	result := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return result
+}
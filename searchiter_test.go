@@ -0,0 +1,74 @@
+package bm25md
+
+import (
+	"iter"
+	"sync/atomic"
+	"testing"
+)
+
+func TestSearchIter(t *testing.T) {
+	corpus := randomCorpus(300)
+	index := corpus.Freeze()
+	queries := []string{"habeas", "habeas corpus petition", "+federal court -state", "+missing court"}
+
+	for _, query := range queries {
+		t.Run(query, func(t *testing.T) {
+			want := corpus.Search(query, 0)
+			for name, results := range map[string]iter.Seq[SearchResult]{
+				"corpus": corpus.SearchIter(query),
+				"index":  index.SearchIter(query),
+			} {
+				var got []SearchResult
+				for result := range results {
+					if result.Document.ID != result.Index {
+						t.Errorf("%s: result %d has document %d", name, result.Index, result.Document.ID)
+					}
+					got = append(got, result)
+				}
+				sameResults(t, got, want)
+			}
+		})
+	}
+
+	// stopping early yields only the best results
+	var got []SearchResult
+	for result := range corpus.SearchIter("habeas") {
+		got = append(got, result)
+		if len(got) == 3 {
+			break
+		}
+	}
+	sameResults(t, got, corpus.Search("habeas", 3))
+}
+
+func TestIndex_SearchIter_Batches(t *testing.T) {
+	calls := new(atomic.Int64)
+	index := randomCorpus(3000, WithSimilarity(countingSimilarity{calls: calls})).Freeze()
+	query := "court constitution"
+
+	// reading past several batches matches a plain search
+	for _, n := range []int{1, searchIterBatch, searchIterBatch + 1, 7 * searchIterBatch} {
+		var got []SearchResult
+		for result := range index.SearchIter(query) {
+			got = append(got, result)
+			if len(got) == n {
+				break
+			}
+		}
+		sameResults(t, got, index.Search(query, n))
+	}
+
+	calls.Store(0)
+	for range index.SearchIter(query) {
+		break
+	}
+	first := calls.Load()
+
+	calls.Store(0)
+	index.SearchWithOptions(query, SearchOptions{})
+	full := calls.Load()
+
+	if first*2 > full {
+		t.Errorf("stopping after one result scored %d document terms, want well under the %d of a full search", first, full)
+	}
+}
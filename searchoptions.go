@@ -0,0 +1,76 @@
+package bm25md
+
+import (
+	"context"
+	"time"
+)
+
+// SearchOptions refines a search beyond the query itself
+type SearchOptions struct {
+	Limit    int     // maximum number of results (0 = unlimited)
+	Offset   int     // number of top results to skip, for pagination
+	MinScore float64 // drop results scoring below this threshold
+	Fields   []Field // only match and score these fields (empty = all fields)
+	Filter   Filter  // only return documents accepted by this filter (eg MetadataEquals)
+	Docs     *DocSet // only return documents in this set (eg a cached FilterSet)
+	Ranges   []Range // only return documents whose metadata falls in every range
+	Dedupe   float64 // drop results whose terms are at least this similar (Jaccard, 0-1) to a higher-ranked result (0 = keep all)
+}
+
+// SearchWithOptions performs a search like Search, with pagination, a minimum
+// score, and optional restriction to specific fields (eg only headers) or to
+// documents accepted by a filter
+func (c *Corpus) SearchWithOptions(query string, opts SearchOptions) []SearchResult {
+	start := time.Now()
+	queryTerms, terms := c.prepareQueryString(query)
+	results, _ := c.search(context.Background(), query, queryTerms, terms, opts, start)
+	return results
+}
+
+// restrictFields narrows each term's postings to the given fields, dropping
+// documents where the term only occurs elsewhere
+func restrictFields(terms []queryTerm, fields []Field) []queryTerm {
+	if len(fields) == 0 {
+		return terms
+	}
+	allowed := make(map[Field]bool, len(fields))
+	for _, field := range fields {
+		allowed[field] = true
+	}
+
+	restricted := make([]queryTerm, len(terms))
+	for i, qt := range terms {
+		postings := make(postingList)
+		for docIndex, freqs := range qt.postings {
+			var kept map[Field]int
+			for field, tf := range freqs {
+				if allowed[field] {
+					if kept == nil {
+						kept = make(map[Field]int, len(freqs))
+					}
+					kept[field] = tf
+				}
+			}
+			if kept != nil {
+				postings[docIndex] = kept
+			}
+		}
+		qt.postings = postings
+		restricted[i] = qt
+	}
+	return restricted
+}
+
+// page applies the offset and limit to ranked results
+func (opts SearchOptions) page(results []SearchResult) []SearchResult {
+	if opts.Offset > 0 {
+		if opts.Offset >= len(results) {
+			return []SearchResult{}
+		}
+		results = results[opts.Offset:]
+	}
+	if opts.Limit > 0 && len(results) > opts.Limit {
+		results = results[:opts.Limit]
+	}
+	return results
+}
@@ -0,0 +1,77 @@
+package bm25md
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func TestCorpus_SearchWithOptions(t *testing.T) {
+	corpus := NewCorpus()
+	// writ appears in headers for the first documents and in body text for the rest
+	for i := 0; i < 6; i++ {
+		fields := map[Field]string{FieldBody: fmt.Sprintf("filler text %d", i)}
+		if i < 2 {
+			fields[FieldH1] = "writ"
+		} else if i < 4 {
+			fields[FieldBody] += " writ"
+		}
+		corpus.AddDocument(Document{Fields: fields})
+	}
+	for i := 0; i < 6; i++ {
+		corpus.AddDocument(Document{Fields: map[Field]string{FieldBody: "unrelated court calendar"}})
+	}
+
+	all := corpus.Search("writ", 0)
+	if len(all) != 4 {
+		t.Fatalf("Search() returned %d results, want 4", len(all))
+	}
+
+	tests := []struct {
+		name     string
+		opts     SearchOptions
+		expected []int
+	}{
+		{"defaults", SearchOptions{}, []int{all[0].Index, all[1].Index, all[2].Index, all[3].Index}},
+		{"limit", SearchOptions{Limit: 2}, []int{all[0].Index, all[1].Index}},
+		{"offset", SearchOptions{Offset: 1, Limit: 2}, []int{all[1].Index, all[2].Index}},
+		{"offset past end", SearchOptions{Offset: 10}, []int{}},
+		{"min score", SearchOptions{MinScore: all[1].Score}, []int{all[0].Index, all[1].Index}},
+		{"headers only", SearchOptions{Fields: []Field{FieldH1}}, []int{0, 1}},
+		{"body only", SearchOptions{Fields: []Field{FieldBody}}, []int{2, 3}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := corpus.SearchWithOptions("writ", tt.opts)
+			if len(results) != len(tt.expected) {
+				t.Fatalf("got %d results, want %d: %+v", len(results), len(tt.expected), results)
+			}
+			for i, result := range results {
+				if tt.opts.Fields == nil && result.Index != tt.expected[i] {
+					t.Errorf("result %d = document %d, want %d", i, result.Index, tt.expected[i])
+				}
+				if tt.opts.Fields != nil && !slices.Contains(tt.expected, result.Index) {
+					t.Errorf("result %d = document %d, want one of %v", i, result.Index, tt.expected)
+				}
+			}
+		})
+	}
+}
+
+func TestCorpus_SearchWithOptionsTotalHits(t *testing.T) {
+	corpus, _ := createTestCorpus()
+
+	var stats SearchStats
+	corpus.searchHook = func(query string, results []SearchResult, s SearchStats) { stats = s }
+
+	all := corpus.Search("shut eyes world dead", 0)
+	results := corpus.SearchWithOptions("shut eyes world dead", SearchOptions{Limit: 1, Offset: 1})
+	if len(all) > 1 && (len(results) != 1 || results[0].Index != all[1].Index) {
+		t.Errorf("SearchWithOptions() = %+v, want second result %d", results, all[1].Index)
+	}
+	// total hits count every match, not just the returned page
+	if stats.TotalHits != len(all) {
+		t.Errorf("TotalHits = %d, want %d", stats.TotalHits, len(all))
+	}
+}
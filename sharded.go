@@ -0,0 +1,319 @@
+package bm25md
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"runtime"
+	"sort"
+	"time"
+)
+
+// ShardedCorpus partitions documents across several corpora that are indexed and
+// searched in parallel, merging each shard's top results into one ranking. Scores
+// use statistics summed over every shard, so they match a single Corpus holding
+// the same documents.
+//
+// Documents with an ExternalID always go to the same shard (so re-adding one
+// replaces it), and the rest are spread round-robin. IDs interleave shards: a
+// document's ID is its ID within its shard times the number of shards, plus the
+// shard number
+type ShardedCorpus struct {
+	shards []*Corpus
+	next   int // shard for the next document without an external ID
+}
+
+// NewShardedCorpus creates a corpus split into n shards (n <= 0 uses one shard
+// per CPU), each configured with opts
+func NewShardedCorpus(n int, opts ...CorpusOption) *ShardedCorpus {
+	if n <= 0 {
+		n = runtime.NumCPU()
+	}
+	s := &ShardedCorpus{shards: make([]*Corpus, n)}
+	for i := range s.shards {
+		s.shards[i] = NewCorpus(opts...)
+	}
+	return s
+}
+
+// Shards returns the number of shards
+func (s *ShardedCorpus) Shards() int {
+	return len(s.shards)
+}
+
+// route picks the shard a document belongs to
+func (s *ShardedCorpus) route(doc Document) int {
+	if doc.ExternalID != "" {
+		return s.shardFor(doc.ExternalID)
+	}
+	shard := s.next
+	s.next = (s.next + 1) % len(s.shards)
+	return shard
+}
+
+// shardFor hashes an external ID to its shard
+func (s *ShardedCorpus) shardFor(externalID string) int {
+	h := fnv.New32a()
+	h.Write([]byte(externalID))
+	return int(h.Sum32() % uint32(len(s.shards)))
+}
+
+// globalID converts a shard's document ID to a sharded corpus ID
+func (s *ShardedCorpus) globalID(shard, local int) int {
+	return local*len(s.shards) + shard
+}
+
+// locate splits a sharded corpus ID into its shard and the ID within that shard
+func (s *ShardedCorpus) locate(id int) (int, int) {
+	return id % len(s.shards), id / len(s.shards)
+}
+
+// AddDocument adds a document to its shard, replacing any document with the same ExternalID
+func (s *ShardedCorpus) AddDocument(doc Document) {
+	s.shards[s.route(doc)].AddDocument(doc)
+}
+
+// AddDocuments adds many documents at once, indexing each shard's share in parallel
+func (s *ShardedCorpus) AddDocuments(docs []Document) {
+	batches := make([][]Document, len(s.shards))
+	for _, doc := range docs {
+		shard := s.route(doc)
+		batches[shard] = append(batches[shard], doc)
+	}
+	s.each(func(shard int, corpus *Corpus) {
+		if len(batches[shard]) > 0 {
+			corpus.AddDocuments(batches[shard])
+		}
+	})
+	s.shards[0].logger().Debug("Added documents to sharded BM25md corpus", "documents", len(docs), "shards", len(s.shards))
+}
+
+// RemoveDocument removes a document from its shard
+func (s *ShardedCorpus) RemoveDocument(id int) error {
+	if id < 0 {
+		return fmt.Errorf("bm25md: no document with ID %d", id)
+	}
+	shard, local := s.locate(id)
+	if !s.shards[shard].isLive(local) {
+		return fmt.Errorf("bm25md: no document with ID %d", id)
+	}
+	return s.shards[shard].RemoveDocument(local)
+}
+
+// LookupID returns the ID of the document with the given external ID
+func (s *ShardedCorpus) LookupID(externalID string) (int, bool) {
+	if externalID == "" {
+		return 0, false
+	}
+	shard := s.shardFor(externalID)
+	local, exists := s.shards[shard].LookupID(externalID)
+	if !exists {
+		return 0, false
+	}
+	return s.globalID(shard, local), true
+}
+
+// Documents returns the indexed documents of every shard, ordered by ID
+func (s *ShardedCorpus) Documents() []Document {
+	var documents []Document
+	for shard, corpus := range s.shards {
+		for _, doc := range corpus.Documents() {
+			doc.ID = s.globalID(shard, doc.ID)
+			documents = append(documents, doc)
+		}
+	}
+	sort.Slice(documents, func(i, j int) bool { return documents[i].ID < documents[j].ID })
+	return documents
+}
+
+// Search performs a search across every shard, like Corpus.Search
+func (s *ShardedCorpus) Search(query string, limit int) []SearchResult {
+	results, _ := s.search(context.Background(), query, SearchOptions{Limit: limit})
+	return results
+}
+
+// SearchContext performs a search like Search that stops promptly once ctx is
+// cancelled or its deadline passes, returning the context's error
+func (s *ShardedCorpus) SearchContext(ctx context.Context, query string, limit int) ([]SearchResult, error) {
+	return s.search(ctx, query, SearchOptions{Limit: limit})
+}
+
+// SearchWithOptions performs a search like Search with pagination, filters, and
+// field restrictions. Filters and document sets see sharded corpus IDs
+func (s *ShardedCorpus) SearchWithOptions(query string, opts SearchOptions) []SearchResult {
+	results, _ := s.search(context.Background(), query, opts)
+	return results
+}
+
+// search resolves the query on every shard, rescores it with corpus-wide
+// statistics, then collects each shard's top results and merges them
+func (s *ShardedCorpus) search(ctx context.Context, query string, opts SearchOptions) ([]SearchResult, error) {
+	start := time.Now()
+	ctx, span := s.shards[0].startSpan(ctx, "bm25md.Search", start)
+	defer span.End()
+	span.SetAttribute("bm25md.shards", len(s.shards))
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var queryTerms []string
+	prepared := make([][]queryTerm, len(s.shards))
+	s.each(func(shard int, corpus *Corpus) {
+		tokens, terms := corpus.prepareQueryString(query)
+		prepared[shard] = terms
+		if shard == 0 {
+			queryTerms = tokens
+		}
+	})
+	s.rescore(prepared)
+
+	k := opts.resultCap()
+	tops := make([]*topResults, len(s.shards))
+	s.each(func(shard int, corpus *Corpus) {
+		tops[shard] = newTopResults(k, opts.MinScore)
+		corpus.collect(ctx, prepared[shard], s.shardOptions(opts, shard), tops[shard], false)
+	})
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	top := newTopResults(k, opts.MinScore)
+	for shard, shardTop := range tops {
+		// renumbering keeps each shard's order, so its heap stays valid
+		for i := range shardTop.results {
+			shardTop.results[i].Index = s.globalID(shard, shardTop.results[i].Index)
+		}
+		top.merge(shardTop)
+	}
+	span.SetAttribute("bm25md.hits", top.hits)
+	return s.finishSearch(query, queryTerms, top, opts, start), nil
+}
+
+// each runs fn on every shard in parallel on pool workers and waits for all of them
+func (s *ShardedCorpus) each(fn func(shard int, corpus *Corpus)) {
+	pool.forEach(len(s.shards), len(s.shards), 1, func(_, shard, _ int) {
+		fn(shard, s.shards[shard])
+	})
+}
+
+// rescore replaces each prepared term's scorer with one built from statistics
+// summed over every shard, scoring against corpus-wide average field lengths
+func (s *ShardedCorpus) rescore(prepared [][]queryTerm) {
+	totalDocs, totalTokens := 0, 0
+	fieldLengths, fieldDocs := make(map[Field]int), make(map[Field]int)
+	for _, corpus := range s.shards {
+		totalDocs += corpus.liveDocuments()
+		totalTokens += corpus.totalTokens()
+		for field, scorer := range corpus.fieldScorers {
+			fieldLengths[field] += scorer.totalLength
+			fieldDocs[field] += scorer.fieldDocs
+		}
+	}
+	avgLengths := make(map[Field]float64, len(fieldDocs))
+	for field, docs := range fieldDocs {
+		if docs > 0 {
+			avgLengths[field] = float64(fieldLengths[field]) / float64(docs)
+		}
+	}
+
+	// a repeated term is counted once per shard
+	termStats := make(map[string]TermStats)
+	for _, terms := range prepared {
+		seen := make(map[string]bool, len(terms))
+		for _, qt := range terms {
+			if qt.scorer == nil || seen[qt.term] {
+				continue
+			}
+			seen[qt.term] = true
+			stats := termStats[qt.term]
+			stats.Term = qt.term
+			stats.DocFreq += qt.stats.DocFreq
+			stats.TotalTermFreq += qt.stats.TotalTermFreq
+			termStats[qt.term] = stats
+		}
+	}
+
+	config := s.shards[0]
+	for _, terms := range prepared {
+		for i, qt := range terms {
+			if qt.scorer == nil {
+				continue
+			}
+			stats := termStats[qt.term]
+			stats.TotalDocs, stats.TotalTokens = totalDocs, totalTokens
+			scorer := config.similarity.TermScorer(stats, config.params)
+			terms[i].stats = stats
+			terms[i].scorer = func(matches []FieldMatch) float64 {
+				for j := range matches {
+					matches[j].AvgLength = avgLengths[matches[j].Field]
+				}
+				return scorer(matches)
+			}
+		}
+	}
+}
+
+// shardOptions translates search options for one shard, mapping filters and
+// document sets from sharded corpus IDs to the shard's IDs
+func (s *ShardedCorpus) shardOptions(opts SearchOptions, shard int) SearchOptions {
+	opts.Limit, opts.Offset = 0, 0 // paging applies to the merged results
+	if filter := opts.Filter; filter != nil {
+		opts.Filter = func(doc Document) bool {
+			doc.ID = s.globalID(shard, doc.ID)
+			return filter(doc)
+		}
+	}
+	if opts.Docs != nil {
+		docs := &DocSet{}
+		for _, id := range opts.Docs.IDs() {
+			if owner, local := s.locate(id); owner == shard {
+				docs.Add(local)
+			}
+		}
+		opts.Docs = docs
+	}
+	return opts
+}
+
+// finishSearch pages merged results, attaches their documents and calibrated
+// probabilities, and reports the search to the hook
+func (s *ShardedCorpus) finishSearch(query string, queryTerms []string, top *topResults, opts SearchOptions, start time.Time) []SearchResult {
+	results, _ := s.shards[0].dedupe(top.sorted(), opts, func(index int) (Document, error) {
+		shard, local := s.locate(index)
+		return s.shards[shard].documents[local], nil
+	})
+	results = opts.page(results)
+	for i := range results {
+		shard, local := s.locate(results[i].Index)
+		doc := s.shards[shard].documents[local]
+		doc.ID = results[i].Index
+		results[i].Document = doc
+		results[i].ExternalID = doc.ExternalID
+	}
+
+	// every shard shares the same options, so the first holds the calibrator and hook
+	config := s.shards[0]
+	if config.calibrator != nil {
+		for i := range results {
+			results[i].Probability = config.calibrator.Probability(results[i].Score)
+		}
+	}
+	duration := time.Since(start)
+	config.logger().Debug("Searched sharded BM25md corpus", "query", query, "hits", top.hits, "results", len(results), "shards", len(s.shards), "duration", duration)
+	if config.searchHook != nil {
+		documents := 0
+		for _, corpus := range s.shards {
+			documents += corpus.liveDocuments()
+		}
+		config.searchHook(query, results, SearchStats{
+			QueryTerms: queryTerms,
+			TotalHits:  top.hits,
+			Candidates: top.candidates,
+			Scored:     top.scored,
+			Documents:  documents,
+			Duration:   duration,
+			Parallel:   len(s.shards) > 1,
+		})
+	}
+	return results
+}
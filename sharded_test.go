@@ -0,0 +1,144 @@
+package bm25md
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"testing"
+)
+
+// sameShardedResults compares sharded results with a single corpus's by score,
+// and by external ID where scores are not tied
+func sameShardedResults(t *testing.T, got, want []SearchResult) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("got %d results, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if math.Abs(got[i].Score-want[i].Score) > 1e-9 {
+			t.Fatalf("result %d score = %v, want %v", i, got[i].Score, want[i].Score)
+		}
+		tied := (i > 0 && math.Abs(want[i].Score-want[i-1].Score) <= 1e-9) ||
+			(i < len(want)-1 && math.Abs(want[i].Score-want[i+1].Score) <= 1e-9)
+		if !tied && got[i].ExternalID != want[i].ExternalID {
+			t.Fatalf("result %d = %q, want %q", i, got[i].ExternalID, want[i].ExternalID)
+		}
+		if got[i].Document.ID != got[i].Index {
+			t.Fatalf("result %d has document ID %d, want %d", i, got[i].Document.ID, got[i].Index)
+		}
+	}
+}
+
+func TestShardedCorpus_MatchesCorpus(t *testing.T) {
+	corpus := randomCorpus(500)
+	queries := []string{
+		"habeas",
+		"habeas corpus petition",
+		"+federal court -state",
+		`"habeas corpus" review`,
+		"habeas NEAR/3 petition",
+		"constitu*",
+		"+missing court",
+	}
+	options := []SearchOptions{
+		{Limit: 10},
+		{Limit: 5, Offset: 5},
+		{MinScore: 2},
+		{Limit: 10, Fields: []Field{FieldH1}},
+		{Limit: 10, Filter: MetadataEquals("even", true)},
+		{Limit: 10, Dedupe: 0.3},
+		{Limit: 10, Ranges: []Range{{Key: "n", Min: 100, Max: 199}}},
+	}
+
+	for _, shards := range []int{1, 3, 8} {
+		sharded := NewShardedCorpus(shards)
+		sharded.AddDocuments(corpus.Documents())
+		for _, query := range queries {
+			for _, opts := range options {
+				t.Run(fmt.Sprintf("%d/%s/%+v", shards, query, opts), func(t *testing.T) {
+					sameShardedResults(t, sharded.SearchWithOptions(query, opts), corpus.SearchWithOptions(query, opts))
+				})
+			}
+		}
+	}
+}
+
+func TestShardedCorpus_Documents(t *testing.T) {
+	sharded := NewShardedCorpus(4)
+	for i := 0; i < 10; i++ {
+		sharded.AddDocument(Document{ExternalID: fmt.Sprintf("doc-%d", i), Fields: map[Field]string{FieldBody: "habeas corpus"}})
+	}
+	sharded.AddDocument(Document{Fields: map[Field]string{FieldBody: "anonymous petition"}})
+	// re-adding an external ID replaces the document in its shard
+	sharded.AddDocument(Document{ExternalID: "doc-3", Fields: map[Field]string{FieldBody: "amended petition"}})
+
+	docs := sharded.Documents()
+	if len(docs) != 11 {
+		t.Fatalf("Documents() returned %d documents, want 11", len(docs))
+	}
+	for i := 1; i < len(docs); i++ {
+		if docs[i].ID <= docs[i-1].ID {
+			t.Fatal("Documents() is not ordered by ID")
+		}
+	}
+
+	id, exists := sharded.LookupID("doc-3")
+	if !exists {
+		t.Fatal("LookupID(doc-3) not found")
+	}
+	results := sharded.Search("amended", 10)
+	if len(results) != 1 || results[0].Index != id || results[0].ExternalID != "doc-3" {
+		t.Fatalf("Search(amended) = %+v, want doc-3 with ID %d", results, id)
+	}
+
+	// filters and document sets see sharded IDs
+	opts := SearchOptions{Filter: func(doc Document) bool { return doc.ID == id }}
+	if results := sharded.SearchWithOptions("petition", opts); len(results) != 1 || results[0].Index != id {
+		t.Errorf("filtered search returned %d results, want document %d", len(results), id)
+	}
+	opts = SearchOptions{Docs: NewDocSet(id)}
+	if results := sharded.SearchWithOptions("petition", opts); len(results) != 1 || results[0].Index != id {
+		t.Errorf("search within a document set returned %d results, want document %d", len(results), id)
+	}
+
+	if err := sharded.RemoveDocument(id); err != nil {
+		t.Fatalf("RemoveDocument() error = %v", err)
+	}
+	if err := sharded.RemoveDocument(id); err == nil {
+		t.Error("removing a removed document should fail")
+	}
+	if _, exists := sharded.LookupID("doc-3"); exists {
+		t.Error("removed document is still found by external ID")
+	}
+	if got := len(sharded.Search("amended", 10)); got != 0 {
+		t.Errorf("Search(amended) after removal returned %d results", got)
+	}
+}
+
+func TestShardedCorpus_Hook(t *testing.T) {
+	var calls int
+	var stats SearchStats
+	hook := func(query string, results []SearchResult, s SearchStats) {
+		calls++
+		stats = s
+	}
+	sharded := NewShardedCorpus(3, WithSearchHook(hook))
+	sharded.AddDocuments(randomCorpus(60).Documents())
+
+	results, err := sharded.SearchContext(context.Background(), "custody", 2)
+	if err != nil {
+		t.Fatalf("SearchContext() error = %v", err)
+	}
+	if calls != 1 {
+		t.Fatalf("hook called %d times, want 1", calls)
+	}
+	if stats.Documents != 60 || stats.TotalHits < len(results) {
+		t.Errorf("hook stats = %+v", stats)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := sharded.SearchContext(ctx, "custody", 2); err == nil {
+		t.Error("SearchContext() with a cancelled context should fail")
+	}
+}
@@ -0,0 +1,77 @@
+package bm25md
+
+import "strings"
+
+// ShingleSeparator joins the words of a shingle; tokenizers never produce it
+// inside a token, so shingles cannot collide with single-word terms
+const ShingleSeparator = " "
+
+// shingleSize is the range of shingle lengths indexed by WithShingles
+type shingleSize struct {
+	min, max int
+}
+
+// ShingleFilter appends shingles (runs of minWords to maxWords adjacent tokens,
+// eg "habeas corpus") after the tokens themselves. The query parser splits
+// queries into words before tokenizing, so to match shingles in queries use
+// WithShingles rather than a shingle filter in the corpus analyzer
+func ShingleFilter(minWords, maxWords int) TokenFilter {
+	size := shingleSize{min: max(minWords, 2), max: maxWords}
+	return TokenFilterFunc(func(tokens []string) []string {
+		return size.appendShingles(append(make([]string, 0, 2*len(tokens)), tokens...), tokens)
+	})
+}
+
+// WithShingles indexes runs of minWords to maxWords adjacent tokens as extra
+// terms and adds the same shingles of adjacent query words as optional clauses,
+// so documents containing a multi-word concept ("machine learning") outrank
+// those mentioning its words apart, without writing a quoted phrase
+func WithShingles(minWords, maxWords int) CorpusOption {
+	return func(c *Corpus) {
+		c.shingles = shingleSize{min: max(minWords, 2), max: maxWords}
+	}
+}
+
+// enabled reports whether shingles are indexed
+func (s shingleSize) enabled() bool {
+	return s.min >= 2 && s.max >= s.min
+}
+
+// appendShingles appends the shingles of tokens in the size range to out; they
+// follow every single token, so the positions used for phrases are unchanged
+func (s shingleSize) appendShingles(out, tokens []string) []string {
+	if !s.enabled() {
+		return out
+	}
+	for i := range tokens {
+		for n := s.min; n <= s.max && i+n <= len(tokens); n++ {
+			out = append(out, strings.Join(tokens[i:i+n], ShingleSeparator))
+		}
+	}
+	return out
+}
+
+// shingleClauses appends optional clauses for the shingles of each run of
+// adjacent single-word query clauses (phrases, prefixes, and excluded terms end a run)
+func (s shingleSize) shingleClauses(clauses []queryClause) []queryClause {
+	if !s.enabled() {
+		return clauses
+	}
+	var shingles []queryClause
+	var run []string
+	flush := func() {
+		for _, shingle := range s.appendShingles(nil, run) {
+			shingles = append(shingles, queryClause{tokens: []string{shingle}, occur: occurShould})
+		}
+		run = run[:0]
+	}
+	for _, clause := range clauses {
+		if !clause.isTerm() || clause.occur == occurMustNot {
+			flush()
+			continue
+		}
+		run = append(run, clause.tokens[0])
+	}
+	flush()
+	return append(clauses, shingles...)
+}
@@ -0,0 +1,104 @@
+package bm25md
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestShingleFilter(t *testing.T) {
+	tests := []struct {
+		name     string
+		min, max int
+		input    []string
+		expected []string
+	}{
+		{
+			name:     "bigrams",
+			min:      2,
+			max:      2,
+			input:    []string{"habeas", "corpus", "petition"},
+			expected: []string{"habeas", "corpus", "petition", "habeas corpus", "corpus petition"},
+		},
+		{
+			name:     "bigrams and trigrams",
+			min:      2,
+			max:      3,
+			input:    []string{"machine", "learning", "models"},
+			expected: []string{"machine", "learning", "models", "machine learning", "machine learning models", "learning models"},
+		},
+		{
+			name:     "single token",
+			min:      2,
+			max:      3,
+			input:    []string{"writ"},
+			expected: []string{"writ"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ShingleFilter(tt.min, tt.max).Filter(tt.input); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Filter(%q) = %q, want %q", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestShingleClauses(t *testing.T) {
+	corpus := NewCorpus(WithShingles(2, 3))
+	tests := []struct {
+		query    string
+		expected []string
+	}{
+		{query: "machine learning", expected: []string{"machine", "learning", "machine learning"}},
+		{query: "deep machine learning", expected: []string{"deep", "machine", "learning", "deep machine", "deep machine learning", "machine learning"}},
+		{query: "machine -learning models", expected: []string{"machine", "learning", "models"}},
+		{query: `"habeas corpus" petition`, expected: []string{"habeas", "corpus", "petition"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			var got []string
+			for _, clause := range corpus.parseQuery(tt.query) {
+				got = append(got, clause.tokens...)
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("parseQuery(%q) tokens = %q, want %q", tt.query, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestWithShingles(t *testing.T) {
+	docs := []Document{
+		{Fields: map[Field]string{FieldBody: "Learning about machine maintenance and shop safety."}},
+		{Fields: map[Field]string{FieldBody: "Machine learning models for legal research."}},
+	}
+	for i := range 10 {
+		docs = append(docs, Document{Fields: map[Field]string{FieldBody: fmt.Sprintf("Filler document %d about calendars.", i)}})
+	}
+
+	corpus := NewCorpus(WithShingles(2, 2))
+	corpus.AddDocuments(docs)
+	results := corpus.Search("machine learning", 10)
+	if len(results) != 2 || results[0].Document.ID != 1 {
+		t.Fatalf("Search(machine learning) = %+v, want document 1 first", results)
+	}
+	if results[0].Score <= results[1].Score {
+		t.Errorf("shingle match scored %v, want more than %v", results[0].Score, results[1].Score)
+	}
+
+	// shingles follow the single tokens, so phrases still match
+	if results := corpus.Search(`"legal research"`, 10); len(results) != 1 || results[0].Document.ID != 1 {
+		t.Errorf(`Search("legal research") = %+v, want document 1`, results)
+	}
+
+	// shingles are extra postings, so field lengths count words only
+	plain := NewCorpus()
+	plain.AddDocuments(docs)
+	if got, want := corpus.fieldScorers[FieldBody].docLengths, plain.fieldScorers[FieldBody].docLengths; !reflect.DeepEqual(got, want) {
+		t.Errorf("body lengths with shingles = %v, want %v", got, want)
+	}
+	sameResults(t, corpus.Search(`"legal research"`, 10), plain.Search(`"legal research"`, 10))
+}
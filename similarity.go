@@ -0,0 +1,160 @@
+package bm25md
+
+import "math"
+
+// TermStats describes a query term across the corpus
+type TermStats struct {
+	Term          string // the term (or phrase/prefix description)
+	DocFreq       int    // live documents containing the term
+	TotalDocs     int    // live documents in the corpus
+	TotalTermFreq int    // occurrences of the term across all documents and fields
+	TotalTokens   int    // tokens across all documents and fields
+}
+
+// FieldMatch describes a query term's occurrences in one field of a document
+type FieldMatch struct {
+	Field     Field
+	Freq      int            // occurrences of the term in the field
+	Length    int            // tokens in the field
+	AvgLength float64        // average length of the field over documents that have it
+	Weight    float64        // field weight
+	Params    BM25Parameters // the field's BM25 parameters
+
+	norm float64 // precomputed lengthNorm (0 = compute on demand)
+}
+
+// lengthNorm returns the field's relative length under B (1 for average length)
+func (m FieldMatch) lengthNorm() float64 {
+	if m.norm > 0 {
+		return m.norm
+	}
+	if m.AvgLength == 0 {
+		return 1
+	}
+	norm := 1 - m.Params.B + m.Params.B*float64(m.Length)/m.AvgLength
+	if norm <= 0 {
+		return 1
+	}
+	return norm
+}
+
+// TermScorer scores a document from a query term's matches in its fields
+type TermScorer func(matches []FieldMatch) float64
+
+// Similarity is a ranking function. It is asked for a TermScorer once per query
+// term, so corpus-wide statistics (eg IDF) are computed once per query rather
+// than per document; params are the corpus BM25 parameters
+type Similarity interface {
+	TermScorer(stats TermStats, params BM25Parameters) TermScorer
+}
+
+// WithSimilarity sets the ranking function (default BM25FSimilarity)
+func WithSimilarity(similarity Similarity) CorpusOption {
+	return func(c *Corpus) {
+		c.similarity = similarity
+	}
+}
+
+// bm25IDF is the BM25 inverse document frequency, floored at zero for small corpora
+func bm25IDF(stats TermStats) float64 {
+	df := float64(stats.DocFreq)
+	return max(math.Log((float64(stats.TotalDocs)-df+0.5)/(df+0.5)), 0)
+}
+
+// BM25FSimilarity is the default ranking function: each field's term frequency
+// is normalized by the field's length (using that field's B), weighted, summed,
+// and then saturated once with the corpus K1, so repeats across fields cannot stack.
+// A corpus Delta turns it into BM25+, which adds Delta to every matching term so
+// a mention in a very long field still outscores no mention at all
+type BM25FSimilarity struct{}
+
+// TermScorer implements Similarity
+func (BM25FSimilarity) TermScorer(stats TermStats, params BM25Parameters) TermScorer {
+	idf := bm25IDF(stats)
+	k1, delta := params.K1, params.Delta
+	return func(matches []FieldMatch) float64 {
+		weightedTF := 0.0
+		for _, m := range matches {
+			weightedTF += m.Weight * float64(m.Freq) / m.lengthNorm()
+		}
+		if weightedTF <= 0 {
+			return 0
+		}
+		return idf * (weightedTF*(k1+1)/(weightedTF+k1) + delta)
+	}
+}
+
+// BM25LSimilarity is BM25F with BM25L's shifted term frequency, which keeps very
+// long fields from being normalized into irrelevance. Delta defaults to 0.5
+type BM25LSimilarity struct {
+	Delta float64
+}
+
+// TermScorer implements Similarity
+func (s BM25LSimilarity) TermScorer(stats TermStats, params BM25Parameters) TermScorer {
+	delta := s.Delta
+	if delta == 0 {
+		delta = 0.5
+	}
+	// BM25L uses log((N + 1) / (df + 0.5)), which stays positive for common terms
+	idf := math.Log((float64(stats.TotalDocs) + 1) / (float64(stats.DocFreq) + 0.5))
+	k1 := params.K1
+	return func(matches []FieldMatch) float64 {
+		weightedTF := 0.0
+		for _, m := range matches {
+			if m.Freq > 0 {
+				weightedTF += m.Weight * (float64(m.Freq)/m.lengthNorm() + delta)
+			}
+		}
+		if weightedTF <= 0 {
+			return 0
+		}
+		return idf * weightedTF * (k1 + 1) / (weightedTF + k1)
+	}
+}
+
+// TFIDFSimilarity is classic TF-IDF: the square root of each field's term
+// frequency, divided by the square root of the field length, weighted and summed,
+// times the squared IDF
+type TFIDFSimilarity struct{}
+
+// TermScorer implements Similarity
+func (TFIDFSimilarity) TermScorer(stats TermStats, _ BM25Parameters) TermScorer {
+	idf := 1 + math.Log(float64(stats.TotalDocs+1)/float64(stats.DocFreq+1))
+	return func(matches []FieldMatch) float64 {
+		score := 0.0
+		for _, m := range matches {
+			if m.Length > 0 {
+				score += m.Weight * math.Sqrt(float64(m.Freq)) / math.Sqrt(float64(m.Length))
+			}
+		}
+		return score * idf * idf
+	}
+}
+
+// LMDirichletSimilarity ranks by query likelihood under a language model of each
+// field smoothed with the corpus model (Dirichlet prior Mu, default 2000). Field
+// scores are weighted and summed; negative field scores count as zero
+type LMDirichletSimilarity struct {
+	Mu float64
+}
+
+// TermScorer implements Similarity
+func (s LMDirichletSimilarity) TermScorer(stats TermStats, _ BM25Parameters) TermScorer {
+	mu := s.Mu
+	if mu == 0 {
+		mu = 2000
+	}
+	// probability of the term in the corpus, smoothed so unseen terms stay finite
+	collectionProb := (float64(stats.TotalTermFreq) + 1) / (float64(stats.TotalTokens) + 1)
+	return func(matches []FieldMatch) float64 {
+		score := 0.0
+		for _, m := range matches {
+			fieldScore := math.Log(1+float64(m.Freq)/(mu*collectionProb)) + math.Log(mu/(float64(m.Length)+mu))
+			if fieldScore > 0 {
+				score += m.Weight * fieldScore
+			}
+		}
+		return score
+	}
+}
@@ -0,0 +1,133 @@
+package bm25md
+
+import (
+	"math"
+	"testing"
+)
+
+// constantSimilarity scores every matching term as 1
+type constantSimilarity struct{}
+
+func (constantSimilarity) TermScorer(TermStats, BM25Parameters) TermScorer {
+	return func([]FieldMatch) float64 { return 1 }
+}
+
+func TestBM25FSimilarity(t *testing.T) {
+	stats := TermStats{DocFreq: 1, TotalDocs: 10}
+	scorer := BM25FSimilarity{}.TermScorer(stats, BM25Parameters{K1: 1.2, B: 0.75})
+
+	// one occurrence in an average-length field of weight 2
+	got := scorer([]FieldMatch{{Freq: 1, Length: 10, AvgLength: 10, Weight: 2, Params: BM25Parameters{B: 0.75}}})
+	idf := math.Log((10 - 1 + 0.5) / (1 + 0.5))
+	want := idf * 2 * 2.2 / (2 + 1.2)
+	if math.Abs(got-want) > 1e-9 {
+		t.Errorf("score = %v, want %v", got, want)
+	}
+
+	if got := scorer(nil); got != 0 {
+		t.Errorf("score without matches = %v, want 0", got)
+	}
+}
+
+func TestWithSimilarity(t *testing.T) {
+	bodies := []string{
+		"habeas corpus petition",
+		"habeas corpus " + repeatWords("procedure", 60),
+		"court calendar",
+		"appeal rules",
+		"filing deadlines",
+		"judge assignments",
+	}
+
+	tests := []struct {
+		name       string
+		similarity Similarity
+	}{
+		{"bm25f", BM25FSimilarity{}},
+		{"bm25l", BM25LSimilarity{}},
+		{"tfidf", TFIDFSimilarity{}},
+		{"lm dirichlet", LMDirichletSimilarity{Mu: 100}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			corpus := NewCorpus(WithSimilarity(tt.similarity))
+			for _, body := range bodies {
+				corpus.AddDocument(Document{Fields: map[Field]string{FieldBody: body}})
+			}
+
+			// the short document mentioning both terms ranks first under every model
+			results := corpus.Search("habeas petition", 5)
+			if len(results) == 0 || results[0].Index != 0 {
+				t.Fatalf("Search() = %+v, want document 0 first", results)
+			}
+			for _, result := range results {
+				if result.Score <= 0 || math.IsNaN(result.Score) || math.IsInf(result.Score, 0) {
+					t.Errorf("document %d score = %v", result.Index, result.Score)
+				}
+			}
+		})
+	}
+
+	corpus := NewCorpus(WithSimilarity(constantSimilarity{}))
+	for _, body := range bodies {
+		corpus.AddDocument(Document{Fields: map[Field]string{FieldBody: body}})
+	}
+	if got := corpus.Score(`habeas "habeas corpus" proc*`, 1); got != 3 {
+		t.Errorf("Score() with constant similarity = %v, want 3", got)
+	}
+}
+
+func TestBM25LSimilarity_LongFields(t *testing.T) {
+	params := BM25Parameters{K1: 1.2, B: 0.75}
+	stats := TermStats{DocFreq: 2, TotalDocs: 10}
+	long := []FieldMatch{{Freq: 1, Length: 1000, AvgLength: 10, Weight: 1, Params: params}}
+
+	// the delta shift keeps a single mention in a very long field from vanishing
+	bm25 := BM25FSimilarity{}.TermScorer(stats, params)(long) / bm25IDF(stats)
+	bm25l := BM25LSimilarity{}.TermScorer(stats, params)(long) / math.Log(11/2.5)
+	if bm25l <= bm25 {
+		t.Errorf("BM25L tf component = %v, want more than BM25's %v", bm25l, bm25)
+	}
+}
+
+// repeatWords returns word repeated n times, separated by spaces
+func repeatWords(word string, n int) string {
+	words := make([]byte, 0, n*(len(word)+1))
+	for i := 0; i < n; i++ {
+		words = append(words, word...)
+		words = append(words, ' ')
+	}
+	return string(words)
+}
+
+func TestBM25Plus(t *testing.T) {
+	bodies := []string{
+		"habeas " + repeatWords("procedure", 300),
+		"court calendar",
+		"appeal rules",
+		"filing deadlines",
+		"judge assignments",
+	}
+	build := func(params BM25Parameters) *Corpus {
+		corpus := NewCorpus(WithBM25Params(params))
+		corpus.AddDocument(Document{Fields: map[Field]string{FieldH1: "habeas corpus"}})
+		for _, body := range bodies {
+			corpus.AddDocument(Document{Fields: map[Field]string{FieldBody: body}})
+		}
+		return corpus
+	}
+
+	plain := build(BM25Parameters{K1: 1.2, B: 0.75})
+	plus := build(BM25Parameters{K1: 1.2, B: 0.75, Delta: 1})
+
+	// the delta lifts the long body mention relative to the short header match
+	plainRatio := plain.Score("habeas", 1) / plain.Score("habeas", 0)
+	plusRatio := plus.Score("habeas", 1) / plus.Score("habeas", 0)
+	if plusRatio <= plainRatio {
+		t.Errorf("long/short score ratio with delta = %v, want more than %v", plusRatio, plainRatio)
+	}
+	if got, floor := plus.Score("habeas", 1), plus.idf(2); got < floor {
+		t.Errorf("BM25+ score = %v, want at least idf*delta = %v", got, floor)
+	}
+}
@@ -0,0 +1,299 @@
+//go:build !bm25md_noparser
+
+package bm25md
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// SiteFlavor selects the static-site generator conventions used during ingestion
+type SiteFlavor int
+
+const (
+	SiteHugo   SiteFlavor = iota // Hugo: content/ dir, draft flag, {{< shortcodes >}}
+	SiteJekyll                   // Jekyll: _posts/_drafts, published flag, {% liquid %} tags
+)
+
+// Metadata keys attached to documents loaded from a static site
+const (
+	MetaSitePath  = "path"      // source path relative to the site root
+	MetaPermalink = "permalink" // generated URL path of the rendered page
+)
+
+// DefaultFrontMatterFields maps common front matter keys onto indexed fields
+var DefaultFrontMatterFields = map[string]Field{
+	"title":         FieldTitle,
+	"linkTitle":     FieldTitle, // Hugo navigation title
+	"sidebar_label": FieldTitle, // Docusaurus sidebar title
+	"nav_title":     FieldTitle,
+	"description":   FieldDescription,
+	"summary":       FieldDescription,
+	"tags":          FieldTags,
+	"categories":    FieldTags,
+	"keywords":      FieldTags,
+}
+
+// SiteOptions configures static-site ingestion
+type SiteOptions struct {
+	Flavor            SiteFlavor
+	ContentDir        string               // defaults to "content" for Hugo and the site root for Jekyll
+	BaseURL           string               // optional prefix for generated permalinks
+	IncludeDrafts     bool                 // index drafts and unpublished pages
+	FrontMatterFields map[string]Field     // defaults to DefaultFrontMatterFields
+	Parser            *MarkdownFieldParser // defaults to NewMarkdownFieldParser()
+}
+
+var (
+	// hugoShortcodeRegex matches {{< name >}}, {{% name %}}, and their closing forms
+	hugoShortcodeRegex = regexp.MustCompile(`(?s){{[<%]\s*/?.*?\s*[>%]}}`)
+	// hugoCommentRegex matches {{/* comments */}} inside templates
+	hugoCommentRegex = regexp.MustCompile(`(?s){{/\*.*?\*/}}`)
+	// liquidCommentRegex matches {% comment %} blocks, whose content is dropped entirely
+	liquidCommentRegex = regexp.MustCompile(`(?s){%-?\s*comment\s*-?%}.*?{%-?\s*endcomment\s*-?%}`)
+	// liquidTagRegex matches {% tags %} and {{ output }} expressions
+	liquidTagRegex = regexp.MustCompile(`(?s){%.*?%}|{{.*?}}`)
+	// jekyllPostRegex matches the date-prefixed file names of Jekyll posts
+	jekyllPostRegex = regexp.MustCompile(`^(\d{4})-(\d{2})-(\d{2})-(.+)$`)
+)
+
+// LoadSite walks a Hugo or Jekyll site and returns one Document per markdown page.
+// Drafts are skipped unless requested, shortcodes are stripped from the body,
+// front matter is mapped onto fields, and each document records its permalink.
+func LoadSite(fsys fs.FS, opts SiteOptions) ([]Document, error) {
+	root := opts.contentDir()
+
+	var paths []string
+	err := fs.WalkDir(fsys, root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if p != root && opts.skipDir(d.Name()) {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if isMarkdownFile(p) {
+			paths = append(paths, p)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("bm25md: walking site: %w", err)
+	}
+	sort.Strings(paths)
+
+	documents := make([]Document, 0, len(paths))
+	for _, p := range paths {
+		content, err := fs.ReadFile(fsys, p)
+		if err != nil {
+			return nil, fmt.Errorf("bm25md: reading %s: %w", p, err)
+		}
+
+		doc, ok, err := opts.LoadPage(p, string(content))
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			doc.ID = len(documents)
+			documents = append(documents, doc)
+		}
+	}
+
+	return documents, nil
+}
+
+// LoadPage converts a single page into a Document. The path is relative to the
+// site root; ok is false when the page is a draft that should not be indexed.
+func (o SiteOptions) LoadPage(sitePath, content string) (doc Document, ok bool, err error) {
+	fm, body, err := ParseFrontMatter(content)
+	if err != nil {
+		return Document{}, false, fmt.Errorf("bm25md: %s: %w", sitePath, err)
+	}
+	if !o.IncludeDrafts && o.isDraft(sitePath, fm) {
+		return Document{}, false, nil
+	}
+
+	parser := o.Parser
+	if parser == nil {
+		parser = NewMarkdownFieldParser()
+	}
+	body = o.StripShortcodes(body)
+	fields := parser.ParseDocument(body)
+
+	// map front matter values onto their fields
+	mapping := o.FrontMatterFields
+	if mapping == nil {
+		mapping = DefaultFrontMatterFields
+	}
+	keys := make([]string, 0, len(mapping))
+	for key := range mapping {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		appendField(fields, mapping[key], strings.Join(fm.Strings(key), " "))
+	}
+
+	metadata := make(map[string]any, len(fm)+2)
+	for key, value := range fm {
+		metadata[key] = value
+	}
+	metadata[MetaSitePath] = sitePath
+	metadata[MetaPermalink] = o.Permalink(sitePath, fm)
+
+	return Document{
+		ExternalID: sitePath,
+		Fields:     fields,
+		Original:   body,
+		Metadata:   metadata,
+	}, true, nil
+}
+
+// StripShortcodes removes shortcode and template tags while keeping the text they wrap
+func (o SiteOptions) StripShortcodes(body string) string {
+	switch o.Flavor {
+	case SiteJekyll:
+		body = liquidCommentRegex.ReplaceAllString(body, "")
+		return liquidTagRegex.ReplaceAllString(body, "")
+	default:
+		body = hugoCommentRegex.ReplaceAllString(body, "")
+		return hugoShortcodeRegex.ReplaceAllString(body, "")
+	}
+}
+
+// Permalink generates the URL path a page is published at, honoring front matter overrides
+func (o SiteOptions) Permalink(sitePath string, fm FrontMatter) string {
+	var permalink string
+	switch o.Flavor {
+	case SiteJekyll:
+		permalink = o.jekyllPermalink(sitePath, fm)
+	default:
+		permalink = o.hugoPermalink(sitePath, fm)
+	}
+	return strings.TrimSuffix(o.BaseURL, "/") + permalink
+}
+
+// hugoPermalink follows Hugo's default "pretty URL" layout
+func (o SiteOptions) hugoPermalink(sitePath string, fm FrontMatter) string {
+	if url := fm.String("url"); url != "" {
+		return ensureLeadingSlash(url)
+	}
+
+	rel := o.relPath(sitePath)
+	dir, file := path.Split(rel)
+	name := strings.TrimSuffix(file, path.Ext(file))
+
+	// section lists (_index.md) and leaf bundles (index.md) publish at their directory
+	if name == "_index" || name == "index" {
+		return ensureLeadingSlash(dir)
+	}
+	if slug := fm.String("slug"); slug != "" {
+		name = slug
+	}
+	return ensureLeadingSlash(path.Join(dir, name) + "/")
+}
+
+// jekyllPermalink follows Jekyll's default "date" permalink style for posts
+func (o SiteOptions) jekyllPermalink(sitePath string, fm FrontMatter) string {
+	if permalink := fm.String("permalink"); permalink != "" {
+		return ensureLeadingSlash(permalink)
+	}
+
+	rel := o.relPath(sitePath)
+	dir, file := path.Split(rel)
+	name := strings.TrimSuffix(file, path.Ext(file))
+
+	if match := jekyllPostRegex.FindStringSubmatch(name); match != nil && isJekyllPostDir(dir) {
+		title := match[4]
+		if slug := fm.String("slug"); slug != "" {
+			title = slug
+		}
+		categories := fm.Strings("categories")
+		if len(categories) == 0 {
+			categories = fm.Strings("category")
+		}
+		parts := append(append([]string{}, categories...), match[1], match[2], match[3], title+".html")
+		return "/" + strings.Join(parts, "/")
+	}
+
+	if name == "index" {
+		return ensureLeadingSlash(dir)
+	}
+	return ensureLeadingSlash(path.Join(dir, name) + ".html")
+}
+
+// isDraft reports whether a page is unpublished under the flavor's conventions
+func (o SiteOptions) isDraft(sitePath string, fm FrontMatter) bool {
+	if fm.Bool("draft") {
+		return true
+	}
+	if o.Flavor == SiteJekyll {
+		if published, ok := fm["published"].(bool); ok && !published {
+			return true
+		}
+		return strings.HasPrefix(sitePath, "_drafts/") || strings.Contains(sitePath, "/_drafts/")
+	}
+	return false
+}
+
+// skipDir reports whether a directory should not be walked
+func (o SiteOptions) skipDir(name string) bool {
+	if strings.HasPrefix(name, ".") || name == "node_modules" {
+		return true
+	}
+	if o.Flavor == SiteJekyll && strings.HasPrefix(name, "_") {
+		// _posts holds content; _drafts is only walked when drafts are requested
+		return name != "_posts" && !(name == "_drafts" && o.IncludeDrafts)
+	}
+	return false
+}
+
+// contentDir returns the directory holding page sources
+func (o SiteOptions) contentDir() string {
+	if o.ContentDir != "" {
+		return strings.Trim(o.ContentDir, "/")
+	}
+	if o.Flavor == SiteHugo {
+		return "content"
+	}
+	return "."
+}
+
+// relPath returns a site path relative to the content directory
+func (o SiteOptions) relPath(sitePath string) string {
+	dir := o.contentDir()
+	if dir == "." {
+		return sitePath
+	}
+	return strings.TrimPrefix(sitePath, dir+"/")
+}
+
+// isJekyllPostDir reports whether dir is a _posts or _drafts collection directory
+func isJekyllPostDir(dir string) bool {
+	dir = "/" + strings.Trim(dir, "/") + "/"
+	return strings.Contains(dir, "/_posts/") || strings.Contains(dir, "/_drafts/")
+}
+
+// isMarkdownFile reports whether p has a markdown file extension
+func isMarkdownFile(p string) bool {
+	switch strings.ToLower(path.Ext(p)) {
+	case ".md", ".markdown", ".mdown":
+		return true
+	default:
+		return false
+	}
+}
+
+// ensureLeadingSlash normalizes a URL path to start with a slash
+func ensureLeadingSlash(p string) string {
+	if !strings.HasPrefix(p, "/") {
+		return "/" + p
+	}
+	return p
+}
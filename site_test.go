@@ -0,0 +1,108 @@
+//go:build !bm25md_noparser
+
+package bm25md
+
+import (
+	"testing"
+	"testing/fstest"
+)
+
+func TestLoadSite_Hugo(t *testing.T) {
+	fsys := fstest.MapFS{
+		"content/_index.md": {Data: []byte("---\ntitle: Home\n---\nWelcome.")},
+		"content/posts/habeas.md": {Data: []byte("---\ntitle: Habeas Corpus\ntags: [law, writ]\n---\n" +
+			"The writ {{< ref \"detention.md\" >}} protects against {{% highlight %}}unlawful detention{{% /highlight %}}.")},
+		"content/posts/custom.md": {Data: []byte("+++\ntitle = \"Custom\"\nslug = \"renamed\"\n+++\nBody")},
+		"content/posts/draft.md":  {Data: []byte("---\ntitle: Draft\ndraft: true\n---\nUnfinished")},
+		"themes/theme/README.md":  {Data: []byte("# Not content")},
+	}
+
+	docs, err := LoadSite(fsys, SiteOptions{Flavor: SiteHugo, BaseURL: "https://example.com/"})
+	if err != nil {
+		t.Fatalf("LoadSite() error = %v", err)
+	}
+	if len(docs) != 3 {
+		t.Fatalf("LoadSite() returned %d documents, want 3", len(docs))
+	}
+
+	permalinks := make(map[string]Document)
+	for _, doc := range docs {
+		permalinks[doc.Metadata[MetaPermalink].(string)] = doc
+	}
+
+	habeas, ok := permalinks["https://example.com/posts/habeas/"]
+	if !ok {
+		t.Fatalf("missing habeas permalink, got %v", permalinks)
+	}
+	if habeas.Fields[FieldTitle] != "Habeas Corpus" {
+		t.Errorf("title = %q, want %q", habeas.Fields[FieldTitle], "Habeas Corpus")
+	}
+	if habeas.Fields[FieldTags] != "law writ" {
+		t.Errorf("tags = %q, want %q", habeas.Fields[FieldTags], "law writ")
+	}
+	want := "The writ protects against unlawful detention."
+	if got := normalizeWhitespace(habeas.Fields[FieldBody]); got != want {
+		t.Errorf("body = %q, want %q", got, want)
+	}
+	if _, ok := permalinks["https://example.com/posts/renamed/"]; !ok {
+		t.Error("slug should override the permalink")
+	}
+	if _, ok := permalinks["https://example.com/"]; !ok {
+		t.Error("section index should publish at its directory")
+	}
+
+	withDrafts, err := LoadSite(fsys, SiteOptions{Flavor: SiteHugo, IncludeDrafts: true})
+	if err != nil {
+		t.Fatalf("LoadSite() error = %v", err)
+	}
+	if len(withDrafts) != 4 {
+		t.Errorf("LoadSite() with drafts returned %d documents, want 4", len(withDrafts))
+	}
+}
+
+func TestLoadSite_Jekyll(t *testing.T) {
+	fsys := fstest.MapFS{
+		"about.md": {Data: []byte("---\ntitle: About\n---\nAbout {{ site.title }}.")},
+		"_posts/2023-01-02-writ.md": {Data: []byte("---\ntitle: Writ\ncategories: [law]\n---\n" +
+			"{% comment %}hidden note{% endcomment %}Visible {% include note.html %}text.")},
+		"_posts/2023-02-03-hidden.md": {Data: []byte("---\npublished: false\n---\nHidden")},
+		"_drafts/idea.md":             {Data: []byte("Draft idea")},
+		"_includes/note.md":           {Data: []byte("Partial")},
+		"_site/index.md":              {Data: []byte("Generated")},
+		"docs/index.md":               {Data: []byte("---\npermalink: /manual/\n---\nManual")},
+		"_posts/2023-03-04-plain.md":  {Data: []byte("Plain post")},
+	}
+
+	docs, err := LoadSite(fsys, SiteOptions{Flavor: SiteJekyll})
+	if err != nil {
+		t.Fatalf("LoadSite() error = %v", err)
+	}
+
+	permalinks := make(map[string]Document)
+	for _, doc := range docs {
+		permalinks[doc.Metadata[MetaPermalink].(string)] = doc
+	}
+
+	expected := []string{
+		"/about.html",
+		"/law/2023/01/02/writ.html",
+		"/manual/",
+		"/2023/03/04/plain.html",
+	}
+	if len(docs) != len(expected) {
+		t.Errorf("LoadSite() returned %d documents, want %d: %v", len(docs), len(expected), permalinks)
+	}
+	for _, permalink := range expected {
+		if _, ok := permalinks[permalink]; !ok {
+			t.Errorf("missing permalink %q", permalink)
+		}
+	}
+
+	writ := permalinks["/law/2023/01/02/writ.html"]
+	if got := normalizeWhitespace(writ.Fields[FieldBody]); got != "Visible text." {
+		t.Errorf("body = %q, want %q", got, "Visible text.")
+	}
+	if got := normalizeWhitespace(permalinks["/about.html"].Fields[FieldBody]); got != "About ." {
+		t.Errorf("body = %q, want %q", got, "About .")
+	}
+}
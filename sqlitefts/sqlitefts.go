@@ -0,0 +1,226 @@
+// Package sqlitefts bridges bm25md and SQLite FTS5: it exports parsed documents
+// into an FTS5 table, with one column per markdown field, and hydrates documents
+// or a Corpus back from one. It works with any database/sql SQLite driver that
+// includes FTS5 (eg modernc.org/sqlite or mattn/go-sqlite3 with the sqlite_fts5 tag).
+package sqlitefts
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/chriscorrea/bm25md"
+)
+
+// reserved (unindexed) columns stored alongside field columns
+const (
+	columnID       = "doc_id"
+	columnExternal = "external_id"
+	columnOriginal = "original"
+	columnMetadata = "metadata"
+	columnBoost    = "boost"
+)
+
+// identifierRegex matches table and field names that are safe to use unquoted
+var identifierRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// DefaultFields returns the fields of bm25md.DefaultFieldWeights in a stable order
+func DefaultFields() []bm25md.Field {
+	fields := make([]bm25md.Field, 0, len(bm25md.DefaultFieldWeights))
+	for field := range bm25md.DefaultFieldWeights {
+		fields = append(fields, field)
+	}
+	sort.Slice(fields, func(i, j int) bool { return fields[i] < fields[j] })
+	return fields
+}
+
+// CreateTable creates an FTS5 table with one indexed column per field, if it does not exist
+func CreateTable(ctx context.Context, db *sql.DB, table string, fields []bm25md.Field) error {
+	if err := validate(table, fields); err != nil {
+		return err
+	}
+
+	columns := []string{
+		columnID + " UNINDEXED",
+		columnExternal + " UNINDEXED",
+		columnOriginal + " UNINDEXED",
+		columnMetadata + " UNINDEXED",
+		columnBoost + " UNINDEXED",
+	}
+	for _, field := range fields {
+		columns = append(columns, quote(string(field)))
+	}
+
+	stmt := fmt.Sprintf("CREATE VIRTUAL TABLE IF NOT EXISTS %s USING fts5(%s)", table, strings.Join(columns, ", "))
+	if _, err := db.ExecContext(ctx, stmt); err != nil {
+		return fmt.Errorf("sqlitefts: creating table %s: %w", table, err)
+	}
+	return nil
+}
+
+// Export creates the table if needed and inserts documents in a single transaction.
+// Metadata is stored as JSON, so values round-trip as JSON types (eg numbers as float64)
+func Export(ctx context.Context, db *sql.DB, table string, fields []bm25md.Field, docs []bm25md.Document) error {
+	if err := CreateTable(ctx, db, table, fields); err != nil {
+		return err
+	}
+
+	columns := []string{columnID, columnExternal, columnOriginal, columnMetadata, columnBoost}
+	for _, field := range fields {
+		columns = append(columns, quote(string(field)))
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(columns)), ", ")
+	stmt := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", table, strings.Join(columns, ", "), placeholders)
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("sqlitefts: starting transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck // no-op after commit
+
+	insert, err := tx.PrepareContext(ctx, stmt)
+	if err != nil {
+		return fmt.Errorf("sqlitefts: preparing insert: %w", err)
+	}
+	defer insert.Close()
+
+	for _, doc := range docs {
+		metadata, err := json.Marshal(doc.Metadata)
+		if err != nil {
+			return fmt.Errorf("sqlitefts: encoding metadata of document %d: %w", doc.ID, err)
+		}
+		args := []any{doc.ID, doc.ExternalID, doc.Original, string(metadata), doc.Boost}
+		for _, field := range fields {
+			args = append(args, doc.Fields[field])
+		}
+		if _, err := insert.ExecContext(ctx, args...); err != nil {
+			return fmt.Errorf("sqlitefts: inserting document %d: %w", doc.ID, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("sqlitefts: committing export: %w", err)
+	}
+	return nil
+}
+
+// ExportCorpus exports every document in the corpus
+func ExportCorpus(ctx context.Context, db *sql.DB, table string, fields []bm25md.Field, corpus *bm25md.Corpus) error {
+	return Export(ctx, db, table, fields, corpus.Documents())
+}
+
+// Import reads documents back from an exported table, ordered by document ID
+func Import(ctx context.Context, db *sql.DB, table string, fields []bm25md.Field) ([]bm25md.Document, error) {
+	if err := validate(table, fields); err != nil {
+		return nil, err
+	}
+
+	columns := []string{columnID, columnExternal, columnOriginal, columnMetadata, columnBoost}
+	for _, field := range fields {
+		columns = append(columns, quote(string(field)))
+	}
+	query := fmt.Sprintf("SELECT %s FROM %s ORDER BY CAST(%s AS INTEGER)", strings.Join(columns, ", "), table, columnID)
+
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("sqlitefts: reading table %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	var docs []bm25md.Document
+	for rows.Next() {
+		var (
+			id       int
+			external sql.NullString
+			original sql.NullString
+			metadata sql.NullString
+			boost    sql.NullFloat64
+			values   = make([]sql.NullString, len(fields))
+		)
+		dest := []any{&id, &external, &original, &metadata, &boost}
+		for i := range values {
+			dest = append(dest, &values[i])
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, fmt.Errorf("sqlitefts: scanning row: %w", err)
+		}
+
+		doc := bm25md.Document{
+			ID:         id,
+			ExternalID: external.String,
+			Fields:     make(map[bm25md.Field]string, len(fields)),
+			Original:   original.String,
+			Boost:      boost.Float64,
+		}
+		for i, field := range fields {
+			doc.Fields[field] = values[i].String
+		}
+		if metadata.Valid && metadata.String != "null" && metadata.String != "" {
+			if err := json.Unmarshal([]byte(metadata.String), &doc.Metadata); err != nil {
+				return nil, fmt.Errorf("sqlitefts: decoding metadata of document %d: %w", id, err)
+			}
+		}
+		docs = append(docs, doc)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("sqlitefts: reading table %s: %w", table, err)
+	}
+
+	return docs, nil
+}
+
+// LoadCorpus hydrates a new Corpus from an exported table
+func LoadCorpus(ctx context.Context, db *sql.DB, table string, fields []bm25md.Field, opts ...bm25md.CorpusOption) (*bm25md.Corpus, error) {
+	docs, err := Import(ctx, db, table, fields)
+	if err != nil {
+		return nil, err
+	}
+	corpus := bm25md.NewCorpus(opts...)
+	for _, doc := range docs {
+		corpus.AddDocument(doc)
+	}
+	return corpus, nil
+}
+
+// RankExpression returns an FTS5 bm25() call applying bm25md field weights to the
+// table's columns, eg for "ORDER BY bm25(docs, 0, 0, 0, 0, 0, 3.0, ...)". FTS5 ranks
+// better matches with lower values, so results should be ordered ascending
+func RankExpression(table string, fields []bm25md.Field, weights map[bm25md.Field]float64) string {
+	// reserved columns carry no weight
+	args := []string{table, "0", "0", "0", "0", "0"}
+	for _, field := range fields {
+		args = append(args, fmt.Sprintf("%g", weights[field]))
+	}
+	return fmt.Sprintf("bm25(%s)", strings.Join(args, ", "))
+}
+
+// quote quotes a validated field name, since some (eg "table") are SQL keywords
+func quote(name string) string {
+	return `"` + name + `"`
+}
+
+// validate checks that the table and field names are safe SQL identifiers
+func validate(table string, fields []bm25md.Field) error {
+	if !identifierRegex.MatchString(table) {
+		return fmt.Errorf("sqlitefts: invalid table name %q", table)
+	}
+	if len(fields) == 0 {
+		return errors.New("sqlitefts: no fields")
+	}
+	for _, field := range fields {
+		name := string(field)
+		if !identifierRegex.MatchString(name) {
+			return fmt.Errorf("sqlitefts: invalid field name %q", name)
+		}
+		switch name {
+		case columnID, columnExternal, columnOriginal, columnMetadata, columnBoost:
+			return fmt.Errorf("sqlitefts: field name %q is reserved", name)
+		}
+	}
+	return nil
+}
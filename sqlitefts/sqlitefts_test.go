@@ -0,0 +1,128 @@
+//go:build !bm25md_noparser
+
+package sqlitefts
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+
+	"github.com/chriscorrea/bm25md"
+	_ "modernc.org/sqlite"
+)
+
+func openDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatalf("opening database: %v", err)
+	}
+	// an in-memory database lives only as long as its connection
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestExportImport(t *testing.T) {
+	ctx := context.Background()
+	db := openDB(t)
+
+	parser := bm25md.NewMarkdownFieldParser()
+	docs := parser.ParseDocuments([]string{
+		"# Habeas Corpus\n\nThe **great writ** protects against unlawful detention.",
+		"# Appeals\n\nFile a notice of appeal within thirty days.",
+		"# Calendar\n\nThe court sits on weekdays.",
+	})
+	docs[0].Metadata = map[string]any{"path": "habeas.md"}
+	docs[0].ExternalID = "habeas.md"
+	docs[1].Boost = 2
+
+	fields := DefaultFields()
+	if err := Export(ctx, db, "docs", fields, docs); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+
+	// FTS5 can query the exported fields directly with bm25md weights
+	query := "SELECT doc_id FROM docs WHERE docs MATCH ? ORDER BY " + RankExpression("docs", fields, bm25md.DefaultFieldWeights)
+	var id int
+	if err := db.QueryRowContext(ctx, query, "writ").Scan(&id); err != nil {
+		t.Fatalf("FTS5 query error = %v", err)
+	}
+	if id != 0 {
+		t.Errorf("FTS5 match = %d, want 0", id)
+	}
+
+	imported, err := Import(ctx, db, "docs", fields)
+	if err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+	if len(imported) != len(docs) {
+		t.Fatalf("imported %d documents, want %d", len(imported), len(docs))
+	}
+	for i, doc := range imported {
+		if doc.ID != docs[i].ID || doc.ExternalID != docs[i].ExternalID || doc.Original != docs[i].Original || doc.Boost != docs[i].Boost {
+			t.Errorf("document %d = %+v, want %+v", i, doc, docs[i])
+		}
+		for _, field := range fields {
+			if doc.Fields[field] != docs[i].Fields[field] {
+				t.Errorf("document %d field %s = %q, want %q", i, field, doc.Fields[field], docs[i].Fields[field])
+			}
+		}
+	}
+	if imported[0].Metadata["path"] != "habeas.md" {
+		t.Errorf("metadata = %v, want path habeas.md", imported[0].Metadata)
+	}
+	if imported[1].Metadata != nil {
+		t.Errorf("metadata = %v, want nil", imported[1].Metadata)
+	}
+
+	corpus, err := LoadCorpus(ctx, db, "docs", fields)
+	if err != nil {
+		t.Fatalf("LoadCorpus() error = %v", err)
+	}
+	results := corpus.Search("appeal", 1)
+	if len(results) != 1 || results[0].Document.ID != 1 {
+		t.Errorf("Search() = %+v, want document 1", results)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		table  string
+		fields []bm25md.Field
+		errMsg string
+	}{
+		{"valid", "docs", []bm25md.Field{bm25md.FieldBody}, ""},
+		{"injected table", "docs; DROP TABLE x", []bm25md.Field{bm25md.FieldBody}, "invalid table name"},
+		{"no fields", "docs", nil, "no fields"},
+		{"reserved field", "docs", []bm25md.Field{"boost"}, "reserved"},
+		{"invalid field", "docs", []bm25md.Field{"meta.title"}, "invalid field name"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validate(tt.table, tt.fields)
+			if tt.errMsg == "" {
+				if err != nil {
+					t.Errorf("validate() error = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+				t.Errorf("validate() error = %v, want %q", err, tt.errMsg)
+			}
+		})
+	}
+}
+
+func TestRankExpression(t *testing.T) {
+	got := RankExpression("docs", []bm25md.Field{bm25md.FieldH1, bm25md.FieldBody}, map[bm25md.Field]float64{
+		bm25md.FieldH1:   3,
+		bm25md.FieldBody: 1.5,
+	})
+	if want := "bm25(docs, 0, 0, 0, 0, 0, 3, 1.5)"; got != want {
+		t.Errorf("RankExpression() = %q, want %q", got, want)
+	}
+}
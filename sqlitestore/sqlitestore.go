@@ -0,0 +1,265 @@
+// Package sqlitestore implements bm25md.Storage on SQLite, so a StoredCorpus
+// keeps its documents and postings on disk. It works with any database/sql
+// SQLite driver (eg modernc.org/sqlite or mattn/go-sqlite3).
+package sqlitestore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/chriscorrea/bm25md"
+)
+
+// DefaultPrefix names the store's tables when no prefix is given
+const DefaultPrefix = "bm25md"
+
+// metaKey is the meta table row holding the corpus statistics
+const metaKey = "stats"
+
+// identifierRegex matches table prefixes that are safe to use unquoted
+var identifierRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Store is a bm25md.Storage in a SQLite database, using four tables named after
+// its prefix: documents, info (the document info searches read), postings (one
+// row per term, document, and field), and meta
+type Store struct {
+	db        *sql.DB
+	documents string
+	info      string
+	postings  string
+	meta      string
+}
+
+// Open creates the store's tables in db if they do not exist. Tables are named
+// prefix_documents, prefix_info, prefix_postings, and prefix_meta (prefix
+// defaults to DefaultPrefix).
+// The caller owns db: closing the store does not close it
+func Open(ctx context.Context, db *sql.DB, prefix string) (*Store, error) {
+	if prefix == "" {
+		prefix = DefaultPrefix
+	}
+	if !identifierRegex.MatchString(prefix) {
+		return nil, fmt.Errorf("sqlitestore: invalid table prefix %q", prefix)
+	}
+	s := &Store{
+		db:        db,
+		documents: prefix + "_documents",
+		info:      prefix + "_info",
+		postings:  prefix + "_postings",
+		meta:      prefix + "_meta",
+	}
+
+	statements := []string{
+		fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (id INTEGER PRIMARY KEY, external_id TEXT UNIQUE, data BLOB NOT NULL)", s.documents),
+		fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (id INTEGER PRIMARY KEY, data BLOB NOT NULL)", s.info),
+		fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (term TEXT NOT NULL, doc INTEGER NOT NULL, field TEXT NOT NULL, freq INTEGER NOT NULL, PRIMARY KEY (term, doc, field)) WITHOUT ROWID", s.postings),
+		fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (key TEXT PRIMARY KEY, value BLOB NOT NULL)", s.meta),
+	}
+	for _, stmt := range statements {
+		if _, err := db.ExecContext(ctx, stmt); err != nil {
+			return nil, fmt.Errorf("sqlitestore: creating tables: %w", err)
+		}
+	}
+	return s, nil
+}
+
+// View runs fn in a transaction that is always rolled back
+func (s *Store) View(ctx context.Context, fn func(tx bm25md.StorageTx) error) error {
+	sqlTx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("sqlitestore: starting transaction: %w", err)
+	}
+	defer sqlTx.Rollback() //nolint:errcheck // read-only
+	return fn(&tx{store: s, ctx: ctx, tx: sqlTx})
+}
+
+// Update runs fn in a transaction, committing it if fn returns nil
+func (s *Store) Update(ctx context.Context, fn func(tx bm25md.StorageTx) error) error {
+	sqlTx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("sqlitestore: starting transaction: %w", err)
+	}
+	defer sqlTx.Rollback() //nolint:errcheck // no-op after commit
+
+	if err := fn(&tx{store: s, ctx: ctx, tx: sqlTx, writable: true}); err != nil {
+		return err
+	}
+	if err := sqlTx.Commit(); err != nil {
+		return fmt.Errorf("sqlitestore: committing: %w", err)
+	}
+	return nil
+}
+
+// Close does nothing, since the caller owns the database
+func (s *Store) Close() error {
+	return nil
+}
+
+// errReadOnly is returned by writes in a View transaction
+var errReadOnly = errors.New("sqlitestore: write in read-only transaction")
+
+// tx is a bm25md.StorageTx over a SQL transaction
+type tx struct {
+	store    *Store
+	ctx      context.Context
+	tx       *sql.Tx
+	writable bool
+}
+
+// exec runs a write statement
+func (t *tx) exec(query string, args ...any) error {
+	if !t.writable {
+		return errReadOnly
+	}
+	_, err := t.tx.ExecContext(t.ctx, query, args...)
+	return err
+}
+
+// blob reads the data column of a row of table by document ID
+func (t *tx) blob(table string, id int) ([]byte, error) {
+	var data []byte
+	err := t.tx.QueryRowContext(t.ctx, "SELECT data FROM "+table+" WHERE id = ?", id).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("sqlitestore: reading document %d: %w", id, err)
+	}
+	if data == nil {
+		data = []byte{}
+	}
+	return data, nil
+}
+
+func (t *tx) Document(id int) ([]byte, error) {
+	return t.blob(t.store.documents, id)
+}
+
+func (t *tx) DocumentInfo(id int) ([]byte, error) {
+	return t.blob(t.store.info, id)
+}
+
+func (t *tx) PutDocument(id int, externalID string, info, data []byte) error {
+	external := sql.NullString{String: externalID, Valid: externalID != ""}
+	if err := t.exec("INSERT OR REPLACE INTO "+t.store.documents+" (id, external_id, data) VALUES (?, ?, ?)", id, external, data); err != nil {
+		return fmt.Errorf("sqlitestore: writing document %d: %w", id, err)
+	}
+	if err := t.exec("INSERT OR REPLACE INTO "+t.store.info+" (id, data) VALUES (?, ?)", id, info); err != nil {
+		return fmt.Errorf("sqlitestore: writing document %d: %w", id, err)
+	}
+	return nil
+}
+
+func (t *tx) DeleteDocument(id int) error {
+	if err := t.exec("DELETE FROM "+t.store.documents+" WHERE id = ?", id); err != nil {
+		return fmt.Errorf("sqlitestore: deleting document %d: %w", id, err)
+	}
+	if err := t.exec("DELETE FROM "+t.store.info+" WHERE id = ?", id); err != nil {
+		return fmt.Errorf("sqlitestore: deleting document %d: %w", id, err)
+	}
+	return nil
+}
+
+func (t *tx) LookupID(externalID string) (int, bool, error) {
+	var id int
+	err := t.tx.QueryRowContext(t.ctx, "SELECT id FROM "+t.store.documents+" WHERE external_id = ?", externalID).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("sqlitestore: looking up %q: %w", externalID, err)
+	}
+	return id, true, nil
+}
+
+func (t *tx) Postings(term string) (map[int]map[bm25md.Field]int, error) {
+	rows, err := t.tx.QueryContext(t.ctx, "SELECT doc, field, freq FROM "+t.store.postings+" WHERE term = ?", term)
+	if err != nil {
+		return nil, fmt.Errorf("sqlitestore: reading postings of %q: %w", term, err)
+	}
+	defer rows.Close()
+
+	postings := make(map[int]map[bm25md.Field]int)
+	for rows.Next() {
+		var doc, freq int
+		var field string
+		if err := rows.Scan(&doc, &field, &freq); err != nil {
+			return nil, fmt.Errorf("sqlitestore: scanning posting: %w", err)
+		}
+		if postings[doc] == nil {
+			postings[doc] = make(map[bm25md.Field]int, 1)
+		}
+		postings[doc][bm25md.Field(field)] = freq
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("sqlitestore: reading postings of %q: %w", term, err)
+	}
+	return postings, nil
+}
+
+func (t *tx) PutPosting(term string, id int, freqs map[bm25md.Field]int) error {
+	if err := t.DeletePosting(term, id); err != nil {
+		return err
+	}
+	for field, freq := range freqs {
+		if err := t.exec("INSERT INTO "+t.store.postings+" (term, doc, field, freq) VALUES (?, ?, ?, ?)", term, id, string(field), freq); err != nil {
+			return fmt.Errorf("sqlitestore: writing posting of %q: %w", term, err)
+		}
+	}
+	return nil
+}
+
+func (t *tx) DeletePosting(term string, id int) error {
+	if err := t.exec("DELETE FROM "+t.store.postings+" WHERE term = ? AND doc = ?", term, id); err != nil {
+		return fmt.Errorf("sqlitestore: deleting posting of %q: %w", term, err)
+	}
+	return nil
+}
+
+func (t *tx) Terms(prefix string) ([]string, error) {
+	// terms are read in index order from the prefix until one no longer matches
+	rows, err := t.tx.QueryContext(t.ctx, "SELECT DISTINCT term FROM "+t.store.postings+" WHERE term >= ? ORDER BY term", prefix)
+	if err != nil {
+		return nil, fmt.Errorf("sqlitestore: listing terms: %w", err)
+	}
+	defer rows.Close()
+
+	var terms []string
+	for rows.Next() {
+		var term string
+		if err := rows.Scan(&term); err != nil {
+			return nil, fmt.Errorf("sqlitestore: scanning term: %w", err)
+		}
+		if !strings.HasPrefix(term, prefix) {
+			break
+		}
+		terms = append(terms, term)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("sqlitestore: listing terms: %w", err)
+	}
+	return terms, nil
+}
+
+func (t *tx) Meta() ([]byte, error) {
+	var data []byte
+	err := t.tx.QueryRowContext(t.ctx, "SELECT value FROM "+t.store.meta+" WHERE key = ?", metaKey).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("sqlitestore: reading statistics: %w", err)
+	}
+	return data, nil
+}
+
+func (t *tx) PutMeta(data []byte) error {
+	if err := t.exec("INSERT OR REPLACE INTO "+t.store.meta+" (key, value) VALUES (?, ?)", metaKey, data); err != nil {
+		return fmt.Errorf("sqlitestore: writing statistics: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package sqlitestore
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"github.com/chriscorrea/bm25md"
+	"github.com/chriscorrea/bm25md/storagetest"
+	_ "modernc.org/sqlite"
+)
+
+func openDB(t *testing.T, path string) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatalf("opening database: %v", err)
+	}
+	// a single connection keeps writes serialized
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestStore(t *testing.T) {
+	storagetest.TestStorage(t, func(t *testing.T) bm25md.Storage {
+		store, err := Open(context.Background(), openDB(t, ":memory:"), "")
+		if err != nil {
+			t.Fatalf("Open() error = %v", err)
+		}
+		return store
+	})
+}
+
+func TestStore_Reopen(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "index.db")
+
+	store, err := Open(ctx, openDB(t, path), "notes")
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	corpus := bm25md.NewStoredCorpus(store)
+	docs := []bm25md.Document{
+		{ExternalID: "habeas.md", Fields: map[bm25md.Field]string{bm25md.FieldH1: "Habeas Corpus", bm25md.FieldBody: "the great writ"}},
+		{ExternalID: "appeals.md", Fields: map[bm25md.Field]string{bm25md.FieldBody: "notice of appeal"}},
+		{ExternalID: "calendar.md", Fields: map[bm25md.Field]string{bm25md.FieldBody: "court calendar"}},
+		{ExternalID: "fees.md", Fields: map[bm25md.Field]string{bm25md.FieldBody: "filing fees"}},
+	}
+	if err := corpus.AddDocuments(ctx, docs); err != nil {
+		t.Fatalf("AddDocuments() error = %v", err)
+	}
+	want, err := corpus.Search(ctx, "habeas writ", 10)
+	if err != nil || len(want) != 1 {
+		t.Fatalf("Search() = %v, %v, want one result", want, err)
+	}
+
+	// a new connection to the same file sees the index
+	reopened, err := Open(ctx, openDB(t, path), "notes")
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	got, err := bm25md.NewStoredCorpus(reopened).Search(ctx, "habeas writ", 10)
+	if err != nil || len(got) != 1 || got[0].ExternalID != "habeas.md" || got[0].Score != want[0].Score {
+		t.Errorf("Search() after reopening = %v, %v, want %v", got, err, want)
+	}
+}
+
+func TestOpen_InvalidPrefix(t *testing.T) {
+	if _, err := Open(context.Background(), openDB(t, ":memory:"), "bad name"); err == nil {
+		t.Error("Open() with an invalid prefix should fail")
+	}
+}
@@ -0,0 +1,89 @@
+package bm25md
+
+// DocumentStats counts structural elements of a document, usable as ranking
+// features or for corpus quality reporting
+type DocumentStats struct {
+	Fields     map[Field]int // occurrences per field (eg number of H2 headings)
+	CodeBlocks int           // fenced and indented code blocks
+	Links      int           // inline, reference, and autolinks
+}
+
+// FieldStats aggregates occurrences of a single field across a corpus
+type FieldStats struct {
+	Occurrences int     // total occurrences across all documents
+	Documents   int     // documents with at least one occurrence
+	Mean        float64 // average occurrences per document
+}
+
+// CorpusStats aggregates structural counts across a corpus
+type CorpusStats struct {
+	Documents  int                  // number of documents
+	Fields     map[Field]FieldStats // per-field occurrence statistics
+	CodeBlocks int                  // total code blocks
+	Links      int                  // total links
+}
+
+// fieldCounts returns per-field occurrence counts for a document, falling back to
+// occurrences or non-empty fields when the document was not built by ParseDocuments
+func (d Document) fieldCounts() map[Field]int {
+	if d.Stats.Fields != nil {
+		return d.Stats.Fields
+	}
+	counts := make(map[Field]int)
+	if d.Occurrences != nil {
+		for field, occs := range d.Occurrences {
+			counts[field] = len(occs)
+		}
+		return counts
+	}
+	for field, content := range d.Fields {
+		if content != "" {
+			counts[field] = 1
+		}
+	}
+	return counts
+}
+
+// DocumentStats returns the structural counts of the document at docIndex
+func (c *Corpus) DocumentStats(docIndex int) DocumentStats {
+	if !c.isLive(docIndex) {
+		return DocumentStats{}
+	}
+	doc := c.documents[docIndex]
+	stats := doc.Stats
+	stats.Fields = doc.fieldCounts()
+	return stats
+}
+
+// Stats aggregates field occurrence counts, code blocks, and links across the corpus
+func (c *Corpus) Stats() CorpusStats {
+	stats := CorpusStats{
+		Documents: c.liveDocuments(),
+		Fields:    make(map[Field]FieldStats),
+	}
+
+	for i, doc := range c.documents {
+		if c.deleted[i] {
+			continue
+		}
+		for field, count := range doc.fieldCounts() {
+			if count == 0 {
+				continue
+			}
+			fieldStats := stats.Fields[field]
+			fieldStats.Occurrences += count
+			fieldStats.Documents++
+			stats.Fields[field] = fieldStats
+		}
+		stats.CodeBlocks += doc.Stats.CodeBlocks
+		stats.Links += doc.Stats.Links
+	}
+
+	// averages are over all documents, not just those containing the field
+	for field, fieldStats := range stats.Fields {
+		fieldStats.Mean = float64(fieldStats.Occurrences) / float64(stats.Documents)
+		stats.Fields[field] = fieldStats
+	}
+
+	return stats
+}
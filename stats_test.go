@@ -0,0 +1,74 @@
+//go:build !bm25md_noparser
+
+package bm25md
+
+import "testing"
+
+func TestMarkdownFieldParser_ParseStats(t *testing.T) {
+	parser := NewMarkdownFieldParser()
+	input := "# Guide\n\n## Filing\n\nSee [the form](http://example.com/form) or <http://example.com>.\n\n## Appeals\n\n```go\nreturn nil\n```\n\nUse `appeal`."
+
+	stats := parser.ParseStats(input)
+
+	if stats.Fields[FieldH2] != 2 {
+		t.Errorf("H2 count = %d, want 2", stats.Fields[FieldH2])
+	}
+	if stats.Fields[FieldH1] != 1 {
+		t.Errorf("H1 count = %d, want 1", stats.Fields[FieldH1])
+	}
+	// one code block plus one inline code span
+	if stats.Fields[FieldCode] != 2 {
+		t.Errorf("Code count = %d, want 2", stats.Fields[FieldCode])
+	}
+	if stats.CodeBlocks != 1 {
+		t.Errorf("CodeBlocks = %d, want 1", stats.CodeBlocks)
+	}
+	if stats.Links != 2 {
+		t.Errorf("Links = %d, want 2", stats.Links)
+	}
+}
+
+func TestCorpus_Stats(t *testing.T) {
+	parser := NewMarkdownFieldParser()
+	docs := parser.ParseDocuments([]string{
+		"## One\n\n## Two\n\n[link](http://example.com)",
+		"## Three\n\n```\ncode\n```",
+		"Plain text only.",
+	})
+
+	corpus := NewCorpus()
+	for _, doc := range docs {
+		corpus.AddDocument(doc)
+	}
+	// documents built by hand fall back to non-empty fields
+	corpus.AddDocument(Document{Fields: map[Field]string{FieldH2: "Manual", FieldBody: ""}})
+
+	stats := corpus.Stats()
+	if stats.Documents != 4 {
+		t.Errorf("Documents = %d, want 4", stats.Documents)
+	}
+
+	h2 := stats.Fields[FieldH2]
+	if h2.Occurrences != 4 || h2.Documents != 3 {
+		t.Errorf("H2 stats = %+v, want 4 occurrences in 3 documents", h2)
+	}
+	if h2.Mean != 1.0 {
+		t.Errorf("H2 mean = %v, want 1", h2.Mean)
+	}
+	if _, exists := stats.Fields[FieldBold]; exists {
+		t.Errorf("Bold stats present, want absent for unused field")
+	}
+	if stats.CodeBlocks != 1 || stats.Links != 1 {
+		t.Errorf("CodeBlocks = %d, Links = %d, want 1 and 1", stats.CodeBlocks, stats.Links)
+	}
+
+	if got := corpus.DocumentStats(0).Fields[FieldH2]; got != 2 {
+		t.Errorf("DocumentStats(0) H2 = %d, want 2", got)
+	}
+	if got := corpus.DocumentStats(3).Fields[FieldH2]; got != 1 {
+		t.Errorf("DocumentStats(3) H2 = %d, want 1", got)
+	}
+	if got := corpus.DocumentStats(99); got.Fields != nil {
+		t.Errorf("DocumentStats(99) = %+v, want zero value", got)
+	}
+}
@@ -0,0 +1,140 @@
+package bm25md
+
+import (
+	"strings"
+	"sync"
+)
+
+// EnglishStopwords is the bundled English stopword list, registered as "en"
+var EnglishStopwords = []string{
+	"a", "about", "above", "after", "again", "against", "all", "am", "an", "and",
+	"any", "are", "as", "at", "be", "because", "been", "before", "being", "below",
+	"between", "both", "but", "by", "can", "could", "did", "do", "does", "doing",
+	"down", "during", "each", "few", "for", "from", "further", "had", "has", "have",
+	"having", "he", "her", "here", "hers", "herself", "him", "himself", "his", "how",
+	"i", "if", "in", "into", "is", "it", "its", "itself", "just", "me",
+	"more", "most", "my", "myself", "no", "nor", "not", "now", "of", "off",
+	"on", "once", "only", "or", "other", "our", "ours", "ourselves", "out", "over",
+	"own", "same", "she", "should", "so", "some", "such", "than", "that", "the",
+	"their", "theirs", "them", "themselves", "then", "there", "these", "they", "this", "those",
+	"through", "to", "too", "under", "until", "up", "very", "was", "we", "were",
+	"what", "when", "where", "which", "while", "who", "whom", "why", "will", "with",
+	"would", "you", "your", "yours", "yourself", "yourselves",
+}
+
+var (
+	stopwordsMu   sync.RWMutex
+	stopwordLists = map[string][]string{}
+)
+
+func init() {
+	RegisterStopwords("en", EnglishStopwords)
+}
+
+// RegisterStopwords makes a stopword list available to WithLanguageStopwords,
+// replacing any list registered for the same language
+func RegisterStopwords(lang string, words []string) {
+	stopwordsMu.Lock()
+	defer stopwordsMu.Unlock()
+	stopwordLists[lang] = append([]string(nil), words...)
+}
+
+// LookupStopwords returns a copy of the stopword list registered for a language
+func LookupStopwords(lang string) ([]string, bool) {
+	stopwordsMu.RLock()
+	defer stopwordsMu.RUnlock()
+	words, ok := stopwordLists[lang]
+	if !ok {
+		return nil, false
+	}
+	return append([]string(nil), words...), true
+}
+
+// WithStopwords drops the given words from indexed content and queries;
+// it can be combined with other stopword options
+func WithStopwords(words ...string) CorpusOption {
+	return func(c *Corpus) {
+		c.addStopwords(words)
+	}
+}
+
+// WithLanguageStopwords drops the stopwords registered for a language (eg "en")
+func WithLanguageStopwords(lang string) CorpusOption {
+	return func(c *Corpus) {
+		words, ok := LookupStopwords(lang)
+		if !ok {
+			c.logger().Warn("Unknown bm25md stopword language, keeping current configuration", "lang", lang)
+			return
+		}
+		c.addStopwords(words)
+	}
+}
+
+// addStopwords adds words to the corpus stopword set
+func (c *Corpus) addStopwords(words []string) {
+	if c.stopwords == nil {
+		c.stopwords = make(map[string]bool, len(words))
+	}
+	for _, word := range words {
+		c.stopwords[strings.ToLower(word)] = true
+	}
+}
+
+// tokenize runs the query analyzer (the corpus tokenizer unless set with
+// WithQueryAnalyzer) and removes stopwords
+func (c *Corpus) tokenize(text string) []string {
+	tokenizer := c.tokenizer
+	if c.queryAnalyzer != nil {
+		tokenizer = c.queryAnalyzer
+	}
+	return c.filterStopwords(tokenizer.Tokenize(text))
+}
+
+// tokenizePhrase tokenizes the words of a phrase like tokenize, without the query
+// analyzer's synonym filters
+func (c *Corpus) tokenizePhrase(text string) []string {
+	analyzer, ok := c.queryAnalyzer.(Analyzer)
+	if !ok {
+		return c.tokenize(text)
+	}
+	return c.filterStopwords(analyzer.withoutSynonyms().Tokenize(text))
+}
+
+// tokenizeIndexed runs the index analyzer (the corpus tokenizer unless set with
+// WithIndexAnalyzer) and removes stopwords
+func (c *Corpus) tokenizeIndexed(text string) []string {
+	tokenizer := c.tokenizer
+	if c.indexAnalyzer != nil {
+		tokenizer = c.indexAnalyzer
+	}
+	return c.filterStopwords(tokenizer.Tokenize(text))
+}
+
+// tokenizeField runs a document field through its tokenizer (set with
+// WithFieldTokenizer, else the analyzer of the document's language with
+// WithLanguageDetection, else the index analyzer) and removes stopwords
+func (c *Corpus) tokenizeField(doc Document, field Field) []string {
+	text := doc.Fields[field]
+	if tokenizer, exists := c.tokenizers[field]; exists {
+		return c.filterStopwords(tokenizer.Tokenize(text))
+	}
+	if c.languages != nil {
+		return c.languageTokenize(c.documentLanguage(doc), c.tokenizeIndexed)(text)
+	}
+	return c.tokenizeIndexed(text)
+}
+
+// filterStopwords removes stopwords from tokens
+func (c *Corpus) filterStopwords(tokens []string) []string {
+	if len(c.stopwords) == 0 {
+		return tokens
+	}
+
+	filtered := make([]string, 0, len(tokens))
+	for _, token := range tokens {
+		if !c.stopwords[strings.ToLower(token)] {
+			filtered = append(filtered, token)
+		}
+	}
+	return filtered
+}
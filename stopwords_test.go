@@ -0,0 +1,107 @@
+package bm25md
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestCorpus_Stopwords(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     []CorpusOption
+		input    string
+		expected []string
+	}{
+		{
+			name:     "no stopwords by default",
+			input:    "The writ and the court",
+			expected: []string{"the", "writ", "and", "the", "court"},
+		},
+		{
+			name:     "english list",
+			opts:     []CorpusOption{WithLanguageStopwords("en")},
+			input:    "The writ and the court",
+			expected: []string{"writ", "court"},
+		},
+		{
+			name:     "custom words are case-insensitive",
+			opts:     []CorpusOption{WithStopwords("Writ")},
+			input:    "The writ and the court",
+			expected: []string{"the", "and", "the", "court"},
+		},
+		{
+			name:     "lists combine",
+			opts:     []CorpusOption{WithLanguageStopwords("en"), WithStopwords("court")},
+			input:    "The writ and the court",
+			expected: []string{"writ"},
+		},
+		{
+			name:     "unknown language is ignored",
+			opts:     []CorpusOption{WithLanguageStopwords("xx")},
+			input:    "The writ",
+			expected: []string{"the", "writ"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			corpus := NewCorpus(tt.opts...)
+			if got := corpus.tokenize(tt.input); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("tokenize(%q) = %v, want %v", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRegisterStopwords(t *testing.T) {
+	RegisterStopwords("de", []string{"und", "der", "die", "das"})
+	t.Cleanup(func() {
+		stopwordsMu.Lock()
+		delete(stopwordLists, "de")
+		stopwordsMu.Unlock()
+	})
+
+	words, ok := LookupStopwords("de")
+	if !ok || len(words) != 4 {
+		t.Fatalf("LookupStopwords(de) = %v, %v", words, ok)
+	}
+	// lookups return copies
+	words[0] = "changed"
+	if again, _ := LookupStopwords("de"); again[0] != "und" {
+		t.Error("LookupStopwords() exposed the registered list")
+	}
+
+	corpus := NewCorpus(WithLanguageStopwords("de"))
+	if got := corpus.tokenize("der Gerichtshof und die Klage"); !reflect.DeepEqual(got, []string{"gerichtshof", "klage"}) {
+		t.Errorf("tokenize() = %v, want [gerichtshof klage]", got)
+	}
+}
+
+func TestCorpus_StopwordsInSearch(t *testing.T) {
+	corpus := NewCorpus(WithLanguageStopwords("en"))
+	for _, body := range []string{"the writ of habeas corpus", "the court calendar", "the appeal rules"} {
+		corpus.AddDocument(Document{Fields: map[Field]string{FieldBody: body}})
+	}
+
+	// stopwords neither match documents nor count toward document length
+	if results := corpus.Search("the", 10); len(results) != 0 {
+		t.Errorf("Search(the) = %+v, want no results", results)
+	}
+	if got := corpus.fieldScorers[FieldBody].docLengths[0]; got != 3 {
+		t.Errorf("doc length = %d, want 3", got)
+	}
+
+	// stopwords are saved with the index
+	var buf bytes.Buffer
+	if err := corpus.Save(&buf); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	loaded, err := LoadCorpus(&buf)
+	if err != nil {
+		t.Fatalf("LoadCorpus() error = %v", err)
+	}
+	if got := loaded.tokenize("the writ"); !reflect.DeepEqual(got, []string{"writ"}) {
+		t.Errorf("loaded tokenize() = %v, want [writ]", got)
+	}
+}
@@ -0,0 +1,258 @@
+package bm25md
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Storage persists the documents, postings, and statistics of a StoredCorpus, so
+// an index can outlive the process and only the postings a query needs are read
+// into memory. Implementations must apply each Update atomically: when fn returns
+// an error, none of its writes may be kept
+type Storage interface {
+	// View runs fn in a read-only transaction
+	View(ctx context.Context, fn func(tx StorageTx) error) error
+	// Update runs fn in a read-write transaction, committing if it returns nil
+	Update(ctx context.Context, fn func(tx StorageTx) error) error
+	// Close releases the storage
+	Close() error
+}
+
+// StorageTx reads and writes index data within a Storage transaction. Documents
+// and statistics are opaque encoded values; postings map each document ID
+// containing a term to the term's frequency per field. Each document has a
+// short info record, read for every search candidate, kept apart from its
+// data, which is read only for search results
+type StorageTx interface {
+	// Document returns an encoded document (nil when there is no such document)
+	Document(id int) ([]byte, error)
+	// DocumentInfo returns a document's encoded info (nil when there is no such document)
+	DocumentInfo(id int) ([]byte, error)
+	// PutDocument stores an encoded document and its info, replacing any with the same ID
+	PutDocument(id int, externalID string, info, data []byte) error
+	// DeleteDocument removes a document, its info, and its external ID
+	DeleteDocument(id int) error
+	// LookupID returns the ID of the document with an external ID
+	LookupID(externalID string) (int, bool, error)
+
+	// Postings returns the documents containing a term
+	Postings(term string) (map[int]map[Field]int, error)
+	// PutPosting records a term's per-field frequencies in a document
+	PutPosting(term string, id int, freqs map[Field]int) error
+	// DeletePosting removes a document from a term's postings
+	DeletePosting(term string, id int) error
+	// Terms returns the indexed terms starting with prefix, in sorted order
+	Terms(prefix string) ([]string, error)
+
+	// Meta returns the encoded corpus statistics (nil when empty)
+	Meta() ([]byte, error)
+	// PutMeta stores the encoded corpus statistics
+	PutMeta(data []byte) error
+}
+
+// MemoryStorage is a Storage held in memory, for tests and for StoredCorpus use
+// without a database
+type MemoryStorage struct {
+	mu          sync.RWMutex
+	documents   map[int]memoryDocument
+	externalIDs map[string]int
+	postings    map[string]map[int]map[Field]int
+	meta        []byte
+}
+
+// NewMemoryStorage creates an empty in-memory storage
+func NewMemoryStorage() *MemoryStorage {
+	return &MemoryStorage{
+		documents:   make(map[int]memoryDocument),
+		externalIDs: make(map[string]int),
+		postings:    make(map[string]map[int]map[Field]int),
+	}
+}
+
+// View runs fn with shared access to the storage
+func (m *MemoryStorage) View(ctx context.Context, fn func(tx StorageTx) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return fn(&memoryTx{storage: m})
+}
+
+// Update runs fn with exclusive access to the storage, undoing its writes if it fails
+func (m *MemoryStorage) Update(ctx context.Context, fn func(tx StorageTx) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	tx := &memoryTx{storage: m, writable: true}
+	if err := fn(tx); err != nil {
+		// undo writes newest first
+		for i := len(tx.undo) - 1; i >= 0; i-- {
+			tx.undo[i]()
+		}
+		return err
+	}
+	return nil
+}
+
+// Close is a no-op for in-memory storage
+func (m *MemoryStorage) Close() error {
+	return nil
+}
+
+// memoryDocument is an encoded document, its info, and its external ID
+type memoryDocument struct {
+	data       []byte
+	info       []byte
+	externalID string
+}
+
+// memoryTx is a MemoryStorage transaction, recording how to undo each write
+type memoryTx struct {
+	storage  *MemoryStorage
+	writable bool
+	undo     []func()
+}
+
+// errReadOnly is returned by writes in a View transaction
+var errReadOnly = errors.New("bm25md: write in read-only transaction")
+
+func (tx *memoryTx) Document(id int) ([]byte, error) {
+	return tx.storage.documents[id].data, nil
+}
+
+func (tx *memoryTx) DocumentInfo(id int) ([]byte, error) {
+	return tx.storage.documents[id].info, nil
+}
+
+func (tx *memoryTx) PutDocument(id int, externalID string, info, data []byte) error {
+	if !tx.writable {
+		return errReadOnly
+	}
+	if err := tx.DeleteDocument(id); err != nil {
+		return err
+	}
+	m := tx.storage
+	m.documents[id] = memoryDocument{data: slices.Clone(data), info: slices.Clone(info), externalID: externalID}
+	if externalID != "" {
+		m.externalIDs[externalID] = id
+	}
+	tx.undo = append(tx.undo, func() {
+		delete(m.documents, id)
+		if externalID != "" {
+			delete(m.externalIDs, externalID)
+		}
+	})
+	return nil
+}
+
+func (tx *memoryTx) DeleteDocument(id int) error {
+	if !tx.writable {
+		return errReadOnly
+	}
+	m := tx.storage
+	doc, exists := m.documents[id]
+	if !exists {
+		return nil
+	}
+	delete(m.documents, id)
+	if doc.externalID != "" {
+		delete(m.externalIDs, doc.externalID)
+	}
+	tx.undo = append(tx.undo, func() {
+		m.documents[id] = doc
+		if doc.externalID != "" {
+			m.externalIDs[doc.externalID] = id
+		}
+	})
+	return nil
+}
+
+func (tx *memoryTx) LookupID(externalID string) (int, bool, error) {
+	id, exists := tx.storage.externalIDs[externalID]
+	return id, exists, nil
+}
+
+func (tx *memoryTx) Postings(term string) (map[int]map[Field]int, error) {
+	postings := make(map[int]map[Field]int, len(tx.storage.postings[term]))
+	for id, freqs := range tx.storage.postings[term] {
+		postings[id] = maps.Clone(freqs)
+	}
+	return postings, nil
+}
+
+func (tx *memoryTx) PutPosting(term string, id int, freqs map[Field]int) error {
+	if !tx.writable {
+		return errReadOnly
+	}
+	if err := tx.DeletePosting(term, id); err != nil {
+		return err
+	}
+	m := tx.storage
+	if m.postings[term] == nil {
+		m.postings[term] = make(map[int]map[Field]int)
+	}
+	m.postings[term][id] = maps.Clone(freqs)
+	tx.undo = append(tx.undo, func() {
+		delete(m.postings[term], id)
+		if len(m.postings[term]) == 0 {
+			delete(m.postings, term)
+		}
+	})
+	return nil
+}
+
+func (tx *memoryTx) DeletePosting(term string, id int) error {
+	if !tx.writable {
+		return errReadOnly
+	}
+	m := tx.storage
+	freqs, exists := m.postings[term][id]
+	if !exists {
+		return nil
+	}
+	delete(m.postings[term], id)
+	if len(m.postings[term]) == 0 {
+		delete(m.postings, term)
+	}
+	tx.undo = append(tx.undo, func() {
+		if m.postings[term] == nil {
+			m.postings[term] = make(map[int]map[Field]int)
+		}
+		m.postings[term][id] = freqs
+	})
+	return nil
+}
+
+func (tx *memoryTx) Terms(prefix string) ([]string, error) {
+	var terms []string
+	for term := range tx.storage.postings {
+		if strings.HasPrefix(term, prefix) {
+			terms = append(terms, term)
+		}
+	}
+	slices.Sort(terms)
+	return terms, nil
+}
+
+func (tx *memoryTx) Meta() ([]byte, error) {
+	return tx.storage.meta, nil
+}
+
+func (tx *memoryTx) PutMeta(data []byte) error {
+	if !tx.writable {
+		return errReadOnly
+	}
+	m := tx.storage
+	previous := m.meta
+	m.meta = slices.Clone(data)
+	tx.undo = append(tx.undo, func() { m.meta = previous })
+	return nil
+}
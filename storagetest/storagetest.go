@@ -0,0 +1,284 @@
+// Package storagetest checks bm25md.Storage implementations against the
+// behavior StoredCorpus relies on.
+package storagetest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/chriscorrea/bm25md"
+)
+
+// TestStorage runs the conformance tests against storages created by open, which
+// is called once per test and should return an empty storage
+func TestStorage(t *testing.T, open func(t *testing.T) bm25md.Storage) {
+	t.Run("Documents", func(t *testing.T) { testDocuments(t, open(t)) })
+	t.Run("Postings", func(t *testing.T) { testPostings(t, open(t)) })
+	t.Run("Meta", func(t *testing.T) { testMeta(t, open(t)) })
+	t.Run("Rollback", func(t *testing.T) { testRollback(t, open(t)) })
+	t.Run("Corpus", func(t *testing.T) { testCorpus(t, open(t)) })
+}
+
+// update runs fn in a write transaction, failing the test on error
+func update(t *testing.T, storage bm25md.Storage, fn func(tx bm25md.StorageTx) error) {
+	t.Helper()
+	if err := storage.Update(context.Background(), fn); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+}
+
+// view runs fn in a read transaction, failing the test on error
+func view(t *testing.T, storage bm25md.Storage, fn func(tx bm25md.StorageTx) error) {
+	t.Helper()
+	if err := storage.View(context.Background(), fn); err != nil {
+		t.Fatalf("View() error = %v", err)
+	}
+}
+
+func testDocuments(t *testing.T, storage bm25md.Storage) {
+	update(t, storage, func(tx bm25md.StorageTx) error {
+		if err := tx.PutDocument(1, "a.md", []byte("first info"), []byte("first")); err != nil {
+			return err
+		}
+		if err := tx.PutDocument(2, "", []byte("second info"), []byte("second")); err != nil {
+			return err
+		}
+		// replacing a document may change its external ID
+		return tx.PutDocument(1, "b.md", []byte("replaced info"), []byte("replaced"))
+	})
+
+	view(t, storage, func(tx bm25md.StorageTx) error {
+		for id, want := range map[int]string{1: "replaced", 2: "second"} {
+			if data, err := tx.Document(id); err != nil || string(data) != want {
+				t.Errorf("Document(%d) = %q, %v, want %q", id, data, err, want)
+			}
+			if info, err := tx.DocumentInfo(id); err != nil || string(info) != want+" info" {
+				t.Errorf("DocumentInfo(%d) = %q, %v, want %q", id, info, err, want+" info")
+			}
+		}
+		if data, err := tx.Document(3); err != nil || data != nil {
+			t.Errorf("Document(3) = %q, %v, want nil", data, err)
+		}
+		if info, err := tx.DocumentInfo(3); err != nil || info != nil {
+			t.Errorf("DocumentInfo(3) = %q, %v, want nil", info, err)
+		}
+		if _, exists, err := tx.LookupID("a.md"); err != nil || exists {
+			t.Errorf("LookupID(a.md) = %v, %v, want a replaced external ID to be gone", exists, err)
+		}
+		if id, exists, err := tx.LookupID("b.md"); err != nil || !exists || id != 1 {
+			t.Errorf("LookupID(b.md) = %d, %v, %v, want 1", id, exists, err)
+		}
+		return nil
+	})
+
+	update(t, storage, func(tx bm25md.StorageTx) error {
+		if err := tx.DeleteDocument(1); err != nil {
+			return err
+		}
+		// deleting a missing document is not an error
+		return tx.DeleteDocument(5)
+	})
+	view(t, storage, func(tx bm25md.StorageTx) error {
+		if data, err := tx.Document(1); err != nil || data != nil {
+			t.Errorf("Document(1) after delete = %q, %v", data, err)
+		}
+		if info, err := tx.DocumentInfo(1); err != nil || info != nil {
+			t.Errorf("DocumentInfo(1) after delete = %q, %v", info, err)
+		}
+		if _, exists, err := tx.LookupID("b.md"); err != nil || exists {
+			t.Errorf("LookupID(b.md) after delete = %v, %v", exists, err)
+		}
+		return nil
+	})
+}
+
+func testPostings(t *testing.T, storage bm25md.Storage) {
+	update(t, storage, func(tx bm25md.StorageTx) error {
+		puts := []struct {
+			term  string
+			id    int
+			freqs map[bm25md.Field]int
+		}{
+			{"habeas", 1, map[bm25md.Field]int{bm25md.FieldBody: 2, bm25md.FieldH1: 1}},
+			{"habeas", 2, map[bm25md.Field]int{bm25md.FieldBody: 1}},
+			{"habitat", 3, map[bm25md.Field]int{bm25md.FieldBody: 1}},
+			{"hab", 3, map[bm25md.Field]int{bm25md.FieldCode: 1}},
+			{"court", 1, map[bm25md.Field]int{bm25md.FieldBody: 4}},
+			// replacing a posting drops fields it no longer has
+			{"habeas", 1, map[bm25md.Field]int{bm25md.FieldBody: 3}},
+		}
+		for _, put := range puts {
+			if err := tx.PutPosting(put.term, put.id, put.freqs); err != nil {
+				return err
+			}
+		}
+		return tx.DeletePosting("habeas", 2)
+	})
+
+	view(t, storage, func(tx bm25md.StorageTx) error {
+		postings, err := tx.Postings("habeas")
+		want := map[int]map[bm25md.Field]int{1: {bm25md.FieldBody: 3}}
+		if err != nil || !reflect.DeepEqual(postings, want) {
+			t.Errorf("Postings(habeas) = %v, %v, want %v", postings, err, want)
+		}
+		if postings, err := tx.Postings("missing"); err != nil || len(postings) != 0 {
+			t.Errorf("Postings(missing) = %v, %v, want none", postings, err)
+		}
+
+		for prefix, want := range map[string][]string{
+			"hab":  {"hab", "habeas", "habitat"},
+			"habi": {"habitat"},
+			"z":    nil,
+		} {
+			terms, err := tx.Terms(prefix)
+			if err != nil || (len(terms) > 0 || len(want) > 0) && !reflect.DeepEqual(terms, want) {
+				t.Errorf("Terms(%q) = %v, %v, want %v", prefix, terms, err, want)
+			}
+		}
+		return nil
+	})
+
+	// a term with no postings left is no longer listed
+	update(t, storage, func(tx bm25md.StorageTx) error {
+		return tx.DeletePosting("habitat", 3)
+	})
+	view(t, storage, func(tx bm25md.StorageTx) error {
+		if terms, err := tx.Terms("habi"); err != nil || len(terms) != 0 {
+			t.Errorf("Terms(habi) = %v, %v, want none", terms, err)
+		}
+		return nil
+	})
+}
+
+func testMeta(t *testing.T, storage bm25md.Storage) {
+	view(t, storage, func(tx bm25md.StorageTx) error {
+		if data, err := tx.Meta(); err != nil || data != nil {
+			t.Errorf("Meta() of empty storage = %q, %v, want nil", data, err)
+		}
+		return nil
+	})
+	update(t, storage, func(tx bm25md.StorageTx) error {
+		if err := tx.PutMeta([]byte("v1")); err != nil {
+			return err
+		}
+		return tx.PutMeta([]byte("v2"))
+	})
+	view(t, storage, func(tx bm25md.StorageTx) error {
+		if data, err := tx.Meta(); err != nil || string(data) != "v2" {
+			t.Errorf("Meta() = %q, %v, want v2", data, err)
+		}
+		return nil
+	})
+}
+
+func testRollback(t *testing.T, storage bm25md.Storage) {
+	update(t, storage, func(tx bm25md.StorageTx) error {
+		if err := tx.PutDocument(1, "kept.md", []byte("kept info"), []byte("kept")); err != nil {
+			return err
+		}
+		return tx.PutPosting("kept", 1, map[bm25md.Field]int{bm25md.FieldBody: 1})
+	})
+
+	errFailed := errors.New("failed")
+	err := storage.Update(context.Background(), func(tx bm25md.StorageTx) error {
+		if err := tx.PutDocument(2, "lost.md", []byte("lost info"), []byte("lost")); err != nil {
+			return err
+		}
+		if err := tx.DeleteDocument(1); err != nil {
+			return err
+		}
+		if err := tx.PutPosting("lost", 2, map[bm25md.Field]int{bm25md.FieldBody: 1}); err != nil {
+			return err
+		}
+		if err := tx.DeletePosting("kept", 1); err != nil {
+			return err
+		}
+		if err := tx.PutMeta([]byte("lost")); err != nil {
+			return err
+		}
+		return errFailed
+	})
+	if !errors.Is(err, errFailed) {
+		t.Fatalf("Update() error = %v, want %v", err, errFailed)
+	}
+
+	view(t, storage, func(tx bm25md.StorageTx) error {
+		if data, _ := tx.Document(1); string(data) != "kept" {
+			t.Errorf("Document(1) = %q, want the write to be rolled back", data)
+		}
+		if info, _ := tx.DocumentInfo(1); string(info) != "kept info" {
+			t.Errorf("DocumentInfo(1) = %q, want the write to be rolled back", info)
+		}
+		if data, _ := tx.Document(2); data != nil {
+			t.Errorf("Document(2) = %q, want the write to be rolled back", data)
+		}
+		if info, _ := tx.DocumentInfo(2); info != nil {
+			t.Errorf("DocumentInfo(2) = %q, want the write to be rolled back", info)
+		}
+		if _, exists, _ := tx.LookupID("lost.md"); exists {
+			t.Error("LookupID(lost.md) found a rolled back document")
+		}
+		if postings, _ := tx.Postings("kept"); len(postings) != 1 {
+			t.Errorf("Postings(kept) = %v, want the delete to be rolled back", postings)
+		}
+		if postings, _ := tx.Postings("lost"); len(postings) != 0 {
+			t.Errorf("Postings(lost) = %v, want the write to be rolled back", postings)
+		}
+		if data, _ := tx.Meta(); data != nil {
+			t.Errorf("Meta() = %q, want the write to be rolled back", data)
+		}
+		return nil
+	})
+}
+
+func testCorpus(t *testing.T, storage bm25md.Storage) {
+	ctx := context.Background()
+	var docs []bm25md.Document
+	for i := 0; i < 40; i++ {
+		body := fmt.Sprintf("filing %d for the court", i)
+		switch {
+		case i%10 == 0:
+			body += " habeas corpus petition"
+		case i%4 == 0:
+			body += " appeal of the habeas ruling"
+		}
+		docs = append(docs, bm25md.Document{
+			ExternalID: fmt.Sprintf("doc-%d", i),
+			Fields:     map[bm25md.Field]string{bm25md.FieldH1: fmt.Sprintf("Filing %d", i), bm25md.FieldBody: body},
+		})
+	}
+
+	want := bm25md.NewStoredCorpus(bm25md.NewMemoryStorage())
+	got := bm25md.NewStoredCorpus(storage)
+	for _, corpus := range []*bm25md.StoredCorpus{want, got} {
+		if err := corpus.AddDocuments(ctx, docs); err != nil {
+			t.Fatalf("AddDocuments() error = %v", err)
+		}
+		if err := corpus.RemoveDocument(ctx, 8); err != nil {
+			t.Fatalf("RemoveDocument() error = %v", err)
+		}
+	}
+
+	for _, query := range []string{"habeas", `"habeas corpus"`, "+appeal habeas", "hab*", "filing -habeas"} {
+		wantResults, err := want.Search(ctx, query, 5)
+		if err != nil {
+			t.Fatalf("Search(%q) error = %v", query, err)
+		}
+		gotResults, err := got.Search(ctx, query, 5)
+		if err != nil {
+			t.Fatalf("Search(%q) error = %v", query, err)
+		}
+		if len(gotResults) != len(wantResults) {
+			t.Fatalf("Search(%q) returned %d results, want %d", query, len(gotResults), len(wantResults))
+		}
+		for i := range wantResults {
+			if gotResults[i].ExternalID != wantResults[i].ExternalID || gotResults[i].Score != wantResults[i].Score {
+				t.Errorf("Search(%q) result %d = %s (%v), want %s (%v)", query, i,
+					gotResults[i].ExternalID, gotResults[i].Score, wantResults[i].ExternalID, wantResults[i].Score)
+			}
+		}
+	}
+}
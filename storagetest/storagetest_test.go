@@ -0,0 +1,13 @@
+package storagetest
+
+import (
+	"testing"
+
+	"github.com/chriscorrea/bm25md"
+)
+
+func TestMemoryStorage(t *testing.T) {
+	TestStorage(t, func(t *testing.T) bm25md.Storage {
+		return bm25md.NewMemoryStorage()
+	})
+}
@@ -0,0 +1,583 @@
+package bm25md
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"time"
+)
+
+// StoredCorpus is a corpus whose documents and postings live in a Storage (eg
+// SQLite or bbolt) rather than in memory, so the index survives restarts and
+// corpora larger than RAM stay searchable. Each write is a single storage
+// transaction, and searches read only the postings they touch, a short record
+// of each candidate, and the documents they return. It ranks like a Corpus with
+// the same options, except that feedback priors are not applied. Search filters
+// see each candidate's ID, ExternalID, Metadata, Boost, and Timestamp, but not
+// its fields or original text
+type StoredCorpus struct {
+	storage Storage
+	config  *Corpus // settings: tokenizer, weights, parameters, similarity, hooks
+}
+
+// storedDocument is the encoded form of a document in storage
+type storedDocument struct {
+	Document Document
+	Terms    []string // distinct indexed terms, for removing postings
+}
+
+// storedInfo is the encoded form of what searches read to filter and score a
+// document. It is stored apart from the document, so scoring a candidate does
+// not decode its text
+type storedInfo struct {
+	ExternalID string
+	Metadata   map[string]any
+	Boost      float64
+	Timestamp  time.Time
+	Lengths    map[Field]int // indexed tokens per field
+}
+
+// document returns the parts of a document that search filters see
+func (info storedInfo) document(id int) Document {
+	return Document{ID: id, ExternalID: info.ExternalID, Metadata: info.Metadata, Boost: info.Boost, Timestamp: info.Timestamp}
+}
+
+// storedStats is the encoded form of the corpus statistics in storage
+type storedStats struct {
+	NextID       int           // ID for the next new document
+	Documents    int           // live documents
+	FieldLengths map[Field]int // tokens per field across documents
+	FieldDocs    map[Field]int // documents with each field
+}
+
+// NewStoredCorpus creates a corpus backed by storage, which may already hold an
+// index built with the same options (in particular the same tokenizer)
+func NewStoredCorpus(storage Storage, opts ...CorpusOption) *StoredCorpus {
+	return &StoredCorpus{storage: storage, config: NewCorpus(opts...)}
+}
+
+// Close closes the underlying storage
+func (s *StoredCorpus) Close() error {
+	return s.storage.Close()
+}
+
+// AddDocument adds a document, replacing any document with the same ExternalID
+// (which keeps its ID)
+func (s *StoredCorpus) AddDocument(ctx context.Context, doc Document) error {
+	return s.AddDocuments(ctx, []Document{doc})
+}
+
+// AddDocuments adds many documents in one transaction, tokenizing them in
+// parallel first; if any write fails, none of the documents are added
+func (s *StoredCorpus) AddDocuments(ctx context.Context, docs []Document) error {
+	analyzed := s.config.analyzeDocuments(docs)
+	err := s.storage.Update(ctx, func(tx StorageTx) error {
+		stats, err := loadStats(tx)
+		if err != nil {
+			return err
+		}
+		for i, doc := range docs {
+			if err := s.put(tx, &stats, doc, analyzed[i]); err != nil {
+				return err
+			}
+		}
+		return saveStats(tx, stats)
+	})
+	if err != nil {
+		return err
+	}
+	s.config.logger().Debug("Added documents to stored BM25md corpus", "documents", len(docs))
+	return nil
+}
+
+// RemoveDocument removes a document and its postings
+func (s *StoredCorpus) RemoveDocument(ctx context.Context, id int) error {
+	return s.storage.Update(ctx, func(tx StorageTx) error {
+		stats, err := loadStats(tx)
+		if err != nil {
+			return err
+		}
+		if err := s.remove(tx, &stats, id); err != nil {
+			return err
+		}
+		return saveStats(tx, stats)
+	})
+}
+
+// LookupID returns the ID of the document with the given external ID
+func (s *StoredCorpus) LookupID(ctx context.Context, externalID string) (int, bool, error) {
+	if externalID == "" {
+		return 0, false, nil
+	}
+	var id int
+	var exists bool
+	err := s.storage.View(ctx, func(tx StorageTx) error {
+		var err error
+		id, exists, err = tx.LookupID(externalID)
+		return err
+	})
+	return id, exists, err
+}
+
+// Document returns the document with an ID
+func (s *StoredCorpus) Document(ctx context.Context, id int) (Document, bool, error) {
+	var record storedDocument
+	var exists bool
+	err := s.storage.View(ctx, func(tx StorageTx) error {
+		var err error
+		record, exists, err = loadDocument(tx, id)
+		return err
+	})
+	return record.Document, exists, err
+}
+
+// Len returns the number of documents
+func (s *StoredCorpus) Len(ctx context.Context) (int, error) {
+	var stats storedStats
+	err := s.storage.View(ctx, func(tx StorageTx) error {
+		var err error
+		stats, err = loadStats(tx)
+		return err
+	})
+	return stats.Documents, err
+}
+
+// put indexes a document, replacing the document with its external ID if there is one
+func (s *StoredCorpus) put(tx StorageTx, stats *storedStats, doc Document, analysis map[Field]fieldAnalysis) error {
+	doc.ID = stats.NextID
+	if doc.ExternalID != "" {
+		id, exists, err := tx.LookupID(doc.ExternalID)
+		if err != nil {
+			return fmt.Errorf("bm25md: looking up %q: %w", doc.ExternalID, err)
+		}
+		if exists {
+			if err := s.remove(tx, stats, id); err != nil {
+				return err
+			}
+			doc.ID = id
+		}
+	}
+	if doc.ID == stats.NextID {
+		stats.NextID++
+	}
+
+	record := storedDocument{Document: doc}
+	info := storedInfo{ExternalID: doc.ExternalID, Metadata: doc.Metadata, Boost: doc.Boost, Timestamp: doc.Timestamp, Lengths: make(map[Field]int)}
+	freqs := make(map[string]map[Field]int)
+	for field, fa := range analysis {
+		if fa.length == 0 {
+			continue
+		}
+		info.Lengths[field] = fa.length
+		stats.FieldLengths[field] += fa.length
+		stats.FieldDocs[field]++
+		for term, tf := range fa.tf {
+			if freqs[term] == nil {
+				freqs[term] = make(map[Field]int, 1)
+			}
+			freqs[term][field] = tf
+		}
+	}
+	for term, termFreqs := range freqs {
+		if err := tx.PutPosting(term, doc.ID, termFreqs); err != nil {
+			return fmt.Errorf("bm25md: storing postings of %q: %w", term, err)
+		}
+		record.Terms = append(record.Terms, term)
+	}
+	sort.Strings(record.Terms)
+
+	var infoBuf, buf bytes.Buffer
+	if err := gob.NewEncoder(&infoBuf).Encode(info); err != nil {
+		return fmt.Errorf("bm25md: encoding document %d: %w", doc.ID, err)
+	}
+	if err := gob.NewEncoder(&buf).Encode(record); err != nil {
+		return fmt.Errorf("bm25md: encoding document %d: %w", doc.ID, err)
+	}
+	if err := tx.PutDocument(doc.ID, doc.ExternalID, infoBuf.Bytes(), buf.Bytes()); err != nil {
+		return fmt.Errorf("bm25md: storing document %d: %w", doc.ID, err)
+	}
+	stats.Documents++
+	return nil
+}
+
+// remove deletes a document's postings and record, and its share of the statistics
+func (s *StoredCorpus) remove(tx StorageTx, stats *storedStats, id int) error {
+	record, exists, err := loadDocument(tx, id)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("bm25md: no document with ID %d", id)
+	}
+	info, _, err := loadInfo(tx, id)
+	if err != nil {
+		return err
+	}
+	for _, term := range record.Terms {
+		if err := tx.DeletePosting(term, id); err != nil {
+			return fmt.Errorf("bm25md: removing postings of %q: %w", term, err)
+		}
+	}
+	for field, length := range info.Lengths {
+		stats.FieldLengths[field] -= length
+		stats.FieldDocs[field]--
+	}
+	stats.Documents--
+	if err := tx.DeleteDocument(id); err != nil {
+		return fmt.Errorf("bm25md: removing document %d: %w", id, err)
+	}
+	return nil
+}
+
+// loadDocument reads and decodes a stored document
+func loadDocument(tx StorageTx, id int) (storedDocument, bool, error) {
+	var record storedDocument
+	data, err := tx.Document(id)
+	if err != nil {
+		return record, false, fmt.Errorf("bm25md: reading document %d: %w", id, err)
+	}
+	if data == nil {
+		return record, false, nil
+	}
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&record); err != nil {
+		return record, false, fmt.Errorf("bm25md: decoding document %d: %w", id, err)
+	}
+	return record, true, nil
+}
+
+// loadInfo reads and decodes the search record of a stored document
+func loadInfo(tx StorageTx, id int) (storedInfo, bool, error) {
+	var info storedInfo
+	data, err := tx.DocumentInfo(id)
+	if err != nil {
+		return info, false, fmt.Errorf("bm25md: reading document %d: %w", id, err)
+	}
+	if data == nil {
+		return info, false, nil
+	}
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&info); err != nil {
+		return info, false, fmt.Errorf("bm25md: decoding document %d: %w", id, err)
+	}
+	return info, true, nil
+}
+
+// loadStats reads the corpus statistics, which are empty for new storage
+func loadStats(tx StorageTx) (storedStats, error) {
+	stats := storedStats{FieldLengths: make(map[Field]int), FieldDocs: make(map[Field]int)}
+	data, err := tx.Meta()
+	if err != nil {
+		return stats, fmt.Errorf("bm25md: reading corpus statistics: %w", err)
+	}
+	if data == nil {
+		return stats, nil
+	}
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&stats); err != nil {
+		return stats, fmt.Errorf("bm25md: decoding corpus statistics: %w", err)
+	}
+	return stats, nil
+}
+
+// saveStats writes the corpus statistics
+func saveStats(tx StorageTx, stats storedStats) error {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(stats); err != nil {
+		return fmt.Errorf("bm25md: encoding corpus statistics: %w", err)
+	}
+	if err := tx.PutMeta(buf.Bytes()); err != nil {
+		return fmt.Errorf("bm25md: storing corpus statistics: %w", err)
+	}
+	return nil
+}
+
+// totalTokens returns the number of tokens indexed across all fields
+func (stats storedStats) totalTokens() int {
+	total := 0
+	for _, length := range stats.FieldLengths {
+		total += length
+	}
+	return total
+}
+
+// avgLength returns the average length of a field over documents that have it
+func (stats storedStats) avgLength(field Field) float64 {
+	if stats.FieldDocs[field] == 0 {
+		return 0
+	}
+	return float64(stats.FieldLengths[field]) / float64(stats.FieldDocs[field])
+}
+
+// Search performs a search like Corpus.Search, reading from storage
+func (s *StoredCorpus) Search(ctx context.Context, query string, limit int) ([]SearchResult, error) {
+	return s.SearchWithOptions(ctx, query, SearchOptions{Limit: limit})
+}
+
+// SearchWithOptions performs a search like Corpus.SearchWithOptions, reading from storage
+func (s *StoredCorpus) SearchWithOptions(ctx context.Context, query string, opts SearchOptions) ([]SearchResult, error) {
+	start := time.Now()
+	ctx, span := s.config.startSpan(ctx, "bm25md.Search", start)
+	defer span.End()
+	var results []SearchResult
+	var queryTerms []string
+	var totalHits, documents, matched, scored int
+
+	err := s.storage.View(ctx, func(tx StorageTx) error {
+		stats, err := loadStats(tx)
+		if err != nil {
+			return err
+		}
+		var terms []queryTerm
+		queryTerms, terms, err = s.prepareQuery(tx, stats, query)
+		if err != nil {
+			return err
+		}
+		terms = restrictFields(terms, opts.Fields)
+
+		top := newTopResults(opts.resultCap(), opts.MinScore)
+		matching := candidates(terms)
+		top.candidates = len(matching)
+		for i, docIndex := range matching {
+			if i%cancelCheckInterval == 0 && ctx.Err() != nil {
+				return ctx.Err()
+			}
+			info, exists, err := loadInfo(tx, docIndex)
+			if err != nil {
+				return err
+			}
+			if exists && opts.accepts(docIndex, info.document(docIndex)) {
+				top.push(SearchResult{Score: s.score(terms, docIndex, info, stats), Index: docIndex})
+			}
+		}
+
+		// whole documents are read for the returned page (and any deduplication) only,
+		// keeping memory bounded
+		results, err = s.config.dedupe(top.sorted(), opts, func(index int) (Document, error) {
+			record, _, err := loadDocument(tx, index)
+			return record.Document, err
+		})
+		if err != nil {
+			return err
+		}
+		results = opts.page(results)
+		for i := range results {
+			record, _, err := loadDocument(tx, results[i].Index)
+			if err != nil {
+				return err
+			}
+			results[i].Document = record.Document
+			results[i].ExternalID = record.Document.ExternalID
+		}
+		totalHits, documents, matched, scored = top.hits, stats.Documents, top.candidates, top.scored
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	c := s.config
+	if c.calibrator != nil {
+		for i := range results {
+			results[i].Probability = c.calibrator.Probability(results[i].Score)
+		}
+	}
+	span.SetAttribute("bm25md.query_terms", len(queryTerms))
+	span.SetAttribute("bm25md.hits", totalHits)
+	duration := time.Since(start)
+	c.logger().Debug("Searched stored BM25md corpus", "query", query, "hits", totalHits, "results", len(results), "duration", duration)
+	if c.searchHook != nil {
+		c.searchHook(query, results, SearchStats{
+			QueryTerms: queryTerms,
+			TotalHits:  totalHits,
+			Candidates: matched,
+			Scored:     scored,
+			Documents:  documents,
+			Duration:   duration,
+		})
+	}
+	return results, nil
+}
+
+// prepareQuery parses a query and resolves its clauses against stored postings,
+// like Corpus.prepareQueryString
+func (s *StoredCorpus) prepareQuery(tx StorageTx, stats storedStats, query string) ([]string, []queryTerm, error) {
+	c := s.config
+	var tokens []string
+	var prepared []queryTerm
+	for _, clause := range c.parseQuery(query) {
+		tokens = append(tokens, clause.tokens...)
+
+		var name string
+		var postings postingList
+		var err error
+		switch {
+		case clause.regexp != nil:
+			name = clause.tokens[0]
+			postings, err = s.regexpPostings(tx, clause.regexp)
+		case clause.prefix:
+			name = clause.tokens[0] + "*"
+			postings, err = s.prefixPostings(tx, clause.tokens[0])
+		case len(clause.tokens) == 1:
+			name = clause.tokens[0]
+			postings, err = tx.Postings(name)
+		default:
+			name = phraseName(clause.tokens, clause.near)
+			postings, err = s.phrasePostings(tx, clause.tokens, clause.near)
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("bm25md: reading postings of %q: %w", name, err)
+		}
+
+		// missing required terms still apply (matching nothing); missing others are dropped
+		switch {
+		case len(postings) > 0:
+			termStats := termStats(name, postings, stats.Documents, stats.totalTokens())
+			prepared = append(prepared, queryTerm{
+				term:     name,
+				scorer:   clause.scale(c.similarity.TermScorer(termStats, c.params)),
+				postings: postings,
+				stats:    termStats,
+				occur:    clause.occur,
+			})
+		case clause.occur == occurMust:
+			prepared = append(prepared, queryTerm{term: name, occur: clause.occur})
+		}
+	}
+	return tokens, prepared, nil
+}
+
+// prefixPostings combines the postings of the stored terms starting with prefix,
+// keeping the most common ones when there are more than the expansion cap
+func (s *StoredCorpus) prefixPostings(tx StorageTx, prefix string) (postingList, error) {
+	// an indexed edge n-gram already combines every term it begins
+	if s.config.edgeNGrams.indexed(prefix) {
+		return tx.Postings(prefix)
+	}
+	terms, err := tx.Terms(prefix)
+	if err != nil {
+		return nil, err
+	}
+	return s.combinePostings(tx, terms)
+}
+
+// combinePostings merges the postings of stored terms, keeping the most common
+// ones when there are more than the expansion cap
+func (s *StoredCorpus) combinePostings(tx StorageTx, terms []string) (postingList, error) {
+	lists := make([]postingList, 0, len(terms))
+	for _, term := range terms {
+		list, err := tx.Postings(term)
+		if err != nil {
+			return nil, err
+		}
+		lists = append(lists, list)
+	}
+
+	limit := s.config.maxExpansions
+	if limit <= 0 {
+		limit = DefaultMaxPrefixExpansions
+	}
+	if len(lists) > limit {
+		sort.SliceStable(lists, func(i, j int) bool { return len(lists[i]) > len(lists[j]) })
+		lists = lists[:limit]
+	}
+
+	combined := make(postingList)
+	for _, list := range lists {
+		for docIndex, fields := range list {
+			if combined[docIndex] == nil {
+				combined[docIndex] = make(map[Field]int, len(fields))
+			}
+			for field, tf := range fields {
+				combined[docIndex][field] += tf
+			}
+		}
+	}
+	return combined, nil
+}
+
+// phrasePostings finds the documents and fields where tokens appear consecutively
+// (or, for NEAR, within near positions). Positions are not stored, so candidate
+// documents are re-tokenized
+func (s *StoredCorpus) phrasePostings(tx StorageTx, tokens []string, near int) (postingList, error) {
+	lists := make([]postingList, len(tokens))
+	for i, token := range tokens {
+		list, err := tx.Postings(token)
+		if err != nil || len(list) == 0 {
+			return nil, err
+		}
+		lists[i] = list
+	}
+	sort.Slice(lists, func(i, j int) bool { return len(lists[i]) < len(lists[j]) })
+
+	postings := make(postingList)
+	for _, docIndex := range slices.Sorted(maps.Keys(lists[0])) {
+		// only fields containing every token can contain the phrase
+		var fields []Field
+		for field := range lists[0][docIndex] {
+			inAll := true
+			for _, list := range lists[1:] {
+				if list[docIndex][field] == 0 {
+					inAll = false
+					break
+				}
+			}
+			if inAll {
+				fields = append(fields, field)
+			}
+		}
+		if len(fields) == 0 {
+			continue
+		}
+
+		record, exists, err := loadDocument(tx, docIndex)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			continue
+		}
+		for _, field := range fields {
+			positions := s.config.analyzeField(record.Document, field).positions
+			if freq := phraseFrequency(positions, tokens, near); freq > 0 {
+				if postings[docIndex] == nil {
+					postings[docIndex] = make(map[Field]int, 1)
+				}
+				postings[docIndex][field] = freq
+			}
+		}
+	}
+	return postings, nil
+}
+
+// score scores a stored document like Corpus.scoreDocument, using the stored
+// field lengths and corpus-wide average lengths
+func (s *StoredCorpus) score(terms []queryTerm, docIndex int, info storedInfo, stats storedStats) float64 {
+	c := s.config
+	totalScore := 0.0
+	for _, qt := range terms {
+		fields, exists := qt.postings[docIndex]
+		if !exists || qt.occur == occurMustNot {
+			continue
+		}
+
+		fieldMatches := make([]FieldMatch, 0, len(fields))
+		for field, tf := range fields {
+			scorer := c.fieldScorers[field]
+			if scorer == nil {
+				continue
+			}
+			fieldMatches = append(fieldMatches, FieldMatch{
+				Field:     field,
+				Freq:      tf,
+				Length:    info.Lengths[field],
+				AvgLength: stats.avgLength(field),
+				Weight:    c.fieldWeights[field],
+				Params:    scorer.params,
+			})
+		}
+		totalScore += qt.scorer(fieldMatches)
+	}
+	return totalScore * info.document(docIndex).boost() * c.recencyAt(info.Timestamp)
+}
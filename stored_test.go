@@ -0,0 +1,206 @@
+package bm25md
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestStoredCorpus_MatchesCorpus(t *testing.T) {
+	ctx := context.Background()
+	corpus := randomCorpus(300)
+	stored := NewStoredCorpus(NewMemoryStorage())
+	if err := stored.AddDocuments(ctx, corpus.Documents()); err != nil {
+		t.Fatalf("AddDocuments() error = %v", err)
+	}
+
+	queries := []string{
+		"habeas",
+		"habeas corpus petition",
+		"+federal court -state",
+		`"habeas corpus" review`,
+		"habeas NEAR/3 petition",
+		"constitu*",
+		"+missing court",
+	}
+	options := []SearchOptions{
+		{Limit: 10},
+		{Limit: 5, Offset: 5},
+		{MinScore: 2},
+		{Limit: 10, Fields: []Field{FieldH1}},
+		{Limit: 10, Filter: MetadataEquals("even", true)},
+		{Limit: 10, Dedupe: 0.3},
+		{Limit: 10, Ranges: []Range{{Key: "n", Min: 100, Max: 199}}},
+	}
+	for _, query := range queries {
+		for _, opts := range options {
+			t.Run(fmt.Sprintf("%s/%+v", query, opts), func(t *testing.T) {
+				got, err := stored.SearchWithOptions(ctx, query, opts)
+				if err != nil {
+					t.Fatalf("SearchWithOptions() error = %v", err)
+				}
+				sameResults(t, got, corpus.SearchWithOptions(query, opts))
+			})
+		}
+	}
+}
+
+func TestStoredCorpus_Updates(t *testing.T) {
+	ctx := context.Background()
+	storage := NewMemoryStorage()
+	stored := NewStoredCorpus(storage)
+	docs := []Document{
+		{ExternalID: "a.md", Fields: map[Field]string{FieldBody: "habeas corpus petition"}},
+		{ExternalID: "b.md", Fields: map[Field]string{FieldBody: "notice of appeal"}},
+		{Fields: map[Field]string{FieldBody: "court calendar"}},
+	}
+	// filler keeps rare terms' IDF positive in such a small corpus
+	for i := 0; i < 5; i++ {
+		docs = append(docs, Document{Fields: map[Field]string{FieldBody: "filler text"}})
+	}
+	if err := stored.AddDocuments(ctx, docs); err != nil {
+		t.Fatalf("AddDocuments() error = %v", err)
+	}
+
+	// re-adding an external ID replaces the document and keeps its ID
+	if err := stored.AddDocument(ctx, Document{ExternalID: "a.md", Fields: map[Field]string{FieldBody: "amended petition"}}); err != nil {
+		t.Fatalf("AddDocument() error = %v", err)
+	}
+	if id, exists, _ := stored.LookupID(ctx, "a.md"); !exists || id != 0 {
+		t.Errorf("LookupID(a.md) = %d, %v, want 0", id, exists)
+	}
+	if results, _ := stored.Search(ctx, "habeas", 10); len(results) != 0 {
+		t.Error("replaced content is still searchable")
+	}
+
+	if err := stored.RemoveDocument(ctx, 1); err != nil {
+		t.Fatalf("RemoveDocument() error = %v", err)
+	}
+	if err := stored.RemoveDocument(ctx, 1); err == nil {
+		t.Error("removing a removed document should fail")
+	}
+
+	// a new corpus over the same storage sees the index
+	reopened := NewStoredCorpus(storage)
+	if n, _ := reopened.Len(ctx); n != 7 {
+		t.Errorf("Len() = %d, want 7", n)
+	}
+	results, err := reopened.Search(ctx, "petition", 10)
+	if err != nil || len(results) != 1 || results[0].ExternalID != "a.md" {
+		t.Fatalf("Search(petition) = %v, %v, want a.md", results, err)
+	}
+	doc, exists, err := reopened.Document(ctx, 2)
+	if err != nil || !exists || doc.Fields[FieldBody] != "court calendar" {
+		t.Errorf("Document(2) = %v, %v, %v", doc, exists, err)
+	}
+}
+
+// failingStorage fails writes of postings for one term
+type failingStorage struct {
+	*MemoryStorage
+	term string
+}
+
+func (f failingStorage) Update(ctx context.Context, fn func(tx StorageTx) error) error {
+	return f.MemoryStorage.Update(ctx, func(tx StorageTx) error {
+		return fn(failingTx{StorageTx: tx, term: f.term})
+	})
+}
+
+type failingTx struct {
+	StorageTx
+	term string
+}
+
+func (tx failingTx) PutPosting(term string, id int, freqs map[Field]int) error {
+	if term == tx.term {
+		return errors.New("disk full")
+	}
+	return tx.StorageTx.PutPosting(term, id, freqs)
+}
+
+func TestStoredCorpus_AtomicWrites(t *testing.T) {
+	ctx := context.Background()
+	storage := NewMemoryStorage()
+	stored := NewStoredCorpus(failingStorage{MemoryStorage: storage, term: "broken"})
+
+	docs := []Document{{Fields: map[Field]string{FieldBody: "habeas corpus"}}}
+	for i := 0; i < 5; i++ {
+		docs = append(docs, Document{Fields: map[Field]string{FieldBody: "filler text"}})
+	}
+	if err := stored.AddDocuments(ctx, docs); err != nil {
+		t.Fatalf("AddDocuments() error = %v", err)
+	}
+	err := stored.AddDocuments(ctx, []Document{
+		{Fields: map[Field]string{FieldBody: "habeas petition"}},
+		{Fields: map[Field]string{FieldBody: "broken appeal"}},
+	})
+	if err == nil {
+		t.Fatal("AddDocuments() should fail when a write fails")
+	}
+
+	// the failed batch left no trace
+	if n, _ := stored.Len(ctx); n != 6 {
+		t.Errorf("Len() = %d, want 6", n)
+	}
+	if results, _ := stored.Search(ctx, "petition", 10); len(results) != 0 {
+		t.Error("a document from the failed batch was indexed")
+	}
+	if results, _ := stored.Search(ctx, "habeas", 10); len(results) != 1 {
+		t.Errorf("Search(habeas) returned %d results, want 1", len(results))
+	}
+}
+
+// countingStorage counts the documents read by View transactions
+type countingStorage struct {
+	*MemoryStorage
+	reads *int
+}
+
+func (c countingStorage) View(ctx context.Context, fn func(tx StorageTx) error) error {
+	return c.MemoryStorage.View(ctx, func(tx StorageTx) error {
+		return fn(countingTx{StorageTx: tx, reads: c.reads})
+	})
+}
+
+type countingTx struct {
+	StorageTx
+	reads *int
+}
+
+func (tx countingTx) Document(id int) ([]byte, error) {
+	*tx.reads++
+	return tx.StorageTx.Document(id)
+}
+
+func TestStoredCorpus_ReadsResultsOnly(t *testing.T) {
+	ctx := context.Background()
+	var reads int
+	stored := NewStoredCorpus(countingStorage{MemoryStorage: NewMemoryStorage(), reads: &reads})
+	var docs []Document
+	for i := range 50 {
+		body := fmt.Sprintf("filing %d", i)
+		if i%5 == 0 {
+			body += " habeas petition"
+		}
+		docs = append(docs, Document{Fields: map[Field]string{FieldBody: body}, Metadata: map[string]any{"n": i}})
+	}
+	if err := stored.AddDocuments(ctx, docs); err != nil {
+		t.Fatalf("AddDocuments() error = %v", err)
+	}
+
+	// candidates are filtered and scored from their info; only results are read whole
+	results, err := stored.SearchWithOptions(ctx, "habeas", SearchOptions{Limit: 3, Ranges: []Range{{Key: "n", Min: 10}}})
+	if err != nil || len(results) != 3 {
+		t.Fatalf("SearchWithOptions() = %v, %v, want 3 results", results, err)
+	}
+	for _, result := range results {
+		if n := result.Document.Metadata["n"].(int); n < 10 || result.Document.Fields[FieldBody] == "" {
+			t.Errorf("result = %+v, want a whole document with n >= 10", result.Document)
+		}
+	}
+	if reads != 3 {
+		t.Errorf("search read %d documents, want 3", reads)
+	}
+}
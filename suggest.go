@@ -0,0 +1,160 @@
+package bm25md
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Suggestion is a corrected query proposed by Suggest
+type Suggestion struct {
+	Query string  // the query with misspelled words replaced by index terms
+	Score float64 // higher for more common replacements needing fewer edits
+}
+
+// suggestCandidates is how many replacements are considered per misspelled word
+const suggestCandidates = 3
+
+// suggestEditPenalty is the score cost of each edit, in units of log document
+// frequency, so a common term two edits away rarely beats a rarer one at one edit
+const suggestEditPenalty = 3.0
+
+// Suggest proposes up to n corrected versions of a query ("did you mean"), best
+// first, typically shown when a search returns few or no results. Each word
+// missing from the index is replaced by index terms within one edit (two for
+// words of five or more characters), counting transpositions as one edit and
+// preferring terms found in more documents; query syntax such as +, -, quotes,
+// and operators is kept. Words found in the index only as edge n-grams or
+// within shingles count as missing. It returns nil when every word is in the index
+func (c *Corpus) Suggest(query string, n int) []Suggestion {
+	if n <= 0 {
+		return nil
+	}
+	words := strings.Fields(query)
+	options := make([][]Suggestion, len(words)) // replacements of each word, scored
+	corrected := false
+	for i, word := range words {
+		options[i] = []Suggestion{{Query: word}}
+		lead, core, trail := splitQueryWord(word)
+		tokens := c.tokenize(core)
+		if len(tokens) != 1 || isQueryOperator(core) || strings.HasPrefix(core, "/") {
+			continue
+		}
+		if docFreq := c.wordDocFreq(tokens[0]); docFreq > 0 {
+			options[i][0].Score = math.Log1p(float64(docFreq))
+			continue
+		}
+		candidates := c.spellingCandidates(tokens[0])
+		if len(candidates) == 0 {
+			continue
+		}
+		corrected = true
+		options[i] = options[i][:0]
+		for _, candidate := range candidates {
+			options[i] = append(options[i], Suggestion{Query: lead + candidate.Query + trail, Score: candidate.Score})
+		}
+	}
+	if !corrected {
+		return nil
+	}
+
+	// beam search over word replacements, keeping the n best partial queries
+	beam := []Suggestion{{}}
+	for i, replacements := range options {
+		next := make([]Suggestion, 0, len(beam)*len(replacements))
+		for _, partial := range beam {
+			for _, replacement := range replacements {
+				text := replacement.Query
+				if i > 0 {
+					text = partial.Query + " " + text
+				}
+				next = append(next, Suggestion{Query: text, Score: partial.Score + replacement.Score})
+			}
+		}
+		sort.SliceStable(next, func(a, b int) bool { return next[a].Score > next[b].Score })
+		beam = next[:min(len(next), n)]
+	}
+	return beam
+}
+
+// spellingCandidates returns the index terms closest to a misspelled term,
+// scored by document frequency less a penalty per edit. Shingles and terms
+// indexed only as edge n-grams are not words, so they are never proposed
+func (c *Corpus) spellingCandidates(term string) []Suggestion {
+	maxEdits := 1
+	length := utf8.RuneCountInString(term)
+	if length >= 5 {
+		maxEdits = 2
+	}
+
+	var candidates []Suggestion
+	source := []rune(term)
+	for _, indexed := range c.sortedTerms() {
+		if strings.Contains(indexed, ShingleSeparator) {
+			continue
+		}
+		if diff := utf8.RuneCountInString(indexed) - length; diff > maxEdits || -diff > maxEdits {
+			continue
+		}
+		edits := editDistance(source, []rune(indexed), maxEdits)
+		if edits > maxEdits {
+			continue
+		}
+		docFreq := c.wordDocFreq(indexed)
+		if docFreq == 0 {
+			continue
+		}
+		score := math.Log1p(float64(docFreq)) - suggestEditPenalty*float64(edits)
+		candidates = append(candidates, Suggestion{Query: indexed, Score: score})
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].Score > candidates[j].Score })
+	return candidates[:min(len(candidates), suggestCandidates)]
+}
+
+// editDistance returns the optimal string alignment distance between a and b
+// (insertions, deletions, substitutions, and adjacent transpositions), or
+// limit+1 once it must exceed limit
+func editDistance(a, b []rune, limit int) int {
+	// rows of the dynamic programming table: two back, previous, and current
+	prev2 := make([]int, len(b)+1)
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		rowMin := curr[0]
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				curr[j] = min(curr[j], prev2[j-2]+1)
+			}
+			rowMin = min(rowMin, curr[j])
+		}
+		if rowMin > limit {
+			return limit + 1
+		}
+		prev2, prev, curr = prev, curr, prev2
+	}
+	return prev[len(b)]
+}
+
+// splitQueryWord separates a query word's syntax (+, -, quotes, and a trailing
+// *) from the word itself
+func splitQueryWord(word string) (lead, core, trail string) {
+	core = strings.TrimLeft(word, `+-"`)
+	lead = word[:len(word)-len(core)]
+	trimmed := strings.TrimRight(core, `"*`)
+	return lead, trimmed, core[len(trimmed):]
+}
+
+// isQueryOperator reports whether a query word is an operator (AND, OR, NOT, NEAR)
+func isQueryOperator(word string) bool {
+	return word == "AND" || word == "OR" || word == "NOT" || nearRegex.MatchString(word)
+}
@@ -0,0 +1,106 @@
+package bm25md
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		limit    int
+		expected int
+	}{
+		{a: "habeas", b: "habeas", limit: 2, expected: 0},
+		{a: "habaes", b: "habeas", limit: 2, expected: 1},
+		{a: "corpsu", b: "corpus", limit: 2, expected: 1},
+		{a: "petiton", b: "petition", limit: 2, expected: 1},
+		{a: "kitten", b: "sitting", limit: 3, expected: 3},
+		{a: "kitten", b: "sitting", limit: 1, expected: 2},
+		{a: "", b: "writ", limit: 5, expected: 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+"/"+tt.b, func(t *testing.T) {
+			if got := editDistance([]rune(tt.a), []rune(tt.b), tt.limit); got != tt.expected {
+				t.Errorf("editDistance(%q, %q, %d) = %d, want %d", tt.a, tt.b, tt.limit, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCorpus_Suggest(t *testing.T) {
+	corpus := NewCorpus()
+	bodies := []string{
+		"habeas corpus petition",
+		"habeas corpus review",
+		"petition for review",
+		"corpse found at the scene",
+	}
+	for _, body := range bodies {
+		corpus.AddDocument(Document{Fields: map[Field]string{FieldBody: body}})
+	}
+	for i := range 5 {
+		corpus.AddDocument(Document{Fields: map[Field]string{FieldBody: fmt.Sprintf("filler document %d", i)}})
+	}
+
+	tests := []struct {
+		query    string
+		expected string
+	}{
+		{query: "habaes corpus", expected: "habeas corpus"},
+		{query: "+habeas corpsu", expected: "+habeas corpus"},
+		{query: `"habeas corpos" -petiton`, expected: `"habeas corpus" -petition`},
+		{query: "revew*", expected: "review*"},
+		{query: "habeas NEAR/2 corpsu", expected: "habeas NEAR/2 corpus"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			suggestions := corpus.Suggest(tt.query, 3)
+			if len(suggestions) == 0 || suggestions[0].Query != tt.expected {
+				t.Fatalf("Suggest(%q) = %+v, want %q first", tt.query, suggestions, tt.expected)
+			}
+			for i := 1; i < len(suggestions); i++ {
+				if suggestions[i].Score > suggestions[i-1].Score {
+					t.Errorf("suggestions not sorted by score: %+v", suggestions)
+				}
+			}
+		})
+	}
+
+	if got := corpus.Suggest("habeas corpus", 3); got != nil {
+		t.Errorf("Suggest() of known words = %+v, want nil", got)
+	}
+	if got := corpus.Suggest("zzzzzzzz", 3); got != nil {
+		t.Errorf("Suggest() with no close terms = %+v, want nil", got)
+	}
+}
+
+func TestCorpus_Suggest_EdgeNGrams(t *testing.T) {
+	corpus := NewCorpus(WithEdgeNGrams(2, 6), WithShingles(2, 2))
+	for _, body := range []string{
+		"habeas corpus petition",
+		"habeas corpus review",
+		"the constitution of the state",
+		"the consul of the state",
+	} {
+		corpus.AddDocument(Document{Fields: map[Field]string{FieldBody: body}})
+	}
+
+	// n-grams such as "hab" and "habe" are indexed but are not words
+	suggestions := corpus.Suggest("habas", 3)
+	if len(suggestions) == 0 || suggestions[0].Query != "habeas" {
+		t.Fatalf("Suggest(habas) = %+v, want habeas first", suggestions)
+	}
+	for _, suggestion := range suggestions {
+		if suggestion.Query != "habeas" {
+			t.Errorf("Suggest(habas) proposed a non-word: %+v", suggestion)
+		}
+	}
+
+	// an n-gram is not a known word, so it is corrected
+	suggestions = corpus.Suggest("consti", 3)
+	if len(suggestions) != 1 || suggestions[0].Query != "consul" {
+		t.Errorf("Suggest(consti) = %+v, want consul", suggestions)
+	}
+}
@@ -0,0 +1,102 @@
+package bm25md
+
+import (
+	"container/heap"
+	"sort"
+)
+
+// topResults collects the k best results with a bounded min-heap, so selecting
+// the top of n matches costs O(n log k) instead of sorting every match
+type topResults struct {
+	k          int // results to keep (0 = keep all)
+	minScore   float64
+	hits       int // results scored above minScore, including those not kept
+	scored     int // results offered, including those scoring too low
+	candidates int // documents matching the query before filters (counted by the caller)
+	results    resultHeap
+	collector  Collector // receives every result instead, keeping none (nil = keep the top k)
+}
+
+// newTopResults creates a collector keeping the k best results scoring at least minScore
+func newTopResults(k int, minScore float64) *topResults {
+	capacity := k
+	if capacity <= 0 || capacity > 64 {
+		capacity = 64
+	}
+	return &topResults{k: k, minScore: minScore, results: make(resultHeap, 0, capacity)}
+}
+
+// push offers a result, keeping it only if it ranks among the best k so far
+func (t *topResults) push(result SearchResult) {
+	t.scored++
+	if result.Score <= 0 || result.Score < t.minScore {
+		return
+	}
+	t.hits++
+	if t.collector != nil {
+		t.collector.Collect(result.Index, result.Score)
+		return
+	}
+
+	switch {
+	case t.k <= 0:
+		t.results = append(t.results, result)
+	case len(t.results) < t.k:
+		heap.Push(&t.results, result)
+	case ranksBefore(result, t.results[0]):
+		t.results[0] = result
+		heap.Fix(&t.results, 0)
+	}
+}
+
+// prunable reports whether a document scoring at most bound could not be kept:
+// it would score zero, fall below the minimum, or rank after every kept result
+// (later documents lose ties)
+func (t *topResults) prunable(bound float64) bool {
+	bound *= 1 + scoreSlack
+	if bound <= 0 || bound < t.minScore {
+		return true
+	}
+	return t.k > 0 && len(t.results) == t.k && bound <= t.results[0].Score
+}
+
+// merge adds every result kept by other (used to combine per-worker collectors)
+func (t *topResults) merge(other *topResults) {
+	hits, scored := t.hits+other.hits, t.scored+other.scored
+	for _, result := range other.results {
+		t.push(result)
+	}
+	t.hits, t.scored = hits, scored
+	t.candidates += other.candidates
+}
+
+// sorted returns the kept results, best first
+func (t *topResults) sorted() []SearchResult {
+	results := []SearchResult(t.results)
+	sort.Slice(results, func(i, j int) bool {
+		return ranksBefore(results[i], results[j])
+	})
+	return results
+}
+
+// ranksBefore orders results by score, breaking ties by index for stable output
+func ranksBefore(a, b SearchResult) bool {
+	if a.Score != b.Score {
+		return a.Score > b.Score
+	}
+	return a.Index < b.Index
+}
+
+// resultHeap is a min-heap of results with the worst-ranked result on top
+type resultHeap []SearchResult
+
+func (h resultHeap) Len() int           { return len(h) }
+func (h resultHeap) Less(i, j int) bool { return ranksBefore(h[j], h[i]) }
+func (h resultHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *resultHeap) Push(x any)        { *h = append(*h, x.(SearchResult)) }
+func (h *resultHeap) Pop() any {
+	old := *h
+	result := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return result
+}
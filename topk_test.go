@@ -0,0 +1,57 @@
+package bm25md
+
+import (
+	"math/rand"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestTopResults(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	all := make([]SearchResult, 500)
+	for i := range all {
+		// coarse scores produce ties, which must break by index
+		all[i] = SearchResult{Index: i, Score: float64(rng.Intn(50))}
+	}
+
+	expected := make([]SearchResult, 0, len(all))
+	for _, result := range all {
+		if result.Score > 0 {
+			expected = append(expected, result)
+		}
+	}
+	sort.Slice(expected, func(i, j int) bool { return ranksBefore(expected[i], expected[j]) })
+
+	for _, k := range []int{0, 1, 3, 10, 499, 1000} {
+		top := newTopResults(k, 0)
+		for _, result := range all {
+			top.push(result)
+		}
+
+		want := expected
+		if k > 0 && k < len(want) {
+			want = want[:k]
+		}
+		if got := top.sorted(); !reflect.DeepEqual(got, want) {
+			t.Errorf("k=%d: sorted() differs from a full sort", k)
+		}
+		if top.hits != len(expected) {
+			t.Errorf("k=%d: hits = %d, want %d", k, top.hits, len(expected))
+		}
+	}
+
+	// merged collectors match a single collector
+	single, merged := newTopResults(5, 10), newTopResults(5, 10)
+	parts := []*topResults{newTopResults(5, 10), newTopResults(5, 10)}
+	for i, result := range all {
+		single.push(result)
+		parts[i%2].push(result)
+	}
+	for _, part := range parts {
+		merged.merge(part)
+	}
+	if !reflect.DeepEqual(merged.sorted(), single.sorted()) || merged.hits != single.hits {
+		t.Errorf("merged = %v (%d hits), want %v (%d hits)", merged.sorted(), merged.hits, single.sorted(), single.hits)
+	}
+}
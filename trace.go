@@ -0,0 +1,43 @@
+package bm25md
+
+import (
+	"context"
+	"time"
+)
+
+// Tracer starts spans around searches and indexing, for distributed tracing.
+// The oteltrace package adapts an OpenTelemetry tracer
+type Tracer interface {
+	// Start begins a span named name at start, returning a context carrying it
+	Start(ctx context.Context, name string, start time.Time) (context.Context, Span)
+}
+
+// Span is an operation traced by a Tracer
+type Span interface {
+	SetAttribute(key string, value any) // value is a string, bool, int, or float64
+	End()
+}
+
+// WithTracer traces searches and document additions with tracer. A search
+// gets a bm25md.Search span with bm25md.tokenize, bm25md.score, and bm25md.sort
+// children, carrying attributes such as the query term count and hits; searches
+// taking a context (eg SearchContext) join the caller's trace
+func WithTracer(tracer Tracer) CorpusOption {
+	return func(c *Corpus) {
+		c.tracer = tracer
+	}
+}
+
+// startSpan begins a span with the corpus tracer, or a no-op span without one
+func (c *Corpus) startSpan(ctx context.Context, name string, start time.Time) (context.Context, Span) {
+	if c.tracer == nil {
+		return ctx, noopSpan{}
+	}
+	return c.tracer.Start(ctx, name, start)
+}
+
+// noopSpan is the span of an untraced corpus
+type noopSpan struct{}
+
+func (noopSpan) SetAttribute(string, any) {}
+func (noopSpan) End()                     {}
@@ -0,0 +1,124 @@
+package bm25md
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordingTracer records the spans it starts, with their parents
+type recordingTracer struct {
+	mu    sync.Mutex
+	spans []*recordedSpan
+}
+
+type recordedSpan struct {
+	name       string
+	parent     *recordedSpan
+	attributes map[string]any
+	ended      bool
+}
+
+type spanKey struct{}
+
+func (t *recordingTracer) Start(ctx context.Context, name string, _ time.Time) (context.Context, Span) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	parent, _ := ctx.Value(spanKey{}).(*recordedSpan)
+	span := &recordedSpan{name: name, parent: parent, attributes: make(map[string]any)}
+	t.spans = append(t.spans, span)
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+func (s *recordedSpan) SetAttribute(key string, value any) { s.attributes[key] = value }
+func (s *recordedSpan) End()                               { s.ended = true }
+
+// span returns the last recorded span named name
+func (t *recordingTracer) span(name string) *recordedSpan {
+	for _, span := range slices.Backward(t.spans) {
+		if span.name == name {
+			return span
+		}
+	}
+	return nil
+}
+
+func TestWithTracer(t *testing.T) {
+	tests := []struct {
+		name   string
+		search func(c *Corpus) error
+		score  string // attribute only the engine's score span carries
+	}{
+		{
+			name: "corpus",
+			search: func(c *Corpus) error {
+				_, err := c.SearchContext(context.Background(), "habeas corpus", 10)
+				return err
+			},
+			score: "bm25md.parallel",
+		},
+		{
+			name: "index",
+			search: func(c *Corpus) error {
+				_, err := c.Freeze().SearchContext(context.Background(), "habeas corpus", 10)
+				return err
+			},
+			score: "bm25md.wand",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracer := &recordingTracer{}
+			corpus := randomCorpus(50, WithTracer(tracer))
+			if err := tt.search(corpus); err != nil {
+				t.Fatal(err)
+			}
+
+			search := tracer.span("bm25md.Search")
+			if search == nil {
+				t.Fatal("no search span")
+			}
+			if _, ok := search.attributes["bm25md.hits"]; !ok {
+				t.Errorf("search span attributes = %v, want hits", search.attributes)
+			}
+			for _, name := range []string{"bm25md.tokenize", "bm25md.score", "bm25md.sort"} {
+				span := tracer.span(name)
+				if span == nil || span.parent != search {
+					t.Errorf("%s span is not a child of the search span", name)
+				}
+			}
+			if got := tracer.span("bm25md.tokenize").attributes["bm25md.query_terms"]; got != 2 {
+				t.Errorf("query terms = %v, want 2", got)
+			}
+			if _, ok := tracer.span("bm25md.score").attributes[tt.score]; !ok {
+				t.Errorf("score span attributes = %v, want %s", tracer.span("bm25md.score").attributes, tt.score)
+			}
+			for _, span := range tracer.spans {
+				if !span.ended {
+					t.Errorf("%s span was not ended", span.name)
+				}
+			}
+		})
+	}
+}
+
+func TestWithTracerAddDocument(t *testing.T) {
+	tracer := &recordingTracer{}
+	corpus := NewCorpus(WithTracer(tracer))
+	corpus.AddDocument(Document{Fields: map[Field]string{FieldBody: "habeas corpus", FieldTitle: "writs"}})
+	corpus.AddDocuments([]Document{{Fields: map[Field]string{FieldBody: "jury trial"}}})
+
+	if got := tracer.span("bm25md.AddDocument").attributes["bm25md.fields"]; got != 2 {
+		t.Errorf("fields = %v, want 2", got)
+	}
+	batch := tracer.span("bm25md.AddDocuments")
+	if got := batch.attributes["bm25md.documents"]; got != 1 {
+		t.Errorf("documents = %v, want 1", got)
+	}
+	if analyze := tracer.span("bm25md.analyze"); analyze == nil || analyze.parent != batch {
+		t.Error("analyze span is not a child of the batch span")
+	}
+}
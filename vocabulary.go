@@ -0,0 +1,130 @@
+package bm25md
+
+import (
+	"encoding/binary"
+	"slices"
+	"strings"
+)
+
+// vocabulary interns index terms as uint32 IDs shared by every field, so each
+// distinct term string is stored once however many documents contain it. IDs are
+// assigned in order of first appearance and never reused
+type vocabulary struct {
+	ids   map[string]uint32
+	terms []string // term for each ID
+}
+
+// newVocabulary creates an empty vocabulary
+func newVocabulary() *vocabulary {
+	return &vocabulary{ids: make(map[string]uint32)}
+}
+
+// intern returns the ID of a term, assigning a new one if the term is unseen
+func (v *vocabulary) intern(term string) uint32 {
+	if id, exists := v.ids[term]; exists {
+		return id
+	}
+	id := uint32(len(v.terms))
+	// clone so the canonical copy does not pin the text it was tokenized from
+	term = strings.Clone(term)
+	v.ids[term] = id
+	v.terms = append(v.terms, term)
+	return id
+}
+
+// lookup returns the ID of a term, if it has been interned
+func (v *vocabulary) lookup(term string) (uint32, bool) {
+	id, exists := v.ids[term]
+	return id, exists
+}
+
+// term returns the term with an ID
+func (v *vocabulary) term(id uint32) string {
+	return v.terms[id]
+}
+
+// termVector holds a document field's term frequencies as varint-encoded
+// (term ID delta, frequency) pairs in ascending term ID order
+type termVector []byte
+
+// encodeTermVector interns the terms of a frequency map and encodes them
+func encodeTermVector(vocab *vocabulary, tf map[string]int) termVector {
+	if len(tf) == 0 {
+		return nil
+	}
+	ids := make([]uint32, 0, len(tf))
+	freqs := make(map[uint32]int, len(tf))
+	for term, freq := range tf {
+		id := vocab.intern(term)
+		ids = append(ids, id)
+		freqs[id] = freq
+	}
+	slices.Sort(ids)
+
+	vector := make(termVector, 0, 3*len(ids))
+	previous := uint32(0)
+	for _, id := range ids {
+		vector = binary.AppendUvarint(vector, uint64(id-previous))
+		vector = binary.AppendUvarint(vector, uint64(freqs[id]))
+		previous = id
+	}
+	return vector
+}
+
+// each calls fn for every term ID and frequency in ascending ID order
+func (v termVector) each(fn func(id uint32, tf int)) {
+	id := uint32(0)
+	for len(v) > 0 {
+		delta, n := binary.Uvarint(v)
+		tf, m := binary.Uvarint(v[n:])
+		v = v[n+m:]
+		id += uint32(delta)
+		fn(id, int(tf))
+	}
+}
+
+// frequency returns the frequency of a term ID, stopping once IDs pass it
+func (v termVector) frequency(target uint32) int {
+	id := uint32(0)
+	for len(v) > 0 {
+		delta, n := binary.Uvarint(v)
+		tf, m := binary.Uvarint(v[n:])
+		v = v[n+m:]
+		id += uint32(delta)
+		switch {
+		case id == target:
+			return int(tf)
+		case id > target:
+			return 0
+		}
+	}
+	return 0
+}
+
+// eachTerm calls fn for every term of a document in this field with its frequency
+func (f *fieldBM25) eachTerm(docIndex int, fn func(term string, tf int)) {
+	if docIndex < 0 || docIndex >= len(f.termFrequencies) {
+		return
+	}
+	f.termFrequencies[docIndex].each(func(id uint32, tf int) {
+		fn(f.vocab.term(id), tf)
+	})
+}
+
+// termFrequency returns how often a term occurs in a document in this field
+func (f *fieldBM25) termFrequency(docIndex int, term string) int {
+	id, exists := f.vocab.lookup(term)
+	if !exists || docIndex < 0 || docIndex >= len(f.termFrequencies) {
+		return 0
+	}
+	return f.termFrequencies[docIndex].frequency(id)
+}
+
+// termMap decodes a document's terms into a frequency map (for persistence)
+func (f *fieldBM25) termMap(docIndex int) map[string]int {
+	tf := make(map[string]int)
+	f.eachTerm(docIndex, func(term string, freq int) {
+		tf[term] = freq
+	})
+	return tf
+}
@@ -0,0 +1,81 @@
+package bm25md
+
+import (
+	"reflect"
+	"testing"
+	"unsafe"
+)
+
+func TestTermVector(t *testing.T) {
+	tests := []struct {
+		name string
+		tf   map[string]int
+	}{
+		{"empty", map[string]int{}},
+		{"single", map[string]int{"habeas": 1}},
+		{"many", map[string]int{"habeas": 3, "corpus": 1, "writ": 200, "court": 70000}},
+	}
+
+	vocab := newVocabulary()
+	vocab.intern("corpus") // an earlier ID, so vectors are not in insertion order
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vector := encodeTermVector(vocab, tt.tf)
+
+			got := make(map[string]int)
+			last := -1
+			vector.each(func(id uint32, tf int) {
+				if int(id) <= last {
+					t.Errorf("term IDs out of order: %d after %d", id, last)
+				}
+				last = int(id)
+				got[vocab.term(id)] = tf
+			})
+			if !reflect.DeepEqual(got, tt.tf) {
+				t.Errorf("decoded %v, want %v", got, tt.tf)
+			}
+			for term, want := range tt.tf {
+				id, _ := vocab.lookup(term)
+				if got := vector.frequency(id); got != want {
+					t.Errorf("frequency(%q) = %d, want %d", term, got, want)
+				}
+			}
+			if id, exists := vocab.lookup("unindexed"); exists || vector.frequency(id+1000) != 0 {
+				t.Error("missing terms should have zero frequency")
+			}
+		})
+	}
+}
+
+func TestCorpus_InternsTerms(t *testing.T) {
+	corpus := NewCorpus()
+	corpus.AddDocument(Document{Fields: map[Field]string{FieldH1: "Habeas corpus", FieldBody: "the writ of habeas corpus"}})
+	corpus.AddDocument(Document{Fields: map[Field]string{FieldBody: "habeas petitions"}})
+
+	// every field and document shares the vocabulary's copy of a term
+	var data *byte
+	for _, field := range []Field{FieldH1, FieldBody} {
+		scorer := corpus.fieldScorers[field]
+		for doc := range corpus.documents {
+			for term := range scorer.positions[doc] {
+				if term != "habeas" {
+					continue
+				}
+				if data != nil && unsafe.StringData(term) != data {
+					t.Errorf("field %s document %d holds its own copy of %q", field, doc, term)
+				}
+				data = unsafe.StringData(term)
+			}
+		}
+	}
+	if data == nil {
+		t.Fatal("habeas was not indexed")
+	}
+
+	if got := corpus.fieldScorers[FieldBody].termFrequency(0, "habeas"); got != 1 {
+		t.Errorf("termFrequency(0, habeas) = %d, want 1", got)
+	}
+	if got := corpus.fieldScorers[FieldH1].termFrequency(1, "habeas"); got != 0 {
+		t.Errorf("termFrequency(1, habeas) in h1 = %d, want 0", got)
+	}
+}
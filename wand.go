@@ -0,0 +1,150 @@
+package bm25md
+
+import (
+	"context"
+	"sort"
+)
+
+// impactBlockSize is the number of postings summarized by each block maximum
+const impactBlockSize = 64
+
+// scoreSlack absorbs rounding differences between score bounds and actual scores
+const scoreSlack = 1e-9
+
+// computeImpacts records, for each block of a term's postings, the highest score
+// any of its documents can get from the term (including document boosts), so
+// Block-Max WAND can skip blocks that cannot reach the top results
+func (ix *Index) computeImpacts(p *indexPostings, term string) {
+	scorer := ix.config.similarity.TermScorer(ix.termStats(term, *p), ix.config.params)
+	blocks := (len(p.docs) + impactBlockSize - 1) / impactBlockSize
+	p.blockMax = make([]float64, blocks)
+	p.blockLast = make([]uint32, blocks)
+
+	var matches []FieldMatch
+	for i, doc := range p.docs {
+		matches = ix.fieldMatches(matches[:0], p, i, int(doc))
+		impact := scorer(matches) * ix.config.documents[doc].boost() * ix.config.feedbackPrior(int(doc))
+		block := i / impactBlockSize
+		// negative contributions only lower a score, so zero is still an upper bound
+		p.blockMax[block] = max(p.blockMax[block], impact)
+		p.blockLast[block] = doc
+		p.maxImpact = max(p.maxImpact, impact)
+	}
+}
+
+// blockBound returns the block maximum of the block that would hold doc, searching
+// forward from the cursor's block, and the last document of that block
+func (p *indexPostings) blockBound(cursor int, doc uint32) (float64, uint32) {
+	for block := cursor / impactBlockSize; block < len(p.blockLast); block++ {
+		if p.blockLast[block] >= doc {
+			return p.blockMax[block], p.blockLast[block]
+		}
+	}
+	return 0, ^uint32(0)
+}
+
+// canUseWAND reports whether a query can skip documents by score bounds: only
+// optional dictionary terms (whose impacts are precomputed) with a result limit
+// and no time-dependent recency decay qualify
+func (ix *Index) canUseWAND(terms []indexTerm, k int) bool {
+	if k <= 0 || ix.config.decay != nil || len(terms) == 0 {
+		return false
+	}
+	for _, t := range terms {
+		if t.occur != occurShould || t.postings.blockMax == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// collectWAND collects the top results with Block-Max WAND: cursors are kept in
+// document order, a pivot is chosen where the summed term maxima could beat the
+// current top-k threshold, and whole blocks are skipped when their block maxima
+// cannot. Skipped documents are not counted in TotalHits
+func (ix *Index) collectWAND(ctx context.Context, terms []indexTerm, opts SearchOptions, top *topResults) {
+	cursors := make([]*indexTerm, 0, len(terms))
+	for i := range terms {
+		if len(terms[i].postings.docs) > 0 {
+			cursors = append(cursors, &terms[i])
+		}
+	}
+	doc := func(t *indexTerm) uint32 { return t.postings.docs[t.cursor] }
+
+	var matches []FieldMatch
+	for iteration := 0; ; iteration++ {
+		if iteration%cancelCheckInterval == 0 && ctx.Err() != nil {
+			return
+		}
+
+		// drop exhausted cursors and order the rest by document
+		live := cursors[:0]
+		for _, t := range cursors {
+			if t.cursor < len(t.postings.docs) {
+				live = append(live, t)
+			}
+		}
+		cursors = live
+		sort.Slice(cursors, func(i, j int) bool { return doc(cursors[i]) < doc(cursors[j]) })
+
+		// the pivot is the first cursor where the summed maxima could enter the results
+		pivot, bound := -1, 0.0
+		for i, t := range cursors {
+			bound += t.postings.maxImpact
+			if !top.prunable(bound) {
+				pivot = i
+				break
+			}
+		}
+		if pivot < 0 {
+			return
+		}
+		pivotDoc := doc(cursors[pivot])
+		for pivot+1 < len(cursors) && doc(cursors[pivot+1]) == pivotDoc {
+			pivot++
+		}
+
+		// check the tighter block maxima around the pivot document
+		blockSum, next := 0.0, ^uint32(0)
+		for _, t := range cursors[:pivot+1] {
+			blockMax, last := t.postings.blockBound(t.cursor, pivotDoc)
+			blockSum += blockMax
+			next = min(next, last)
+		}
+		if top.prunable(blockSum) {
+			// no document up to the end of the shortest block can enter the results
+			if next == ^uint32(0) {
+				return
+			}
+			next++
+			if pivot+1 < len(cursors) {
+				next = min(next, doc(cursors[pivot+1]))
+			}
+			for _, t := range cursors[:pivot+1] {
+				t.cursor = t.postings.seek(t.cursor, next)
+			}
+			continue
+		}
+
+		if doc(cursors[0]) != pivotDoc {
+			// move the cursors before the pivot up to it
+			for _, t := range cursors[:pivot] {
+				t.cursor = t.postings.seek(t.cursor, pivotDoc)
+			}
+			continue
+		}
+
+		// every cursor up to the pivot is on the pivot document: score it
+		score := 0.0
+		for _, t := range cursors[:pivot+1] {
+			matches = ix.fieldMatches(matches[:0], &t.postings, t.cursor, int(pivotDoc))
+			score += t.scorer(matches)
+			t.cursor++
+		}
+		top.candidates++
+		if opts.accepts(int(pivotDoc), ix.config.documents[pivotDoc]) {
+			c := ix.config
+			top.push(SearchResult{Score: score * c.documents[pivotDoc].boost() * c.feedbackPrior(int(pivotDoc)), Index: int(pivotDoc)})
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package bm25md
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+)
+
+// countingSimilarity wraps BM25F, counting how many document-term pairs it scores
+type countingSimilarity struct {
+	calls *atomic.Int64
+}
+
+func (s countingSimilarity) TermScorer(stats TermStats, params BM25Parameters) TermScorer {
+	scorer := BM25FSimilarity{}.TermScorer(stats, params)
+	return func(matches []FieldMatch) float64 {
+		s.calls.Add(1)
+		return scorer(matches)
+	}
+}
+
+func TestIndex_WANDMatchesExhaustive(t *testing.T) {
+	index := randomCorpus(3000).Freeze()
+	queries := []string{
+		"petition",
+		"custody detention",
+		"constitution constitutional statute review",
+		"court federal state appeal judge jury",
+		"deadline deadline filing",
+	}
+
+	for _, query := range queries {
+		for _, k := range []int{1, 5, 50} {
+			t.Run(fmt.Sprintf("%s/%d", query, k), func(t *testing.T) {
+				var terms []indexTerm
+				for _, clause := range index.config.parseQuery(query) {
+					postings, _ := index.lookup(clause.tokens[0])
+					terms = append(terms, indexTerm{
+						postings: postings,
+						scorer:   index.config.similarity.TermScorer(index.termStats(clause.tokens[0], postings), index.config.params),
+						occur:    clause.occur,
+					})
+				}
+				if !index.canUseWAND(terms, k) {
+					t.Fatal("canUseWAND() = false for optional terms")
+				}
+
+				exhaustive := newTopResults(k, 0)
+				index.collect(context.Background(), append([]indexTerm(nil), terms...), SearchOptions{}, exhaustive)
+				wand := newTopResults(k, 0)
+				index.collectWAND(context.Background(), terms, SearchOptions{}, wand)
+				sameResults(t, wand.sorted(), exhaustive.sorted())
+			})
+		}
+	}
+}
+
+func TestIndex_WANDSkipsDocuments(t *testing.T) {
+	calls := new(atomic.Int64)
+	index := randomCorpus(3000, WithSimilarity(countingSimilarity{calls: calls})).Freeze()
+	query := "court constitution"
+
+	calls.Store(0)
+	index.Search(query, 5)
+	pruned := calls.Load()
+
+	calls.Store(0)
+	index.SearchWithOptions(query, SearchOptions{}) // no limit, so every match is scored
+	full := calls.Load()
+
+	if pruned*2 > full {
+		t.Errorf("top-5 search scored %d document terms, want well under the %d of a full search", pruned, full)
+	}
+}
+
+func TestIndex_WANDEligibility(t *testing.T) {
+	index := randomCorpus(100).Freeze()
+	term := func(occur occur) indexTerm {
+		postings, _ := index.lookup("court")
+		return indexTerm{postings: postings, occur: occur}
+	}
+
+	tests := []struct {
+		name     string
+		terms    []indexTerm
+		k        int
+		expected bool
+	}{
+		{"optional terms", []indexTerm{term(occurShould), term(occurShould)}, 10, true},
+		{"no limit", []indexTerm{term(occurShould)}, 0, false},
+		{"required term", []indexTerm{term(occurShould), term(occurMust)}, 10, false},
+		{"excluded term", []indexTerm{term(occurShould), term(occurMustNot)}, 10, false},
+		{"computed postings", []indexTerm{{postings: index.preparePrefix("con"), occur: occurShould}}, 10, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := index.canUseWAND(tt.terms, tt.k); got != tt.expected {
+				t.Errorf("canUseWAND() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
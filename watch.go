@@ -0,0 +1,324 @@
+//go:build !bm25md_noparser
+
+package bm25md
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// WatchDebounce is how long a Watcher waits after a file changes before
+// reindexing it, so bursts of editor writes cause a single update
+var WatchDebounce = 100 * time.Millisecond
+
+// WatchCompactRatio is the share of a watched corpus's document slots held by
+// removed documents at which a Watcher compacts it (see Corpus.Compact): each
+// reindexed file leaves its previous documents behind as tombstones
+var WatchCompactRatio = 0.25
+
+// Watcher keeps a corpus in sync with a directory of markdown files, reindexing
+// files as they are created, modified, renamed, or deleted. Searches through the
+// watcher are safe during updates; the corpus must not be modified elsewhere.
+// The watcher compacts the corpus as removed documents accumulate, so document
+// IDs are not stable across updates (ExternalIDs are)
+type Watcher struct {
+	mu      sync.RWMutex
+	corpus  *Corpus
+	dir     string
+	fsys    fs.FS
+	pattern string
+	chunker *Chunker
+	fsw     *fsnotify.Watcher
+
+	pending  map[string]bool // relative paths waiting for the debounce timer
+	pmu      sync.Mutex
+	timer    *time.Timer
+	flushing bool // a flush is running; guarded by pmu
+	done     chan struct{}
+	wg       sync.WaitGroup
+}
+
+// WatchDir indexes dir like IndexDir, then watches it (including subdirectories
+// created later) and updates the corpus incrementally until Close
+func WatchDir(dir, pattern string, chunker *Chunker, opts ...CorpusOption) (*Watcher, error) {
+	if pattern == "" {
+		pattern = "*.md"
+	}
+	fsw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("bm25md: creating watcher: %w", err)
+	}
+	w := &Watcher{
+		dir:     dir,
+		fsys:    os.DirFS(dir),
+		pattern: pattern,
+		chunker: chunker,
+		fsw:     fsw,
+		pending: make(map[string]bool),
+		done:    make(chan struct{}),
+	}
+
+	// watch before the initial load, so files written meanwhile are not missed
+	if err := w.watchTree("."); err != nil {
+		fsw.Close()
+		return nil, err
+	}
+	w.corpus, err = IndexDir(w.fsys, pattern, chunker, opts...)
+	if err != nil {
+		fsw.Close()
+		return nil, err
+	}
+
+	w.wg.Add(1)
+	go w.run()
+	return w, nil
+}
+
+// Close stops watching, waiting for a reindex in progress to stop; the corpus
+// keeps its last state
+func (w *Watcher) Close() error {
+	w.pmu.Lock()
+	select {
+	case <-w.done:
+		w.pmu.Unlock()
+		return nil
+	default:
+	}
+	close(w.done)
+	if w.timer != nil {
+		w.timer.Stop()
+	}
+	w.pmu.Unlock()
+
+	err := w.fsw.Close()
+	w.wg.Wait()
+	return err
+}
+
+// Search searches the current corpus
+func (w *Watcher) Search(query string, limit int) []SearchResult {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.corpus.Search(query, limit)
+}
+
+// SearchWithOptions searches the current corpus with options
+func (w *Watcher) SearchWithOptions(query string, opts SearchOptions) []SearchResult {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.corpus.SearchWithOptions(query, opts)
+}
+
+// View runs fn with the corpus, blocking updates until it returns. fn must not
+// modify the corpus
+func (w *Watcher) View(fn func(corpus *Corpus)) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	fn(w.corpus)
+}
+
+// run receives file events until the watcher is closed
+func (w *Watcher) run() {
+	defer w.wg.Done()
+	for {
+		select {
+		case event, ok := <-w.fsw.Events:
+			if !ok {
+				return
+			}
+			if p, ok := w.relative(event.Name); ok {
+				w.schedule(p)
+			}
+		case err, ok := <-w.fsw.Errors:
+			if !ok {
+				return
+			}
+			w.corpus.logger().Warn("bm25md watcher error", "dir", w.dir, "error", err)
+		}
+	}
+}
+
+// relative converts an event path to a slash-separated path within the directory,
+// reporting false for paths in hidden directories
+func (w *Watcher) relative(name string) (string, bool) {
+	rel, err := filepath.Rel(w.dir, name)
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return "", false
+	}
+	rel = filepath.ToSlash(rel)
+	for _, part := range strings.Split(rel, "/") {
+		if strings.HasPrefix(part, ".") {
+			return "", false
+		}
+	}
+	return rel, true
+}
+
+// schedule queues a path to be reindexed once changes settle
+func (w *Watcher) schedule(p string) {
+	w.pmu.Lock()
+	defer w.pmu.Unlock()
+	w.pending[p] = true
+	if w.timer == nil {
+		w.timer = time.AfterFunc(WatchDebounce, w.flush)
+	} else {
+		w.timer.Reset(WatchDebounce)
+	}
+}
+
+// flush reindexes the queued paths, then compacts the corpus once enough
+// tombstones accumulate. It stops between paths when the watcher is closed.
+// Flushes run one at a time, so an older version of a file read by a slow flush
+// never replaces a newer one: when the timer fires during a flush, it is re-armed
+func (w *Watcher) flush() {
+	// joining wg under pmu, where Close closes done, keeps Close from waiting too early
+	w.pmu.Lock()
+	select {
+	case <-w.done:
+		w.pmu.Unlock()
+		return
+	default:
+	}
+	if w.flushing {
+		w.timer.Reset(WatchDebounce)
+		w.pmu.Unlock()
+		return
+	}
+	paths := w.pending
+	w.pending = make(map[string]bool)
+	w.flushing = true
+	w.wg.Add(1)
+	w.pmu.Unlock()
+	defer w.wg.Done()
+	defer func() {
+		w.pmu.Lock()
+		w.flushing = false
+		w.pmu.Unlock()
+	}()
+
+	for p := range paths {
+		select {
+		case <-w.done:
+			return
+		default:
+		}
+		if err := w.sync(p); err != nil {
+			w.corpus.logger().Warn("bm25md watcher failed to reindex", "path", p, "error", err)
+		}
+	}
+	w.compact()
+}
+
+// compact compacts the corpus once removed documents make up WatchCompactRatio
+// of its document slots
+func (w *Watcher) compact() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if deleted := len(w.corpus.deleted); deleted > 0 && float64(deleted) >= WatchCompactRatio*float64(len(w.corpus.documents)) {
+		w.corpus.Compact()
+	}
+}
+
+// sync brings the corpus up to date with a path: deleted files and directories are
+// removed, new directories are watched and indexed, and matching files are reloaded
+func (w *Watcher) sync(p string) error {
+	info, err := fs.Stat(w.fsys, p)
+	if errors.Is(err, fs.ErrNotExist) {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		w.remove(p)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if info.IsDir() {
+		return w.syncDir(p)
+	}
+
+	if !matchesPattern(w.pattern, p) {
+		return nil
+	}
+	docs, err := LoadFile(w.fsys, p, w.chunker)
+	if err != nil {
+		return err
+	}
+	w.corpus.logger().Debug("Reindexing watched file", "path", p, "documents", len(docs))
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.remove(p)
+	w.corpus.AddDocuments(docs)
+	return nil
+}
+
+// syncDir watches a new directory and indexes its matching files
+func (w *Watcher) syncDir(dir string) error {
+	if err := w.watchTree(dir); err != nil {
+		return err
+	}
+	var docs []Document
+	err := fs.WalkDir(w.fsys, dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if p != dir && strings.HasPrefix(d.Name(), ".") {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if !matchesPattern(w.pattern, p) {
+			return nil
+		}
+		fileDocs, err := LoadFile(w.fsys, p, w.chunker)
+		docs = append(docs, fileDocs...)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.remove(dir)
+	w.corpus.AddDocuments(docs)
+	return nil
+}
+
+// remove drops the documents of a file, or of every file under a directory
+func (w *Watcher) remove(p string) {
+	for _, doc := range w.corpus.Documents() {
+		path, _ := doc.Metadata[MetaFilePath].(string)
+		if path == p || strings.HasPrefix(path, p+"/") {
+			_ = w.corpus.RemoveDocument(doc.ID)
+		}
+	}
+}
+
+// watchTree watches a directory and its non-hidden subdirectories
+func (w *Watcher) watchTree(root string) error {
+	return fs.WalkDir(w.fsys, root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if p != "." && strings.HasPrefix(d.Name(), ".") {
+			return fs.SkipDir
+		}
+		if err := w.fsw.Add(filepath.Join(w.dir, filepath.FromSlash(p))); err != nil {
+			return fmt.Errorf("bm25md: watching %s: %w", p, err)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,246 @@
+//go:build !bm25md_noparser
+
+package bm25md
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWatchDir(t *testing.T) {
+	previous := WatchDebounce
+	WatchDebounce = 10 * time.Millisecond
+	defer func() { WatchDebounce = previous }()
+
+	dir := t.TempDir()
+	write := func(name, content string) {
+		t.Helper()
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("habeas.md", "# Habeas Corpus\n\nPetitions for release.")
+	write("calendar.md", "Court calendar.")
+	write("filing.md", "Filing deadlines.")
+	write("judges.md", "Judge assignments.")
+	write(".obsidian/hidden.md", "Hidden release notes.")
+
+	watcher, err := WatchDir(dir, "", NewChunker())
+	if err != nil {
+		t.Fatalf("WatchDir() error = %v", err)
+	}
+	defer watcher.Close()
+
+	// ids returns the file paths matching a query
+	ids := func(query string) []string {
+		var paths []string
+		for _, result := range watcher.Search(query, 10) {
+			paths = append(paths, result.Document.Metadata[MetaFilePath].(string))
+		}
+		return paths
+	}
+	eventually := func(description string, cond func() bool) {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for !cond() {
+			if time.Now().After(deadline) {
+				t.Fatalf("timed out waiting for %s", description)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+
+	if got := ids("release"); len(got) != 1 || got[0] != "habeas.md" {
+		t.Fatalf("initial Search(release) = %v, want habeas.md", got)
+	}
+
+	write("appeals.md", "# Appeals\n\nAppeal after release.")
+	eventually("created file", func() bool { return len(ids("appeal")) == 1 })
+
+	write("habeas.md", "# Habeas Corpus\n\nPetitions for custody review.")
+	eventually("modified file", func() bool { return len(ids("custody")) == 1 && len(ids("release")) == 1 })
+
+	if err := os.Remove(filepath.Join(dir, "appeals.md")); err != nil {
+		t.Fatal(err)
+	}
+	eventually("deleted file", func() bool { return len(ids("appeal")) == 0 })
+
+	write("circuits/ninth.md", "Ninth circuit rules.")
+	eventually("file in new directory", func() bool { return len(ids("ninth")) == 1 })
+
+	if err := os.RemoveAll(filepath.Join(dir, "circuits")); err != nil {
+		t.Fatal(err)
+	}
+	eventually("deleted directory", func() bool { return len(ids("ninth")) == 0 })
+
+	write(".obsidian/workspace.md", "Ninth hidden note.")
+	time.Sleep(5 * WatchDebounce)
+	if got := ids("ninth"); len(got) != 0 {
+		t.Errorf("hidden directory was indexed: %v", got)
+	}
+
+	watcher.View(func(corpus *Corpus) {
+		if n := len(corpus.Documents()); n != 4 {
+			t.Errorf("corpus has %d documents, want 4", n)
+		}
+	})
+}
+
+func TestWatchDir_Compact(t *testing.T) {
+	previous := WatchDebounce
+	WatchDebounce = 10 * time.Millisecond
+	defer func() { WatchDebounce = previous }()
+
+	dir := t.TempDir()
+	for _, name := range []string{"habeas.md", "calendar.md", "filing.md", "judges.md"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("Notes about "+name), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	watcher, err := WatchDir(dir, "", NewChunker())
+	if err != nil {
+		t.Fatalf("WatchDir() error = %v", err)
+	}
+	defer watcher.Close()
+
+	// each save of a file leaves its previous document behind as a tombstone
+	for i := range 10 {
+		word := fmt.Sprintf("revision%c", 'a'+i)
+		if err := os.WriteFile(filepath.Join(dir, "habeas.md"), []byte("Habeas "+word), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		deadline := time.Now().Add(5 * time.Second)
+		for len(watcher.Search(word, 10)) != 1 {
+			if time.Now().After(deadline) {
+				t.Fatalf("timed out waiting for save %d", i)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+
+	watcher.View(func(corpus *Corpus) {
+		if n := len(corpus.Documents()); n != 4 {
+			t.Errorf("corpus has %d documents, want 4", n)
+		}
+		if deleted := len(corpus.deleted); float64(deleted) >= WatchCompactRatio*float64(len(corpus.documents)) {
+			t.Errorf("corpus keeps %d tombstones in %d slots, want it compacted", deleted, len(corpus.documents))
+		}
+	})
+}
+
+// blockingFS holds up the first read of a file until release is closed, after
+// reading its content, to stand in for a slow reindex
+type blockingFS struct {
+	fs.FS
+	name    string
+	first   atomic.Bool
+	started chan struct{}
+	release chan struct{}
+}
+
+func (b *blockingFS) ReadFile(name string) ([]byte, error) {
+	content, err := fs.ReadFile(b.FS, name)
+	if name == b.name && b.first.CompareAndSwap(false, true) {
+		close(b.started)
+		<-b.release
+	}
+	return content, err
+}
+
+func TestWatchDir_SlowReindex(t *testing.T) {
+	previous := WatchDebounce
+	WatchDebounce = 10 * time.Millisecond
+	defer func() { WatchDebounce = previous }()
+
+	dir := t.TempDir()
+	for _, name := range []string{"calendar.md", "filing.md"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("Notes about "+name), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	watcher, err := WatchDir(dir, "", nil)
+	if err != nil {
+		t.Fatalf("WatchDir() error = %v", err)
+	}
+	defer watcher.Close()
+	blocking := &blockingFS{FS: watcher.fsys, name: "habeas.md", started: make(chan struct{}), release: make(chan struct{})}
+	watcher.pmu.Lock()
+	watcher.fsys = blocking
+	watcher.pmu.Unlock()
+
+	write := func(content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, "habeas.md"), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// the second save lands while the first is still being reindexed
+	write("Habeas draft.")
+	select {
+	case <-blocking.started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the first reindex")
+	}
+	write("Habeas final.")
+	time.Sleep(10 * WatchDebounce)
+	close(blocking.release)
+
+	settled := func() bool {
+		return len(watcher.Search("final", 10)) == 1 && len(watcher.Search("draft", 10)) == 0
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for !settled() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for the final save: draft %d, final %d", len(watcher.Search("draft", 10)), len(watcher.Search("final", 10)))
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	time.Sleep(10 * WatchDebounce)
+	if !settled() {
+		t.Errorf("stale save replaced the final one: draft %d, final %d", len(watcher.Search("draft", 10)), len(watcher.Search("final", 10)))
+	}
+}
+
+func TestWatcher_Close(t *testing.T) {
+	previous := WatchDebounce
+	WatchDebounce = 10 * time.Millisecond
+	defer func() { WatchDebounce = previous }()
+
+	dir := t.TempDir()
+	watcher, err := WatchDir(dir, "", NewChunker())
+	if err != nil {
+		t.Fatalf("WatchDir() error = %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "habeas.md"), []byte("Habeas corpus."), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := watcher.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if err := watcher.Close(); err != nil {
+		t.Errorf("second Close() error = %v", err)
+	}
+
+	// nothing is reindexed once Close returns
+	var before int
+	watcher.View(func(corpus *Corpus) { before = len(corpus.documents) })
+	if err := os.WriteFile(filepath.Join(dir, "filing.md"), []byte("Filing deadlines."), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(5 * WatchDebounce)
+	watcher.View(func(corpus *Corpus) {
+		if n := len(corpus.documents); n != before {
+			t.Errorf("corpus changed after Close: %d documents, had %d", n, before)
+		}
+	})
+}
@@ -0,0 +1,127 @@
+//go:build !bm25md_noparser
+
+package bm25md
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// obsidianExtension adds goldmark parsers for Obsidian wikilinks, embeds, and tags
+type obsidianExtension struct{}
+
+// Extend implements goldmark.Extender
+func (obsidianExtension) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(
+		// wikilinks must be tried before citations and links, which also trigger on '['
+		parser.WithInlineParsers(util.Prioritized(&wikiParser{}, 140)),
+	)
+}
+
+var (
+	kindWikiLink = ast.NewNodeKind("WikiLink")
+	kindTag      = ast.NewNodeKind("Tag")
+
+	// inlineWikiLinkRegex matches a [[link]] or ![[embed]] at the start of a line
+	inlineWikiLinkRegex = regexp.MustCompile(`^!?\[\[([^\[\]\n]+)\]\]`)
+	// inlineTagRegex matches a #tag at the start of a line
+	inlineTagRegex = regexp.MustCompile(`^#([\p{L}\p{N}_/-]+)`)
+)
+
+// wikiLinkNode is an inline [[Target#Heading|Alias]] link or ![[embed]]
+type wikiLinkNode struct {
+	ast.BaseInline
+	target     string // linked note (or embedded file)
+	display    string // text shown in place of the link
+	embed      bool
+	start, end int // byte range of the link in the source
+}
+
+// Kind implements ast.Node
+func (n *wikiLinkNode) Kind() ast.NodeKind {
+	return kindWikiLink
+}
+
+// Dump implements ast.Node
+func (n *wikiLinkNode) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{"Target": n.target, "Display": n.display}, nil)
+}
+
+// tagNode is an inline #tag, including nested #tags/like/this
+type tagNode struct {
+	ast.BaseInline
+	tag        string
+	start, end int // byte range of the tag in the source
+}
+
+// Kind implements ast.Node
+func (n *tagNode) Kind() ast.NodeKind {
+	return kindTag
+}
+
+// Dump implements ast.Node
+func (n *tagNode) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{"Tag": n.tag}, nil)
+}
+
+// wikiParser parses wikilinks, embeds, and tags
+type wikiParser struct{}
+
+// Trigger implements parser.InlineParser
+func (w *wikiParser) Trigger() []byte {
+	return []byte{'[', '!', '#'}
+}
+
+// Parse implements parser.InlineParser
+func (w *wikiParser) Parse(parent ast.Node, block text.Reader, pc parser.Context) ast.Node {
+	line, segment := block.PeekLine()
+	if len(line) == 0 {
+		return nil
+	}
+
+	if line[0] == '#' {
+		// tags must start a word and cannot be purely numeric (eg issue #42)
+		prev := block.PrecendingCharacter()
+		if unicode.IsLetter(prev) || unicode.IsDigit(prev) || prev == '#' || prev == '&' {
+			return nil
+		}
+		match := inlineTagRegex.FindSubmatchIndex(line)
+		if match == nil || !isVaultTag(string(line[match[2]:match[3]])) {
+			return nil
+		}
+		block.Advance(match[1])
+		return &tagNode{tag: string(line[match[2]:match[3]]), start: segment.Start, end: segment.Start + match[1]}
+	}
+
+	match := inlineWikiLinkRegex.FindSubmatchIndex(line)
+	if match == nil {
+		return nil
+	}
+	target, display := splitWikiLink(string(line[match[2]:match[3]]))
+	block.Advance(match[1])
+
+	node := &wikiLinkNode{
+		target:  target,
+		display: display,
+		embed:   line[0] == '!',
+		start:   segment.Start,
+		end:     segment.Start + match[1],
+	}
+	// display text stays readable inside headings and emphasis, which extract child text
+	if !node.embed {
+		node.AppendChild(node, ast.NewString([]byte(display)))
+	}
+	return node
+}
+
+// tagText returns a tag as indexable words, splitting nested tags into their segments
+func tagText(tag string) string {
+	return strings.ReplaceAll(strings.TrimPrefix(tag, "#"), "/", " ")
+}
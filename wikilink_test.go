@@ -0,0 +1,96 @@
+//go:build !bm25md_noparser
+
+package bm25md
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMarkdownFieldParser_Obsidian(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected map[Field]string
+	}{
+		{
+			name:  "wikilink",
+			input: "See [[Habeas Corpus]] first.",
+			expected: map[Field]string{
+				FieldBody: "See Habeas Corpus first.",
+				FieldLink: "Habeas Corpus",
+				FieldURL:  "Habeas Corpus",
+			},
+		},
+		{
+			name:  "alias and heading",
+			input: "Read [[Writs#Filing|the filing rules]] and [[Writs#Appeals]].",
+			expected: map[Field]string{
+				FieldBody: "Read the filing rules and Writs Appeals .",
+				FieldLink: "the filing rules Writs Appeals",
+				FieldURL:  "Writs Writs",
+			},
+		},
+		{
+			name:  "embed",
+			input: "Diagram: ![[court-map.png]]",
+			expected: map[Field]string{
+				FieldBody: "Diagram:",
+				FieldLink: "",
+				FieldURL:  "court-map.png",
+			},
+		},
+		{
+			name:  "tags",
+			input: "Filed #draft under #project/habeas, see issue #42 and a#b.",
+			expected: map[Field]string{
+				FieldBody: "Filed under , see issue #42 and a #b.",
+				FieldTags: "draft project habeas",
+			},
+		},
+		{
+			name:  "in headings and emphasis",
+			input: "# About [[Writs]]\n\n**Read [[Appeals]]**",
+			expected: map[Field]string{
+				FieldH1:   "About Writs",
+				FieldBold: "Read Appeals",
+			},
+		},
+		{
+			name:  "markdown links still work",
+			input: "A [normal link](https://example.com) and [[Note]].",
+			expected: map[Field]string{
+				FieldLink: "normal link Note",
+				FieldURL:  "https://example.com Note",
+			},
+		},
+	}
+
+	parser := NewMarkdownFieldParser(WithObsidian())
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := parser.ParseDocument(tt.input)
+			for field, expected := range tt.expected {
+				if got := normalizeWhitespace(result[field]); got != expected {
+					t.Errorf("%s field = %q, want %q", field, got, expected)
+				}
+			}
+			if strings.Contains(result[FieldBody], "[[") {
+				t.Errorf("body still contains wikilink brackets: %q", result[FieldBody])
+			}
+		})
+	}
+
+	// without the option, wikilinks and tags are left as body text
+	result := NewMarkdownFieldParser().ParseDocument("See [[Habeas Corpus]] #draft")
+	if result[FieldLink] != "" || result[FieldTags] != "" || !strings.Contains(result[FieldBody], "#draft") {
+		t.Errorf("default fields = %q, want wikilinks and tags untouched", result)
+	}
+}
+
+func TestMarkdownFieldParser_ObsidianStats(t *testing.T) {
+	stats := NewMarkdownFieldParser(WithObsidian()).ParseStats("[[One]], [[Two|2]], and ![[image.png]]")
+	if stats.Links != 2 {
+		t.Errorf("Links = %d, want 2", stats.Links)
+	}
+}